@@ -2,15 +2,22 @@
 
 import (
 	"context"
+	"strings"
 
 	"github.com/pkg/errors"
 
 	"github.com/kopia/kopia/internal/acl"
+	"github.com/kopia/kopia/internal/auth"
+	"github.com/kopia/kopia/internal/user"
 	"github.com/kopia/kopia/repo"
 	"github.com/kopia/kopia/repo/manifest"
 )
 
-var aclListCommand = aclCommands.Command("list", "List ACL entries").Alias("ls")
+var (
+	aclListCommand = aclCommands.Command("list", "List ACL entries").Alias("ls")
+	aclListUser    = aclListCommand.Flag("user", "Only list entries applicable to the provided user@hostname and show their effective access").String()
+	aclListGroups  = aclListCommand.Flag("group", "Additional groups the user belongs to, such as groups asserted by the identity provider").Strings()
+)
 
 func runACLList(ctx context.Context, rep repo.Repository) error {
 	var jl jsonList
@@ -23,20 +30,93 @@ func runACLList(ctx context.Context, rep repo.Repository) error {
 		return errors.Wrap(err, "error loading ACL entries")
 	}
 
+	var (
+		userSummary func()
+		effective   map[*acl.Entry]acl.AccessLevel
+	)
+
+	if *aclListUser != "" {
+		if entries, effective, userSummary, err = aclEntriesForUser(ctx, rep, entries, *aclListUser); err != nil {
+			return err
+		}
+	}
+
 	for _, e := range entries {
-		if jsonOutput {
-			jl.emit(aclListItem{e.ManifestID, e})
-		} else {
+		eff, hasEffective := effective[e]
+
+		switch {
+		case jsonOutput && hasEffective:
+			jl.emit(aclListItem{e.ManifestID, e, &eff})
+		case jsonOutput:
+			jl.emit(aclListItem{e.ManifestID, e, nil})
+		case hasEffective:
+			printStdout("id:%v user:%v access:%v target:%v effective:%v\n", e.ManifestID, e.User, e.Access, e.Target, eff)
+		default:
 			printStdout("id:%v user:%v access:%v target:%v\n", e.ManifestID, e.User, e.Access, e.Target)
 		}
 	}
 
+	if userSummary != nil && !jsonOutput {
+		userSummary()
+	}
+
 	return nil
 }
 
+// aclEntriesForUser returns ACL entries applicable to the provided user, taking into account groups the user
+// belongs to, the effective access of the user to the target of each entry, which may be higher than what
+// the entry itself grants, and a function that prints the summary of user's effective access.
+func aclEntriesForUser(ctx context.Context, rep repo.Repository, entries []*acl.Entry, usernameAtHostname string) ([]*acl.Entry, map[*acl.Entry]acl.AccessLevel, func(), error) {
+	parts := strings.Split(usernameAtHostname, "@")
+	if len(parts) != 2 { //nolint:gomnd
+		return nil, nil, nil, errors.Errorf("user must be specified as 'username@hostname'")
+	}
+
+	groupMap, err := user.LoadGroupMap(ctx, rep, nil)
+	if err != nil {
+		return nil, nil, nil, errors.Wrap(err, "error loading groups")
+	}
+
+	groups := auth.UserGroups(auth.ContextWithGroups(ctx, *aclListGroups), groupMap, usernameAtHostname)
+	authz := auth.DefaultAuthorizer().Authorize(auth.ContextWithGroups(ctx, groups), rep, usernameAtHostname)
+
+	summary := func() {
+		if len(entries) == 0 {
+			printStdout("No ACL entries defined, legacy access rules apply.\n")
+		}
+
+		printStdout("User %v, groups: [%v], content access: %v\n", usernameAtHostname, strings.Join(groups, ", "), authz.ContentAccessLevel())
+	}
+
+	applicable := acl.EntriesForUser(entries, parts[0], parts[1], groups...)
+	effective := map[*acl.Entry]acl.AccessLevel{}
+
+	for _, e := range applicable {
+		effective[e] = acl.EffectivePermissions(parts[0], parts[1], aclTargetForUser(e.Target, parts[0], parts[1]), entries, groups...)
+	}
+
+	return applicable, effective, summary, nil
+}
+
+// aclTargetForUser returns the labels of manifests targeted by the rule on behalf of the provided user,
+// with OWN_USER and OWN_HOST placeholders substituted.
+func aclTargetForUser(r acl.TargetRule, username, hostname string) map[string]string {
+	target := map[string]string{}
+
+	for k, v := range r {
+		v = strings.ReplaceAll(v, acl.OwnUser, username)
+		v = strings.ReplaceAll(v, acl.OwnHost, hostname)
+
+		target[k] = v
+	}
+
+	return target
+}
+
 type aclListItem struct {
 	ID manifest.ID `json:"id"`
 	*acl.Entry
+	EffectiveAccess *acl.AccessLevel `json:"effectiveAccess,omitempty"`
 }
 
 func init() {
@@ -2,12 +2,14 @@
 
 import (
 	"context"
+	"sync"
 	"sync/atomic"
 
 	"github.com/pkg/errors"
 
 	"github.com/kopia/kopia/repo"
 	"github.com/kopia/kopia/repo/blob"
+	"github.com/kopia/kopia/repo/blob/ecc"
 	"github.com/kopia/kopia/repo/content"
 )
 
@@ -17,6 +19,7 @@
 	contentVerifyParallel       = contentVerifyCommand.Flag("parallel", "Parallelism").Default("16").Int()
 	contentVerifyFull           = contentVerifyCommand.Flag("full", "Full verification (including download)").Bool()
 	contentVerifyIncludeDeleted = contentVerifyCommand.Flag("include-deleted", "Include deleted contents").Bool()
+	contentVerifyRepair         = contentVerifyCommand.Flag("repair", "Repair and rewrite corrupted pack blobs using error correction data").Bool()
 )
 
 func readBlobMap(ctx context.Context, br blob.Reader) (map[blob.ID]blob.Metadata, error) {
@@ -51,7 +54,12 @@ func runContentVerifyCommand(ctx context.Context, rep repo.DirectRepository) err
 		blobMap = m
 	}
 
-	var totalCount, successCount, errorCount int32
+	var (
+		totalCount, successCount, errorCount int32
+
+		mu        sync.Mutex
+		packBlobs = map[blob.ID]bool{}
+	)
 
 	log(ctx).Infof("Verifying all contents...")
 
@@ -60,6 +68,12 @@ func runContentVerifyCommand(ctx context.Context, rep repo.DirectRepository) err
 		Parallel:       *contentVerifyParallel,
 		IncludeDeleted: *contentVerifyIncludeDeleted,
 	}, func(ci content.Info) error {
+		if *contentVerifyRepair {
+			mu.Lock()
+			packBlobs[ci.GetPackBlobID()] = true
+			mu.Unlock()
+		}
+
 		if err := contentVerify(ctx, rep.ContentReader(), ci, blobMap); err != nil {
 			log(ctx).Errorf("error %v", err)
 			atomic.AddInt32(&errorCount, 1)
@@ -79,6 +93,12 @@ func runContentVerifyCommand(ctx context.Context, rep repo.DirectRepository) err
 
 	log(ctx).Infof("Finished verifying %v contents, found %v errors.", totalCount, errorCount)
 
+	if *contentVerifyRepair {
+		if err := repairPackBlobs(ctx, rep, packBlobs); err != nil {
+			return err
+		}
+	}
+
 	if errorCount == 0 {
 		return nil
 	}
@@ -86,6 +106,44 @@ func runContentVerifyCommand(ctx context.Context, rep repo.DirectRepository) err
 	return errors.Errorf("encountered %v errors", errorCount)
 }
 
+func repairPackBlobs(ctx context.Context, rep repo.DirectRepository, packBlobs map[blob.ID]bool) error {
+	log(ctx).Infof("Repairing %v pack blobs...", len(packBlobs))
+
+	var repairedCount, errorCount int
+
+	// nolint:wrapcheck
+	return repo.DirectWriteSession(ctx, rep, repo.WriteSessionOptions{
+		Purpose: "content verify --repair",
+	}, func(w repo.DirectRepositoryWriter) error {
+		for blobID := range packBlobs {
+			repaired, err := ecc.Repair(ctx, w.BlobStorage(), blobID)
+			if errors.Is(err, ecc.ErrNotEnabled) {
+				return errors.Errorf("repository does not use error correction, unable to repair")
+			}
+
+			if err != nil {
+				log(ctx).Errorf("unable to repair %v: %v", blobID, err)
+				errorCount++
+
+				continue
+			}
+
+			if repaired {
+				log(ctx).Infof("Repaired %v", blobID)
+				repairedCount++
+			}
+		}
+
+		log(ctx).Infof("Repaired %v pack blobs, %v could not be repaired.", repairedCount, errorCount)
+
+		if errorCount > 0 {
+			return errors.Errorf("unable to repair %v pack blobs", errorCount)
+		}
+
+		return nil
+	})
+}
+
 func contentVerify(ctx context.Context, r content.Reader, ci content.Info, blobMap map[blob.ID]blob.Metadata) error {
 	if *contentVerifyFull {
 		if _, err := r.GetContent(ctx, ci.GetContentID()); err != nil {
@@ -100,7 +158,7 @@ func contentVerify(ctx context.Context, r content.Reader, ci content.Info, blobM
 		return errors.Errorf("content %v depends on missing blob %v", ci.GetContentID(), ci.GetPackBlobID())
 	}
 
-	if int64(ci.GetPackOffset()+ci.GetPackedLength()) > bi.Length {
+	if int64(ci.GetPackOffset()+uint64(ci.GetPackedLength())) > bi.Length {
 		return errors.Errorf("content %v out of bounds of its pack blob %v", ci.GetContentID(), ci.GetPackBlobID())
 	}
 
@@ -0,0 +1,119 @@
+package cli
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/kopia/kopia/internal/user"
+	"github.com/kopia/kopia/repo"
+)
+
+var (
+	groupCommands = serverCommands.Command("groups", "Manage repository user groups, which can be referenced in ACL entries as 'group:name'").Alias("group")
+
+	groupListCommand = groupCommands.Command("list", "List user groups").Alias("ls")
+
+	groupAddCommand = groupCommands.Command("add", "Add users to a group, creating it if it does not exist")
+	groupAddName    = groupAddCommand.Arg("group", "Group name").Required().String()
+	groupAddMembers = groupAddCommand.Arg("member", "Usernames (user@hostname) to add").Strings()
+
+	groupRemoveCommand = groupCommands.Command("remove", "Remove users from a group")
+	groupRemoveName    = groupRemoveCommand.Arg("group", "Group name").Required().String()
+	groupRemoveMembers = groupRemoveCommand.Arg("member", "Usernames (user@hostname) to remove").Required().Strings()
+
+	groupDeleteCommand = groupCommands.Command("delete", "Delete group").Alias("rm")
+	groupDeleteName    = groupDeleteCommand.Arg("group", "Group name").Required().String()
+)
+
+func runGroupList(ctx context.Context, rep repo.Repository) error {
+	var jl jsonList
+
+	jl.begin()
+	defer jl.end()
+
+	groups, err := user.ListGroups(ctx, rep)
+	if err != nil {
+		return errors.Wrap(err, "error listing groups")
+	}
+
+	for _, g := range groups {
+		if jsonOutput {
+			jl.emit(g)
+		} else {
+			printStdout("%v: %v\n", g.Name, strings.Join(g.Members, " "))
+		}
+	}
+
+	return nil
+}
+
+func runGroupAdd(ctx context.Context, rep repo.RepositoryWriter) error {
+	g, err := user.GetGroup(ctx, rep, *groupAddName)
+
+	switch {
+	case errors.Is(err, user.ErrGroupNotFound):
+		g = &user.Group{Name: *groupAddName}
+
+	case err != nil:
+		return errors.Wrap(err, "error getting group")
+	}
+
+	for _, m := range *groupAddMembers {
+		if !g.HasMember(m) {
+			g.Members = append(g.Members, m)
+		}
+	}
+
+	if err := user.SetGroup(ctx, rep, g); err != nil {
+		return errors.Wrap(err, "error setting group")
+	}
+
+	log(ctx).Infof("Group %q has %v members.", g.Name, len(g.Members))
+
+	return nil
+}
+
+func runGroupRemove(ctx context.Context, rep repo.RepositoryWriter) error {
+	g, err := user.GetGroup(ctx, rep, *groupRemoveName)
+	if err != nil {
+		return errors.Wrap(err, "error getting group")
+	}
+
+	var members []string
+
+	for _, m := range g.Members {
+		if !containsString(*groupRemoveMembers, m) {
+			members = append(members, m)
+		}
+	}
+
+	g.Members = members
+
+	if err := user.SetGroup(ctx, rep, g); err != nil {
+		return errors.Wrap(err, "error setting group")
+	}
+
+	log(ctx).Infof("Group %q has %v members.", g.Name, len(g.Members))
+
+	return nil
+}
+
+func runGroupDelete(ctx context.Context, rep repo.RepositoryWriter) error {
+	if err := user.DeleteGroup(ctx, rep, *groupDeleteName); err != nil {
+		return errors.Wrap(err, "error deleting group")
+	}
+
+	log(ctx).Infof("Group %q deleted.", *groupDeleteName)
+
+	return nil
+}
+
+func init() {
+	registerJSONOutputFlags(groupListCommand)
+	groupListCommand.Action(repositoryReaderAction(runGroupList))
+	groupAddCommand.Action(repositoryWriterAction(runGroupAdd))
+	groupRemoveCommand.Action(repositoryWriterAction(runGroupRemove))
+	groupDeleteCommand.Action(repositoryWriterAction(runGroupDelete))
+}
@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"context"
+	"io/ioutil"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/kopia/kopia/notification/sender/command"
+	"github.com/kopia/kopia/notification/sender/email"
+	"github.com/kopia/kopia/notification/sender/webhook"
+	"github.com/kopia/kopia/repo"
+)
+
+var (
+	notificationConfigureCommands = notificationProfileCommands.Command("configure", "Create or update notification profile.").Alias("setup")
+
+	notificationConfigureEmailCommand      = notificationConfigureCommands.Command("email", "Send notifications using SMTP.")
+	notificationConfigureEmailFlags        notificationProfileFlags
+	notificationConfigureEmailSMTPServer   = notificationConfigureEmailCommand.Flag("smtp-server", "SMTP server").Required().String()
+	notificationConfigureEmailSMTPPort     = notificationConfigureEmailCommand.Flag("smtp-port", "SMTP port").Default("587").Int()
+	notificationConfigureEmailSMTPUsername = notificationConfigureEmailCommand.Flag("smtp-username", "SMTP username").String()
+	notificationConfigureEmailSMTPPassword = notificationConfigureEmailCommand.Flag("smtp-password", "SMTP password").Envar("KOPIA_SMTP_PASSWORD").String()
+	notificationConfigureEmailFrom         = notificationConfigureEmailCommand.Flag("mail-from", "Sender address").Required().String()
+	notificationConfigureEmailTo           = notificationConfigureEmailCommand.Flag("mail-to", "Recipient addresses (comma-separated)").Required().String()
+	notificationConfigureEmailTimeout      = notificationConfigureEmailCommand.Flag("timeout", "Timeout of sending the message").Default("1m").Duration()
+
+	notificationConfigureWebhookCommand      = notificationConfigureCommands.Command("webhook", "Send notifications using HTTP requests.")
+	notificationConfigureWebhookFlags        notificationProfileFlags
+	notificationConfigureWebhookEndpoint     = notificationConfigureWebhookCommand.Flag("endpoint", "Webhook URL").Required().String()
+	notificationConfigureWebhookMethod       = notificationConfigureWebhookCommand.Flag("method", "HTTP method").Default("POST").String()
+	notificationConfigureWebhookHeaders      = notificationConfigureWebhookCommand.Flag("header", "HTTP header to send (name:value)").Strings()
+	notificationConfigureWebhookTemplateFile = notificationConfigureWebhookCommand.Flag("template-file", "File containing Go template of the request body").ExistingFile()
+	notificationConfigureWebhookTimeout      = notificationConfigureWebhookCommand.Flag("timeout", "Request timeout").Default("1m").Duration()
+
+	notificationConfigureCommandCommand = notificationConfigureCommands.Command("command", "Send notifications by running a local command.")
+	notificationConfigureCommandFlags   notificationProfileFlags
+	notificationConfigureCommandPath    = notificationConfigureCommandCommand.Flag("command", "Command to run").Required().String()
+	notificationConfigureCommandArgs    = notificationConfigureCommandCommand.Flag("arg", "Command argument").Strings()
+	notificationConfigureCommandTimeout = notificationConfigureCommandCommand.Flag("timeout", "Command timeout").Default("1m").Duration()
+)
+
+func runNotificationConfigureEmail(ctx context.Context, rep repo.RepositoryWriter) error {
+	return notificationConfigureEmailFlags.save(ctx, rep, email.MethodEmail, &email.Options{
+		SMTPServer:   *notificationConfigureEmailSMTPServer,
+		SMTPPort:     *notificationConfigureEmailSMTPPort,
+		SMTPUsername: *notificationConfigureEmailSMTPUsername,
+		SMTPPassword: *notificationConfigureEmailSMTPPassword,
+		From:         *notificationConfigureEmailFrom,
+		To:           *notificationConfigureEmailTo,
+		Timeout:      *notificationConfigureEmailTimeout,
+	})
+}
+
+func runNotificationConfigureWebhook(ctx context.Context, rep repo.RepositoryWriter) error {
+	opt := &webhook.Options{
+		Endpoint: *notificationConfigureWebhookEndpoint,
+		Method:   *notificationConfigureWebhookMethod,
+		Headers:  map[string]string{},
+		Timeout:  *notificationConfigureWebhookTimeout,
+	}
+
+	for _, h := range *notificationConfigureWebhookHeaders {
+		parts := strings.SplitN(h, ":", 2) //nolint:gomnd
+		if len(parts) != 2 {               //nolint:gomnd
+			return errors.Errorf("invalid header %q, expected name:value", h)
+		}
+
+		opt.Headers[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	}
+
+	if fn := *notificationConfigureWebhookTemplateFile; fn != "" {
+		tmpl, err := ioutil.ReadFile(fn) //nolint:gosec
+		if err != nil {
+			return errors.Wrap(err, "unable to read template file")
+		}
+
+		opt.Template = string(tmpl)
+	}
+
+	return notificationConfigureWebhookFlags.save(ctx, rep, webhook.MethodWebhook, opt)
+}
+
+func runNotificationConfigureCommand(ctx context.Context, rep repo.RepositoryWriter) error {
+	return notificationConfigureCommandFlags.save(ctx, rep, command.MethodCommand, &command.Options{
+		Command: *notificationConfigureCommandPath,
+		Args:    *notificationConfigureCommandArgs,
+		Timeout: *notificationConfigureCommandTimeout,
+	})
+}
+
+func init() {
+	notificationConfigureEmailFlags.setup(notificationConfigureEmailCommand)
+	notificationConfigureEmailCommand.Action(repositoryWriterAction(runNotificationConfigureEmail))
+
+	notificationConfigureWebhookFlags.setup(notificationConfigureWebhookCommand)
+	notificationConfigureWebhookCommand.Action(repositoryWriterAction(runNotificationConfigureWebhook))
+
+	notificationConfigureCommandFlags.setup(notificationConfigureCommandCommand)
+	notificationConfigureCommandCommand.Action(repositoryWriterAction(runNotificationConfigureCommand))
+}
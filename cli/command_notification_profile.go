@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"context"
+
+	"github.com/alecthomas/kingpin"
+	"github.com/pkg/errors"
+
+	"github.com/kopia/kopia/notification/notifyprofile"
+	"github.com/kopia/kopia/notification/sender"
+	"github.com/kopia/kopia/repo"
+)
+
+var (
+	notificationCommands        = app.Command("notification", "Commands to manage notifications.").Alias("notifications")
+	notificationProfileCommands = notificationCommands.Command("profile", "Commands to manage notification profiles.").Alias("profiles")
+
+	notificationProfileListCommand = notificationProfileCommands.Command("list", "List notification profiles.").Alias("ls")
+
+	notificationProfileDeleteCommand = notificationProfileCommands.Command("delete", "Delete notification profile.").Alias("remove").Alias("rm")
+	notificationProfileDeleteName    = notificationProfileDeleteCommand.Arg("profile", "Profile name").Required().String()
+)
+
+func runNotificationProfileList(ctx context.Context, rep repo.Repository) error {
+	var jl jsonList
+
+	jl.begin()
+	defer jl.end()
+
+	profiles, err := notifyprofile.ListProfiles(ctx, rep)
+	if err != nil {
+		return errors.Wrap(err, "error listing notification profiles")
+	}
+
+	for _, pc := range profiles {
+		if jsonOutput {
+			redacted := *pc
+			redacted.MethodConfig = pc.MethodConfig.Redacted()
+
+			jl.emit(&redacted)
+
+			continue
+		}
+
+		s, err := sender.GetSender(ctx, pc.MethodConfig)
+		if err != nil {
+			printStdout("profile:%v method:%v min-severity:%v (invalid: %v)\n", pc.ProfileName, pc.MethodConfig.Type, pc.MinSeverity, err)
+			continue
+		}
+
+		printStdout("profile:%v method:%v min-severity:%v %v\n", pc.ProfileName, pc.MethodConfig.Type, pc.MinSeverity, s.Summary())
+	}
+
+	return nil
+}
+
+func runNotificationProfileDelete(ctx context.Context, rep repo.RepositoryWriter) error {
+	return errors.Wrap(notifyprofile.DeleteProfile(ctx, rep, *notificationProfileDeleteName), "error deleting notification profile")
+}
+
+// notificationProfileFlags holds flags common to all commands configuring notification profiles.
+type notificationProfileFlags struct {
+	profileName *string
+	minSeverity *string
+}
+
+func (f *notificationProfileFlags) setup(cmd *kingpin.CmdClause) {
+	f.profileName = cmd.Arg("profile", "Profile name").Required().String()
+	f.minSeverity = cmd.Flag("min-severity", "Minimum severity of messages sent to the profile").Default(sender.SeverityWarning.String()).Enum(sender.SeverityNames()...)
+}
+
+func (f *notificationProfileFlags) save(ctx context.Context, rep repo.RepositoryWriter, method sender.Method, options interface{}) error {
+	sev, err := sender.ParseSeverity(*f.minSeverity)
+	if err != nil {
+		return errors.Wrap(err, "invalid severity")
+	}
+
+	pc := &notifyprofile.Config{
+		ProfileName: *f.profileName,
+		MethodConfig: sender.MethodConfig{
+			Type:   method,
+			Config: options,
+		},
+		MinSeverity: sev,
+	}
+
+	if err := notifyprofile.SaveProfile(ctx, rep, pc); err != nil {
+		return errors.Wrap(err, "error saving notification profile")
+	}
+
+	log(ctx).Infof("Saved notification profile %q.", pc.ProfileName)
+
+	return nil
+}
+
+func init() {
+	registerJSONOutputFlags(notificationProfileListCommand)
+	notificationProfileListCommand.Action(repositoryReaderAction(runNotificationProfileList))
+	notificationProfileDeleteCommand.Action(repositoryWriterAction(runNotificationProfileDelete))
+}
@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/kopia/kopia/notification"
+	"github.com/kopia/kopia/notification/notifyprofile"
+	"github.com/kopia/kopia/notification/sender"
+	"github.com/kopia/kopia/repo"
+)
+
+var (
+	notificationTestCommand = notificationCommands.Command("test", "Send test notification.")
+	notificationTestProfile = notificationTestCommand.Flag("profile", "Profile to send test notification to, all profiles if not provided").String()
+)
+
+func runNotificationTest(ctx context.Context, rep repo.Repository) error {
+	var profiles []*notifyprofile.Config
+
+	if *notificationTestProfile != "" {
+		pc, err := notifyprofile.GetProfile(ctx, rep, *notificationTestProfile)
+		if err != nil {
+			return errors.Wrapf(err, "unable to get notification profile %q", *notificationTestProfile)
+		}
+
+		profiles = append(profiles, pc)
+	} else {
+		var err error
+
+		if profiles, err = notifyprofile.ListProfiles(ctx, rep); err != nil {
+			return errors.Wrap(err, "unable to list notification profiles")
+		}
+	}
+
+	if len(profiles) == 0 {
+		return errors.Errorf("no notification profiles configured")
+	}
+
+	msg := notification.NewMessage(rep, notification.EventTest, sender.SeverityVerbose,
+		"Test notification", "This is a test notification sent by 'kopia notification test'.\n")
+
+	var failed int
+
+	for _, pc := range profiles {
+		if err := notification.SendToProfile(ctx, rep, pc, msg); err != nil {
+			log(ctx).Errorf("Unable to send test notification to profile %q: %v", pc.ProfileName, err)
+
+			failed++
+
+			continue
+		}
+
+		log(ctx).Infof("Sent test notification to profile %q.", pc.ProfileName)
+	}
+
+	if failed > 0 {
+		return errors.Errorf("failed to send %v test notifications", failed)
+	}
+
+	return nil
+}
+
+func init() {
+	notificationTestCommand.Action(repositoryReaderAction(runNotificationTest))
+}
@@ -47,7 +47,13 @@
   # Snapshot scheduling options. Options include:
   #   "intervalSeconds": number /* 86400-day, 3600-hour, 60-minute */
   #   "timeOfDay": [{"hour":H,"min":M},{"hour":H,"min":M}]
-  #   "manual": false /* Only create snapshots manually if set to true. NOTE: cannot be used with the above two fields */
+  #   "cron": ["minute hour day-of-month month day-of-week", ...] /* e.g. "0 2 * * mon-fri" or "0 3 * * sun#1" */
+  #   "jitterSeconds": number /* Maximum random delay of snapshots scheduled using cron expressions */
+  #   "runMissed": true /* Take snapshots missed while the machine was asleep or not running */
+  #   "maxStalenessSeconds": number /* Do not take missed snapshots older than this */
+  #   "manual": false /* Only create snapshots manually if set to true. NOTE: cannot be used with the above fields */
+  #   "bandwidth": [{"days":[1,2,3,4,5],"start":{"hour":H,"min":M},"end":{"hour":H,"min":M},"maxUploadSpeedBytesPerSecond":number}]
+  #      /* Maximum upload rate during time windows, days are 0-Sunday..6-Saturday, omit days for every day, zero rate is unlimited */
 `
 
 var (
@@ -24,6 +24,14 @@
 	policyOneFileSystem = policySetCommand.Flag("one-file-system", "Stay in parent filesystem when finding files ('true', 'false', 'inherit')").Enum(booleanEnumValues...)
 
 	policyIgnoreCacheDirs = policySetCommand.Flag("ignore-cache-dirs", "Ignore cache directories ('true', 'false', 'inherit')").Enum(booleanEnumValues...)
+
+	// Extended attribute namespaces to capture or exclude.
+	policySetAddXattrNamespace            = policySetCommand.Flag("add-xattr-namespace", "List of extended attribute namespaces to capture ('*' for all)").PlaceHolder("NAMESPACE").Strings()
+	policySetRemoveXattrNamespace         = policySetCommand.Flag("remove-xattr-namespace", "List of extended attribute namespaces to stop capturing").PlaceHolder("NAMESPACE").Strings()
+	policySetClearXattrNamespaces         = policySetCommand.Flag("clear-xattr-namespaces", "Clear list of extended attribute namespaces to capture").Bool()
+	policySetAddExcludedXattrNamespace    = policySetCommand.Flag("add-exclude-xattr-namespace", "List of extended attribute namespaces to exclude ('*' for all)").PlaceHolder("NAMESPACE").Strings()
+	policySetRemoveExcludedXattrNamespace = policySetCommand.Flag("remove-exclude-xattr-namespace", "List of extended attribute namespaces to stop excluding").PlaceHolder("NAMESPACE").Strings()
+	policySetClearExcludedXattrNamespaces = policySetCommand.Flag("clear-exclude-xattr-namespaces", "Clear list of excluded extended attribute namespaces").Bool()
 )
 
 func setFilesPolicyFromFlags(ctx context.Context, fp *policy.FilesPolicy, changeCount *int) error {
@@ -33,6 +41,8 @@ func setFilesPolicyFromFlags(ctx context.Context, fp *policy.FilesPolicy, change
 
 	applyPolicyStringList(ctx, "dot-ignore filenames", &fp.DotIgnoreFiles, *policySetAddDotIgnore, *policySetRemoveDotIgnore, *policySetClearDotIgnore, changeCount)
 	applyPolicyStringList(ctx, "ignore rules", &fp.IgnoreRules, *policySetAddIgnore, *policySetRemoveIgnore, *policySetClearIgnore, changeCount)
+	applyPolicyStringList(ctx, "extended attribute namespaces", &fp.ExtendedAttributeNamespaces, *policySetAddXattrNamespace, *policySetRemoveXattrNamespace, *policySetClearXattrNamespaces, changeCount)
+	applyPolicyStringList(ctx, "excluded extended attribute namespaces", &fp.ExcludedExtendedAttributeNamespaces, *policySetAddExcludedXattrNamespace, *policySetRemoveExcludedXattrNamespace, *policySetClearExcludedXattrNamespaces, changeCount)
 
 	if err := applyPolicyBoolPtr(ctx, "ignore cache dirs", &fp.IgnoreCacheDirs, *policyIgnoreCacheDirs, changeCount); err != nil {
 		return err
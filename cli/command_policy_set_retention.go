@@ -2,7 +2,12 @@
 
 import (
 	"context"
+	"strconv"
+	"time"
 
+	"github.com/pkg/errors"
+
+	"github.com/kopia/kopia/internal/units"
 	"github.com/kopia/kopia/snapshot/policy"
 )
 
@@ -13,6 +18,9 @@
 	policySetKeepWeekly  = policySetCommand.Flag("keep-weekly", "Number of most-recent weekly backups to keep per source (or 'inherit')").PlaceHolder("N").String()
 	policySetKeepMonthly = policySetCommand.Flag("keep-monthly", "Number of most-recent monthly backups to keep per source (or 'inherit')").PlaceHolder("N").String()
 	policySetKeepAnnual  = policySetCommand.Flag("keep-annual", "Number of most-recent annual backups to keep per source (or 'inherit')").PlaceHolder("N").String()
+
+	policySetKeepWithin             = policySetCommand.Flag("keep-within", "Keep all backups younger than the provided duration, e.g. '720h' (or 'inherit')").PlaceHolder("DURATION").String()
+	policySetMaxRetainedUniqueBytes = policySetCommand.Flag("max-retained-unique-bytes", "Maximum number of bytes of unique files retained per source, 0 means unlimited (or 'inherit')").PlaceHolder("N").String()
 )
 
 func setRetentionPolicyFromFlags(ctx context.Context, rp *policy.RetentionPolicy, changeCount *int) error {
@@ -35,5 +43,68 @@ func setRetentionPolicyFromFlags(ctx context.Context, rp *policy.RetentionPolicy
 		}
 	}
 
+	if err := applyPolicyDurationSecondsPtr(ctx, "duration to keep all backups within", &rp.KeepWithinSeconds, *policySetKeepWithin, changeCount); err != nil {
+		return err
+	}
+
+	return applyPolicyBytesPtr(ctx, "max retained unique bytes", &rp.MaxRetainedUniqueBytes, *policySetMaxRetainedUniqueBytes, changeCount)
+}
+
+func applyPolicyDurationSecondsPtr(ctx context.Context, desc string, val **int64, str string, changeCount *int) error {
+	if str == "" {
+		// not changed
+		return nil
+	}
+
+	if str == inheritPolicyString || str == defaultPolicyString {
+		*changeCount++
+
+		log(ctx).Infof(" - resetting %q to a default value inherited from parent.\n", desc)
+
+		*val = nil
+
+		return nil
+	}
+
+	d, err := time.ParseDuration(str)
+	if err != nil {
+		return errors.Wrapf(err, "can't parse the %v %q", desc, str)
+	}
+
+	v := int64(d.Seconds())
+	*changeCount++
+
+	log(ctx).Infof(" - setting %q to %v.\n", desc, time.Duration(v)*time.Second)
+	*val = &v
+
+	return nil
+}
+
+func applyPolicyBytesPtr(ctx context.Context, desc string, val **int64, str string, changeCount *int) error {
+	if str == "" {
+		// not changed
+		return nil
+	}
+
+	if str == inheritPolicyString || str == defaultPolicyString {
+		*changeCount++
+
+		log(ctx).Infof(" - resetting %q to a default value inherited from parent.\n", desc)
+
+		*val = nil
+
+		return nil
+	}
+
+	v, err := strconv.ParseInt(str, 10, 64)
+	if err != nil {
+		return errors.Wrapf(err, "can't parse the %v %q", desc, str)
+	}
+
+	*changeCount++
+
+	log(ctx).Infof(" - setting %q to %v.\n", desc, units.BytesStringBase10(v))
+	*val = &v
+
 	return nil
 }
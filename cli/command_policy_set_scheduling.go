@@ -13,10 +13,37 @@
 	// Frequency.
 	policySetInterval   = policySetCommand.Flag("snapshot-interval", "Interval between snapshots").DurationList()
 	policySetTimesOfDay = policySetCommand.Flag("snapshot-time", "Times of day when to take snapshot (HH:mm)").Strings()
+	policySetCron       = policySetCommand.Flag("snapshot-cron", "Cron expressions when to take snapshots (minute hour day-of-month month day-of-week), e.g. '0 2 * * mon-fri'").Strings()
+	policySetJitter     = policySetCommand.Flag("snapshot-jitter", "Maximum random delay of snapshots scheduled using cron expressions").DurationList()
 	policySetManual     = policySetCommand.Flag("manual", "Only create snapshots manually").Bool()
+
+	// Missed snapshots.
+	policySetRunMissed    = policySetCommand.Flag("run-missed", "Take snapshots missed while the machine was asleep or not running ('true', 'false', 'inherit')").Enum(booleanEnumValues...)
+	policySetMaxStaleness = policySetCommand.Flag("max-staleness", "Do not take missed snapshots that are older than the provided duration").DurationList()
+
+	// Bandwidth.
+	policySetUploadBandwidth = policySetCommand.Flag("upload-bandwidth", "Maximum upload rate during a time window ([DAYS] HH:mm-HH:mm BYTES_PER_SECOND|unlimited), e.g. 'mon-fri 9:00-17:00 1048576'").Strings()
 )
 
 func setSchedulingPolicyFromFlags(ctx context.Context, sp *policy.SchedulingPolicy, changeCount *int) error {
+	if err := setUploadBandwidthFromFlags(ctx, sp, changeCount); err != nil {
+		return err
+	}
+
+	if err := applyPolicyBoolPtr(ctx, "run missed snapshots", &sp.RunMissed, *policySetRunMissed, changeCount); err != nil {
+		return errors.Wrap(err, "run missed snapshots")
+	}
+
+	// It's not really a list, just optional value.
+	for _, staleness := range *policySetMaxStaleness {
+		*changeCount++
+
+		sp.MaxStalenessSeconds = int64(staleness.Seconds())
+		log(ctx).Infof(" - setting max staleness of missed snapshots to %v\n", sp.MaxStaleness())
+
+		break
+	}
+
 	if *policySetManual {
 		return setManualFromFlags(ctx, sp, changeCount)
 	}
@@ -24,6 +51,40 @@ func setSchedulingPolicyFromFlags(ctx context.Context, sp *policy.SchedulingPoli
 	return setScheduleFromFlags(ctx, sp, changeCount)
 }
 
+func setUploadBandwidthFromFlags(ctx context.Context, sp *policy.SchedulingPolicy, changeCount *int) error {
+	if len(*policySetUploadBandwidth) == 0 {
+		return nil
+	}
+
+	var windows []policy.BandwidthWindow
+
+	for _, ws := range *policySetUploadBandwidth {
+		if ws == inheritPolicyString {
+			windows = nil
+			break
+		}
+
+		w, err := policy.ParseBandwidthWindow(ws)
+		if err != nil {
+			return errors.Wrap(err, "unable to parse upload bandwidth window")
+		}
+
+		windows = append(windows, w)
+	}
+
+	*changeCount++
+
+	sp.BandwidthWindows = windows
+
+	if windows == nil {
+		log(ctx).Infof(" - resetting upload bandwidth windows to default\n")
+	} else {
+		log(ctx).Infof(" - setting upload bandwidth windows to %v\n", windows)
+	}
+
+	return nil
+}
+
 func setScheduleFromFlags(ctx context.Context, sp *policy.SchedulingPolicy, changeCount *int) error {
 	// It's not really a list, just optional value.
 	for _, interval := range *policySetInterval {
@@ -64,6 +125,10 @@ func setScheduleFromFlags(ctx context.Context, sp *policy.SchedulingPolicy, chan
 		}
 	}
 
+	if err := setCronFromFlags(ctx, sp, changeCount); err != nil {
+		return err
+	}
+
 	if sp.Manual {
 		*changeCount++
 
@@ -75,9 +140,50 @@ func setScheduleFromFlags(ctx context.Context, sp *policy.SchedulingPolicy, chan
 	return nil
 }
 
+func setCronFromFlags(ctx context.Context, sp *policy.SchedulingPolicy, changeCount *int) error {
+	if len(*policySetCron) > 0 {
+		var cron []string
+
+		for _, expr := range *policySetCron {
+			if expr == inheritPolicyString {
+				cron = nil
+				break
+			}
+
+			if err := policy.ValidateCronExpression(expr); err != nil {
+				return errors.Wrap(err, "unable to parse cron expression")
+			}
+
+			cron = append(cron, expr)
+		}
+
+		*changeCount++
+
+		sp.Cron = cron
+
+		if cron == nil {
+			log(ctx).Infof(" - resetting snapshot cron expressions to default\n")
+		} else {
+			log(ctx).Infof(" - setting snapshot cron expressions to %q\n", cron)
+		}
+	}
+
+	// It's not really a list, just optional value.
+	for _, jitter := range *policySetJitter {
+		*changeCount++
+
+		sp.JitterSeconds = int64(jitter.Seconds())
+		log(ctx).Infof(" - setting snapshot jitter to %v\n", sp.Jitter())
+
+		break
+	}
+
+	return nil
+}
+
 func setManualFromFlags(ctx context.Context, sp *policy.SchedulingPolicy, changeCount *int) error {
 	// Cannot set both schedule and manual setting
-	if len(*policySetInterval) > 0 || len(*policySetTimesOfDay) > 0 {
+	if len(*policySetInterval) > 0 || len(*policySetTimesOfDay) > 0 || len(*policySetCron) > 0 {
 		return errors.New("cannot set manual field when scheduling snapshots")
 	}
 
@@ -98,6 +204,14 @@ func setManualFromFlags(ctx context.Context, sp *policy.SchedulingPolicy, change
 		log(ctx).Infof(" - resetting snapshot times of day to default\n")
 	}
 
+	if len(sp.Cron) > 0 {
+		*changeCount++
+
+		sp.Cron = nil
+
+		log(ctx).Infof(" - resetting snapshot cron expressions to default\n")
+	}
+
 	*changeCount++
 
 	sp.Manual = *policySetManual
@@ -122,6 +122,22 @@ func printRetentionPolicy(p *policy.Policy, parents []*policy.Policy) {
 		getDefinitionPoint(p.Target(), parents, func(pol *policy.Policy) bool {
 			return pol.RetentionPolicy.KeepLatest != nil
 		}))
+
+	if p.RetentionPolicy.KeepWithin() > 0 {
+		printStdout("  Keep all within:   %v   %v\n",
+			p.RetentionPolicy.KeepWithin(),
+			getDefinitionPoint(p.Target(), parents, func(pol *policy.Policy) bool {
+				return pol.RetentionPolicy.KeepWithinSeconds != nil
+			}))
+	}
+
+	if m := p.RetentionPolicy.MaxRetainedUniqueBytes; m != nil && *m > 0 {
+		printStdout("  Max unique bytes:  %v   %v\n",
+			units.BytesStringBase10(*m),
+			getDefinitionPoint(p.Target(), parents, func(pol *policy.Policy) bool {
+				return pol.RetentionPolicy.MaxRetainedUniqueBytes != nil
+			}))
+	}
 }
 
 func printFilesPolicy(p *policy.Policy, parents []*policy.Policy) {
@@ -170,6 +186,30 @@ func printFilesPolicy(p *policy.Policy, parents []*policy.Policy) {
 		getDefinitionPoint(p.Target(), parents, func(pol *policy.Policy) bool {
 			return pol.FilesPolicy.OneFileSystem != nil
 		}))
+
+	if len(p.FilesPolicy.ExtendedAttributeNamespaces) > 0 {
+		printStdout("  Capture extended attributes in namespaces:\n")
+	} else {
+		printStdout("  Capture extended attributes in all namespaces.\n")
+	}
+
+	for _, ns := range p.FilesPolicy.ExtendedAttributeNamespaces {
+		ns := ns
+		printStdout("    %-30v %v\n", ns, getDefinitionPoint(p.Target(), parents, func(pol *policy.Policy) bool {
+			return containsString(pol.FilesPolicy.ExtendedAttributeNamespaces, ns)
+		}))
+	}
+
+	if len(p.FilesPolicy.ExcludedExtendedAttributeNamespaces) > 0 {
+		printStdout("  Exclude extended attributes in namespaces:\n")
+	}
+
+	for _, ns := range p.FilesPolicy.ExcludedExtendedAttributeNamespaces {
+		ns := ns
+		printStdout("    %-30v %v\n", ns, getDefinitionPoint(p.Target(), parents, func(pol *policy.Policy) bool {
+			return containsString(pol.FilesPolicy.ExcludedExtendedAttributeNamespaces, ns)
+		}))
+	}
 }
 
 func printErrorHandlingPolicy(p *policy.Policy, parents []*policy.Policy) {
@@ -228,6 +268,24 @@ func printSchedulingPolicy(p *policy.Policy, parents []*policy.Policy) {
 		any = true
 	}
 
+	if len(p.SchedulingPolicy.Cron) > 0 {
+		printStdout("    Snapshot cron expressions:       %v\n", getDefinitionPoint(p.Target(), parents, func(pol *policy.Policy) bool {
+			return len(pol.SchedulingPolicy.Cron) > 0
+		}))
+
+		for _, expr := range p.SchedulingPolicy.Cron {
+			printStdout("      %v\n", expr)
+		}
+
+		if p.SchedulingPolicy.Jitter() != 0 {
+			printStdout("    Snapshot jitter:     %10v  %v\n", p.SchedulingPolicy.Jitter(), getDefinitionPoint(p.Target(), parents, func(pol *policy.Policy) bool {
+				return pol.SchedulingPolicy.JitterSeconds != 0
+			}))
+		}
+
+		any = true
+	}
+
 	if !any {
 		printStdout("    None\n")
 	}
@@ -237,6 +295,30 @@ func printSchedulingPolicy(p *policy.Policy, parents []*policy.Policy) {
 		getDefinitionPoint(p.Target(), parents, func(pol *policy.Policy) bool {
 			return pol.SchedulingPolicy.Manual
 		}))
+
+	printStdout("  Run missed snapshots:      %5v   %v\n",
+		p.SchedulingPolicy.RunMissedOrDefault(false),
+		getDefinitionPoint(p.Target(), parents, func(pol *policy.Policy) bool {
+			return pol.SchedulingPolicy.RunMissed != nil
+		}))
+
+	if p.SchedulingPolicy.MaxStaleness() != 0 {
+		printStdout("  Max staleness:        %10v   %v\n",
+			p.SchedulingPolicy.MaxStaleness(),
+			getDefinitionPoint(p.Target(), parents, func(pol *policy.Policy) bool {
+				return pol.SchedulingPolicy.MaxStalenessSeconds != 0
+			}))
+	}
+
+	if len(p.SchedulingPolicy.BandwidthWindows) > 0 {
+		printStdout("  Upload bandwidth:                  %v\n", getDefinitionPoint(p.Target(), parents, func(pol *policy.Policy) bool {
+			return len(pol.SchedulingPolicy.BandwidthWindows) > 0
+		}))
+
+		for _, w := range p.SchedulingPolicy.BandwidthWindows {
+			printStdout("    %v\n", w)
+		}
+	}
 }
 
 func printCompressionPolicy(p *policy.Policy, parents []*policy.Policy) {
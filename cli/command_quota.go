@@ -0,0 +1,174 @@
+package cli
+
+import (
+	"context"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/kopia/kopia/internal/quota"
+	"github.com/kopia/kopia/internal/units"
+	"github.com/kopia/kopia/repo"
+)
+
+var (
+	quotaCommands = serverCommands.Command("quota", "Manage storage quotas of users ('user@hostname') and hosts ('*@hostname'), changes take effect when the server refreshes").Alias("quotas")
+
+	quotaListCommand = quotaCommands.Command("list", "List quotas and storage usage").Alias("ls")
+
+	quotaSetCommand  = quotaCommands.Command("set", "Set storage quota")
+	quotaSetSubject  = quotaSetCommand.Arg("subject", "User (user@hostname) or host (*@hostname)").Required().String()
+	quotaSetMaxBytes = quotaSetCommand.Arg("limit", "Maximum number of bytes of new contents (e.g. 100GB)").Required().Bytes()
+
+	quotaDeleteCommand = quotaCommands.Command("delete", "Delete storage quota").Alias("rm")
+	quotaDeleteSubject = quotaDeleteCommand.Arg("subject", "User (user@hostname) or host (*@hostname)").Required().String()
+
+	quotaResetCommand  = quotaCommands.Command("reset", "Reset accounted storage usage to zero")
+	quotaResetSubjects = quotaResetCommand.Arg("subject", "Users (user@hostname) or hosts (*@hostname)").Strings()
+	quotaResetAll      = quotaResetCommand.Flag("all", "Reset usage of all users and hosts").Bool()
+
+	quotaRecomputeCommand = quotaCommands.Command("recompute", "Recompute storage usage from contents referenced by snapshots of each user")
+)
+
+type quotaListEntry struct {
+	Subject  string `json:"subject"`
+	MaxBytes int64  `json:"maxBytes,omitempty"`
+	Bytes    int64  `json:"bytes"`
+}
+
+func runQuotaList(ctx context.Context, rep repo.Repository) error {
+	limits, err := quota.ListLimits(ctx, rep)
+	if err != nil {
+		return errors.Wrap(err, "error listing quotas")
+	}
+
+	usage, err := quota.ListUsage(ctx, rep)
+	if err != nil {
+		return errors.Wrap(err, "error listing usage")
+	}
+
+	entries := map[string]*quotaListEntry{}
+
+	entryFor := func(subject string) *quotaListEntry {
+		if entries[subject] == nil {
+			entries[subject] = &quotaListEntry{Subject: subject}
+		}
+
+		return entries[subject]
+	}
+
+	for _, l := range limits {
+		entryFor(l.Subject).MaxBytes = l.MaxBytes
+	}
+
+	for _, u := range usage {
+		entryFor(u.Subject).Bytes = u.Bytes
+	}
+
+	var sorted []*quotaListEntry
+	for _, e := range entries {
+		sorted = append(sorted, e)
+	}
+
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Subject < sorted[j].Subject
+	})
+
+	var jl jsonList
+
+	jl.begin()
+	defer jl.end()
+
+	for _, e := range sorted {
+		switch {
+		case jsonOutput:
+			jl.emit(e)
+		case e.MaxBytes > 0:
+			printStdout("%-40v %10v of %v\n", e.Subject, units.BytesStringBase10(e.Bytes), units.BytesStringBase10(e.MaxBytes))
+		default:
+			printStdout("%-40v %10v (no quota)\n", e.Subject, units.BytesStringBase10(e.Bytes))
+		}
+	}
+
+	return nil
+}
+
+func runQuotaSet(ctx context.Context, rep repo.RepositoryWriter) error {
+	l := &quota.Limit{
+		Subject:  *quotaSetSubject,
+		MaxBytes: int64(*quotaSetMaxBytes),
+	}
+
+	if err := quota.SetLimit(ctx, rep, l); err != nil {
+		return errors.Wrap(err, "error setting quota")
+	}
+
+	log(ctx).Infof("Set quota of %v to %v.", l.Subject, units.BytesStringBase10(l.MaxBytes))
+
+	return nil
+}
+
+func runQuotaDelete(ctx context.Context, rep repo.RepositoryWriter) error {
+	if err := quota.DeleteLimit(ctx, rep, *quotaDeleteSubject); err != nil {
+		return errors.Wrap(err, "error deleting quota")
+	}
+
+	log(ctx).Infof("Quota of %v deleted.", *quotaDeleteSubject)
+
+	return nil
+}
+
+func runQuotaReset(ctx context.Context, rep repo.RepositoryWriter) error {
+	subjects := *quotaResetSubjects
+
+	if *quotaResetAll {
+		usage, err := quota.ListUsage(ctx, rep)
+		if err != nil {
+			return errors.Wrap(err, "error listing usage")
+		}
+
+		for _, u := range usage {
+			subjects = append(subjects, u.Subject)
+		}
+	}
+
+	if len(subjects) == 0 {
+		return errors.Errorf("must specify users or hosts to reset or pass --all")
+	}
+
+	for _, s := range subjects {
+		if err := quota.ValidateSubject(s); err != nil {
+			return err
+		}
+
+		if err := quota.SetUsage(ctx, rep, &quota.Usage{Subject: s, ComputedTime: rep.Time()}); err != nil {
+			return errors.Wrapf(err, "error resetting usage of %v", s)
+		}
+
+		log(ctx).Infof("Usage of %v reset.", s)
+	}
+
+	return nil
+}
+
+func runQuotaRecompute(ctx context.Context, rep repo.DirectRepositoryWriter) error {
+	usage, err := quota.Recompute(ctx, rep)
+	if err != nil {
+		return errors.Wrap(err, "error recomputing usage")
+	}
+
+	for _, u := range usage {
+		log(ctx).Infof("%v: %v", u.Subject, units.BytesStringBase10(u.Bytes))
+	}
+
+	return nil
+}
+
+func init() {
+	registerJSONOutputFlags(quotaListCommand)
+	quotaListCommand.Action(repositoryReaderAction(runQuotaList))
+	quotaSetCommand.Action(repositoryWriterAction(runQuotaSet))
+	quotaDeleteCommand.Action(repositoryWriterAction(runQuotaDelete))
+	quotaResetCommand.Action(repositoryWriterAction(runQuotaReset))
+	quotaRecomputeCommand.Action(directRepositoryWriteAction(runQuotaRecompute))
+}
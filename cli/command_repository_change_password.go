@@ -0,0 +1,41 @@
+package cli
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/kopia/kopia/repo"
+)
+
+var (
+	changePasswordCommand     = repositoryCommands.Command("change-password", "Change repository password.")
+	changePasswordNewPassword = changePasswordCommand.Flag("new-password", "New password.").Envar("KOPIA_NEW_PASSWORD").String()
+)
+
+func runChangePasswordCommand(ctx context.Context, rep repo.DirectRepositoryWriter) error {
+	newPass := strings.TrimSpace(*changePasswordNewPassword)
+
+	if newPass == "" {
+		p, err := askForNewPassword("Enter new password: ")
+		if err != nil {
+			return errors.Wrap(err, "error getting new password")
+		}
+
+		newPass = p
+	}
+
+	if err := rep.ChangePassword(ctx, newPass); err != nil {
+		return errors.Wrap(err, "unable to change password")
+	}
+
+	log(ctx).Infof("Password changed.")
+	log(ctx).Infof("Other clients will be asked for the new password when they next open the repository.")
+
+	return nil
+}
+
+func init() {
+	changePasswordCommand.Action(directRepositoryWriteAction(runChangePasswordCommand))
+}
@@ -25,6 +25,7 @@
 	connectReadonly               bool
 	connectDescription            string
 	connectEnableActions          bool
+	connectEnableNotifyCommands   bool
 )
 
 func setupConnectOptions(cmd *kingpin.CmdClause) {
@@ -41,6 +42,7 @@ func setupConnectOptions(cmd *kingpin.CmdClause) {
 	cmd.Flag("readonly", "Make repository read-only to avoid accidental changes").BoolVar(&connectReadonly)
 	cmd.Flag("description", "Human-readable description of the repository").StringVar(&connectDescription)
 	cmd.Flag("enable-actions", "Allow snapshot actions").BoolVar(&connectEnableActions)
+	cmd.Flag("enable-notification-commands", "Allow notification profiles to run local commands").BoolVar(&connectEnableNotifyCommands)
 }
 
 func connectOptions() *repo.ConnectOptions {
@@ -53,11 +55,12 @@ func connectOptions() *repo.ConnectOptions {
 			MaxListCacheDurationSec:   int(connectMaxListCacheDuration.Seconds()),
 		},
 		ClientOptions: repo.ClientOptions{
-			Hostname:      connectHostname,
-			Username:      connectUsername,
-			ReadOnly:      connectReadonly,
-			Description:   connectDescription,
-			EnableActions: connectEnableActions,
+			Hostname:                   connectHostname,
+			Username:                   connectUsername,
+			ReadOnly:                   connectReadonly,
+			Description:                connectDescription,
+			EnableActions:              connectEnableActions,
+			EnableNotificationCommands: connectEnableNotifyCommands,
 		},
 	}
 }
@@ -2,6 +2,7 @@
 
 import (
 	"context"
+	"path/filepath"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -15,6 +16,8 @@
 	connectAPIServerURL             = connectAPIServerCommand.Flag("url", "Server URL").Required().String()
 	connectAPIServerCertFingerprint = connectAPIServerCommand.Flag("server-cert-fingerprint", "Server certificate fingerprint").String()
 	connectAPIServerUseGRPCAPI      = connectAPIServerCommand.Flag("grpc", "Use GRPC API").Default("true").Bool()
+	connectAPIServerClientCertFile  = connectAPIServerCommand.Flag("client-cert-file", "PEM file with TLS client certificate used to authenticate to the server").ExistingFile()
+	connectAPIServerClientKeyFile   = connectAPIServerCommand.Flag("client-key-file", "PEM file with TLS client key").ExistingFile()
 )
 
 func runConnectAPIServerCommand(ctx context.Context) error {
@@ -24,6 +27,23 @@ func runConnectAPIServerCommand(ctx context.Context) error {
 		DisableGRPC:                         !*connectAPIServerUseGRPCAPI,
 	}
 
+	if (*connectAPIServerClientCertFile == "") != (*connectAPIServerClientKeyFile == "") {
+		return errors.Errorf("both --client-cert-file and --client-key-file must be provided")
+	}
+
+	if *connectAPIServerClientCertFile != "" {
+		var err error
+
+		// store absolute paths, since the configuration is used from other directories.
+		if as.ClientCertificateFile, err = filepath.Abs(*connectAPIServerClientCertFile); err != nil {
+			return errors.Wrap(err, "invalid client certificate file")
+		}
+
+		if as.ClientKeyFile, err = filepath.Abs(*connectAPIServerClientKeyFile); err != nil {
+			return errors.Wrap(err, "invalid client key file")
+		}
+	}
+
 	configFile := repositoryConfigFileName()
 	opt := connectOptions()
 
@@ -39,7 +59,7 @@ func runConnectAPIServerCommand(ctx context.Context) error {
 
 	log(ctx).Infof("Connecting to server '%v' as '%v@%v'...", as.BaseURL, u, h)
 
-	password, err := getPasswordFromFlags(ctx, false, false)
+	password, err := getServerConnectionPassword(ctx, as)
 	if err != nil {
 		return errors.Wrap(err, "getting password")
 	}
@@ -54,6 +74,16 @@ func runConnectAPIServerCommand(ctx context.Context) error {
 	return nil
 }
 
+// getServerConnectionPassword returns the password for connecting to the server, which is not needed
+// when the client authenticates with a TLS certificate.
+func getServerConnectionPassword(ctx context.Context, as *repo.APIServerInfo) (string, error) {
+	if as.ClientCertificateFile != "" && *password == "" {
+		return "", nil
+	}
+
+	return getPasswordFromFlags(ctx, false, false)
+}
+
 func init() {
 	connectAPIServerCommand.Action(noRepositoryAction(runConnectAPIServerCommand))
 }
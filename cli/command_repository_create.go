@@ -2,11 +2,14 @@
 
 import (
 	"context"
+	"strconv"
 
 	"github.com/pkg/errors"
 
+	"github.com/kopia/kopia/internal/epoch"
 	"github.com/kopia/kopia/repo"
 	"github.com/kopia/kopia/repo/blob"
+	"github.com/kopia/kopia/repo/blob/ecc"
 	"github.com/kopia/kopia/repo/content"
 	"github.com/kopia/kopia/repo/encryption"
 	"github.com/kopia/kopia/repo/hashing"
@@ -21,6 +24,14 @@
 	createBlockHashFormat       = createCommand.Flag("block-hash", "Content hash algorithm.").PlaceHolder("ALGO").Default(hashing.DefaultAlgorithm).Enum(hashing.SupportedAlgorithms()...)
 	createBlockEncryptionFormat = createCommand.Flag("encryption", "Content encryption algorithm.").PlaceHolder("ALGO").Default(encryption.DefaultAlgorithm).Enum(encryption.SupportedAlgorithms(false)...)
 	createSplitter              = createCommand.Flag("object-splitter", "The splitter to use for new objects in the repository").Default(splitter.DefaultAlgorithm).Enum(splitter.SupportedAlgorithms()...)
+	createIndexEpochs           = createCommand.Flag("enable-index-epochs", "Use epoch-based index management").Bool()
+	createIndexVersion          = createCommand.Flag("index-version", "Pack index format version").Hidden().Int()
+	createECC                   = createCommand.Flag("enable-error-correction", "Protect repository blobs with Reed-Solomon error correction").Bool()
+	createECCDataShards         = createCommand.Flag("ecc-data-shards", "Number of data shards in each error correction stripe").Default(strconv.Itoa(ecc.DefaultDataShards)).Int()
+	createECCParityShards       = createCommand.Flag("ecc-parity-shards", "Number of parity shards in each error correction stripe").Default(strconv.Itoa(ecc.DefaultParityShards)).Int()
+	createECCShardSize          = createCommand.Flag("ecc-shard-size", "Size of each error correction shard").Default(strconv.Itoa(ecc.DefaultShardSize)).Int()
+	createRetentionMode         = createCommand.Flag("retention-mode", "Lock pack blobs using the specified retention mode").Enum(string(blob.RetentionModeGovernance), string(blob.RetentionModeCompliance))
+	createRetentionPeriod       = createCommand.Flag("retention-period", "Period for which pack blobs remain locked").Duration()
 
 	createOnly = createCommand.Flag("create-only", "Create repository, but don't connect to it.").Short('c').Bool()
 )
@@ -30,7 +41,7 @@ func init() {
 }
 
 func newRepositoryOptionsFromFlags() *repo.NewRepositoryOptions {
-	return &repo.NewRepositoryOptions{
+	opt := &repo.NewRepositoryOptions{
 		BlockFormat: content.FormattingOptions{
 			Hash:       *createBlockHashFormat,
 			Encryption: *createBlockEncryptionFormat,
@@ -40,6 +51,31 @@ func newRepositoryOptionsFromFlags() *repo.NewRepositoryOptions {
 			Splitter: *createSplitter,
 		},
 	}
+
+	if *createIndexEpochs {
+		opt.BlockFormat.Version = content.FormatVersion2
+		opt.BlockFormat.EpochParameters = epoch.DefaultParameters
+	}
+
+	if *createIndexVersion >= content.IndexVersion2 {
+		opt.BlockFormat.Version = content.FormatVersion2
+		opt.BlockFormat.IndexVersion = *createIndexVersion
+	}
+
+	if *createRetentionMode != "" {
+		opt.BlockFormat.RetentionMode = blob.RetentionMode(*createRetentionMode)
+		opt.BlockFormat.RetentionPeriod = *createRetentionPeriod
+	}
+
+	if *createECC {
+		opt.ErrorCorrection = &ecc.Options{
+			DataShards:   *createECCDataShards,
+			ParityShards: *createECCParityShards,
+			ShardSize:    *createECCShardSize,
+		}
+	}
+
+	return opt
 }
 
 func ensureEmpty(ctx context.Context, s blob.Storage) error {
@@ -74,6 +110,19 @@ func runCreateCommandWithStorage(ctx context.Context, st blob.Storage) error {
 	log(ctx).Infof("  block hash:          %v", options.BlockFormat.Hash)
 	log(ctx).Infof("  encryption:          %v", options.BlockFormat.Encryption)
 	log(ctx).Infof("  splitter:            %v", options.ObjectFormat.Splitter)
+	log(ctx).Infof("  index epochs:        %v", options.BlockFormat.EpochParameters.Enabled)
+
+	if ec := options.ErrorCorrection; ec != nil {
+		log(ctx).Infof("  error correction:    %v data + %v parity shards of %v bytes", ec.DataShards, ec.ParityShards, ec.ShardSize)
+	}
+
+	if mode := options.BlockFormat.RetentionMode; mode != "" {
+		if _, ok := st.(blob.RetentionStorage); !ok {
+			return errors.Wrap(blob.ErrRetentionUnsupported, "unable to enable retention")
+		}
+
+		log(ctx).Infof("  retention:           %v for %v", mode, options.BlockFormat.RetentionPeriod)
+	}
 
 	if err := repo.Initialize(ctx, st, options, password); err != nil {
 		return errors.Wrap(err, "cannot initialize repository")
@@ -0,0 +1,116 @@
+package cli
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/pkg/errors"
+
+	"github.com/kopia/kopia/repo"
+)
+
+const unlimitedThrottle = "unlimited"
+
+var (
+	throttleCommands = repositoryCommands.Command("throttle", "Commands to manipulate throttling of storage operations.")
+
+	throttleGetCommand = throttleCommands.Command("get", "Get throttling limits.")
+
+	throttleSetCommand                = throttleCommands.Command("set", "Set throttling limits.")
+	throttleSetDownloadBytesPerSecond = throttleSetCommand.Flag("download-bytes-per-second", "Set the download bytes per second ('unlimited' to remove the limit)").String()
+	throttleSetUploadBytesPerSecond   = throttleSetCommand.Flag("upload-bytes-per-second", "Set the upload bytes per second ('unlimited' to remove the limit)").String()
+	throttleSetReadsPerSecond         = throttleSetCommand.Flag("read-requests-per-second", "Set max reads per second ('unlimited' to remove the limit)").String()
+	throttleSetWritesPerSecond        = throttleSetCommand.Flag("write-requests-per-second", "Set max writes per second ('unlimited' to remove the limit)").String()
+	throttleSetListsPerSecond         = throttleSetCommand.Flag("list-requests-per-second", "Set max lists per second ('unlimited' to remove the limit)").String()
+)
+
+func runThrottleGetCommand(ctx context.Context, rep repo.DirectRepository) error {
+	limits := rep.Throttler().Limits()
+
+	if jsonOutput {
+		printStdout("%s\n", jsonBytes(limits))
+		return nil
+	}
+
+	printThrottleValue("Max Download Speed", limits.DownloadBytesPerSecond, " bytes/s")
+	printThrottleValue("Max Upload Speed", limits.UploadBytesPerSecond, " bytes/s")
+	printThrottleValue("Max Read Requests", limits.ReadsPerSecond, " per second")
+	printThrottleValue("Max Write Requests", limits.WritesPerSecond, " per second")
+	printThrottleValue("Max List Requests", limits.ListsPerSecond, " per second")
+
+	return nil
+}
+
+func printThrottleValue(desc string, val float64, suffix string) {
+	if val == 0 {
+		printStdout("%-20v %v\n", desc+":", unlimitedThrottle)
+		return
+	}
+
+	printStdout("%-20v %v%v\n", desc+":", val, suffix)
+}
+
+func runThrottleSetCommand(ctx context.Context, rep repo.DirectRepository) error {
+	limits := rep.Throttler().Limits()
+
+	var changeCount int
+
+	for _, v := range []struct {
+		desc   string
+		flag   string
+		target *float64
+	}{
+		{"download speed", *throttleSetDownloadBytesPerSecond, &limits.DownloadBytesPerSecond},
+		{"upload speed", *throttleSetUploadBytesPerSecond, &limits.UploadBytesPerSecond},
+		{"read requests", *throttleSetReadsPerSecond, &limits.ReadsPerSecond},
+		{"write requests", *throttleSetWritesPerSecond, &limits.WritesPerSecond},
+		{"list requests", *throttleSetListsPerSecond, &limits.ListsPerSecond},
+	} {
+		if v.flag == "" {
+			continue
+		}
+
+		val, err := parseThrottleValue(v.flag)
+		if err != nil {
+			return errors.Wrapf(err, "invalid %v limit", v.desc)
+		}
+
+		log(ctx).Infof("Setting %v limit to %v", v.desc, v.flag)
+
+		*v.target = val
+		changeCount++
+	}
+
+	if changeCount == 0 {
+		return errors.Errorf("no changes made")
+	}
+
+	if err := repo.SetThrottlingLimits(ctx, rep.ConfigFilename(), limits); err != nil {
+		return errors.Wrap(err, "unable to save throttling limits")
+	}
+
+	return errors.Wrap(rep.Throttler().SetLimits(limits), "unable to set limits")
+}
+
+func parseThrottleValue(v string) (float64, error) {
+	if v == unlimitedThrottle || v == "-" {
+		return 0, nil
+	}
+
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, errors.Wrap(err, "invalid number")
+	}
+
+	if f < 0 {
+		return 0, errors.Errorf("limit must not be negative")
+	}
+
+	return f, nil
+}
+
+func init() {
+	registerJSONOutputFlags(throttleGetCommand)
+	throttleGetCommand.Action(directRepositoryReadAction(runThrottleGetCommand))
+	throttleSetCommand.Action(directRepositoryReadAction(runThrottleSetCommand))
+}
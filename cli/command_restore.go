@@ -74,6 +74,8 @@
 	restoreParallel               = 8
 	restoreIgnorePermissionErrors = true
 	restoreSkipTimes              = false
+	restoreSkipXattrs             = false
+	restoreWriteSparseFiles       = false
 	restoreSkipOwners             = false
 	restoreSkipPermissions        = false
 	restoreIncremental            = false
@@ -101,6 +103,8 @@ func addRestoreFlags(cmd *kingpin.CmdClause) {
 	cmd.Flag("skip-owners", "Skip owners during restore").BoolVar(&restoreSkipOwners)
 	cmd.Flag("skip-permissions", "Skip permissions during restore").BoolVar(&restoreSkipPermissions)
 	cmd.Flag("skip-times", "Skip times during restore").BoolVar(&restoreSkipTimes)
+	cmd.Flag("skip-xattrs", "Skip extended attributes and ACLs during restore").BoolVar(&restoreSkipXattrs)
+	cmd.Flag("write-sparse-files", "When doing a restore, attempt to write files sparsely, allocating the minimum amount of disk space needed").BoolVar(&restoreWriteSparseFiles)
 	cmd.Flag("ignore-permission-errors", "Ignore permission errors").BoolVar(&restoreIgnorePermissionErrors)
 	cmd.Flag("ignore-errors", "Ignore all errors").BoolVar(&restoreIgnoreErrors)
 	cmd.Flag("skip-existing", "Skip files and symlinks that exist in the output").BoolVar(&restoreIncremental)
@@ -124,6 +128,8 @@ func restoreOutput(ctx context.Context) (restore.Output, error) {
 			SkipOwners:             restoreSkipOwners,
 			SkipPermissions:        restoreSkipPermissions,
 			SkipTimes:              restoreSkipTimes,
+			SkipExtendedAttributes: restoreSkipXattrs,
+			WriteSparseFiles:       restoreWriteSparseFiles,
 		}, nil
 
 	case restoreModeZip, restoreModeZipNoCompress:
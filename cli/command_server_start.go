@@ -36,11 +36,21 @@
 	serverStartRefreshInterval = serverStartCommand.Flag("refresh-interval", "Frequency for refreshing repository status").Default("300s").Duration()
 	serverStartInsecure        = serverStartCommand.Flag("insecure", "Allow insecure configurations (do not use in production)").Hidden().Bool()
 	serverStartMaxConcurrency  = serverStartCommand.Flag("max-concurrency", "Maximum number of server goroutines").Default("0").Int()
+	serverStartMaintenance     = serverStartCommand.Flag("maintenance-owner", "Take over repository maintenance ownership and run maintenance on the configured intervals").Bool()
 
 	serverStartWithoutPassword = serverStartCommand.Flag("without-password", "Start the server without a password").Hidden().Bool()
 	serverStartRandomPassword  = serverStartCommand.Flag("random-password", "Generate random password and print to stderr").Hidden().Bool()
 	serverStartHtpasswdFile    = serverStartCommand.Flag("htpasswd-file", "Path to htpasswd file that contains allowed user@hostname entries").Hidden().ExistingFile()
 
+	serverStartJWTJWKSFile        = serverStartCommand.Flag("jwt-jwks-file", "Accept JWT bearer tokens signed by keys in the provided JSON Web Key Set file").ExistingFile()
+	serverStartJWTJWKSURL         = serverStartCommand.Flag("jwt-jwks-url", "Accept JWT bearer tokens signed by keys in the JSON Web Key Set at the provided URL").String()
+	serverStartJWTIssuer          = serverStartCommand.Flag("jwt-issuer", "Required issuer ('iss' claim) of JWT bearer tokens").String()
+	serverStartJWTAudience        = serverStartCommand.Flag("jwt-audience", "Required audience ('aud' claim) of JWT bearer tokens, must be provided when accepting them").String()
+	serverStartJWTUsernameClaim   = serverStartCommand.Flag("jwt-username-claim", "JWT claim holding the username").Default("sub").String()
+	serverStartJWTHostnameClaim   = serverStartCommand.Flag("jwt-hostname-claim", "JWT claim holding the hostname").String()
+	serverStartJWTDefaultHostname = serverStartCommand.Flag("jwt-default-hostname", "Hostname of JWT users when the token does not specify one").String()
+	serverStartJWTGroupsClaim     = serverStartCommand.Flag("jwt-groups-claim", "JWT claim holding the list of user groups").Default("groups").String()
+
 	serverAuthCookieSingingKey = serverStartCommand.Flag("auth-cookie-signing-key", "Force particular auth cookie signing key").Envar("KOPIA_AUTH_COOKIE_SIGNING_KEY").Hidden().String()
 
 	serverStartShutdownWhenStdinClosed = serverStartCommand.Flag("shutdown-on-stdin", "Shut down the server when stdin handle has closed.").Hidden().Bool()
@@ -69,6 +79,7 @@ func runServer(ctx context.Context, rep repo.Repository) error {
 		Authorizer:           auth.DefaultAuthorizer(),
 		AuthCookieSigningKey: *serverAuthCookieSingingKey,
 		UIUser:               *serverUsername,
+		MaintenanceOwner:     *serverStartMaintenance,
 	})
 	if err != nil {
 		return errors.Wrap(err, "unable to initialize server")
@@ -267,10 +278,40 @@ func getAuthenticator(ctx context.Context) (auth.Authenticator, error) {
 	log(ctx).Infof(`
 Server will allow connections from users whose accounts are stored in the repository.
 User accounts can be added using 'kopia server user add'.
+API tokens can be issued to users using 'kopia server user token create'.
 `)
 
-	// handle user accounts stored in the repository
-	authenticators = append(authenticators, auth.AuthenticateRepositoryUsers())
+	// handle user accounts and API tokens stored in the repository
+	authenticators = append(authenticators, auth.AuthenticateRepositoryUsers(), auth.AuthenticateRepositoryTokens())
+
+	// handle JWT bearer tokens issued by external identity provider.
+	if *serverStartJWTJWKSFile != "" || *serverStartJWTJWKSURL != "" {
+		// identity providers commonly sign tokens issued to all applications with the same keys,
+		// without audience restriction the server would accept tokens meant for any of them.
+		if *serverStartJWTAudience == "" {
+			return nil, errors.Errorf("--jwt-audience must be provided when accepting JWT bearer tokens")
+		}
+
+		if *serverStartJWTIssuer == "" {
+			log(ctx).Infof("--jwt-issuer not provided, accepting JWT bearer tokens from any issuer using the same keys.")
+		}
+
+		a, err := auth.AuthenticateJWT(ctx, auth.JWTOptions{
+			JWKSFile:        *serverStartJWTJWKSFile,
+			JWKSURL:         *serverStartJWTJWKSURL,
+			Issuer:          *serverStartJWTIssuer,
+			Audience:        *serverStartJWTAudience,
+			UsernameClaim:   *serverStartJWTUsernameClaim,
+			HostnameClaim:   *serverStartJWTHostnameClaim,
+			DefaultHostname: *serverStartJWTDefaultHostname,
+			GroupsClaim:     *serverStartJWTGroupsClaim,
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "error initializing JWT authentication")
+		}
+
+		authenticators = append(authenticators, a)
+	}
 
 	return auth.CombineAuthenticators(authenticators...), nil
 }
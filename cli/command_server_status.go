@@ -23,6 +23,11 @@ func runServerStatus(ctx context.Context, cli *apiclient.KopiaAPIClient) error {
 	}
 
 	for _, src := range status.Sources {
+		if src.Overdue && src.MissedSnapshotTime != nil {
+			fmt.Printf("%15v %v (overdue, missed snapshot at %v)\n", src.Status, src.Source, formatTimestamp(*src.MissedSnapshotTime))
+			continue
+		}
+
 		fmt.Printf("%15v %v\n", src.Status, src.Source)
 	}
 
@@ -31,6 +31,8 @@
 	serverStartTLSGenerateCertValidDays = serverStartCommand.Flag("tls-generate-cert-valid-days", "How long should the TLS certificate be valid").Default("3650").Hidden().Int()
 	serverStartTLSGenerateCertNames     = serverStartCommand.Flag("tls-generate-cert-name", "Host names/IP addresses to generate TLS certificate for").Default("127.0.0.1").Hidden().Strings()
 	serverStartTLSPrintFullServerCert   = serverStartCommand.Flag("tls-print-server-cert", "Print server certificate").Hidden().Bool()
+	serverStartTLSClientCAFile          = serverStartCommand.Flag("tls-client-ca-file", "Authenticate users defined in the repository with TLS client certificates signed by CAs in the provided PEM file").ExistingFile()
+	serverStartTLSRequireClientCert     = serverStartCommand.Flag("tls-require-client-cert", "Require all clients to present a TLS client certificate").Bool()
 )
 
 func generateServerCertificate(ctx context.Context) (*x509.Certificate, *rsa.PrivateKey, error) {
@@ -89,6 +91,39 @@ func maybeGenerateTLS(ctx context.Context) error {
 	return nil
 }
 
+// configureTLSClientAuth configures the server to verify TLS client certificates, which are used to authenticate users.
+func configureTLSClientAuth(ctx context.Context, httpServer *http.Server) error {
+	if *serverStartTLSClientCAFile == "" {
+		if *serverStartTLSRequireClientCert {
+			return errors.Errorf("--tls-require-client-cert requires --tls-client-ca-file")
+		}
+
+		return nil
+	}
+
+	pool, err := tlsutil.LoadCertPool(*serverStartTLSClientCAFile)
+	if err != nil {
+		return errors.Wrap(err, "unable to load client CA certificates")
+	}
+
+	if httpServer.TLSConfig == nil {
+		httpServer.TLSConfig = &tls.Config{
+			MinVersion: tls.VersionTLS12,
+		}
+	}
+
+	httpServer.TLSConfig.ClientCAs = pool
+	httpServer.TLSConfig.ClientAuth = tls.VerifyClientCertIfGiven
+
+	if *serverStartTLSRequireClientCert {
+		httpServer.TLSConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	log(ctx).Infof("Server will authenticate users with TLS client certificates signed by CAs in %v.", *serverStartTLSClientCAFile)
+
+	return nil
+}
+
 func startServerWithOptionalTLSAndListener(ctx context.Context, httpServer *http.Server, listener net.Listener) error {
 	if err := maybeGenerateTLS(ctx); err != nil {
 		return err
@@ -97,6 +132,10 @@ func startServerWithOptionalTLSAndListener(ctx context.Context, httpServer *http
 	switch {
 	case *serverStartTLSCertFile != "" && *serverStartTLSKeyFile != "":
 		// PEM files provided
+		if err := configureTLSClientAuth(ctx, httpServer); err != nil {
+			return err
+		}
+
 		fmt.Fprintf(os.Stderr, "SERVER ADDRESS: https://%v\n", httpServer.Addr)
 		showServerUIPrompt(ctx)
 
@@ -119,6 +158,10 @@ func startServerWithOptionalTLSAndListener(ctx context.Context, httpServer *http
 			},
 		}
 
+		if err := configureTLSClientAuth(ctx, httpServer); err != nil {
+			return err
+		}
+
 		fingerprint := sha256.Sum256(cert.Raw)
 		fmt.Fprintf(os.Stderr, "SERVER CERT SHA256: %v\n", hex.EncodeToString(fingerprint[:]))
 
@@ -143,6 +186,10 @@ func startServerWithOptionalTLSAndListener(ctx context.Context, httpServer *http
 			return errors.Errorf("TLS not configured. To start server without encryption pass --insecure.")
 		}
 
+		if *serverStartTLSClientCAFile != "" {
+			return errors.Errorf("TLS client certificates require TLS to be configured.")
+		}
+
 		fmt.Fprintf(os.Stderr, "SERVER ADDRESS: http://%v\n", httpServer.Addr)
 		showServerUIPrompt(ctx)
 
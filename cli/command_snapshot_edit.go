@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/kopia/kopia/repo"
+	"github.com/kopia/kopia/repo/manifest"
+	"github.com/kopia/kopia/snapshot"
+)
+
+var (
+	snapshotEditCommand    = snapshotCommands.Command("edit", "Edit tags of existing snapshots.")
+	snapshotEditIDs        = snapshotEditCommand.Arg("id", "Snapshot ID to edit").Required().Strings()
+	snapshotEditAddTags    = snapshotEditCommand.Flag("add-tags", "Tags to add or replace, in the <key>:<value> format.").Strings()
+	snapshotEditRemoveTags = snapshotEditCommand.Flag("remove-tags", "Keys of tags to remove.").Strings()
+)
+
+func runSnapshotEditCommand(ctx context.Context, rep repo.RepositoryWriter) error {
+	addTags, err := snapshot.ParseTags(*snapshotEditAddTags)
+	if err != nil {
+		return errors.Wrap(err, "invalid tags")
+	}
+
+	if len(addTags) == 0 && len(*snapshotEditRemoveTags) == 0 {
+		return errors.New("no changes requested, use --add-tags or --remove-tags")
+	}
+
+	for _, id := range *snapshotEditIDs {
+		m, err := snapshot.LoadSnapshot(ctx, rep, manifest.ID(id))
+		if err != nil {
+			return errors.Wrapf(err, "error loading snapshot %v", id)
+		}
+
+		if m.Tags == nil {
+			m.Tags = map[string]string{}
+		}
+
+		for _, k := range *snapshotEditRemoveTags {
+			delete(m.Tags, k)
+		}
+
+		for k, v := range addTags {
+			m.Tags[k] = v
+		}
+
+		newID, err := snapshot.UpdateSnapshot(ctx, rep, m)
+		if err != nil {
+			return errors.Wrapf(err, "error updating snapshot %v", id)
+		}
+
+		log(ctx).Infof("Updated snapshot %v, new ID is %v", id, newID)
+	}
+
+	return nil
+}
+
+func init() {
+	snapshotEditCommand.Action(repositoryWriterAction(runSnapshotEditCommand))
+}
@@ -3,6 +3,7 @@
 import (
 	"context"
 	"sort"
+	"strings"
 
 	"github.com/pkg/errors"
 
@@ -17,6 +18,7 @@
 	snapshotExpireAll    = snapshotExpireCommand.Flag("all", "Expire all snapshots").Bool()
 	snapshotExpirePaths  = snapshotExpireCommand.Arg("path", "Expire snapshots for given paths only").Strings()
 	snapshotExpireDelete = snapshotExpireCommand.Flag("delete", "Whether to actually delete snapshots").Bool()
+	snapshotExpireDryRun = snapshotExpireCommand.Flag("dry-run", "Explain which retention rules keep or drop each snapshot, without deleting anything").Bool()
 )
 
 func getSnapshotSourcesToExpire(ctx context.Context, rep repo.Repository) ([]snapshot.SourceInfo, error) {
@@ -38,7 +40,35 @@ func getSnapshotSourcesToExpire(ctx context.Context, rep repo.Repository) ([]sna
 	return result, nil
 }
 
+func explainRetention(ctx context.Context, rep repo.Repository, src snapshot.SourceInfo) error {
+	decisions, err := policy.ComputeRetentionDecisions(ctx, rep, src)
+	if err != nil {
+		return errors.Wrapf(err, "error applying retention policy to %v", src)
+	}
+
+	sort.Slice(decisions, func(i, j int) bool {
+		return decisions[i].Manifest.StartTime.After(decisions[j].Manifest.StartTime)
+	})
+
+	printStdout("%v\n", src)
+
+	for _, d := range decisions {
+		action := "keep"
+		if d.Expire {
+			action = "delete"
+		}
+
+		printStdout("  %v %-6v %v\n", formatTimestamp(d.Manifest.StartTime), action, strings.Join(d.Reasons, ", "))
+	}
+
+	return nil
+}
+
 func runExpireCommand(ctx context.Context, rep repo.RepositoryWriter) error {
+	if *snapshotExpireDryRun && *snapshotExpireDelete {
+		return errors.New("--dry-run and --delete are mutually exclusive")
+	}
+
 	sources, err := getSnapshotSourcesToExpire(ctx, rep)
 	if err != nil {
 		return err
@@ -49,6 +79,14 @@ func runExpireCommand(ctx context.Context, rep repo.RepositoryWriter) error {
 	})
 
 	for _, src := range sources {
+		if *snapshotExpireDryRun {
+			if err := explainRetention(ctx, rep, src); err != nil {
+				return err
+			}
+
+			continue
+		}
+
 		deleted, err := policy.ApplyRetentionPolicy(ctx, rep, src, *snapshotExpireDelete)
 		if err != nil {
 			return errors.Wrapf(err, "error applying retention policy to %v", src)
@@ -4,6 +4,7 @@
 	"context"
 	"fmt"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
@@ -33,11 +34,12 @@
 	snapshotListShowIdentical        = snapshotListCommand.Flag("show-identical", "Show identical snapshots").Short('l').Bool()
 	snapshotListShowAll              = snapshotListCommand.Flag("all", "Show all shapshots (not just current username/host)").Short('a').Bool()
 	maxResultsPerPath                = snapshotListCommand.Flag("max-results", "Maximum number of entries per source.").Short('n').Int()
+	snapshotListTags                 = snapshotListCommand.Flag("tags", "Only list snapshots with the provided tags, in the <key>:<value> format.").Strings()
 )
 
-func findSnapshotsForSource(ctx context.Context, rep repo.Repository, sourceInfo snapshot.SourceInfo) (manifestIDs []manifest.ID, relPath string, err error) {
+func findSnapshotsForSource(ctx context.Context, rep repo.Repository, sourceInfo snapshot.SourceInfo, tags map[string]string) (manifestIDs []manifest.ID, relPath string, err error) {
 	for len(sourceInfo.Path) > 0 {
-		list, err := snapshot.ListSnapshotManifests(ctx, rep, &sourceInfo)
+		list, err := snapshot.ListSnapshotManifestsWithTags(ctx, rep, &sourceInfo, tags)
 		if err != nil {
 			return nil, "", errors.Wrapf(err, "error listing manifests for %v", sourceInfo)
 		}
@@ -65,9 +67,9 @@ func findSnapshotsForSource(ctx context.Context, rep repo.Repository, sourceInfo
 	return nil, "", nil
 }
 
-func findManifestIDs(ctx context.Context, rep repo.Repository, source string) ([]manifest.ID, string, error) {
+func findManifestIDs(ctx context.Context, rep repo.Repository, source string, tags map[string]string) ([]manifest.ID, string, error) {
 	if source == "" {
-		man, err := snapshot.ListSnapshotManifests(ctx, rep, nil)
+		man, err := snapshot.ListSnapshotManifestsWithTags(ctx, rep, nil, tags)
 		return man, "", errors.Wrap(err, "error listing all snapshot manifests")
 	}
 
@@ -76,7 +78,7 @@ func findManifestIDs(ctx context.Context, rep repo.Repository, source string) ([
 		return nil, "", errors.Errorf("invalid directory: '%s': %s", source, err)
 	}
 
-	manifestIDs, relPath, err := findSnapshotsForSource(ctx, rep, si)
+	manifestIDs, relPath, err := findSnapshotsForSource(ctx, rep, si, tags)
 	if relPath != "" {
 		relPath = "/" + relPath
 	}
@@ -90,7 +92,12 @@ func runSnapshotsCommand(ctx context.Context, rep repo.Repository) error {
 	jl.begin()
 	defer jl.end()
 
-	manifestIDs, relPath, err := findManifestIDs(ctx, rep, *snapshotListPath)
+	tags, err := snapshot.ParseTags(*snapshotListTags)
+	if err != nil {
+		return errors.Wrap(err, "invalid tags")
+	}
+
+	manifestIDs, relPath, err := findManifestIDs(ctx, rep, *snapshotListPath, tags)
 	if err != nil {
 		return err
 	}
@@ -263,6 +270,14 @@ func entryBits(ctx context.Context, m *snapshot.Manifest, ent fs.Entry, lastTota
 		bits = append(bits, "manifest:"+string(m.ID))
 	}
 
+	if len(m.Tags) > 0 {
+		bits = append(bits, formatTags(m.Tags))
+	}
+
+	if len(m.Pins) > 0 {
+		bits = append(bits, "pins:["+strings.Join(m.Pins, " ")+"]")
+	}
+
 	if *snapshotListShowDelta {
 		bits = append(bits, deltaBytes(ent.Size()-lastTotalFileSize))
 	}
@@ -288,6 +303,18 @@ func entryBits(ctx context.Context, m *snapshot.Manifest, ent fs.Entry, lastTota
 	return bits, col
 }
 
+func formatTags(tags map[string]string) string {
+	var kvs []string
+
+	for k, v := range tags {
+		kvs = append(kvs, k+":"+v)
+	}
+
+	sort.Strings(kvs)
+
+	return "tags:[" + strings.Join(kvs, " ") + "]"
+}
+
 func deltaBytes(b int64) string {
 	if b > 0 {
 		return "(+" + units.BytesStringBase10(b) + ")"
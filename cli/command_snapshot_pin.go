@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/kopia/kopia/repo"
+	"github.com/kopia/kopia/repo/manifest"
+	"github.com/kopia/kopia/snapshot"
+)
+
+var (
+	snapshotPinCommands = snapshotCommands.Command("pin", "Commands to manipulate pins of existing snapshots, which prevent them from being expired.")
+
+	snapshotPinAddCommand = snapshotPinCommands.Command("add", "Add pins to snapshots.")
+	snapshotPinAddIDs     = snapshotPinAddCommand.Arg("id", "Snapshot ID to pin").Required().Strings()
+	snapshotPinAddPins    = snapshotPinAddCommand.Flag("pin", "Pin to add").Required().Strings()
+
+	snapshotPinRemoveCommand = snapshotPinCommands.Command("remove", "Remove pins from snapshots.").Alias("rm")
+	snapshotPinRemoveIDs     = snapshotPinRemoveCommand.Arg("id", "Snapshot ID to unpin").Required().Strings()
+	snapshotPinRemovePins    = snapshotPinRemoveCommand.Flag("pin", "Pin to remove").Required().Strings()
+)
+
+func runSnapshotPinAddCommand(ctx context.Context, rep repo.RepositoryWriter) error {
+	return updateSnapshotPins(ctx, rep, *snapshotPinAddIDs, *snapshotPinAddPins, nil)
+}
+
+func runSnapshotPinRemoveCommand(ctx context.Context, rep repo.RepositoryWriter) error {
+	return updateSnapshotPins(ctx, rep, *snapshotPinRemoveIDs, nil, *snapshotPinRemovePins)
+}
+
+func updateSnapshotPins(ctx context.Context, rep repo.RepositoryWriter, ids, add, remove []string) error {
+	for _, p := range append(append([]string(nil), add...), remove...) {
+		if p == "" {
+			return errors.New("pin must not be empty")
+		}
+	}
+
+	for _, id := range ids {
+		m, err := snapshot.LoadSnapshot(ctx, rep, manifest.ID(id))
+		if err != nil {
+			return errors.Wrapf(err, "error loading snapshot %v", id)
+		}
+
+		if !m.UpdatePins(add, remove) {
+			log(ctx).Infof("Snapshot %v is unchanged, pins: %v", id, m.Pins)
+			continue
+		}
+
+		newID, err := snapshot.UpdateSnapshot(ctx, rep, m)
+		if err != nil {
+			return errors.Wrapf(err, "error updating snapshot %v", id)
+		}
+
+		log(ctx).Infof("Updated snapshot %v, new ID is %v, pins: %v", id, newID, m.Pins)
+	}
+
+	return nil
+}
+
+func init() {
+	snapshotPinAddCommand.Action(repositoryWriterAction(runSnapshotPinAddCommand))
+	snapshotPinRemoveCommand.Action(repositoryWriterAction(runSnapshotPinRemoveCommand))
+}
@@ -5,6 +5,7 @@
 
 	"github.com/pkg/errors"
 
+	"github.com/kopia/kopia/internal/apitoken"
 	"github.com/kopia/kopia/internal/user"
 	"github.com/kopia/kopia/repo"
 )
@@ -20,6 +21,17 @@ func runUserDelete(ctx context.Context, rep repo.RepositoryWriter) error {
 		return errors.Wrap(err, "error deleting user profile")
 	}
 
+	tokens, err := apitoken.List(ctx, rep, *userDeleteName)
+	if err != nil {
+		return errors.Wrap(err, "error listing user tokens")
+	}
+
+	for _, t := range tokens {
+		if err := apitoken.Revoke(ctx, rep, t.ID); err != nil {
+			return errors.Wrapf(err, "error revoking token %v", t.ID)
+		}
+	}
+
 	log(ctx).Infof("User %q deleted.", *userDeleteName)
 
 	return nil
@@ -0,0 +1,106 @@
+package cli
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/kopia/kopia/internal/acl"
+	"github.com/kopia/kopia/internal/apitoken"
+	"github.com/kopia/kopia/internal/clock"
+	"github.com/kopia/kopia/repo"
+)
+
+var (
+	userTokenCommands = userCommands.Command("token", "Manage API tokens, which can be used by repository users instead of passwords.").Alias("tokens")
+
+	userTokenCreateCommand     = userTokenCommands.Command("create", "Create new API token for a repository user").Alias("add")
+	userTokenCreateUsername    = userTokenCreateCommand.Arg("username", "The username to create token for").Required().String()
+	userTokenCreateDescription = userTokenCreateCommand.Flag("description", "Token description").String()
+	userTokenCreateExpiresIn   = userTokenCreateCommand.Flag("expires-in", "Token validity").Default("720h").Duration()
+	userTokenCreateAccess      = userTokenCreateCommand.Flag("access", "Maximum access level granted by the token, in addition to user's permissions").Enum(acl.SupportedAccessLevels()...)
+
+	userTokenListCommand  = userTokenCommands.Command("list", "List API tokens").Alias("ls")
+	userTokenListUsername = userTokenListCommand.Arg("username", "Only list tokens of the provided user").String()
+
+	userTokenRevokeCommand = userTokenCommands.Command("revoke", "Revoke API token").Alias("delete").Alias("rm")
+	userTokenRevokeIDs     = userTokenRevokeCommand.Arg("id", "Token ID").Required().Strings()
+)
+
+func runUserTokenCreate(ctx context.Context, rep repo.RepositoryWriter) error {
+	var maxAccess acl.AccessLevel
+
+	if *userTokenCreateAccess != "" {
+		var err error
+
+		if maxAccess, err = acl.ParseAccessLevel(*userTokenCreateAccess); err != nil {
+			return errors.Wrap(err, "invalid access level")
+		}
+	}
+
+	t, tokenString, err := apitoken.Create(ctx, rep, *userTokenCreateUsername, *userTokenCreateDescription, *userTokenCreateExpiresIn, maxAccess)
+	if err != nil {
+		return errors.Wrap(err, "error creating token")
+	}
+
+	log(ctx).Infof("Created token %v for %v, expires at %v.", t.ID, t.Username, formatTimestamp(t.ExpiresAt))
+	log(ctx).Infof("Pass the following token instead of the password, it will not be shown again:")
+
+	printStdout("%v\n", tokenString)
+
+	return nil
+}
+
+func runUserTokenList(ctx context.Context, rep repo.Repository) error {
+	var jl jsonList
+
+	jl.begin()
+	defer jl.end()
+
+	tokens, err := apitoken.List(ctx, rep, *userTokenListUsername)
+	if err != nil {
+		return errors.Wrap(err, "error listing tokens")
+	}
+
+	now := clock.Now()
+
+	for _, t := range tokens {
+		if jsonOutput {
+			jl.emit(t)
+			continue
+		}
+
+		status := "active"
+		if t.IsExpired(now) {
+			status = "expired"
+		}
+
+		access := "user"
+		if t.MaxAccess != 0 {
+			access = t.MaxAccess.String()
+		}
+
+		printStdout("%v %v expires:%v access:%v %v %q\n", t.ID, t.Username, formatTimestamp(t.ExpiresAt), access, status, t.Description)
+	}
+
+	return nil
+}
+
+func runUserTokenRevoke(ctx context.Context, rep repo.RepositoryWriter) error {
+	for _, id := range *userTokenRevokeIDs {
+		if err := apitoken.Revoke(ctx, rep, id); err != nil {
+			return errors.Wrapf(err, "error revoking token %v", id)
+		}
+
+		log(ctx).Infof("Token %v revoked.", id)
+	}
+
+	return nil
+}
+
+func init() {
+	userTokenCreateCommand.Action(repositoryWriterAction(runUserTokenCreate))
+	registerJSONOutputFlags(userTokenListCommand)
+	userTokenListCommand.Action(repositoryReaderAction(runUserTokenList))
+	userTokenRevokeCommand.Action(repositoryWriterAction(runUserTokenRevoke))
+}
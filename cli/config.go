@@ -76,6 +76,10 @@ func openRepository(ctx context.Context, required bool) (repo.Repository, error)
 	}
 
 	r, err := repo.Open(ctx, repositoryConfigFileName(), pass, optionsFromFlags(ctx))
+	if errors.Is(err, repo.ErrInvalidPassword) && isPersistedPassword(ctx, pass) {
+		r, err = reopenWithChangedPassword(ctx)
+	}
+
 	if os.IsNotExist(err) {
 		return nil, errors.New("not connected to a repository, use 'kopia connect'")
 	}
@@ -83,6 +87,39 @@ func openRepository(ctx context.Context, required bool) (repo.Repository, error)
 	return r, errors.Wrap(err, "unable to open repository")
 }
 
+// reopenWithChangedPassword asks for the password again after the stored one was rejected,
+// which happens when the repository password was changed by another client.
+func reopenWithChangedPassword(ctx context.Context) (repo.Repository, error) {
+	log(ctx).Infof("Unable to open repository with the stored password, it may have been changed by another client.")
+
+	pass, err := askForExistingRepositoryPassword()
+	if err != nil {
+		return nil, errors.Wrap(err, "get password")
+	}
+
+	r, err := repo.Open(ctx, repositoryConfigFileName(), pass, optionsFromFlags(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := repo.SetPersistedPassword(ctx, repositoryConfigFileName(), pass); err != nil {
+		log(ctx).Errorf("unable to persist password: %v", err)
+	}
+
+	return r, nil
+}
+
+// isPersistedPassword returns true if the provided password was retrieved from persistent storage.
+func isPersistedPassword(ctx context.Context, pass string) bool {
+	if passwordFromToken != "" || *password != "" {
+		return false
+	}
+
+	persisted, ok := repo.GetPersistedPassword(ctx, repositoryConfigFileName())
+
+	return ok && persisted == pass
+}
+
 func optionsFromFlags(ctx context.Context) *repo.Options {
 	var opts repo.Options
 
@@ -33,6 +33,36 @@ type DeviceInfo struct {
 	Rdev uint64 `json:"rdev"`
 }
 
+// InodeInfo describes the inode backing a filesystem entry.
+type InodeInfo struct {
+	Inode     uint64 `json:"ino"`
+	LinkCount uint64 `json:"nlink"`
+}
+
+// EntryWithInodeInfo is optionally implemented by entries backed by inodes, which may have multiple hard links.
+type EntryWithInodeInfo interface {
+	InodeInfo() InodeInfo
+}
+
+// ExtendedAttributes maps names of extended attributes (including the namespace, such as "user.comment")
+// to their values.
+type ExtendedAttributes map[string][]byte
+
+// EntryWithExtendedAttributes is optionally implemented by entries that support extended attributes.
+type EntryWithExtendedAttributes interface {
+	ExtendedAttributes(ctx context.Context) (ExtendedAttributes, error)
+}
+
+// GetExtendedAttributes returns extended attributes of the provided entry or nil if the entry does not support them.
+func GetExtendedAttributes(ctx context.Context, e Entry) (ExtendedAttributes, error) {
+	if ea, ok := e.(EntryWithExtendedAttributes); ok {
+		// nolint:wrapcheck
+		return ea.ExtendedAttributes(ctx)
+	}
+
+	return nil, nil
+}
+
 // Entries is a list of entries sorted by name.
 type Entries []Entry
 
@@ -44,6 +74,14 @@ type Reader interface {
 	Entry() (Entry, error)
 }
 
+// SparseReader is optionally implemented by readers of files that may contain holes, which read as zeros
+// but are not backed by any data.
+type SparseReader interface {
+	// NextDataRegion returns the start and end offsets of the first region of data at or after the provided offset,
+	// or io.EOF if there's no more data. It may change the current read position.
+	NextDataRegion(offset int64) (start, end int64, err error)
+}
+
 // File represents an entry that is a file.
 type File interface {
 	Entry
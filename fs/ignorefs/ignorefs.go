@@ -128,6 +128,11 @@ func (d *ignoreDirectory) skipCacheDirectory(ctx context.Context, entries fs.Ent
 	return entries
 }
 
+// ExtendedAttributes implements fs.EntryWithExtendedAttributes.
+func (d *ignoreDirectory) ExtendedAttributes(ctx context.Context) (fs.ExtendedAttributes, error) {
+	return fs.GetExtendedAttributes(ctx, d.Directory)
+}
+
 func (d *ignoreDirectory) Readdir(ctx context.Context) (fs.Entries, error) {
 	entries, err := d.Directory.Readdir(ctx)
 	if err != nil {
@@ -25,6 +25,7 @@ type filesystemEntry struct {
 	mode       os.FileMode
 	owner      fs.OwnerInfo
 	device     fs.DeviceInfo
+	inode      fs.InodeInfo
 
 	parentDir string
 }
@@ -65,10 +66,18 @@ func (e *filesystemEntry) Device() fs.DeviceInfo {
 	return e.device
 }
 
+func (e *filesystemEntry) InodeInfo() fs.InodeInfo {
+	return e.inode
+}
+
 func (e *filesystemEntry) LocalFilesystemPath() string {
 	return e.fullPath()
 }
 
+func (e *filesystemEntry) ExtendedAttributes(ctx context.Context) (fs.ExtendedAttributes, error) {
+	return platformSpecificExtendedAttributes(e.fullPath())
+}
+
 var _ os.FileInfo = (*filesystemEntry)(nil)
 
 func newEntry(fi os.FileInfo, parentDir string) filesystemEntry {
@@ -79,6 +88,7 @@ func newEntry(fi os.FileInfo, parentDir string) filesystemEntry {
 		fi.Mode(),
 		platformSpecificOwnerInfo(fi),
 		platformSpecificDeviceInfo(fi),
+		platformSpecificInodeInfo(fi),
 		parentDir,
 	}
 }
@@ -303,6 +313,9 @@ func entryFromChildFileInfo(fi os.FileInfo, parentDir string) fs.Entry {
 }
 
 var (
+	_ fs.EntryWithExtendedAttributes = &filesystemEntry{}
+	_ fs.EntryWithInodeInfo          = &filesystemEntry{}
+
 	_ fs.Directory  = &filesystemDirectory{}
 	_ fs.File       = &filesystemFile{}
 	_ fs.Symlink    = &filesystemSymlink{}
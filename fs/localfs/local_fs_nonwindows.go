@@ -29,3 +29,14 @@ func platformSpecificDeviceInfo(fi os.FileInfo) fs.DeviceInfo {
 
 	return oi
 }
+
+func platformSpecificInodeInfo(fi os.FileInfo) fs.InodeInfo {
+	var ii fs.InodeInfo
+	if stat, ok := fi.Sys().(*syscall.Stat_t); ok {
+		// widths of these fields differ between platforms.
+		ii.Inode = uint64(stat.Ino)       //nolint:unconvert
+		ii.LinkCount = uint64(stat.Nlink) //nolint:unconvert
+	}
+
+	return ii
+}
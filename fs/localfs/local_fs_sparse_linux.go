@@ -0,0 +1,39 @@
+package localfs
+
+import (
+	"io"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+
+	"github.com/kopia/kopia/fs"
+)
+
+// whence values of lseek() on Linux, see lseek(2).
+const (
+	seekData = 3
+	seekHole = 4
+)
+
+// NextDataRegion implements fs.SparseReader using SEEK_DATA and SEEK_HOLE.
+// File systems that don't support them report the entire file as a single region of data.
+func (f *fileWithMetadata) NextDataRegion(offset int64) (start, end int64, err error) {
+	start, err = f.Seek(offset, seekData)
+	if errors.Is(err, unix.ENXIO) {
+		// no more data after the offset.
+		return 0, 0, io.EOF
+	}
+
+	if err != nil {
+		return 0, 0, errors.Wrap(err, "SEEK_DATA")
+	}
+
+	end, err = f.Seek(start, seekHole)
+	if err != nil {
+		return 0, 0, errors.Wrap(err, "SEEK_HOLE")
+	}
+
+	return start, end, nil
+}
+
+var _ fs.SparseReader = (*fileWithMetadata)(nil)
@@ -13,3 +13,7 @@ func platformSpecificOwnerInfo(fi os.FileInfo) fs.OwnerInfo {
 func platformSpecificDeviceInfo(fi os.FileInfo) fs.DeviceInfo {
 	return fs.DeviceInfo{}
 }
+
+func platformSpecificInodeInfo(fi os.FileInfo) fs.InodeInfo {
+	return fs.InodeInfo{}
+}
@@ -0,0 +1,87 @@
+package localfs
+
+import (
+	"bytes"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+
+	"github.com/kopia/kopia/fs"
+)
+
+// platformSpecificExtendedAttributes returns all extended attributes of the file, without following symlinks.
+// This includes POSIX ACLs, which Linux exposes as 'system.posix_acl_access' and 'system.posix_acl_default'.
+func platformSpecificExtendedAttributes(path string) (fs.ExtendedAttributes, error) {
+	names, err := readXattr(func(dest []byte) (int, error) {
+		return unix.Llistxattr(path, dest)
+	})
+	if err != nil {
+		if isXattrUnsupported(err) {
+			return nil, nil
+		}
+
+		return nil, errors.Wrapf(err, "unable to list extended attributes of %v", path)
+	}
+
+	var result fs.ExtendedAttributes
+
+	for _, name := range bytes.Split(names, []byte{0}) {
+		if len(name) == 0 {
+			continue
+		}
+
+		n := string(name)
+
+		v, err := readXattr(func(dest []byte) (int, error) {
+			return unix.Lgetxattr(path, n, dest)
+		})
+
+		switch {
+		case errors.Is(err, unix.ENODATA):
+			// attribute removed after listing.
+			continue
+		case err != nil:
+			return nil, errors.Wrapf(err, "unable to get extended attribute %v of %v", n, path)
+		}
+
+		if result == nil {
+			result = fs.ExtendedAttributes{}
+		}
+
+		result[n] = v
+	}
+
+	return result, nil
+}
+
+// readXattr invokes the provided xattr syscall wrapper first to determine the size of the result
+// and then to read it, retrying if the value grows in the meantime.
+func readXattr(f func(dest []byte) (int, error)) ([]byte, error) {
+	for {
+		sz, err := f(nil)
+		if err != nil {
+			return nil, err
+		}
+
+		if sz == 0 {
+			return nil, nil
+		}
+
+		buf := make([]byte, sz)
+
+		sz, err = f(buf)
+		if errors.Is(err, unix.ERANGE) {
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		return buf[0:sz], nil
+	}
+}
+
+func isXattrUnsupported(err error) bool {
+	return errors.Is(err, unix.ENOTSUP) || errors.Is(err, unix.EOPNOTSUPP)
+}
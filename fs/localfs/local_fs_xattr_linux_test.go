@@ -0,0 +1,41 @@
+package localfs
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+
+	"github.com/kopia/kopia/fs"
+	"github.com/kopia/kopia/internal/testlogging"
+	"github.com/kopia/kopia/internal/testutil"
+)
+
+func TestExtendedAttributes(t *testing.T) {
+	ctx := testlogging.Context(t)
+	tmp := testutil.TempDirectory(t)
+	fn := filepath.Join(tmp, "f")
+
+	require.NoError(t, ioutil.WriteFile(fn, []byte{1, 2, 3}, 0o600))
+
+	e, err := NewEntry(fn)
+	require.NoError(t, err)
+
+	attrs, err := fs.GetExtendedAttributes(ctx, e)
+	require.NoError(t, err)
+	require.Empty(t, attrs)
+
+	if err := unix.Setxattr(fn, "user.a", []byte("value-a"), 0); err != nil {
+		t.Skipf("extended attributes not supported: %v", err)
+	}
+
+	require.NoError(t, unix.Setxattr(fn, "user.empty", nil, 0))
+
+	attrs, err = fs.GetExtendedAttributes(ctx, e)
+	require.NoError(t, err)
+	require.Len(t, attrs, 2)
+	require.Equal(t, []byte("value-a"), attrs["user.a"])
+	require.Empty(t, attrs["user.empty"])
+}
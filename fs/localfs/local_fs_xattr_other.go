@@ -0,0 +1,11 @@
+// +build !linux
+
+package localfs
+
+import (
+	"github.com/kopia/kopia/fs"
+)
+
+func platformSpecificExtendedAttributes(path string) (fs.ExtendedAttributes, error) {
+	return nil, nil
+}
@@ -23,6 +23,9 @@
 	OwnHost = "OWN_HOST"
 )
 
+// GroupPrefix is the prefix of ACL entry user that targets all members of a group, e.g. "group:admins".
+const GroupPrefix = "group:"
+
 // TargetRule specifies a list of key and values that must match labels on the target manifest.
 // The value can have two special placeholders - OWN_USER and OWN_VALUE representing the matched user
 // and host respectively if wildcards are being used.
@@ -64,7 +67,7 @@ func (r TargetRule) matches(target map[string]string, username, hostname string)
 // user certain level of access to a target.
 type Entry struct {
 	ManifestID manifest.ID `json:"-"`
-	User       string      `json:"user"`   // supports wildcards such as "*@*", "user@host", "*@host, user@*"
+	User       string      `json:"user"`   // supports wildcards such as "*@*", "user@host", "*@host, user@*" and groups "group:name"
 	Target     TargetRule  `json:"target"` // supports OwnUser and OwnHost in labels
 	Access     AccessLevel `json:"access,omitempty"`
 }
@@ -112,6 +115,9 @@ func oneOf(allowed ...string) valueValidatorFunc {
 	user.ManifestType: {
 		user.UsernameAtHostnameLabel: nonEmptyString,
 	},
+	user.GroupManifestType: {
+		user.GroupNameLabel: nonEmptyString,
+	},
 	aclManifestType: {},
 }
 
@@ -121,8 +127,11 @@ func (e *Entry) Validate() error {
 		return errors.Errorf("nil acl")
 	}
 
-	parts := strings.Split(e.User, "@")
-	if len(parts) != 2 { //nolint:gomnd
+	if strings.HasPrefix(e.User, GroupPrefix) {
+		if err := user.ValidateGroupName(strings.TrimPrefix(e.User, GroupPrefix)); err != nil {
+			return errors.Wrap(err, "invalid group")
+		}
+	} else if parts := strings.Split(e.User, "@"); len(parts) != 2 { //nolint:gomnd
 		return errors.Errorf("user must be 'username@hostname' possibly including wildcards")
 	}
 
@@ -23,7 +23,20 @@ func matchOrWildcard(rule, actual string) bool {
 	return rule == actual
 }
 
-func userMatches(rule, username, hostname string) bool {
+// userMatches determines whether the rule matches the given user, which belongs to the provided groups.
+func userMatches(rule, username, hostname string, groups []string) bool {
+	if strings.HasPrefix(rule, GroupPrefix) {
+		name := strings.TrimPrefix(rule, GroupPrefix)
+
+		for _, g := range groups {
+			if g == name {
+				return true
+			}
+		}
+
+		return false
+	}
+
 	ruleParts := strings.Split(rule, "@")
 	if len(ruleParts) != 2 { // nolint:gomnd
 		return false
@@ -32,12 +45,12 @@ func userMatches(rule, username, hostname string) bool {
 	return matchOrWildcard(ruleParts[0], username) && matchOrWildcard(ruleParts[1], hostname)
 }
 
-// EntriesForUser computes the list of ACL entries matching the given user.
-func EntriesForUser(entries []*Entry, username, hostname string) []*Entry {
+// EntriesForUser computes the list of ACL entries matching the given user, which belongs to the provided groups.
+func EntriesForUser(entries []*Entry, username, hostname string, groups ...string) []*Entry {
 	result := []*Entry{}
 
 	for _, e := range entries {
-		if userMatches(e.User, username, hostname) {
+		if userMatches(e.User, username, hostname, groups) {
 			result = append(result, e)
 		}
 	}
@@ -45,13 +58,13 @@ func EntriesForUser(entries []*Entry, username, hostname string) []*Entry {
 	return result
 }
 
-// EffectivePermissions computes the effective access level for a given user@hostname to subject
-// for a given set of ACL Entries.
-func EffectivePermissions(username, hostname string, target map[string]string, entries []*Entry) AccessLevel {
+// EffectivePermissions computes the effective access level for a given user@hostname, which belongs
+// to the provided groups, to subject for a given set of ACL Entries.
+func EffectivePermissions(username, hostname string, target map[string]string, entries []*Entry, groups ...string) AccessLevel {
 	highest := AccessLevelNone
 
 	for _, e := range entries {
-		if !userMatches(e.User, username, hostname) {
+		if !userMatches(e.User, username, hostname, groups) {
 			continue
 		}
 
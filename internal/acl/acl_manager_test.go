@@ -149,6 +149,39 @@ func TestEffectivePermissions(t *testing.T) {
 	}
 }
 
+func TestEffectivePermissions_Groups(t *testing.T) {
+	entries := []*acl.Entry{
+		{
+			Target: acl.TargetRule{manifest.TypeLabelKey: snapshot.ManifestType},
+			User:   "group:ops",
+			Access: acl.AccessLevelRead,
+		},
+		{
+			Target: acl.TargetRule{manifest.TypeLabelKey: snapshot.ManifestType, snapshot.UsernameLabel: acl.OwnUser},
+			User:   "group:admins",
+			Access: acl.AccessLevelFull,
+		},
+	}
+
+	cases := []struct {
+		groups []string
+		target map[string]string
+		want   acl.AccessLevel
+	}{
+		{nil, map[string]string{manifest.TypeLabelKey: snapshot.ManifestType}, acl.AccessLevelNone},
+		{[]string{"other"}, map[string]string{manifest.TypeLabelKey: snapshot.ManifestType}, acl.AccessLevelNone},
+		{[]string{"ops"}, map[string]string{manifest.TypeLabelKey: snapshot.ManifestType}, acl.AccessLevelRead},
+		{[]string{"admins"}, map[string]string{manifest.TypeLabelKey: snapshot.ManifestType}, acl.AccessLevelNone},
+		{[]string{"ops", "admins"}, map[string]string{manifest.TypeLabelKey: snapshot.ManifestType, snapshot.UsernameLabel: actualUser}, acl.AccessLevelFull},
+		{[]string{"ops", "admins"}, map[string]string{manifest.TypeLabelKey: snapshot.ManifestType, snapshot.UsernameLabel: "another-user"}, acl.AccessLevelRead},
+	}
+
+	for _, tc := range cases {
+		require.Equal(t, tc.want, acl.EffectivePermissions(actualUser, actualHostname, tc.target, entries, tc.groups...), "groups: %v target: %v", tc.groups, tc.target)
+		require.Equal(t, tc.want, acl.EffectivePermissions(actualUser, actualHostname, tc.target, acl.EntriesForUser(entries, actualUser, actualHostname, tc.groups...), tc.groups...))
+	}
+}
+
 func TestLoadEntries(t *testing.T) {
 	ctx, env := repotesting.NewEnvironment(t)
 
@@ -211,6 +244,30 @@ func TestACLEntryValidation(t *testing.T) {
 			Entry:   nil,
 			WantErr: "nil acl",
 		},
+		{
+			Entry: &acl.Entry{
+				User:   "group:ops",
+				Target: acl.TargetRule{"type": "snapshot"},
+				Access: acl.AccessLevelRead,
+			},
+			WantErr: "",
+		},
+		{
+			Entry: &acl.Entry{
+				User:   "group:",
+				Target: acl.TargetRule{"type": "snapshot"},
+				Access: acl.AccessLevelRead,
+			},
+			WantErr: "invalid group: group name is required",
+		},
+		{
+			Entry: &acl.Entry{
+				User:   "group:Some Group",
+				Target: acl.TargetRule{"type": "snapshot"},
+				Access: acl.AccessLevelRead,
+			},
+			WantErr: "invalid group: group name must consist of lowercase letters, digits, dashes, underscores or periods",
+		},
 		{
 			Entry: &acl.Entry{
 				User: "foo@bar",
@@ -220,7 +277,7 @@ func TestACLEntryValidation(t *testing.T) {
 				},
 				Access: acl.AccessLevelFull,
 			},
-			WantErr: "invalid 'type' label, must be one of: acl, content, policy, snapshot, user",
+			WantErr: "invalid 'type' label, must be one of: acl, content, policy, snapshot, user, usergroup",
 		},
 		{
 			Entry: &acl.Entry{
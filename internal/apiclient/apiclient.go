@@ -137,6 +137,10 @@ type Options struct {
 
 	TrustedServerCertificateFingerprint string
 
+	// optional PEM files with client certificate and key used for TLS client authentication.
+	ClientCertificateFile string
+	ClientKeyFile         string
+
 	LogRequests bool
 }
 
@@ -144,9 +148,14 @@ type Options struct {
 func NewKopiaAPIClient(options Options) (*KopiaAPIClient, error) {
 	var transport http.RoundTripper
 
-	// override transport which trusts only one certificate
-	if f := options.TrustedServerCertificateFingerprint; f != "" {
-		transport = tlsutil.TransportTrustingSingleCertificate(f)
+	// override transport which trusts only one certificate or presents client certificate
+	if options.TrustedServerCertificateFingerprint != "" || options.ClientCertificateFile != "" {
+		tlsConfig, err := tlsutil.ClientTLSConfig(options.TrustedServerCertificateFingerprint, options.ClientCertificateFile, options.ClientKeyFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to initialize TLS")
+		}
+
+		transport = tlsutil.TransportWithTLSConfig(tlsConfig)
 	} else {
 		transport = http.DefaultTransport
 	}
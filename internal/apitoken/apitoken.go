@@ -0,0 +1,241 @@
+// Package apitoken provides management of expiring API tokens that allow repository users
+// to authenticate to the server without their password.
+package apitoken
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/kopia/kopia/internal/acl"
+	"github.com/kopia/kopia/internal/user"
+	"github.com/kopia/kopia/repo"
+	"github.com/kopia/kopia/repo/manifest"
+)
+
+// ManifestType is the type of the manifest used to represent API tokens.
+const ManifestType = "apiToken"
+
+// TokenIDLabel is the manifest label identifying API tokens.
+const TokenIDLabel = "tokenID"
+
+// tokenPrefix is the prefix of all API tokens, which allows them to be told apart from passwords.
+const tokenPrefix = "kt."
+
+const (
+	tokenIDLength     = 8
+	tokenSecretLength = 32
+)
+
+// ErrTokenNotFound is returned to indicate that an API token was not found in the system.
+var ErrTokenNotFound = errors.New("token not found")
+
+// Token describes a single API token. Only the hash of the token secret is stored.
+type Token struct {
+	ManifestID manifest.ID `json:"-"`
+
+	ID          string    `json:"id"`
+	Username    string    `json:"username"`
+	Description string    `json:"description,omitempty"`
+	CreatedAt   time.Time `json:"created"`
+	ExpiresAt   time.Time `json:"expires"`
+
+	// MaxAccess, when set, limits the access of the token below the access level of the user.
+	MaxAccess acl.AccessLevel `json:"maxAccess,omitempty"`
+
+	SecretHash []byte `json:"secretHash"`
+}
+
+// IsExpired returns true if the token is expired at the provided time.
+func (t *Token) IsExpired(now time.Time) bool {
+	return !now.Before(t.ExpiresAt)
+}
+
+// IsValidSecret determines whether the secret is valid for the token at the provided time.
+func (t *Token) IsValidSecret(secret string, now time.Time) bool {
+	if t == nil {
+		// compute the hash anyway to avoid revealing whether the token exists.
+		hashSecret(secret)
+
+		return false
+	}
+
+	return subtle.ConstantTimeCompare(hashSecret(secret), t.SecretHash) == 1 && !t.IsExpired(now)
+}
+
+// LimitAccess returns the provided access level capped at the maximum access level of the token.
+func (t *Token) LimitAccess(level acl.AccessLevel) acl.AccessLevel {
+	if t.MaxAccess != 0 && level > t.MaxAccess {
+		return t.MaxAccess
+	}
+
+	return level
+}
+
+func hashSecret(secret string) []byte {
+	h := sha256.Sum256([]byte(secret))
+	return h[:]
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := io.ReadFull(rand.Reader, b); err != nil {
+		return "", errors.Wrap(err, "error generating random bytes")
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+// Parse splits the API token string into token ID and secret.
+// Returns false if the string is not an API token.
+func Parse(s string) (id, secret string, ok bool) {
+	if !strings.HasPrefix(s, tokenPrefix) {
+		return "", "", false
+	}
+
+	parts := strings.Split(strings.TrimPrefix(s, tokenPrefix), ".")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" { //nolint:gomnd
+		return "", "", false
+	}
+
+	return parts[0], parts[1], true
+}
+
+// Create creates and saves a new API token for the provided user and returns it along with the
+// token string, which is never stored and must be passed by clients instead of the password.
+func Create(ctx context.Context, w repo.RepositoryWriter, username, description string, validity time.Duration, maxAccess acl.AccessLevel) (*Token, string, error) {
+	if _, err := user.GetUserProfile(ctx, w, username); err != nil {
+		return nil, "", errors.Wrap(err, "unable to get user profile")
+	}
+
+	if validity <= 0 {
+		return nil, "", errors.Errorf("token validity must be positive")
+	}
+
+	id, err := randomHex(tokenIDLength)
+	if err != nil {
+		return nil, "", err
+	}
+
+	secret, err := randomHex(tokenSecretLength)
+	if err != nil {
+		return nil, "", err
+	}
+
+	now := w.Time()
+
+	t := &Token{
+		ID:          id,
+		Username:    username,
+		Description: description,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(validity),
+		MaxAccess:   maxAccess,
+		SecretHash:  hashSecret(secret),
+	}
+
+	t.ManifestID, err = w.PutManifest(ctx, map[string]string{
+		manifest.TypeLabelKey:        ManifestType,
+		user.UsernameAtHostnameLabel: username,
+		TokenIDLabel:                 id,
+	}, t)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "error writing token")
+	}
+
+	return t, tokenPrefix + id + "." + secret, nil
+}
+
+// LoadTokenMap returns the map of all API tokens in the repository by token ID, using old map as a cache.
+func LoadTokenMap(ctx context.Context, rep repo.Repository, old map[string]*Token) (map[string]*Token, error) {
+	if rep == nil {
+		return nil, nil
+	}
+
+	entries, err := rep.FindManifests(ctx, map[string]string{manifest.TypeLabelKey: ManifestType})
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing token manifests")
+	}
+
+	result := map[string]*Token{}
+
+	for _, m := range entries {
+		id := m.Labels[TokenIDLabel]
+
+		// same token as before
+		if o := old[id]; o != nil && o.ManifestID == m.ID {
+			result[id] = o
+			continue
+		}
+
+		t := &Token{}
+		if _, err := rep.GetManifest(ctx, m.ID, t); err != nil {
+			return nil, errors.Wrapf(err, "error loading token manifest %v", id)
+		}
+
+		// the labels are what write access to the manifest is authorized against, ignore tokens
+		// claiming to be issued to another user.
+		if t.Username != m.Labels[user.UsernameAtHostnameLabel] {
+			continue
+		}
+
+		t.ManifestID = m.ID
+
+		result[id] = t
+	}
+
+	return result, nil
+}
+
+// List returns API tokens of the provided user or all users if username is empty, sorted by creation time.
+func List(ctx context.Context, rep repo.Repository, username string) ([]*Token, error) {
+	tokens, err := LoadTokenMap(ctx, rep, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []*Token
+
+	for _, t := range tokens {
+		if username == "" || t.Username == username {
+			result = append(result, t)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+
+	return result, nil
+}
+
+// Revoke deletes the API token with a given ID.
+func Revoke(ctx context.Context, w repo.RepositoryWriter, id string) error {
+	manifests, err := w.FindManifests(ctx, map[string]string{
+		manifest.TypeLabelKey: ManifestType,
+		TokenIDLabel:          id,
+	})
+	if err != nil {
+		return errors.Wrap(err, "error looking for token")
+	}
+
+	if len(manifests) == 0 {
+		return errors.Wrap(ErrTokenNotFound, id)
+	}
+
+	for _, m := range manifests {
+		if err := w.DeleteManifest(ctx, m.ID); err != nil {
+			return errors.Wrapf(err, "error deleting token %v", id)
+		}
+	}
+
+	return nil
+}
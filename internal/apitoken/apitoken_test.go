@@ -0,0 +1,74 @@
+package apitoken_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kopia/kopia/internal/acl"
+	"github.com/kopia/kopia/internal/apitoken"
+	"github.com/kopia/kopia/internal/clock"
+	"github.com/kopia/kopia/internal/repotesting"
+	"github.com/kopia/kopia/internal/user"
+)
+
+func TestTokens(t *testing.T) {
+	ctx, env := repotesting.NewEnvironment(t)
+
+	_, _, err := apitoken.Create(ctx, env.RepositoryWriter, "alice@somehost", "ci", time.Hour, 0)
+	require.True(t, errors.Is(err, user.ErrUserNotFound), "unexpected error: %v", err)
+
+	for _, u := range []string{"alice@somehost", "bob@somehost"} {
+		require.NoError(t, user.SetUserProfile(ctx, env.RepositoryWriter, &user.Profile{Username: u}))
+	}
+
+	_, _, err = apitoken.Create(ctx, env.RepositoryWriter, "alice@somehost", "ci", 0, 0)
+	require.Error(t, err)
+
+	t1, s1, err := apitoken.Create(ctx, env.RepositoryWriter, "alice@somehost", "ci", time.Hour, acl.AccessLevelAppend)
+	require.NoError(t, err)
+
+	_, _, err = apitoken.Create(ctx, env.RepositoryWriter, "bob@somehost", "laptop", time.Hour, 0)
+	require.NoError(t, err)
+
+	id, secret, ok := apitoken.Parse(s1)
+	require.True(t, ok)
+	require.Equal(t, t1.ID, id)
+
+	now := clock.Now()
+
+	require.True(t, t1.IsValidSecret(secret, now))
+	require.False(t, t1.IsValidSecret(secret+"x", now))
+	require.False(t, t1.IsValidSecret(secret, t1.ExpiresAt))
+
+	var nilToken *apitoken.Token
+	require.False(t, nilToken.IsValidSecret(secret, now))
+
+	require.Equal(t, acl.AccessLevelRead, t1.LimitAccess(acl.AccessLevelRead))
+	require.Equal(t, acl.AccessLevelAppend, t1.LimitAccess(acl.AccessLevelFull))
+
+	for _, s := range []string{"password", "kt.", "kt.abc", "kt..secret", "kt.abc."} {
+		_, _, ok := apitoken.Parse(s)
+		require.False(t, ok, s)
+	}
+
+	all, err := apitoken.List(ctx, env.RepositoryWriter, "")
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+
+	alice, err := apitoken.List(ctx, env.RepositoryWriter, "alice@somehost")
+	require.NoError(t, err)
+	require.Len(t, alice, 1)
+	require.Equal(t, "ci", alice[0].Description)
+	require.Equal(t, acl.AccessLevelAppend, alice[0].MaxAccess)
+	require.Equal(t, t1.SecretHash, alice[0].SecretHash)
+
+	require.NoError(t, apitoken.Revoke(ctx, env.RepositoryWriter, t1.ID))
+	require.True(t, errors.Is(apitoken.Revoke(ctx, env.RepositoryWriter, t1.ID), apitoken.ErrTokenNotFound))
+
+	alice, err = apitoken.List(ctx, env.RepositoryWriter, "alice@somehost")
+	require.NoError(t, err)
+	require.Empty(t, alice)
+}
@@ -51,6 +51,14 @@ func (c combinedAuthenticator) IsValid(ctx context.Context, rep repo.Repository,
 	return false
 }
 
+func (c combinedAuthenticator) LimitAccess(ctx context.Context, rep repo.Repository, username, password string, authz AuthorizationInfo) AuthorizationInfo {
+	for _, a := range c {
+		authz = LimitAccess(ctx, a, rep, username, password, authz)
+	}
+
+	return authz
+}
+
 func (c combinedAuthenticator) Refresh(ctx context.Context) error {
 	for _, a := range c {
 		if err := a.Refresh(ctx); err != nil {
@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/kopia/kopia/repo"
+)
+
+// Identity describes the user identified by a bearer token.
+type Identity struct {
+	Username string   `json:"username"` // user@host
+	Groups   []string `json:"groups,omitempty"`
+}
+
+// BearerAuthenticator is implemented by authenticators that accept bearer tokens, which carry
+// the identity of the user instead of username and password.
+type BearerAuthenticator interface {
+	AuthenticateBearer(ctx context.Context, rep repo.Repository, token string) (Identity, bool)
+}
+
+func (c combinedAuthenticator) AuthenticateBearer(ctx context.Context, rep repo.Repository, token string) (Identity, bool) {
+	for _, a := range c {
+		if id, ok := AuthenticateBearer(ctx, a, rep, token); ok {
+			return id, true
+		}
+	}
+
+	return Identity{}, false
+}
+
+// AuthenticateBearer returns the identity of the user presenting the provided bearer token, if the authenticator
+// supports bearer tokens and the token is valid.
+func AuthenticateBearer(ctx context.Context, a Authenticator, rep repo.Repository, token string) (Identity, bool) {
+	if b, ok := a.(BearerAuthenticator); ok && token != "" {
+		return b.AuthenticateBearer(ctx, rep, token)
+	}
+
+	return Identity{}, false
+}
+
+type groupsContextKeyType string
+
+const groupsContextKey groupsContextKeyType = "auth-groups"
+
+// ContextWithGroups returns a context that carries the groups the authenticated user belongs to.
+func ContextWithGroups(ctx context.Context, groups []string) context.Context {
+	if len(groups) == 0 {
+		return ctx
+	}
+
+	return context.WithValue(ctx, groupsContextKey, groups)
+}
+
+// GroupsFromContext returns the groups of the authenticated user stored in the context by ContextWithGroups.
+func GroupsFromContext(ctx context.Context) []string {
+	g, _ := ctx.Value(groupsContextKey).([]string)
+
+	return g
+}
+
+var _ BearerAuthenticator = combinedAuthenticator(nil)
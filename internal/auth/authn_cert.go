@@ -0,0 +1,42 @@
+package auth
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"strings"
+)
+
+// ClientCertificateIdentity returns the identity of the user presenting the provided client certificate.
+//
+// The username is taken from the first e-mail Subject Alternative Name or the Common Name of the certificate
+// subject, whichever is in 'user@host' format. Organizational units of the subject are treated as user groups.
+func ClientCertificateIdentity(cert *x509.Certificate) (Identity, bool) {
+	candidates := append(append([]string(nil), cert.EmailAddresses...), cert.Subject.CommonName)
+
+	for _, c := range candidates {
+		if isUsernameAtHostname(c) {
+			return Identity{
+				Username: strings.ToLower(c),
+				Groups:   cert.Subject.OrganizationalUnit,
+			}, true
+		}
+	}
+
+	return Identity{}, false
+}
+
+// VerifiedClientIdentity returns the identity of the user from the client certificate
+// presented during TLS handshake, provided it was verified by the server.
+func VerifiedClientIdentity(cs *tls.ConnectionState) (Identity, bool) {
+	if cs == nil || len(cs.VerifiedChains) == 0 || len(cs.VerifiedChains[0]) == 0 {
+		return Identity{}, false
+	}
+
+	return ClientCertificateIdentity(cs.VerifiedChains[0][0])
+}
+
+func isUsernameAtHostname(s string) bool {
+	p := strings.Index(s, "@")
+
+	return p > 0 && p < len(s)-1 && strings.LastIndex(s, "@") == p
+}
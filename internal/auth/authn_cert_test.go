@@ -0,0 +1,54 @@
+package auth_test
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kopia/kopia/internal/auth"
+)
+
+func TestClientCertificateIdentity(t *testing.T) {
+	cases := []struct {
+		cert   *x509.Certificate
+		want   auth.Identity
+		wantOK bool
+	}{
+		{
+			cert:   &x509.Certificate{Subject: pkix.Name{CommonName: "Alice@Laptop", OrganizationalUnit: []string{"admins"}}},
+			want:   auth.Identity{Username: "alice@laptop", Groups: []string{"admins"}},
+			wantOK: true,
+		},
+		{
+			cert:   &x509.Certificate{Subject: pkix.Name{CommonName: "Alice"}, EmailAddresses: []string{"alice@workstation"}},
+			want:   auth.Identity{Username: "alice@workstation"},
+			wantOK: true,
+		},
+		{
+			// e-mail SAN is preferred over common name.
+			cert:   &x509.Certificate{Subject: pkix.Name{CommonName: "bob@laptop"}, EmailAddresses: []string{"alice@workstation"}},
+			want:   auth.Identity{Username: "alice@workstation"},
+			wantOK: true,
+		},
+		{
+			cert: &x509.Certificate{Subject: pkix.Name{CommonName: "alice"}},
+		},
+		{
+			cert: &x509.Certificate{Subject: pkix.Name{CommonName: "@laptop"}, EmailAddresses: []string{"alice@"}},
+		},
+		{
+			cert: &x509.Certificate{Subject: pkix.Name{CommonName: "alice@some@laptop"}},
+		},
+	}
+
+	for _, tc := range cases {
+		id, ok := auth.ClientCertificateIdentity(tc.cert)
+		require.Equal(t, tc.wantOK, ok, tc.cert.Subject.CommonName)
+		require.Equal(t, tc.want, id, tc.cert.Subject.CommonName)
+	}
+
+	_, ok := auth.VerifiedClientIdentity(nil)
+	require.False(t, ok)
+}
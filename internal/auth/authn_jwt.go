@@ -0,0 +1,319 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/pkg/errors"
+
+	"github.com/kopia/kopia/internal/clock"
+	"github.com/kopia/kopia/repo"
+)
+
+const (
+	defaultJWTUsernameClaim = "sub"
+	defaultJWTGroupsClaim   = "groups"
+
+	// minimum time between reloads of JWKS triggered by tokens signed with unknown keys.
+	minJWKSReloadInterval = time.Minute
+
+	jwksFetchTimeout = 30 * time.Second
+	maxJWKSSize      = 1 << 20
+)
+
+// JWTOptions provides options for authenticating users with JWT bearer tokens, such as OpenID Connect ID tokens.
+type JWTOptions struct {
+	JWKSFile string // local file containing JSON Web Key Set
+	JWKSURL  string // URL of JSON Web Key Set, such as the jwks_uri of OpenID Connect provider
+
+	Issuer   string // if set, 'iss' claim must match
+	Audience string // if set, 'aud' claim must contain it
+
+	UsernameClaim   string // claim holding the username, defaults to 'sub'
+	HostnameClaim   string // optional claim holding the hostname
+	DefaultHostname string // hostname to use when the username claim does not include one
+	GroupsClaim     string // claim holding the list of groups, defaults to 'groups'
+}
+
+type jwtAuthenticator struct {
+	options JWTOptions
+	parser  *jwt.Parser
+
+	mu             sync.Mutex
+	keys           map[string]interface{}
+	lastReloadTime time.Time
+}
+
+func (a *jwtAuthenticator) IsValid(ctx context.Context, rep repo.Repository, username, password string) bool {
+	id, ok := a.AuthenticateBearer(ctx, rep, password)
+
+	// usernames in tokens are case-insensitive and normalized to lowercase, normalize the provided one the same way.
+	return ok && id.Username == strings.ToLower(username)
+}
+
+func (a *jwtAuthenticator) AuthenticateBearer(ctx context.Context, rep repo.Repository, token string) (Identity, bool) {
+	// quickly reject anything that does not look like a JWS in compact serialization.
+	if strings.Count(token, ".") != 2 { //nolint:gomnd
+		return Identity{}, false
+	}
+
+	claims := jwt.MapClaims{}
+
+	if _, err := a.parser.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		return a.verificationKey(ctx, t)
+	}); err != nil {
+		log(ctx).Debugf("invalid JWT: %v", err)
+		return Identity{}, false
+	}
+
+	if err := a.verifyClaims(claims); err != nil {
+		log(ctx).Debugf("invalid JWT claims: %v", err)
+		return Identity{}, false
+	}
+
+	id, err := a.identityFromClaims(claims)
+	if err != nil {
+		log(ctx).Debugf("unable to determine identity from JWT: %v", err)
+		return Identity{}, false
+	}
+
+	return id, true
+}
+
+// verificationKey returns the key that was used to sign the provided token making sure that its type
+// matches the signing method.
+func (a *jwtAuthenticator) verificationKey(ctx context.Context, t *jwt.Token) (interface{}, error) {
+	kid, _ := t.Header["kid"].(string)
+
+	key := a.findKey(ctx, kid)
+	if key == nil {
+		return nil, errors.Errorf("unknown signing key %q", kid)
+	}
+
+	switch t.Method.(type) {
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS:
+		if _, ok := key.(*rsa.PublicKey); ok {
+			return key, nil
+		}
+
+	case *jwt.SigningMethodECDSA:
+		if _, ok := key.(*ecdsa.PublicKey); ok {
+			return key, nil
+		}
+	}
+
+	return nil, errors.Errorf("signing method %v does not match key %q", t.Method.Alg(), kid)
+}
+
+func (a *jwtAuthenticator) findKey(ctx context.Context, kid string) interface{} {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if k := a.lookupKeyLocked(kid); k != nil {
+		return k
+	}
+
+	// the signing keys may have been rotated, try reloading them, but not too often.
+	if clock.Now().Sub(a.lastReloadTime) < minJWKSReloadInterval {
+		return nil
+	}
+
+	if err := a.reloadLocked(ctx); err != nil {
+		log(ctx).Errorf("unable to reload JWKS: %v", err)
+		return nil
+	}
+
+	return a.lookupKeyLocked(kid)
+}
+
+func (a *jwtAuthenticator) lookupKeyLocked(kid string) interface{} {
+	if k, ok := a.keys[kid]; ok {
+		return k
+	}
+
+	// tokens without key ID are accepted when there's only one key.
+	if kid == "" && len(a.keys) == 1 {
+		for _, k := range a.keys {
+			return k
+		}
+	}
+
+	return nil
+}
+
+func (a *jwtAuthenticator) verifyClaims(claims jwt.MapClaims) error {
+	// MapClaims.Valid() treats missing expiration as valid, we don't accept tokens that never expire.
+	if _, ok := claims["exp"]; !ok {
+		return errors.Errorf("missing 'exp' claim")
+	}
+
+	if a.options.Issuer != "" && !claims.VerifyIssuer(a.options.Issuer, true) {
+		return errors.Errorf("unexpected issuer")
+	}
+
+	if a.options.Audience != "" && !containsString(stringsClaim(claims, "aud"), a.options.Audience) {
+		return errors.Errorf("unexpected audience")
+	}
+
+	return nil
+}
+
+func (a *jwtAuthenticator) identityFromClaims(claims jwt.MapClaims) (Identity, error) {
+	usernameClaim := a.options.UsernameClaim
+	if usernameClaim == "" {
+		usernameClaim = defaultJWTUsernameClaim
+	}
+
+	username, _ := claims[usernameClaim].(string)
+	if username == "" {
+		return Identity{}, errors.Errorf("missing %q claim", usernameClaim)
+	}
+
+	if !strings.Contains(username, "@") {
+		hostname := a.options.DefaultHostname
+
+		if a.options.HostnameClaim != "" {
+			if h, _ := claims[a.options.HostnameClaim].(string); h != "" {
+				hostname = h
+			}
+		}
+
+		if hostname == "" {
+			return Identity{}, errors.Errorf("unable to determine hostname of %q", username)
+		}
+
+		username += "@" + hostname
+	}
+
+	groupsClaim := a.options.GroupsClaim
+	if groupsClaim == "" {
+		groupsClaim = defaultJWTGroupsClaim
+	}
+
+	return Identity{
+		Username: strings.ToLower(username),
+		Groups:   stringsClaim(claims, groupsClaim),
+	}, nil
+}
+
+func (a *jwtAuthenticator) reloadLocked(ctx context.Context) error {
+	a.lastReloadTime = clock.Now()
+
+	data, err := a.readJWKS(ctx)
+	if err != nil {
+		return err
+	}
+
+	keys, err := parseJWKS(data)
+	if err != nil {
+		return err
+	}
+
+	a.keys = keys
+
+	return nil
+}
+
+func (a *jwtAuthenticator) readJWKS(ctx context.Context) ([]byte, error) {
+	if a.options.JWKSFile != "" {
+		data, err := ioutil.ReadFile(a.options.JWKSFile)
+		return data, errors.Wrap(err, "unable to read JWKS file")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, jwksFetchTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, a.options.JWKSURL, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to create JWKS request")
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to fetch JWKS")
+	}
+
+	defer resp.Body.Close() //nolint:errcheck
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("unable to fetch JWKS: %v", resp.Status)
+	}
+
+	data, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxJWKSSize))
+
+	return data, errors.Wrap(err, "unable to read JWKS")
+}
+
+func (a *jwtAuthenticator) Refresh(ctx context.Context) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.reloadLocked(ctx)
+}
+
+// stringsClaim returns the value of a claim that can be either a single string or an array of strings.
+func stringsClaim(claims jwt.MapClaims, name string) []string {
+	switch v := claims[name].(type) {
+	case string:
+		return []string{v}
+
+	case []interface{}:
+		var result []string
+
+		for _, it := range v {
+			if s, ok := it.(string); ok {
+				result = append(result, s)
+			}
+		}
+
+		return result
+
+	default:
+		return nil
+	}
+}
+
+func containsString(list []string, s string) bool {
+	for _, it := range list {
+		if it == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AuthenticateJWT returns an authenticator that accepts JWT bearer tokens signed by one of the keys
+// in the provided JSON Web Key Set. Tokens can be passed either as bearer tokens or in place of the password,
+// in which case the identity in the token must match the provided username.
+func AuthenticateJWT(ctx context.Context, opt JWTOptions) (Authenticator, error) {
+	if (opt.JWKSFile == "") == (opt.JWKSURL == "") {
+		return nil, errors.Errorf("exactly one of JWKS file or URL must be provided")
+	}
+
+	a := &jwtAuthenticator{
+		options: opt,
+		parser: &jwt.Parser{
+			ValidMethods: []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"},
+		},
+	}
+
+	if err := a.Refresh(ctx); err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+var (
+	_ Authenticator       = (*jwtAuthenticator)(nil)
+	_ BearerAuthenticator = (*jwtAuthenticator)(nil)
+)
@@ -0,0 +1,210 @@
+package auth_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kopia/kopia/internal/auth"
+	"github.com/kopia/kopia/internal/testlogging"
+	"github.com/kopia/kopia/internal/testutil"
+)
+
+func TestJWTAuthenticator(t *testing.T) {
+	ctx := testlogging.Context(t)
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	jwksFile := filepath.Join(testutil.TempDirectory(t), "jwks.json")
+	writeJWKS(t, jwksFile, map[string]interface{}{"rsa1": &rsaKey.PublicKey, "ec1": &ecKey.PublicKey})
+
+	_, err = auth.AuthenticateJWT(ctx, auth.JWTOptions{})
+	require.Error(t, err)
+
+	_, err = auth.AuthenticateJWT(ctx, auth.JWTOptions{JWKSFile: filepath.Join(testutil.TempDirectory(t), "no-such-file")})
+	require.Error(t, err)
+
+	a, err := auth.AuthenticateJWT(ctx, auth.JWTOptions{
+		JWKSFile:      jwksFile,
+		Issuer:        "https://issuer",
+		Audience:      "kopia",
+		HostnameClaim: "host",
+	})
+	require.NoError(t, err)
+
+	validClaims := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"sub":    "Alice",
+			"host":   "laptop",
+			"iss":    "https://issuer",
+			"aud":    []string{"other", "kopia"},
+			"exp":    time.Now().Add(time.Hour).Unix(),
+			"groups": []string{"admins", "staff"},
+		}
+	}
+
+	withClaim := func(name string, v interface{}) jwt.MapClaims {
+		c := validClaims()
+		if v == nil {
+			delete(c, name)
+		} else {
+			c[name] = v
+		}
+
+		return c
+	}
+
+	rsaToken := signJWT(t, jwt.SigningMethodRS256, "rsa1", rsaKey, validClaims())
+	ecToken := signJWT(t, jwt.SigningMethodES256, "ec1", ecKey, validClaims())
+
+	for _, tok := range []string{rsaToken, ecToken} {
+		id, ok := auth.AuthenticateBearer(ctx, a, nil, tok)
+		require.True(t, ok)
+		require.Equal(t, auth.Identity{Username: "alice@laptop", Groups: []string{"admins", "staff"}}, id)
+
+		verifyAuthenticator(t, a, "alice@laptop", tok, true)
+		verifyAuthenticator(t, a, "Alice@Laptop", tok, true)
+		verifyAuthenticator(t, a, "bob@laptop", tok, false)
+	}
+
+	// bearer tokens work through combined authenticator, other authenticators are ignored.
+	combined := auth.CombineAuthenticators(auth.AuthenticateSingleUser("user1", "password1"), a)
+	id, ok := auth.AuthenticateBearer(ctx, combined, nil, ecToken)
+	require.True(t, ok)
+	require.Equal(t, "alice@laptop", id.Username)
+	verifyAuthenticator(t, combined, "alice@laptop", ecToken, true)
+	verifyAuthenticator(t, combined, "user1", "password1", true)
+
+	_, ok = auth.AuthenticateBearer(ctx, auth.AuthenticateSingleUser("user1", "password1"), nil, ecToken)
+	require.False(t, ok)
+
+	invalidTokens := map[string]string{
+		"not-a-jwt":       "password1",
+		"tampered":        rsaToken + "x",
+		"unknown-key":     signJWT(t, jwt.SigningMethodRS256, "rsa1", otherKey, validClaims()),
+		"unknown-kid":     signJWT(t, jwt.SigningMethodRS256, "rsa2", rsaKey, validClaims()),
+		"key-type":        signJWT(t, jwt.SigningMethodES256, "rsa1", ecKey, validClaims()),
+		"hmac":            signJWT(t, jwt.SigningMethodHS256, "rsa1", []byte("secret"), validClaims()),
+		"expired":         signJWT(t, jwt.SigningMethodRS256, "rsa1", rsaKey, withClaim("exp", time.Now().Add(-time.Minute).Unix())),
+		"no-expiration":   signJWT(t, jwt.SigningMethodRS256, "rsa1", rsaKey, withClaim("exp", nil)),
+		"not-yet-valid":   signJWT(t, jwt.SigningMethodRS256, "rsa1", rsaKey, withClaim("nbf", time.Now().Add(time.Hour).Unix())),
+		"wrong-issuer":    signJWT(t, jwt.SigningMethodRS256, "rsa1", rsaKey, withClaim("iss", "https://other-issuer")),
+		"wrong-audience":  signJWT(t, jwt.SigningMethodRS256, "rsa1", rsaKey, withClaim("aud", "other")),
+		"missing-subject": signJWT(t, jwt.SigningMethodRS256, "rsa1", rsaKey, withClaim("sub", nil)),
+		"missing-host":    signJWT(t, jwt.SigningMethodRS256, "rsa1", rsaKey, withClaim("host", nil)),
+	}
+
+	for name, tok := range invalidTokens {
+		_, ok := auth.AuthenticateBearer(ctx, a, nil, tok)
+		require.False(t, ok, name)
+	}
+}
+
+func TestJWTAuthenticator_ClaimMapping(t *testing.T) {
+	ctx := testlogging.Context(t)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	jwksFile := filepath.Join(testutil.TempDirectory(t), "jwks.json")
+	writeJWKS(t, jwksFile, map[string]interface{}{"": &key.PublicKey})
+
+	a, err := auth.AuthenticateJWT(ctx, auth.JWTOptions{
+		JWKSFile:        jwksFile,
+		UsernameClaim:   "preferred_username",
+		DefaultHostname: "corp",
+		GroupsClaim:     "roles",
+	})
+	require.NoError(t, err)
+
+	exp := time.Now().Add(time.Hour).Unix()
+
+	cases := []struct {
+		claims jwt.MapClaims
+		want   auth.Identity
+	}{
+		{
+			jwt.MapClaims{"preferred_username": "bob", "roles": "operators", "exp": exp},
+			auth.Identity{Username: "bob@corp", Groups: []string{"operators"}},
+		},
+		{
+			jwt.MapClaims{"preferred_username": "bob@workstation", "groups": []string{"ignored"}, "exp": exp},
+			auth.Identity{Username: "bob@workstation"},
+		},
+	}
+
+	for _, tc := range cases {
+		// tokens without 'kid' are accepted when JWKS has a single key.
+		id, ok := auth.AuthenticateBearer(ctx, a, nil, signJWT(t, jwt.SigningMethodPS256, "", key, tc.claims))
+		require.True(t, ok)
+		require.Equal(t, tc.want, id)
+	}
+}
+
+func signJWT(t *testing.T, method jwt.SigningMethod, kid string, key interface{}, claims jwt.MapClaims) string {
+	t.Helper()
+
+	tok := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		tok.Header["kid"] = kid
+	}
+
+	s, err := tok.SignedString(key)
+	require.NoError(t, err)
+
+	return s
+}
+
+func writeJWKS(t *testing.T, fname string, keys map[string]interface{}) {
+	t.Helper()
+
+	enc := func(v *big.Int) string {
+		return base64.RawURLEncoding.EncodeToString(v.Bytes())
+	}
+
+	var jwks struct {
+		Keys []map[string]string `json:"keys"`
+	}
+
+	for kid, k := range keys {
+		switch k := k.(type) {
+		case *rsa.PublicKey:
+			jwks.Keys = append(jwks.Keys, map[string]string{
+				"kty": "RSA", "kid": kid, "use": "sig", "n": enc(k.N), "e": enc(big.NewInt(int64(k.E))),
+			})
+
+		case *ecdsa.PublicKey:
+			jwks.Keys = append(jwks.Keys, map[string]string{
+				"kty": "EC", "kid": kid, "crv": k.Curve.Params().Name, "x": enc(k.X), "y": enc(k.Y),
+			})
+		}
+	}
+
+	// encryption keys and unsupported key types are ignored.
+	jwks.Keys = append(jwks.Keys,
+		map[string]string{"kty": "RSA", "kid": "enc1", "use": "enc", "n": "AQAB", "e": "AQAB"},
+		map[string]string{"kty": "OKP", "kid": "okp1", "crv": "Ed25519", "x": "AQAB"},
+	)
+
+	b, err := json.Marshal(jwks)
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(fname, b, 0o600))
+}
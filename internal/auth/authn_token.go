@@ -0,0 +1,143 @@
+package auth
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/kopia/kopia/internal/apitoken"
+	"github.com/kopia/kopia/internal/clock"
+	"github.com/kopia/kopia/internal/user"
+	"github.com/kopia/kopia/repo"
+)
+
+const defaultTokenRefreshFrequency = 10 * time.Second
+
+// AccessLimiter is implemented by authenticators that grant limited access depending on the provided credentials.
+type AccessLimiter interface {
+	LimitAccess(ctx context.Context, rep repo.Repository, username, password string, authz AuthorizationInfo) AuthorizationInfo
+}
+
+type repositoryTokenAuthenticator struct {
+	lastRep repo.Repository
+
+	mu                    sync.Mutex
+	nextRefreshTime       time.Time
+	tokens                map[string]*apitoken.Token
+	userProfiles          map[string]*user.Profile
+	tokenRefreshFrequency time.Duration
+}
+
+// findToken returns the token for the provided password if it is an API token issued to the provided user,
+// whose profile still exists.
+func (ac *repositoryTokenAuthenticator) findToken(ctx context.Context, rep repo.Repository, username, password string) (tok *apitoken.Token, secret string, isToken bool) {
+	id, secret, ok := apitoken.Parse(password)
+	if !ok {
+		return nil, "", false
+	}
+
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+
+	// if the server switched to serving another repository, discard cache.
+	if rep != ac.lastRep {
+		ac.tokens = nil
+		ac.userProfiles = nil
+		ac.lastRep = rep
+
+		// ensure tokens are reloaded below
+		ac.nextRefreshTime = time.Time{}
+	}
+
+	// see if we're due for a refresh and refresh tokens map
+	if clock.Now().After(ac.nextRefreshTime) {
+		ac.nextRefreshTime = clock.Now().Add(ac.tokenRefreshFrequency)
+
+		newTokens, err := apitoken.LoadTokenMap(ctx, rep, ac.tokens)
+		if err != nil {
+			log(ctx).Errorf("unable to load tokens map: %v", err)
+		} else {
+			ac.tokens = newTokens
+		}
+
+		newUsers, err := user.LoadProfileMap(ctx, rep, ac.userProfiles)
+		if err != nil {
+			log(ctx).Errorf("unable to load userProfiles map: %v", err)
+		} else {
+			ac.userProfiles = newUsers
+		}
+	}
+
+	// tokens of deleted users are no longer valid.
+	if t := ac.tokens[id]; t != nil && t.Username == username && ac.userProfiles[username] != nil {
+		return t, secret, true
+	}
+
+	return nil, secret, true
+}
+
+func (ac *repositoryTokenAuthenticator) IsValid(ctx context.Context, rep repo.Repository, username, password string) bool {
+	t, secret, isToken := ac.findToken(ctx, rep, username, password)
+	if !isToken {
+		return false
+	}
+
+	// IsValidSecret can be safely called on nil and the call will take as much time as for a valid token.
+	return t.IsValidSecret(secret, clock.Now())
+}
+
+func (ac *repositoryTokenAuthenticator) LimitAccess(ctx context.Context, rep repo.Repository, username, password string, authz AuthorizationInfo) AuthorizationInfo {
+	t, secret, isToken := ac.findToken(ctx, rep, username, password)
+	if !isToken || !t.IsValidSecret(secret, clock.Now()) {
+		return authz
+	}
+
+	return tokenAuthorizationInfo{authz, t}
+}
+
+func (ac *repositoryTokenAuthenticator) Refresh(ctx context.Context) error {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+
+	ac.nextRefreshTime = time.Time{}
+
+	return nil
+}
+
+// tokenAuthorizationInfo limits access levels to the maximum access level of the token.
+type tokenAuthorizationInfo struct {
+	base  AuthorizationInfo
+	token *apitoken.Token
+}
+
+func (a tokenAuthorizationInfo) ContentAccessLevel() AccessLevel {
+	return a.token.LimitAccess(a.base.ContentAccessLevel())
+}
+
+func (a tokenAuthorizationInfo) ManifestAccessLevel(labels map[string]string) AccessLevel {
+	return a.token.LimitAccess(a.base.ManifestAccessLevel(labels))
+}
+
+// AuthenticateRepositoryTokens returns authenticator that accepts API tokens stored
+// in the repository in place of passwords of their users.
+func AuthenticateRepositoryTokens() Authenticator {
+	return &repositoryTokenAuthenticator{
+		tokenRefreshFrequency: defaultTokenRefreshFrequency,
+	}
+}
+
+// LimitAccess limits the provided authorization info according to the credentials, if the authenticator
+// grants limited access. Otherwise authorization info is returned unchanged.
+func LimitAccess(ctx context.Context, a Authenticator, rep repo.Repository, username, password string, authz AuthorizationInfo) AuthorizationInfo {
+	if l, ok := a.(AccessLimiter); ok {
+		return l.LimitAccess(ctx, rep, username, password, authz)
+	}
+
+	return authz
+}
+
+var (
+	_ Authenticator = (*repositoryTokenAuthenticator)(nil)
+	_ AccessLimiter = (*repositoryTokenAuthenticator)(nil)
+	_ AccessLimiter = combinedAuthenticator(nil)
+)
@@ -0,0 +1,125 @@
+package auth_test
+
+import (
+	"crypto/sha256"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kopia/kopia/internal/acl"
+	"github.com/kopia/kopia/internal/apitoken"
+	"github.com/kopia/kopia/internal/auth"
+	"github.com/kopia/kopia/internal/clock"
+	"github.com/kopia/kopia/internal/repotesting"
+	"github.com/kopia/kopia/internal/user"
+	"github.com/kopia/kopia/repo"
+	"github.com/kopia/kopia/repo/manifest"
+	"github.com/kopia/kopia/snapshot"
+)
+
+func TestRepositoryTokenAuthenticator(t *testing.T) {
+	a := auth.CombineAuthenticators(auth.AuthenticateRepositoryUsers(), auth.AuthenticateRepositoryTokens())
+	ctx, env := repotesting.NewEnvironment(t)
+
+	var fullToken, appendToken, expiredToken string
+
+	require.NoError(t, repo.WriteSession(ctx, env.Repository, repo.WriteSessionOptions{},
+		func(w repo.RepositoryWriter) error {
+			p := &user.Profile{
+				Username: "user1@host1",
+			}
+
+			p.SetPassword("password1")
+
+			require.NoError(t, user.SetUserProfile(ctx, w, p))
+
+			var err error
+
+			_, fullToken, err = apitoken.Create(ctx, w, "user1@host1", "full", time.Hour, 0)
+			require.NoError(t, err)
+
+			_, appendToken, err = apitoken.Create(ctx, w, "user1@host1", "append", time.Hour, acl.AccessLevelAppend)
+			require.NoError(t, err)
+
+			_, expiredToken, err = apitoken.Create(ctx, w, "user1@host1", "expired", time.Nanosecond, 0)
+			require.NoError(t, err)
+
+			return nil
+		}))
+
+	verifyRepoAuthenticator(ctx, t, a, env.Repository, "user1@host1", "password1", true)
+	verifyRepoAuthenticator(ctx, t, a, env.Repository, "user1@host1", fullToken, true)
+	verifyRepoAuthenticator(ctx, t, a, env.Repository, "user1@host1", appendToken, true)
+	verifyRepoAuthenticator(ctx, t, a, env.Repository, "user1@host1", expiredToken, false)
+	verifyRepoAuthenticator(ctx, t, a, env.Repository, "user1@host1", fullToken+"x", false)
+	verifyRepoAuthenticator(ctx, t, a, env.Repository, "user2@host1", fullToken, false)
+
+	ownSnapshot := map[string]string{
+		manifest.TypeLabelKey:  snapshot.ManifestType,
+		snapshot.UsernameLabel: "user1",
+		snapshot.HostnameLabel: "host1",
+	}
+
+	authz := auth.LegacyAuthorizer().Authorize(ctx, env.Repository, "user1@host1")
+
+	for _, tc := range []struct {
+		password    string
+		wantContent auth.AccessLevel
+		wantOwn     auth.AccessLevel
+	}{
+		{"password1", auth.AccessLevelFull, auth.AccessLevelFull},
+		{fullToken, auth.AccessLevelFull, auth.AccessLevelFull},
+		{appendToken, auth.AccessLevelAppend, auth.AccessLevelAppend},
+	} {
+		limited := auth.LimitAccess(ctx, a, env.Repository, "user1@host1", tc.password, authz)
+		require.Equal(t, tc.wantContent, limited.ContentAccessLevel())
+		require.Equal(t, tc.wantOwn, limited.ManifestAccessLevel(ownSnapshot))
+	}
+
+	// tokens are no longer accepted after the user has been deleted.
+	require.NoError(t, repo.WriteSession(ctx, env.Repository, repo.WriteSessionOptions{},
+		func(w repo.RepositoryWriter) error {
+			return user.DeleteUserProfile(ctx, w, "user1@host1")
+		}))
+
+	require.NoError(t, env.Repository.Refresh(ctx))
+	require.NoError(t, a.Refresh(ctx))
+
+	verifyRepoAuthenticator(ctx, t, a, env.Repository, "user1@host1", fullToken, false)
+	verifyRepoAuthenticator(ctx, t, a, env.Repository, "user1@host1", appendToken, false)
+}
+
+func TestRepositoryTokenAuthenticator_ForgedTokenUsername(t *testing.T) {
+	a := auth.AuthenticateRepositoryTokens()
+	ctx, env := repotesting.NewEnvironment(t)
+
+	secretHash := sha256.Sum256([]byte("s3cret"))
+
+	require.NoError(t, repo.WriteSession(ctx, env.Repository, repo.WriteSessionOptions{},
+		func(w repo.RepositoryWriter) error {
+			for _, u := range []string{"user1@host1", "user2@host1"} {
+				p := &user.Profile{Username: u}
+				require.NoError(t, p.SetPassword("password1"))
+				require.NoError(t, user.SetUserProfile(ctx, w, p))
+			}
+
+			// user2 is allowed to write manifests labeled as its own, but the token claims to be issued to user1.
+			_, err := w.PutManifest(ctx, map[string]string{
+				manifest.TypeLabelKey:  apitoken.ManifestType,
+				snapshot.UsernameLabel: "user2",
+				snapshot.HostnameLabel: "host1",
+				apitoken.TokenIDLabel:  "x",
+			}, &apitoken.Token{
+				ID:         "x",
+				Username:   "user1@host1",
+				ExpiresAt:  clock.Now().Add(time.Hour),
+				SecretHash: secretHash[:],
+			})
+
+			return err
+		}))
+
+	verifyRepoAuthenticator(ctx, t, a, env.Repository, "user1@host1", "kt.x.s3cret", false)
+	verifyRepoAuthenticator(ctx, t, a, env.Repository, "user2@host1", "kt.x.s3cret", false)
+}
@@ -2,6 +2,7 @@
 
 import (
 	"context"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -90,6 +91,7 @@ type aclCache struct {
 	lastRep         repo.Repository
 	nextRefreshTime time.Time
 	aclEntries      []*acl.Entry
+	groups          map[string]*user.Group
 }
 
 // Authorize returns authorization info based on ACLs stored in the repository falling back to legacy authorizer
@@ -125,13 +127,38 @@ func (ac *aclCache) Authorize(ctx context.Context, rep repo.Repository, username
 		} else {
 			ac.aclEntries = newMap
 		}
+
+		newGroups, err := user.LoadGroupMap(ctx, rep, ac.groups)
+		if err != nil {
+			log(ctx).Errorf("unable to load user groups: %v", err)
+		} else {
+			ac.groups = newGroups
+		}
 	}
 
 	if len(ac.aclEntries) == 0 {
 		return legacyAuthorizationInfo{usernameAtHostname}
 	}
 
-	return aclEntriesAuthorizer{acl.EntriesForUser(ac.aclEntries, u, h), u, h}
+	groups := UserGroups(ctx, ac.groups, usernameAtHostname)
+
+	return aclEntriesAuthorizer{acl.EntriesForUser(ac.aclEntries, u, h, groups...), u, h, groups}
+}
+
+// UserGroups returns sorted names of groups the user belongs to, which includes groups stored
+// in the repository and groups asserted by the identity provider during authentication.
+func UserGroups(ctx context.Context, groups map[string]*user.Group, usernameAtHostname string) []string {
+	result := user.GroupsForUser(groups, usernameAtHostname)
+
+	for _, g := range GroupsFromContext(ctx) {
+		if !containsString(result, g) {
+			result = append(result, g)
+		}
+	}
+
+	sort.Strings(result)
+
+	return result
 }
 
 func (ac *aclCache) Refresh(ctx context.Context) error {
@@ -148,14 +175,15 @@ type aclEntriesAuthorizer struct {
 	entries  []*acl.Entry
 	username string
 	hostname string
+	groups   []string
 }
 
 func (a aclEntriesAuthorizer) ContentAccessLevel() AccessLevel {
-	return acl.EffectivePermissions(a.username, a.hostname, ContentRule, a.entries)
+	return acl.EffectivePermissions(a.username, a.hostname, ContentRule, a.entries, a.groups...)
 }
 
 func (a aclEntriesAuthorizer) ManifestAccessLevel(labels map[string]string) AccessLevel {
-	return acl.EffectivePermissions(a.username, a.hostname, labels, a.entries)
+	return acl.EffectivePermissions(a.username, a.hostname, labels, a.entries, a.groups...)
 }
 
 // DefaultAuthorizer returns Authorizer that will fetch ACLs from the repository
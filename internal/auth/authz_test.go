@@ -9,7 +9,11 @@
 	"github.com/kopia/kopia/internal/acl"
 	"github.com/kopia/kopia/internal/auth"
 	"github.com/kopia/kopia/internal/repotesting"
+	"github.com/kopia/kopia/internal/user"
 	"github.com/kopia/kopia/repo"
+	"github.com/kopia/kopia/repo/manifest"
+	"github.com/kopia/kopia/snapshot"
+	"github.com/kopia/kopia/snapshot/policy"
 )
 
 var globalPolicyLabels = map[string]string{
@@ -115,6 +119,52 @@ func TestDefaultAuthorizer_DefaultACLs(t *testing.T) {
 	verifyLegacyAuthorizer(ctx, t, env.Repository, auth.DefaultAuthorizer())
 }
 
+func TestDefaultAuthorizer_Groups(t *testing.T) {
+	ctx, env := repotesting.NewEnvironment(t)
+
+	for _, e := range auth.DefaultACLs {
+		require.NoError(t, acl.AddACL(ctx, env.RepositoryWriter, e))
+	}
+
+	// members of 'ops' group can read all snapshots and policies.
+	for _, typ := range []string{snapshot.ManifestType, policy.ManifestType} {
+		require.NoError(t, acl.AddACL(ctx, env.RepositoryWriter, &acl.Entry{
+			User:   "group:ops",
+			Target: acl.TargetRule{manifest.TypeLabelKey: typ},
+			Access: acl.AccessLevelRead,
+		}))
+	}
+
+	require.NoError(t, user.SetGroup(ctx, env.RepositoryWriter, &user.Group{
+		Name:    "ops",
+		Members: []string{"alice@ops"},
+	}))
+
+	a := auth.DefaultAuthorizer()
+
+	cases := []struct {
+		ctx            context.Context
+		usernameAtHost string
+		want           auth.AccessLevel
+	}{
+		{ctx, "alice@ops", auth.AccessLevelRead},
+		{ctx, "bob@ops", auth.AccessLevelNone},
+		{ctx, "foo@bar", auth.AccessLevelFull},                                          // own snapshot
+		{auth.ContextWithGroups(ctx, []string{"ops"}), "bob@ops", auth.AccessLevelRead}, // group asserted by identity provider
+		{auth.ContextWithGroups(ctx, []string{"other"}), "bob@ops", auth.AccessLevelNone},
+	}
+
+	for _, tc := range cases {
+		authz := a.Authorize(tc.ctx, env.RepositoryWriter, tc.usernameAtHost)
+		verifyManifestAccessLevel(t, authz, fooAtBarSnapshot, tc.want)
+		verifyManifestAccessLevel(t, authz, globalPolicyLabels, auth.AccessLevelRead)
+	}
+
+	require.Equal(t, []string{"ops", "other"}, auth.UserGroups(auth.ContextWithGroups(ctx, []string{"other", "ops"}), map[string]*user.Group{
+		"ops": {Name: "ops", Members: []string{"alice@ops"}},
+	}, "alice@ops"))
+}
+
 // nolint:thelper
 func verifyLegacyAuthorizer(ctx context.Context, t *testing.T, rep repo.Repository, authorizer auth.Authorizer) {
 	cases := []struct {
@@ -0,0 +1,138 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+
+	"github.com/pkg/errors"
+)
+
+// jsonWebKey represents a single public key in JSON Web Key Set (RFC 7517).
+type jsonWebKey struct {
+	KeyType string `json:"kty"`
+	KeyID   string `json:"kid"`
+	Use     string `json:"use"`
+
+	// RSA
+	N string `json:"n"`
+	E string `json:"e"`
+
+	// EC
+	Curve string `json:"crv"`
+	X     string `json:"x"`
+	Y     string `json:"y"`
+}
+
+type jsonWebKeySet struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+
+// parseJWKS parses JSON Web Key Set and returns the map of supported public keys indexed by key ID.
+// Keys not used for signatures and keys of unsupported types are skipped.
+func parseJWKS(data []byte) (map[string]interface{}, error) {
+	var ks jsonWebKeySet
+
+	if err := json.Unmarshal(data, &ks); err != nil {
+		return nil, errors.Wrap(err, "unable to parse JWKS")
+	}
+
+	result := map[string]interface{}{}
+
+	for _, k := range ks.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		var (
+			pub interface{}
+			err error
+		)
+
+		switch k.KeyType {
+		case "RSA":
+			pub, err = k.rsaPublicKey()
+		case "EC":
+			pub, err = k.ecdsaPublicKey()
+		default:
+			continue
+		}
+
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid key %q", k.KeyID)
+		}
+
+		result[k.KeyID] = pub
+	}
+
+	if len(result) == 0 {
+		return nil, errors.Errorf("JWKS does not contain any supported signing keys")
+	}
+
+	return result, nil
+}
+
+func (k jsonWebKey) rsaPublicKey() (*rsa.PublicKey, error) {
+	n, err := decodeBigInt(k.N)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid modulus")
+	}
+
+	e, err := decodeBigInt(k.E)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid exponent")
+	}
+
+	if !e.IsInt64() || e.Int64() <= 1 || e.Int64() > 1<<31-1 {
+		return nil, errors.Errorf("unsupported exponent")
+	}
+
+	return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+}
+
+func (k jsonWebKey) ecdsaPublicKey() (*ecdsa.PublicKey, error) {
+	var curve elliptic.Curve
+
+	switch k.Curve {
+	case "P-256":
+		curve = elliptic.P256()
+	case "P-384":
+		curve = elliptic.P384()
+	case "P-521":
+		curve = elliptic.P521()
+	default:
+		return nil, errors.Errorf("unsupported curve %q", k.Curve)
+	}
+
+	x, err := decodeBigInt(k.X)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid X coordinate")
+	}
+
+	y, err := decodeBigInt(k.Y)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid Y coordinate")
+	}
+
+	if !curve.IsOnCurve(x, y) {
+		return nil, errors.Errorf("point is not on curve")
+	}
+
+	return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	if s == "" {
+		return nil, errors.Errorf("missing value")
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid base64")
+	}
+
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,628 @@
+// Package epoch manages repository epochs.
+//
+// Index blobs are grouped into numbered epochs. Writers always add uncompacted index blobs
+// to the current epoch, and epochs that are at least two behind the current one are considered
+// settled, at which point they can be safely compacted into a single-epoch compaction and later
+// into range checkpoints. Readers only need to list epoch markers, compacted blobs and uncompacted
+// blobs of the epochs not covered by compactions.
+//
+// The package is intentionally separate from 'content' package to be able to test in isolation.
+package epoch
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/kopia/kopia/internal/gather"
+	"github.com/kopia/kopia/repo/blob"
+	"github.com/kopia/kopia/repo/logging"
+)
+
+var log = logging.GetContextLoggerFunc("kopia/epoch")
+
+// LatestEpoch represents the current epoch number in GetCompleteIndexSet.
+const LatestEpoch = -1
+
+const (
+	writeRefreshSafetyFraction = 0.75 // index writes require at least this fraction of EpochRefreshFrequency remaining
+	epochMarkerContents        = "epoch"
+)
+
+// Parameters encapsulates all parameters that influence the behavior of epoch manager.
+type Parameters struct {
+	// Enabled determines whether the epoch manager is used to manage index blobs.
+	Enabled bool `json:"enabled,omitempty"`
+
+	// how frequently each client will list blobs to determine the current epoch.
+	EpochRefreshFrequency time.Duration `json:"epochRefreshFrequency,omitempty"`
+
+	// number of epochs between full checkpoints.
+	FullCheckpointFrequency int `json:"fullCheckpointFrequency,omitempty"`
+
+	// do not delete uncompacted blobs if the corresponding compacted blob age is less than this.
+	CleanupSafetyMargin time.Duration `json:"cleanupSafetyMargin,omitempty"`
+
+	// minimum duration of an epoch
+	MinEpochDuration time.Duration `json:"minEpochDuration,omitempty"`
+
+	// advance epoch if number of files exceeds this
+	EpochAdvanceOnCountThreshold int `json:"epochAdvanceOnCountThreshold,omitempty"`
+
+	// advance epoch if total size of files exceeds this.
+	EpochAdvanceOnTotalSizeBytesThreshold int64 `json:"epochAdvanceOnSizeThreshold,omitempty"`
+
+	// number of blobs to delete in parallel during cleanup
+	DeleteParallelism int `json:"deleteParallelism,omitempty"`
+}
+
+// Validate validates epoch parameters.
+// nolint:gomnd
+func (p *Parameters) Validate() error {
+	if !p.Enabled {
+		return nil
+	}
+
+	if p.MinEpochDuration < 10*time.Minute {
+		return errors.Errorf("minimum epoch duration too low: %v", p.MinEpochDuration)
+	}
+
+	// epochs must last long enough that a client whose view of the current epoch is at most
+	// EpochRefreshFrequency old never writes more than one epoch behind the latest.
+	if p.EpochRefreshFrequency*3 > p.MinEpochDuration {
+		return errors.Errorf("epoch refresh frequency too high, must be 1/3 or minimal epoch duration or less")
+	}
+
+	if p.FullCheckpointFrequency <= 0 {
+		return errors.Errorf("invalid epoch checkpoint frequency")
+	}
+
+	if p.CleanupSafetyMargin < p.EpochRefreshFrequency*3 {
+		return errors.Errorf("invalid cleanup safety margin, must be at least 3x epoch refresh frequency")
+	}
+
+	if p.EpochAdvanceOnCountThreshold < 10 {
+		return errors.Errorf("epoch advance on count too low")
+	}
+
+	if p.EpochAdvanceOnTotalSizeBytesThreshold < 1<<20 {
+		return errors.Errorf("epoch advance on size too low")
+	}
+
+	return nil
+}
+
+// DefaultParameters contains default epoch manager parameters.
+// nolint:gomnd
+var DefaultParameters = Parameters{
+	Enabled:                               true,
+	EpochRefreshFrequency:                 20 * time.Minute,
+	FullCheckpointFrequency:               7,
+	CleanupSafetyMargin:                   4 * time.Hour,
+	MinEpochDuration:                      24 * time.Hour,
+	EpochAdvanceOnCountThreshold:          20,
+	EpochAdvanceOnTotalSizeBytesThreshold: 10 << 20,
+	DeleteParallelism:                     4,
+}
+
+// CompactionFunc merges the provided index blobs and writes the result to one or more blobs
+// whose IDs start with the provided prefix.
+type CompactionFunc func(ctx context.Context, blobIDs []blob.ID, outputPrefix blob.ID) error
+
+// CurrentSnapshot captures a point-in-time snapshot of a repository indexes, including current epoch
+// information and compaction set.
+type CurrentSnapshot struct {
+	WriteEpoch                int                     `json:"writeEpoch"`
+	UncompactedEpochSets      map[int][]blob.Metadata `json:"unsettled"`
+	SingleEpochCompactionSets map[int][]blob.Metadata `json:"singleEpochCompactionSets"`
+	RangeCheckpointSets       []*RangeMetadata        `json:"rangeCheckpointSets"`
+	EpochStartTime            map[int]time.Time       `json:"epochStartTimes"`
+	EpochMarkerBlobs          []blob.Metadata         `json:"epochMarkerBlobs"`
+	ValidUntil                time.Time               `json:"validUntil"` // time after which the contents of this struct are no longer valid
+}
+
+// latestRangeCheckpoint returns the range checkpoint covering the largest number of epochs or nil.
+func (cs *CurrentSnapshot) latestRangeCheckpoint() *RangeMetadata {
+	var result *RangeMetadata
+
+	for _, r := range cs.RangeCheckpointSets {
+		if result == nil || r.MaxEpoch > result.MaxEpoch {
+			result = r
+		}
+	}
+
+	return result
+}
+
+// firstEpochNotInRangeCheckpoint returns the number of the first epoch not covered by a range checkpoint.
+func (cs *CurrentSnapshot) firstEpochNotInRangeCheckpoint() int {
+	if r := cs.latestRangeCheckpoint(); r != nil {
+		return r.MaxEpoch + 1
+	}
+
+	return 0
+}
+
+// Manager manages repository epochs.
+type Manager struct {
+	Params Parameters
+
+	st       blob.Storage
+	timeFunc func() time.Time
+
+	// mutable under lock
+	mu             sync.Mutex
+	lastKnownState CurrentSnapshot
+}
+
+// Invalidate ensures that all cached index information is discarded.
+func (e *Manager) Invalidate() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.lastKnownState = CurrentSnapshot{}
+}
+
+// Refresh refreshes information about current epoch.
+func (e *Manager) Refresh(ctx context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.refreshLocked(ctx)
+}
+
+// Current retrieves current snapshot.
+func (e *Manager) Current(ctx context.Context) (CurrentSnapshot, error) {
+	return e.committedState(ctx, 0)
+}
+
+func (e *Manager) committedState(ctx context.Context, ensureMinTime time.Duration) (CurrentSnapshot, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.timeFunc().Add(ensureMinTime).After(e.lastKnownState.ValidUntil) {
+		if err := e.refreshLocked(ctx); err != nil {
+			return CurrentSnapshot{}, err
+		}
+	}
+
+	return e.lastKnownState, nil
+}
+
+func (e *Manager) refreshLocked(ctx context.Context) error {
+	cs, err := e.loadCurrentState(ctx)
+	if err != nil {
+		return err
+	}
+
+	if shouldAdvance(cs.UncompactedEpochSets[cs.WriteEpoch], e.Params.MinEpochDuration, e.Params.EpochAdvanceOnCountThreshold, e.Params.EpochAdvanceOnTotalSizeBytesThreshold) {
+		if err := e.advanceEpoch(ctx, cs); err != nil {
+			return errors.Wrap(err, "error advancing epoch")
+		}
+
+		// reload the state to pick up the new marker.
+		if cs, err = e.loadCurrentState(ctx); err != nil {
+			return err
+		}
+	}
+
+	e.lastKnownState = cs
+
+	return nil
+}
+
+func (e *Manager) loadCurrentState(ctx context.Context) (CurrentSnapshot, error) {
+	cs := CurrentSnapshot{
+		UncompactedEpochSets:      map[int][]blob.Metadata{},
+		SingleEpochCompactionSets: map[int][]blob.Metadata{},
+		EpochStartTime:            map[int]time.Time{},
+		ValidUntil:                e.timeFunc().Add(e.Params.EpochRefreshFrequency),
+	}
+
+	var eg errgroup.Group
+
+	eg.Go(func() error {
+		return e.loadWriteEpoch(ctx, &cs)
+	})
+
+	eg.Go(func() error {
+		return e.loadRangeCheckpoints(ctx, &cs)
+	})
+
+	eg.Go(func() error {
+		return e.loadSingleEpochCompactions(ctx, &cs)
+	})
+
+	if err := eg.Wait(); err != nil {
+		return cs, errors.Wrap(err, "error refreshing epoch state")
+	}
+
+	// list uncompacted blobs for all epochs not covered by compaction.
+	for epoch := cs.firstEpochNotInRangeCheckpoint(); epoch <= cs.WriteEpoch; epoch++ {
+		if _, ok := cs.SingleEpochCompactionSets[epoch]; ok {
+			continue
+		}
+
+		bm, err := blob.ListAllBlobs(ctx, e.st, UncompactedEpochBlobPrefix(epoch))
+		if err != nil {
+			return cs, errors.Wrapf(err, "error listing uncompacted blobs for epoch %v", epoch)
+		}
+
+		if len(bm) > 0 {
+			cs.UncompactedEpochSets[epoch] = bm
+		}
+	}
+
+	return cs, nil
+}
+
+func (e *Manager) loadWriteEpoch(ctx context.Context, cs *CurrentSnapshot) error {
+	blobs, err := blob.ListAllBlobs(ctx, e.st, EpochMarkerIndexBlobPrefix)
+	if err != nil {
+		return errors.Wrap(err, "error loading write epoch")
+	}
+
+	for epoch, bm := range groupByEpochNumber(blobs) {
+		cs.EpochStartTime[epoch] = bm[0].Timestamp
+
+		if epoch > cs.WriteEpoch {
+			cs.WriteEpoch = epoch
+		}
+	}
+
+	cs.EpochMarkerBlobs = blobs
+
+	return nil
+}
+
+func (e *Manager) loadRangeCheckpoints(ctx context.Context, cs *CurrentSnapshot) error {
+	blobs, err := blob.ListAllBlobs(ctx, e.st, RangeCheckpointIndexBlobPrefix)
+	if err != nil {
+		return errors.Wrap(err, "error loading full checkpoints")
+	}
+
+	cs.RangeCheckpointSets = groupByEpochRanges(blobs)
+
+	return nil
+}
+
+func (e *Manager) loadSingleEpochCompactions(ctx context.Context, cs *CurrentSnapshot) error {
+	blobs, err := blob.ListAllBlobs(ctx, e.st, SingleEpochCompactionBlobPrefix)
+	if err != nil {
+		return errors.Wrap(err, "error loading single-epoch compactions")
+	}
+
+	cs.SingleEpochCompactionSets = groupByEpochNumber(blobs)
+
+	return nil
+}
+
+func (e *Manager) advanceEpoch(ctx context.Context, cs CurrentSnapshot) error {
+	blobID := blob.ID(EpochMarkerIndexBlobPrefix) + blob.ID(formatEpochNumber(cs.WriteEpoch+1))
+
+	log(ctx).Debugf("advancing epoch from %v to %v", cs.WriteEpoch, cs.WriteEpoch+1)
+
+	if err := e.st.PutBlob(ctx, blobID, gather.FromSlice([]byte(epochMarkerContents))); err != nil {
+		return errors.Wrapf(err, "error writing epoch marker %v", blobID)
+	}
+
+	return nil
+}
+
+// GetCompleteIndexSet returns the set of blobs forming a complete index set up to the provided epoch number.
+func (e *Manager) GetCompleteIndexSet(ctx context.Context, maxEpoch int) ([]blob.Metadata, error) {
+	cs, err := e.committedState(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if maxEpoch == LatestEpoch || maxEpoch > cs.WriteEpoch {
+		maxEpoch = cs.WriteEpoch
+	}
+
+	var result []blob.Metadata
+
+	startEpoch := 0
+
+	if r := cs.latestRangeCheckpoint(); r != nil && r.MaxEpoch <= maxEpoch {
+		result = append(result, r.Blobs...)
+		startEpoch = r.MaxEpoch + 1
+	}
+
+	for epoch := startEpoch; epoch <= maxEpoch; epoch++ {
+		if compacted, ok := cs.SingleEpochCompactionSets[epoch]; ok {
+			result = append(result, compacted...)
+			continue
+		}
+
+		result = append(result, cs.UncompactedEpochSets[epoch]...)
+	}
+
+	return result, nil
+}
+
+// WriteIndex writes individual index blobs by prefixing them with the current epoch number.
+// If the write takes long enough for the epoch to advance by two or more, the index is
+// rewritten into the new epoch, which guarantees it won't be missed by compaction.
+func (e *Manager) WriteIndex(ctx context.Context, dataShards map[blob.ID]blob.Bytes) ([]blob.Metadata, error) {
+	for {
+		cs, err := e.committedState(ctx, time.Duration(writeRefreshSafetyFraction*float64(e.Params.EpochRefreshFrequency)))
+		if err != nil {
+			return nil, errors.Wrap(err, "error getting committed state")
+		}
+
+		writtenForEpoch := cs.WriteEpoch
+
+		results, err := e.writeIndexShards(ctx, dataShards, writtenForEpoch)
+		if err != nil {
+			return nil, err
+		}
+
+		cs, err = e.committedState(ctx, 0)
+		if err != nil {
+			return nil, errors.Wrap(err, "error getting committed state")
+		}
+
+		if cs.WriteEpoch >= writtenForEpoch+2 {
+			log(ctx).Debugf("index write into epoch %v took too long, current epoch is %v, retrying", writtenForEpoch, cs.WriteEpoch)
+			continue
+		}
+
+		e.addOwnWrites(writtenForEpoch, results)
+
+		return results, nil
+	}
+}
+
+func (e *Manager) writeIndexShards(ctx context.Context, dataShards map[blob.ID]blob.Bytes, writtenForEpoch int) ([]blob.Metadata, error) {
+	var results []blob.Metadata
+
+	for unprefixedBlobID, data := range dataShards {
+		blobID := UncompactedEpochBlobPrefix(writtenForEpoch) + unprefixedBlobID
+
+		if err := e.st.PutBlob(ctx, blobID, data); err != nil {
+			return nil, errors.Wrapf(err, "error writing index blob %v", blobID)
+		}
+
+		bm, err := e.st.GetMetadata(ctx, blobID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error getting index metadata %v", blobID)
+		}
+
+		results = append(results, bm)
+	}
+
+	return results, nil
+}
+
+// addOwnWrites makes the provided blobs visible in the cached state, even if the
+// storage listing does not reflect them yet.
+func (e *Manager) addOwnWrites(epoch int, bms []blob.Metadata) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.lastKnownState.UncompactedEpochSets == nil {
+		return
+	}
+
+	existing := map[blob.ID]bool{}
+	for _, bm := range e.lastKnownState.UncompactedEpochSets[epoch] {
+		existing[bm.BlobID] = true
+	}
+
+	// make a copy so that snapshots returned to callers are not mutated.
+	merged := append([]blob.Metadata(nil), e.lastKnownState.UncompactedEpochSets[epoch]...)
+
+	for _, bm := range bms {
+		if !existing[bm.BlobID] {
+			merged = append(merged, bm)
+		}
+	}
+
+	sets := map[int][]blob.Metadata{}
+	for k, v := range e.lastKnownState.UncompactedEpochSets {
+		sets[k] = v
+	}
+
+	sets[epoch] = merged
+	e.lastKnownState.UncompactedEpochSets = sets
+}
+
+// Compact performs compaction of all settled epochs (those that are at least two epochs behind
+// the current write epoch), and writes a new range checkpoint when enough single-epoch
+// compactions have accumulated since the last one.
+func (e *Manager) Compact(ctx context.Context, compact CompactionFunc) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err := e.refreshLocked(ctx); err != nil {
+		return err
+	}
+
+	cs := e.lastKnownState
+	lastSettledEpoch := cs.WriteEpoch - 2 //nolint:gomnd
+
+	for epoch := cs.firstEpochNotInRangeCheckpoint(); epoch <= lastSettledEpoch; epoch++ {
+		if _, ok := cs.SingleEpochCompactionSets[epoch]; ok {
+			continue
+		}
+
+		uncompacted := cs.UncompactedEpochSets[epoch]
+		if len(uncompacted) == 0 {
+			continue
+		}
+
+		log(ctx).Debugf("compacting epoch %v (%v blobs)", epoch, len(uncompacted))
+
+		if err := compact(ctx, blobIDsFromMetadata(uncompacted), compactedEpochBlobPrefix(epoch)); err != nil {
+			return errors.Wrapf(err, "unable to compact epoch %v", epoch)
+		}
+	}
+
+	if err := e.refreshLocked(ctx); err != nil {
+		return err
+	}
+
+	cs = e.lastKnownState
+
+	if lastSettledEpoch-cs.firstEpochNotInRangeCheckpoint()+1 >= e.Params.FullCheckpointFrequency {
+		if err := e.generateRangeCheckpointLocked(ctx, cs, lastSettledEpoch, compact); err != nil {
+			return err
+		}
+
+		return e.refreshLocked(ctx)
+	}
+
+	return nil
+}
+
+func (e *Manager) generateRangeCheckpointLocked(ctx context.Context, cs CurrentSnapshot, maxEpoch int, compact CompactionFunc) error {
+	var inputs []blob.Metadata
+
+	if r := cs.latestRangeCheckpoint(); r != nil {
+		inputs = append(inputs, r.Blobs...)
+	}
+
+	for epoch := cs.firstEpochNotInRangeCheckpoint(); epoch <= maxEpoch; epoch++ {
+		compacted, ok := cs.SingleEpochCompactionSets[epoch]
+		if !ok && len(cs.UncompactedEpochSets[epoch]) > 0 {
+			return errors.Errorf("epoch %v has not been compacted yet", epoch)
+		}
+
+		inputs = append(inputs, compacted...)
+	}
+
+	log(ctx).Debugf("generating range checkpoint for epochs 0..%v (%v blobs)", maxEpoch, len(inputs))
+
+	if err := compact(ctx, blobIDsFromMetadata(inputs), rangeCheckpointBlobPrefix(0, maxEpoch)); err != nil {
+		return errors.Wrapf(err, "unable to generate range checkpoint for epochs 0..%v", maxEpoch)
+	}
+
+	return nil
+}
+
+// CleanupSupersededIndexes deletes index blobs that have been superseded by compacted blobs
+// that are older than CleanupSafetyMargin.
+func (e *Manager) CleanupSupersededIndexes(ctx context.Context) error {
+	cs, err := e.Current(ctx)
+	if err != nil {
+		return err
+	}
+
+	var uncompacted, singleEpochCompacted []blob.Metadata
+
+	var eg errgroup.Group
+
+	eg.Go(func() error {
+		var err error
+
+		uncompacted, err = blob.ListAllBlobs(ctx, e.st, UncompactedIndexBlobPrefix)
+
+		return errors.Wrap(err, "error listing uncompacted blobs")
+	})
+
+	eg.Go(func() error {
+		var err error
+
+		singleEpochCompacted, err = blob.ListAllBlobs(ctx, e.st, SingleEpochCompactionBlobPrefix)
+
+		return errors.Wrap(err, "error listing single-epoch compactions")
+	})
+
+	if err := eg.Wait(); err != nil {
+		return errors.Wrap(err, "error listing index blobs")
+	}
+
+	// use server-assigned timestamps as the reference point to avoid relying on local clock.
+	maxTime := latestTimestamp(cs.EpochMarkerBlobs, uncompacted, singleEpochCompacted)
+	for _, r := range cs.RangeCheckpointSets {
+		maxTime = latestTimestamp([]blob.Metadata{{Timestamp: maxTime}}, r.Blobs)
+	}
+
+	cutoffTime := maxTime.Add(-e.Params.CleanupSafetyMargin)
+	latestRange := cs.latestRangeCheckpoint()
+
+	coveredByRangeCheckpoint := func(epoch int) bool {
+		return latestRange != nil && epoch <= latestRange.MaxEpoch && !latestTimestamp(latestRange.Blobs).After(cutoffTime)
+	}
+
+	compactedBySingleEpoch := groupByEpochNumber(singleEpochCompacted)
+
+	var toDelete []blob.ID
+
+	for epoch, bms := range groupByEpochNumber(uncompacted) {
+		compacted, ok := compactedBySingleEpoch[epoch]
+		if coveredByRangeCheckpoint(epoch) || (ok && !latestTimestamp(compacted).After(cutoffTime)) {
+			toDelete = append(toDelete, blobIDsFromMetadata(bms)...)
+		}
+	}
+
+	for epoch, bms := range compactedBySingleEpoch {
+		if coveredByRangeCheckpoint(epoch) {
+			toDelete = append(toDelete, blobIDsFromMetadata(bms)...)
+		}
+	}
+
+	for _, r := range cs.RangeCheckpointSets {
+		if latestRange != nil && r.MaxEpoch < latestRange.MaxEpoch && coveredByRangeCheckpoint(r.MaxEpoch) {
+			toDelete = append(toDelete, blobIDsFromMetadata(r.Blobs)...)
+		}
+	}
+
+	for _, bm := range cs.EpochMarkerBlobs {
+		if epoch, ok := epochNumberFromBlobID(bm.BlobID); ok && epoch < cs.WriteEpoch && coveredByRangeCheckpoint(epoch) {
+			toDelete = append(toDelete, bm.BlobID)
+		}
+	}
+
+	if err := e.deleteBlobs(ctx, toDelete); err != nil {
+		return err
+	}
+
+	e.Invalidate()
+
+	return nil
+}
+
+func (e *Manager) deleteBlobs(ctx context.Context, blobIDs []blob.ID) error {
+	sort.Slice(blobIDs, func(i, j int) bool {
+		return blobIDs[i] < blobIDs[j]
+	})
+
+	sem := make(chan struct{}, maxInt(e.Params.DeleteParallelism, 1))
+
+	var eg errgroup.Group
+
+	for _, blobID := range blobIDs {
+		blobID := blobID
+
+		sem <- struct{}{}
+
+		eg.Go(func() error {
+			defer func() { <-sem }()
+
+			log(ctx).Debugf("deleting superseded index blob %v", blobID)
+
+			if err := e.st.DeleteBlob(ctx, blobID); err != nil && !errors.Is(err, blob.ErrBlobNotFound) {
+				return errors.Wrapf(err, "unable to delete blob %v", blobID)
+			}
+
+			return nil
+		})
+	}
+
+	return errors.Wrap(eg.Wait(), "error deleting superseded index blobs")
+}
+
+// NewManager creates new epoch manager.
+func NewManager(st blob.Storage, params Parameters, timeNow func() time.Time) *Manager {
+	return &Manager{
+		Params:   params,
+		st:       st,
+		timeFunc: timeNow,
+	}
+}
@@ -0,0 +1,240 @@
+package epoch
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kopia/kopia/internal/blobtesting"
+	"github.com/kopia/kopia/internal/faketime"
+	"github.com/kopia/kopia/internal/gather"
+	"github.com/kopia/kopia/internal/testlogging"
+	"github.com/kopia/kopia/repo/blob"
+)
+
+var testParams = Parameters{
+	Enabled:                               true,
+	EpochRefreshFrequency:                 20 * time.Minute,
+	FullCheckpointFrequency:               3,
+	CleanupSafetyMargin:                   1 * time.Hour,
+	MinEpochDuration:                      1 * time.Hour,
+	EpochAdvanceOnCountThreshold:          10,
+	EpochAdvanceOnTotalSizeBytesThreshold: 1 << 20,
+	DeleteParallelism:                     1,
+}
+
+type epochManagerTestEnv struct {
+	data map[blob.ID][]byte
+	st   blob.Storage
+	ft   *faketime.ClockTimeWithOffset
+	mgr  *Manager
+}
+
+func newTestEnv(t *testing.T) *epochManagerTestEnv {
+	t.Helper()
+
+	data := blobtesting.DataMap{}
+	ft := faketime.NewClockTimeWithOffset(0)
+	st := blobtesting.NewMapStorage(data, nil, ft.NowFunc())
+
+	return &epochManagerTestEnv{
+		data: data,
+		st:   st,
+		ft:   ft,
+		mgr:  NewManager(st, testParams, ft.NowFunc()),
+	}
+}
+
+// compact is a test compaction function which merges JSON-encoded sets of integers.
+func (te *epochManagerTestEnv) compact(ctx context.Context, blobIDs []blob.ID, outputPrefix blob.ID) error {
+	merged, err := te.readAndMerge(ctx, blobIDs)
+	if err != nil {
+		return err
+	}
+
+	return te.writeSet(ctx, outputPrefix, merged)
+}
+
+func (te *epochManagerTestEnv) readAndMerge(ctx context.Context, blobIDs []blob.ID) ([]int, error) {
+	var result []int
+
+	for _, id := range blobIDs {
+		data, err := te.st.GetBlob(ctx, id, 0, -1)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to read %v", id)
+		}
+
+		var v []int
+		if err := json.Unmarshal(data, &v); err != nil {
+			return nil, errors.Wrapf(err, "unable to parse %v", id)
+		}
+
+		result = append(result, v...)
+	}
+
+	sort.Ints(result)
+
+	return result, nil
+}
+
+func (te *epochManagerTestEnv) writeSet(ctx context.Context, prefix blob.ID, v []int) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return errors.Wrap(err, "marshal")
+	}
+
+	return te.st.PutBlob(ctx, prefix+blob.ID(hex.EncodeToString(data)), gather.FromSlice(data))
+}
+
+func (te *epochManagerTestEnv) writeIndex(ctx context.Context, t *testing.T, v int) {
+	t.Helper()
+
+	data, err := json.Marshal([]int{v})
+	require.NoError(t, err)
+
+	_, err = te.mgr.WriteIndex(ctx, map[blob.ID]blob.Bytes{
+		blob.ID(fmt.Sprintf("%08x", v)): gather.FromSlice(data),
+	})
+	require.NoError(t, err)
+}
+
+func (te *epochManagerTestEnv) verifyCompleteIndexSet(ctx context.Context, t *testing.T, mgr *Manager, want int) {
+	t.Helper()
+
+	bms, err := mgr.GetCompleteIndexSet(ctx, LatestEpoch)
+	require.NoError(t, err)
+
+	got, err := te.readAndMerge(ctx, blobIDsFromMetadata(bms))
+	require.NoError(t, err)
+
+	var expected []int
+	for i := 0; i < want; i++ {
+		expected = append(expected, i)
+	}
+
+	require.Equal(t, expected, got)
+}
+
+func TestEpochManager_Basic(t *testing.T) {
+	ctx := testlogging.Context(t)
+	te := newTestEnv(t)
+
+	cs, err := te.mgr.Current(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 0, cs.WriteEpoch)
+
+	const numIndexes = 200
+
+	for i := 0; i < numIndexes; i++ {
+		te.writeIndex(ctx, t, i)
+		te.ft.Advance(3 * time.Minute)
+
+		// another client with a fresh view of the repository must always see all the writes.
+		te.verifyCompleteIndexSet(ctx, t, NewManager(te.st, testParams, te.ft.NowFunc()), i+1)
+
+		if i%17 == 0 {
+			require.NoError(t, te.mgr.Compact(ctx, te.compact))
+			require.NoError(t, te.mgr.CleanupSupersededIndexes(ctx))
+		}
+	}
+
+	te.verifyCompleteIndexSet(ctx, t, te.mgr, numIndexes)
+
+	cs, err = te.mgr.Current(ctx)
+	require.NoError(t, err)
+	require.Greater(t, cs.WriteEpoch, 2)
+	require.NotEmpty(t, cs.RangeCheckpointSets)
+
+	// after cleanup the number of uncompacted blobs must be bounded.
+	var uncompactedCount int
+
+	for id := range te.data {
+		if strings.HasPrefix(string(id), string(UncompactedIndexBlobPrefix)) {
+			uncompactedCount++
+		}
+	}
+
+	require.Less(t, uncompactedCount, numIndexes/2)
+}
+
+func TestEpochManager_NoAdvanceBeforeMinDuration(t *testing.T) {
+	ctx := testlogging.Context(t)
+	te := newTestEnv(t)
+
+	for i := 0; i < 3*testParams.EpochAdvanceOnCountThreshold; i++ {
+		te.writeIndex(ctx, t, i)
+		te.ft.Advance(1 * time.Second)
+	}
+
+	te.mgr.Invalidate()
+
+	cs, err := te.mgr.Current(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 0, cs.WriteEpoch)
+
+	te.ft.Advance(testParams.MinEpochDuration)
+	te.writeIndex(ctx, t, 1000)
+	require.NoError(t, te.mgr.Refresh(ctx))
+
+	cs, err = te.mgr.Current(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, cs.WriteEpoch)
+}
+
+func TestEpochNumberFromBlobID(t *testing.T) {
+	cases := []struct {
+		input   blob.ID
+		want    int
+		wantErr bool
+	}{
+		{"xn0_abc", 0, false},
+		{"xn1_abc", 1, false},
+		{"xn1234_abc", 1234, false},
+		{"xe25", 25, false},
+		{"xn-1_abc", 0, true},
+		{"xnx_abc", 0, true},
+		{"x", 0, true},
+	}
+
+	for _, tc := range cases {
+		got, ok := epochNumberFromBlobID(tc.input)
+		require.Equal(t, !tc.wantErr, ok, "input %v", tc.input)
+		require.Equal(t, tc.want, got, "input %v", tc.input)
+	}
+}
+
+func TestEpochRangeFromBlobID(t *testing.T) {
+	n1, n2, ok := epochRangeFromBlobID("xr0_17_abc")
+	require.True(t, ok)
+	require.Equal(t, 0, n1)
+	require.Equal(t, 17, n2)
+
+	_, _, ok = epochRangeFromBlobID("xr5_3_abc")
+	require.False(t, ok)
+
+	_, _, ok = epochRangeFromBlobID("xr5_abc")
+	require.False(t, ok)
+}
+
+func TestParametersValidate(t *testing.T) {
+	require.NoError(t, DefaultParameters.Validate())
+
+	p := DefaultParameters
+	p.EpochRefreshFrequency = p.MinEpochDuration
+	require.Error(t, p.Validate())
+
+	p = DefaultParameters
+	p.FullCheckpointFrequency = 0
+	require.Error(t, p.Validate())
+
+	p = Parameters{}
+	require.NoError(t, p.Validate())
+}
@@ -0,0 +1,207 @@
+package epoch
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kopia/kopia/repo/blob"
+)
+
+// Index blob prefixes.
+const (
+	// UncompactedIndexBlobPrefix is the prefix of all uncompacted index blobs, followed by epoch number.
+	UncompactedIndexBlobPrefix blob.ID = "xn"
+
+	// EpochMarkerIndexBlobPrefix is the prefix of blobs that mark the start of each epoch.
+	EpochMarkerIndexBlobPrefix blob.ID = "xe"
+
+	// SingleEpochCompactionBlobPrefix is the prefix of blobs containing compacted indexes of a single epoch.
+	SingleEpochCompactionBlobPrefix blob.ID = "xs"
+
+	// RangeCheckpointIndexBlobPrefix is the prefix of blobs containing compacted indexes of a range of epochs.
+	RangeCheckpointIndexBlobPrefix blob.ID = "xr"
+)
+
+// RangeMetadata represents a range of epochs covered by a range checkpoint.
+type RangeMetadata struct {
+	MinEpoch int             `json:"min"`
+	MaxEpoch int             `json:"max"`
+	Blobs    []blob.Metadata `json:"blobs"`
+}
+
+// UncompactedEpochBlobPrefix returns the prefix of uncompacted blobs for a given epoch.
+func UncompactedEpochBlobPrefix(epoch int) blob.ID {
+	return UncompactedIndexBlobPrefix + blob.ID(formatEpochNumber(epoch)+"_")
+}
+
+func compactedEpochBlobPrefix(epoch int) blob.ID {
+	return SingleEpochCompactionBlobPrefix + blob.ID(formatEpochNumber(epoch)+"_")
+}
+
+func rangeCheckpointBlobPrefix(epoch1, epoch2 int) blob.ID {
+	return RangeCheckpointIndexBlobPrefix + blob.ID(formatEpochNumber(epoch1)+"_"+formatEpochNumber(epoch2)+"_")
+}
+
+func formatEpochNumber(epoch int) string {
+	return strconv.Itoa(epoch)
+}
+
+// epochNumberFromBlobID extracts the epoch number from a string formatted as
+// <prefix><epochNumber>_<remainder>.
+func epochNumberFromBlobID(blobID blob.ID) (int, bool) {
+	s := string(blobID)
+
+	if len(s) < len(UncompactedIndexBlobPrefix) {
+		return 0, false
+	}
+
+	s = s[len(UncompactedIndexBlobPrefix):]
+
+	if p := strings.IndexByte(s, '_'); p >= 0 {
+		s = s[0:p]
+	}
+
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		return 0, false
+	}
+
+	return n, true
+}
+
+// epochRangeFromBlobID extracts the range of epochs from a string formatted as
+// <prefix><epochNumber1>_<epochNumber2>_<remainder>.
+func epochRangeFromBlobID(blobID blob.ID) (min, max int, ok bool) {
+	s := string(blobID)
+
+	if len(s) < len(RangeCheckpointIndexBlobPrefix) {
+		return 0, 0, false
+	}
+
+	parts := strings.Split(s[len(RangeCheckpointIndexBlobPrefix):], "_")
+	if len(parts) < 3 { //nolint:gomnd
+		return 0, 0, false
+	}
+
+	n1, err1 := strconv.Atoi(parts[0])
+	n2, err2 := strconv.Atoi(parts[1])
+
+	if err1 != nil || err2 != nil || n1 < 0 || n2 < n1 {
+		return 0, 0, false
+	}
+
+	return n1, n2, true
+}
+
+func groupByEpochNumber(bms []blob.Metadata) map[int][]blob.Metadata {
+	result := map[int][]blob.Metadata{}
+
+	for _, bm := range bms {
+		if n, ok := epochNumberFromBlobID(bm.BlobID); ok {
+			result[n] = append(result[n], bm)
+		}
+	}
+
+	return result
+}
+
+func groupByEpochRanges(bms []blob.Metadata) []*RangeMetadata {
+	type rangeKey struct{ min, max int }
+
+	m := map[rangeKey]*RangeMetadata{}
+
+	for _, bm := range bms {
+		n1, n2, ok := epochRangeFromBlobID(bm.BlobID)
+		if !ok {
+			continue
+		}
+
+		k := rangeKey{n1, n2}
+		if m[k] == nil {
+			m[k] = &RangeMetadata{MinEpoch: n1, MaxEpoch: n2}
+		}
+
+		m[k].Blobs = append(m[k].Blobs, bm)
+	}
+
+	var result []*RangeMetadata
+	for _, v := range m {
+		result = append(result, v)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].MinEpoch != result[j].MinEpoch {
+			return result[i].MinEpoch < result[j].MinEpoch
+		}
+
+		return result[i].MaxEpoch < result[j].MaxEpoch
+	})
+
+	return result
+}
+
+// shouldAdvance determines whether the epoch containing the provided blobs should be advanced.
+// Only server-assigned timestamps are used, which avoids relying on client clocks.
+func shouldAdvance(bms []blob.Metadata, minEpochDuration time.Duration, countThreshold int, totalSizeBytesThreshold int64) bool {
+	if len(bms) == 0 {
+		return false
+	}
+
+	var (
+		min       = bms[0].Timestamp
+		max       = bms[0].Timestamp
+		totalSize int64
+	)
+
+	for _, bm := range bms {
+		if bm.Timestamp.Before(min) {
+			min = bm.Timestamp
+		}
+
+		if bm.Timestamp.After(max) {
+			max = bm.Timestamp
+		}
+
+		totalSize += bm.Length
+	}
+
+	if max.Sub(min) < minEpochDuration {
+		return false
+	}
+
+	return len(bms) >= countThreshold || totalSize >= totalSizeBytesThreshold
+}
+
+func blobIDsFromMetadata(bms []blob.Metadata) []blob.ID {
+	var result []blob.ID
+
+	for _, bm := range bms {
+		result = append(result, bm.BlobID)
+	}
+
+	return result
+}
+
+func latestTimestamp(sets ...[]blob.Metadata) time.Time {
+	var max time.Time
+
+	for _, set := range sets {
+		for _, bm := range set {
+			if bm.Timestamp.After(max) {
+				max = bm.Timestamp
+			}
+		}
+	}
+
+	return max
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+
+	return b
+}
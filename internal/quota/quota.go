@@ -0,0 +1,257 @@
+// Package quota manages per-user and per-host storage quotas enforced by the repository server.
+//
+// Quotas limit the number of bytes of new contents written on behalf of users. A quota applies either to
+// a single user ('user@hostname') or to all users of a given host ('*@hostname'). Each content counts once,
+// with its packed length, towards usage of the user who wrote it first. Usage of users subject to quotas is
+// accounted by the server as new contents are written, persisted in the repository and can be reset or
+// recomputed from snapshots during maintenance.
+package quota
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/kopia/kopia/repo"
+	"github.com/kopia/kopia/repo/manifest"
+)
+
+const (
+	// LimitManifestType is the type of the manifest used to represent quota limits.
+	LimitManifestType = "quota"
+
+	// UsageManifestType is the type of the manifest used to represent persisted storage usage.
+	UsageManifestType = "quotausage"
+
+	// SubjectLabel is the manifest label identifying the user or host the quota applies to.
+	SubjectLabel = "subject"
+)
+
+// hostSubjectUsername is the username used in subjects representing all users of a host.
+const hostSubjectUsername = "*"
+
+// ErrLimitNotFound is returned to indicate that a quota limit was not found.
+var ErrLimitNotFound = errors.New("quota not found")
+
+// Limit represents the maximum number of bytes that can be stored by a user or host.
+type Limit struct {
+	ManifestID manifest.ID `json:"-"`
+
+	Subject  string `json:"subject"`
+	MaxBytes int64  `json:"maxBytes"`
+}
+
+// Usage represents the number of bytes stored by a user or host.
+type Usage struct {
+	ManifestID manifest.ID `json:"-"`
+
+	Subject string `json:"subject"`
+	Bytes   int64  `json:"bytes"`
+
+	// time when usage was last reset or recomputed from snapshots.
+	ComputedTime time.Time `json:"computedTime,omitempty"`
+}
+
+// validSubjectRegexp matches username@hostname or *@hostname.
+var validSubjectRegexp = regexp.MustCompile(`^(\*|[a-z0-9\-_.]+)@[a-z0-9\-_.]+$`)
+
+// ValidateSubject returns an error if the given quota subject is invalid.
+func ValidateSubject(subject string) error {
+	if !validSubjectRegexp.MatchString(subject) {
+		return errors.Errorf("quota subject must be specified as lowercase 'user@hostname' or '*@hostname'")
+	}
+
+	return nil
+}
+
+// HostSubject returns the subject representing all users of the provided host.
+func HostSubject(hostname string) string {
+	return hostSubjectUsername + "@" + hostname
+}
+
+// SubjectsForUser returns the subjects whose quotas apply to the provided username@hostname.
+func SubjectsForUser(usernameAtHostname string) []string {
+	p := strings.LastIndex(usernameAtHostname, "@")
+	if p < 0 {
+		return []string{usernameAtHostname}
+	}
+
+	return []string{usernameAtHostname, HostSubject(usernameAtHostname[p+1:])}
+}
+
+// ListLimits returns all quota limits sorted by subject.
+func ListLimits(ctx context.Context, rep repo.Repository) ([]*Limit, error) {
+	entries, err := latestBySubject(ctx, rep, LimitManifestType)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []*Limit
+
+	for _, m := range entries {
+		l := &Limit{}
+		if _, err := rep.GetManifest(ctx, m.ID, l); err != nil {
+			return nil, errors.Wrapf(err, "error loading quota %v", m.Labels[SubjectLabel])
+		}
+
+		l.ManifestID = m.ID
+		result = append(result, l)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Subject < result[j].Subject
+	})
+
+	return result, nil
+}
+
+// SetLimit creates or updates the quota limit.
+func SetLimit(ctx context.Context, w repo.RepositoryWriter, l *Limit) error {
+	if err := ValidateSubject(l.Subject); err != nil {
+		return err
+	}
+
+	if l.MaxBytes <= 0 {
+		return errors.Errorf("quota limit must be positive")
+	}
+
+	id, err := replaceManifest(ctx, w, LimitManifestType, l.Subject, l)
+	if err != nil {
+		return errors.Wrap(err, "error writing quota")
+	}
+
+	l.ManifestID = id
+
+	return nil
+}
+
+// DeleteLimit removes the quota limit of the provided subject.
+func DeleteLimit(ctx context.Context, w repo.RepositoryWriter, subject string) error {
+	manifests, err := findBySubject(ctx, w, LimitManifestType, subject)
+	if err != nil {
+		return err
+	}
+
+	if len(manifests) == 0 {
+		return errors.Wrap(ErrLimitNotFound, subject)
+	}
+
+	for _, m := range manifests {
+		if err := w.DeleteManifest(ctx, m.ID); err != nil {
+			return errors.Wrapf(err, "error deleting quota %v", subject)
+		}
+	}
+
+	return nil
+}
+
+// ListUsage returns persisted storage usage of all subjects sorted by subject.
+func ListUsage(ctx context.Context, rep repo.Repository) ([]*Usage, error) {
+	entries, err := latestBySubject(ctx, rep, UsageManifestType)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []*Usage
+
+	for _, m := range entries {
+		u := &Usage{}
+		if _, err := rep.GetManifest(ctx, m.ID, u); err != nil {
+			return nil, errors.Wrapf(err, "error loading usage %v", m.Labels[SubjectLabel])
+		}
+
+		u.ManifestID = m.ID
+		result = append(result, u)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Subject < result[j].Subject
+	})
+
+	return result, nil
+}
+
+// GetUsage returns persisted storage usage of the provided subject, which is empty if no usage was recorded.
+func GetUsage(ctx context.Context, rep repo.Repository, subject string) (*Usage, error) {
+	manifests, err := findBySubject(ctx, rep, UsageManifestType, subject)
+	if err != nil {
+		return nil, err
+	}
+
+	u := &Usage{Subject: subject}
+
+	if len(manifests) == 0 {
+		return u, nil
+	}
+
+	id := manifest.PickLatestID(manifests)
+	if _, err := rep.GetManifest(ctx, id, u); err != nil {
+		return nil, errors.Wrap(err, "error loading usage")
+	}
+
+	u.ManifestID = id
+
+	return u, nil
+}
+
+// SetUsage replaces persisted storage usage of a subject.
+func SetUsage(ctx context.Context, w repo.RepositoryWriter, u *Usage) error {
+	id, err := replaceManifest(ctx, w, UsageManifestType, u.Subject, u)
+	if err != nil {
+		return errors.Wrap(err, "error writing usage")
+	}
+
+	u.ManifestID = id
+
+	return nil
+}
+
+func findBySubject(ctx context.Context, rep repo.Repository, manifestType, subject string) ([]*manifest.EntryMetadata, error) {
+	manifests, err := rep.FindManifests(ctx, map[string]string{
+		manifest.TypeLabelKey: manifestType,
+		SubjectLabel:          subject,
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "error looking for %v manifests", manifestType)
+	}
+
+	return manifests, nil
+}
+
+// replaceManifest writes the manifest of a given type for the subject and removes manifests it replaces.
+func replaceManifest(ctx context.Context, w repo.RepositoryWriter, manifestType, subject string, payload interface{}) (manifest.ID, error) {
+	manifests, err := findBySubject(ctx, w, manifestType, subject)
+	if err != nil {
+		return "", err
+	}
+
+	id, err := w.PutManifest(ctx, map[string]string{
+		manifest.TypeLabelKey: manifestType,
+		SubjectLabel:          subject,
+	}, payload)
+	if err != nil {
+		return "", errors.Wrapf(err, "error writing %v manifest", manifestType)
+	}
+
+	for _, m := range manifests {
+		if err := w.DeleteManifest(ctx, m.ID); err != nil {
+			return "", errors.Wrapf(err, "error deleting %v manifest", manifestType)
+		}
+	}
+
+	return id, nil
+}
+
+// latestBySubject returns the latest manifest of a given type for each subject.
+func latestBySubject(ctx context.Context, rep repo.Repository, manifestType string) ([]*manifest.EntryMetadata, error) {
+	entries, err := rep.FindManifests(ctx, map[string]string{manifest.TypeLabelKey: manifestType})
+	if err != nil {
+		return nil, errors.Wrapf(err, "error listing %v manifests", manifestType)
+	}
+
+	return manifest.DedupeEntryMetadataByLabel(entries, SubjectLabel), nil
+}
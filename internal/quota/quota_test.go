@@ -0,0 +1,176 @@
+package quota_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kopia/kopia/internal/mockfs"
+	"github.com/kopia/kopia/internal/quota"
+	"github.com/kopia/kopia/internal/repotesting"
+	"github.com/kopia/kopia/repo"
+	"github.com/kopia/kopia/snapshot"
+	"github.com/kopia/kopia/snapshot/policy"
+	"github.com/kopia/kopia/snapshot/snapshotfs"
+)
+
+func TestLimits(t *testing.T) {
+	ctx, env := repotesting.NewEnvironment(t)
+
+	require.Error(t, quota.SetLimit(ctx, env.RepositoryWriter, &quota.Limit{Subject: "alice", MaxBytes: 100}))
+	require.Error(t, quota.SetLimit(ctx, env.RepositoryWriter, &quota.Limit{Subject: "alice@host1", MaxBytes: 0}))
+
+	require.NoError(t, quota.SetLimit(ctx, env.RepositoryWriter, &quota.Limit{Subject: "alice@host1", MaxBytes: 100}))
+	require.NoError(t, quota.SetLimit(ctx, env.RepositoryWriter, &quota.Limit{Subject: "*@host1", MaxBytes: 1000}))
+
+	// replace existing limit
+	require.NoError(t, quota.SetLimit(ctx, env.RepositoryWriter, &quota.Limit{Subject: "alice@host1", MaxBytes: 200}))
+
+	limits, err := quota.ListLimits(ctx, env.RepositoryWriter)
+	require.NoError(t, err)
+	require.Len(t, limits, 2)
+	require.Equal(t, "*@host1", limits[0].Subject)
+	require.Equal(t, "alice@host1", limits[1].Subject)
+	require.Equal(t, int64(200), limits[1].MaxBytes)
+
+	require.NoError(t, quota.DeleteLimit(ctx, env.RepositoryWriter, "alice@host1"))
+	require.True(t, errors.Is(quota.DeleteLimit(ctx, env.RepositoryWriter, "alice@host1"), quota.ErrLimitNotFound))
+
+	limits, err = quota.ListLimits(ctx, env.RepositoryWriter)
+	require.NoError(t, err)
+	require.Len(t, limits, 1)
+
+	require.Equal(t, []string{"alice@host1", "*@host1"}, quota.SubjectsForUser("alice@host1"))
+}
+
+func TestTracker(t *testing.T) {
+	ctx, env := repotesting.NewEnvironment(t)
+
+	require.NoError(t, quota.SetLimit(ctx, env.RepositoryWriter, &quota.Limit{Subject: "alice@host1", MaxBytes: 100}))
+	require.NoError(t, quota.SetLimit(ctx, env.RepositoryWriter, &quota.Limit{Subject: "*@host2", MaxBytes: 150}))
+
+	var tr quota.Tracker
+
+	require.NoError(t, tr.Load(ctx, env.RepositoryWriter))
+
+	require.True(t, tr.HasLimits("alice@host1"))
+	require.True(t, tr.HasLimits("bob@host2"))
+	require.False(t, tr.HasLimits("bob@host1"))
+
+	r, err := tr.Reserve("alice@host1", "c1", 50)
+	require.NoError(t, err)
+	require.Equal(t, int64(50), tr.Usage("alice@host1"))
+
+	// reservation is adjusted to the actual number of bytes written.
+	r.Commit(60)
+	require.Equal(t, int64(60), tr.Usage("alice@host1"))
+
+	_, err = tr.Reserve("alice@host1", "c2", 50)
+	require.True(t, errors.Is(err, repo.ErrQuotaExceeded))
+
+	// canceled reservation is not charged.
+	r, err = tr.Reserve("alice@host1", "c2", 40)
+	require.NoError(t, err)
+	r.Cancel()
+	require.Equal(t, int64(60), tr.Usage("alice@host1"))
+
+	r, err = tr.Reserve("alice@host1", "c2", 40)
+	require.NoError(t, err)
+
+	// concurrent write of the same key is not charged again, even when the quota is full.
+	r2, err := tr.Reserve("alice@host1", "c2", 40)
+	require.NoError(t, err)
+	r2.Commit(40)
+	r.Commit(40)
+	require.Equal(t, int64(100), tr.Usage("alice@host1"))
+
+	// host quota is shared by all users of the host.
+	tr.Charge("alice@host2", 100)
+
+	_, err = tr.Reserve("bob@host2", "c3", 100)
+	require.True(t, errors.Is(err, repo.ErrQuotaExceeded))
+
+	r, err = tr.Reserve("bob@host2", "c3", 50)
+	require.NoError(t, err)
+	r.Commit(50)
+	require.Equal(t, int64(150), tr.Usage("*@host2"))
+
+	require.NoError(t, tr.Flush(ctx, env.RepositoryWriter))
+
+	u, err := quota.GetUsage(ctx, env.RepositoryWriter, "alice@host1")
+	require.NoError(t, err)
+	require.Equal(t, int64(100), u.Bytes)
+
+	// usage is preserved when loaded by another tracker, such as after server restart.
+	var tr2 quota.Tracker
+
+	require.NoError(t, tr2.Load(ctx, env.RepositoryWriter))
+	require.Equal(t, int64(150), tr2.Usage("*@host2"))
+
+	_, err = tr2.Reserve("alice@host1", "c4", 1)
+	require.True(t, errors.Is(err, repo.ErrQuotaExceeded))
+
+	// usage accounted since the last flush is added to the persisted usage, which was reset meanwhile.
+	tr.Charge("bob@host1", 10)
+	require.NoError(t, quota.SetUsage(ctx, env.RepositoryWriter, &quota.Usage{Subject: "bob@host1"}))
+	require.NoError(t, tr.Flush(ctx, env.RepositoryWriter))
+
+	u, err = quota.GetUsage(ctx, env.RepositoryWriter, "bob@host1")
+	require.NoError(t, err)
+	require.Equal(t, int64(10), u.Bytes)
+}
+
+func TestRecompute(t *testing.T) {
+	ctx, env := repotesting.NewEnvironment(t)
+
+	shared := bytes.Repeat([]byte{1}, 1000)
+
+	createSnapshot := func(si snapshot.SourceInfo, files map[string][]byte) {
+		dir := mockfs.NewDirectory()
+		for name, data := range files {
+			dir.AddFile(name, data, 0o644)
+		}
+
+		man, err := snapshotfs.NewUploader(env.RepositoryWriter).Upload(ctx, dir, policy.BuildTree(nil, policy.DefaultPolicy), si)
+		require.NoError(t, err)
+
+		_, err = snapshot.SaveSnapshot(ctx, env.RepositoryWriter, man)
+		require.NoError(t, err)
+	}
+
+	createSnapshot(snapshot.SourceInfo{UserName: "alice", Host: "host1", Path: "/a"}, map[string][]byte{
+		"shared": shared,
+		"alice":  bytes.Repeat([]byte{2}, 2000),
+	})
+	createSnapshot(snapshot.SourceInfo{UserName: "bob", Host: "host1", Path: "/b"}, map[string][]byte{
+		"shared": shared,
+	})
+
+	// usage of users without snapshots is cleared.
+	require.NoError(t, quota.SetUsage(ctx, env.RepositoryWriter, &quota.Usage{Subject: "carol@host1", Bytes: 12345}))
+
+	require.NoError(t, env.RepositoryWriter.Flush(ctx))
+
+	usage, err := quota.Recompute(ctx, env.RepositoryWriter)
+	require.NoError(t, err)
+
+	bySubject := map[string]int64{}
+	for _, u := range usage {
+		bySubject[u.Subject] = u.Bytes
+	}
+
+	require.Len(t, bySubject, 4)
+	require.Zero(t, bySubject["carol@host1"])
+
+	// shared contents count once, towards usage of the user whose snapshot referenced them first.
+	require.Greater(t, bySubject["alice@host1"], int64(3000))
+	require.Greater(t, bySubject["bob@host1"], int64(0))
+	require.Less(t, bySubject["bob@host1"], int64(1000))
+	require.Equal(t, bySubject["alice@host1"]+bySubject["bob@host1"], bySubject["*@host1"])
+
+	u, err := quota.GetUsage(ctx, env.RepositoryWriter, "alice@host1")
+	require.NoError(t, err)
+	require.Equal(t, bySubject["alice@host1"], u.Bytes)
+}
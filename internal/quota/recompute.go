@@ -0,0 +1,173 @@
+package quota
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/kopia/kopia/fs"
+	"github.com/kopia/kopia/repo"
+	"github.com/kopia/kopia/repo/content"
+	"github.com/kopia/kopia/repo/logging"
+	"github.com/kopia/kopia/repo/object"
+	"github.com/kopia/kopia/snapshot"
+	"github.com/kopia/kopia/snapshot/snapshotfs"
+)
+
+var log = logging.GetContextLoggerFunc("quota")
+
+// Recompute recomputes storage usage of all users and hosts from contents referenced by their snapshots,
+// replacing usage accounted by the repository server. Consistent with accounting done by the server, which charges
+// new contents to users writing them, each content counts once, with its packed length, towards usage of the user
+// whose snapshot referenced it first.
+func Recompute(ctx context.Context, rep repo.DirectRepositoryWriter) ([]*Usage, error) {
+	sources, err := snapshot.ListSources(ctx, rep)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to list sources")
+	}
+
+	var manifests []*snapshot.Manifest
+
+	for _, si := range sources {
+		m, err := snapshot.ListSnapshots(ctx, rep, si)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to list snapshots of %v", si)
+		}
+
+		manifests = append(manifests, m...)
+	}
+
+	sort.SliceStable(manifests, func(i, j int) bool {
+		return manifests[i].StartTime.Before(manifests[j].StartTime)
+	})
+
+	var (
+		charged        = map[content.ID]struct{}{}
+		usageBySubject = map[string]int64{}
+	)
+
+	// the walker skips objects seen in earlier snapshots, whose contents have already been charged.
+	w := snapshotfs.NewTreeWalker()
+	w.EntryID = func(e fs.Entry) interface{} { return e.(object.HasObjectID).ObjectID() }
+
+	for _, m := range manifests {
+		u := m.Source.UserName + "@" + m.Source.Host
+
+		log(ctx).Debugf("looking for contents referenced by snapshot %v of %v", m.ID, u)
+
+		contentIDs, err := referencedContents(ctx, rep, w, m)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to find contents referenced by snapshot %v of %v", m.ID, u)
+		}
+
+		var packed int64
+
+		for cid := range contentIDs {
+			if _, ok := charged[cid]; ok {
+				continue
+			}
+
+			charged[cid] = struct{}{}
+
+			ci, err := rep.ContentReader().ContentInfo(ctx, cid)
+			if err != nil {
+				return nil, errors.Wrapf(err, "unable to get content info for %v", cid)
+			}
+
+			packed += int64(ci.GetPackedLength())
+		}
+
+		for _, s := range SubjectsForUser(u) {
+			usageBySubject[s] += packed
+		}
+	}
+
+	// subjects which no longer have any snapshots have their usage cleared.
+	previous, err := ListUsage(ctx, rep)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, u := range previous {
+		if _, ok := usageBySubject[u.Subject]; !ok {
+			usageBySubject[u.Subject] = 0
+		}
+	}
+
+	var result []*Usage
+
+	for s, n := range usageBySubject {
+		u := &Usage{
+			Subject:      s,
+			Bytes:        n,
+			ComputedTime: rep.Time(),
+		}
+
+		if err := SetUsage(ctx, rep, u); err != nil {
+			return nil, err
+		}
+
+		result = append(result, u)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Subject < result[j].Subject
+	})
+
+	return result, nil
+}
+
+// referencedContents returns IDs of contents referenced by objects of the provided snapshot, which the tree walker
+// has not seen before.
+func referencedContents(ctx context.Context, rep repo.Repository, w *snapshotfs.TreeWalker, m *snapshot.Manifest) (map[content.ID]struct{}, error) {
+	var (
+		mu     sync.Mutex
+		result = map[content.ID]struct{}{}
+	)
+
+	addObject := func(oid object.ID) error {
+		contentIDs, err := rep.VerifyObject(ctx, oid)
+		if err != nil {
+			return errors.Wrapf(err, "error verifying %v", oid)
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		for _, cid := range contentIDs {
+			result[cid] = struct{}{}
+		}
+
+		return nil
+	}
+
+	root, err := snapshotfs.SnapshotRoot(rep, m)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get snapshot root")
+	}
+
+	w.RootEntries = []fs.Entry{root}
+
+	w.ObjectCallback = func(entry fs.Entry) error {
+		if err := addObject(entry.(object.HasObjectID).ObjectID()); err != nil {
+			return err
+		}
+
+		// extended attributes that don't fit in the directory entry are stored as separate objects.
+		if h, ok := entry.(snapshot.HasDirEntry); ok {
+			if ea := h.DirEntry().ExtendedAttributes; ea != nil && ea.ObjectID != "" {
+				return addObject(ea.ObjectID)
+			}
+		}
+
+		return nil
+	}
+
+	if err := w.Run(ctx); err != nil {
+		return nil, errors.Wrap(err, "error walking snapshot tree")
+	}
+
+	return result, nil
+}
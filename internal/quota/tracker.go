@@ -0,0 +1,207 @@
+package quota
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/kopia/kopia/internal/units"
+	"github.com/kopia/kopia/repo"
+)
+
+// Tracker enforces quota limits and accounts for bytes of new contents written on behalf of users.
+//
+// Usage is accumulated in memory and must be periodically persisted in the repository using Flush(),
+// which allows it to survive server restarts.
+type Tracker struct {
+	mu sync.Mutex
+
+	limits  map[string]int64 // subject -> maximum number of bytes
+	usage   map[string]int64 // subject -> persisted usage
+	pending map[string]int64 // subject -> usage not persisted yet
+
+	reserved map[string]bool // keys of outstanding reservations
+}
+
+// Load (re-)loads quota limits and persisted usage from the repository.
+func (t *Tracker) Load(ctx context.Context, rep repo.Repository) error {
+	limits, err := ListLimits(ctx, rep)
+	if err != nil {
+		return errors.Wrap(err, "error loading quota limits")
+	}
+
+	usage, err := ListUsage(ctx, rep)
+	if err != nil {
+		return errors.Wrap(err, "error loading quota usage")
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.limits = map[string]int64{}
+	for _, l := range limits {
+		t.limits[l.Subject] = l.MaxBytes
+	}
+
+	t.usage = map[string]int64{}
+	for _, u := range usage {
+		t.usage[u.Subject] = u.Bytes
+	}
+
+	return nil
+}
+
+// HasLimits returns true if any quota applies to the provided user.
+func (t *Tracker) HasLimits(usernameAtHostname string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, s := range SubjectsForUser(usernameAtHostname) {
+		if _, ok := t.limits[s]; ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Reservation represents bytes reserved on behalf of a user by Tracker.Reserve().
+type Reservation struct {
+	t                  *Tracker
+	usernameAtHostname string
+	key                string
+	n                  int64
+}
+
+// Reserve atomically checks that writing the provided number of bytes on behalf of the user does not exceed
+// any of the quotas applicable to the user and charges them, returning an error wrapping repo.ErrQuotaExceeded
+// otherwise. The key identifies what is being written, such as the content ID, and while a reservation for
+// the same key is outstanding, reserving it again does not charge anything, so that concurrent writes
+// of the same data are only charged once.
+//
+// The returned reservation must be either committed with the actual number of bytes written or canceled.
+func (t *Tracker) Reserve(usernameAtHostname, key string, n int64) (*Reservation, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.reserved[key] {
+		return &Reservation{}, nil
+	}
+
+	for _, s := range SubjectsForUser(usernameAtHostname) {
+		limit, ok := t.limits[s]
+		if !ok {
+			continue
+		}
+
+		if used := t.usage[s] + t.pending[s]; used+n > limit {
+			return nil, errors.Wrapf(repo.ErrQuotaExceeded, "%v has used %v of %v", s, units.BytesStringBase10(used), units.BytesStringBase10(limit))
+		}
+	}
+
+	if t.reserved == nil {
+		t.reserved = map[string]bool{}
+	}
+
+	t.reserved[key] = true
+	t.chargeLocked(usernameAtHostname, n)
+
+	return &Reservation{t, usernameAtHostname, key, n}, nil
+}
+
+// Commit adjusts the reservation to the actual number of bytes written.
+func (r *Reservation) Commit(actual int64) {
+	r.release(actual)
+}
+
+// Cancel releases the reservation without charging anything.
+func (r *Reservation) Cancel() {
+	r.release(0)
+}
+
+func (r *Reservation) release(actual int64) {
+	if r.t == nil {
+		return
+	}
+
+	r.t.mu.Lock()
+	defer r.t.mu.Unlock()
+
+	delete(r.t.reserved, r.key)
+	r.t.chargeLocked(r.usernameAtHostname, actual-r.n)
+	r.t = nil
+}
+
+// Charge accounts for the provided number of bytes written on behalf of the user.
+func (t *Tracker) Charge(usernameAtHostname string, n int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.chargeLocked(usernameAtHostname, n)
+}
+
+func (t *Tracker) chargeLocked(usernameAtHostname string, n int64) {
+	if t.pending == nil {
+		t.pending = map[string]int64{}
+	}
+
+	for _, s := range SubjectsForUser(usernameAtHostname) {
+		t.pending[s] += n
+	}
+}
+
+// Usage returns the number of bytes used by the provided subject, including usage not persisted yet.
+func (t *Tracker) Usage(subject string) int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.usage[subject] + t.pending[subject]
+}
+
+// Flush adds usage accumulated since the last flush to usage persisted in the repository.
+func (t *Tracker) Flush(ctx context.Context, w repo.RepositoryWriter) error {
+	t.mu.Lock()
+	pending := t.pending
+	t.pending = nil
+	t.mu.Unlock()
+
+	for s, n := range pending {
+		u, err := GetUsage(ctx, w, s)
+		if err == nil {
+			u.Bytes += n
+			err = SetUsage(ctx, w, u)
+		}
+
+		if err != nil {
+			t.restorePending(pending)
+			return errors.Wrapf(err, "error persisting usage of %v", s)
+		}
+
+		delete(pending, s)
+
+		t.mu.Lock()
+		if t.usage == nil {
+			t.usage = map[string]int64{}
+		}
+
+		t.usage[s] = u.Bytes
+		t.mu.Unlock()
+	}
+
+	return nil
+}
+
+// restorePending adds back usage that could not be persisted, so it's retried during next flush.
+func (t *Tracker) restorePending(pending map[string]int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.pending == nil {
+		t.pending = map[string]int64{}
+	}
+
+	for s, n := range pending {
+		t.pending[s] += n
+	}
+}
@@ -66,8 +66,14 @@ func (s *Server) handleContentPut(ctx context.Context, r *http.Request, data []b
 		return nil, accessDeniedError()
 	}
 
-	actualCID, err := dr.ContentManager().WriteContent(ctx, data, prefix)
+	userAtHost, _ := requestCredentials(r)
+
+	actualCID, err := s.writeContentWithQuota(ctx, dr, userAtHost, data, prefix)
 	if err != nil {
+		if errors.Is(err, repo.ErrQuotaExceeded) {
+			return nil, quotaExceededError(err)
+		}
+
 		return nil, internalServerError(err)
 	}
 
@@ -27,6 +27,10 @@ func accessDeniedError() *apiError {
 	return &apiError{http.StatusForbidden, serverapi.ErrorAccessDenied, "access is denied"}
 }
 
+func quotaExceededError(err error) *apiError {
+	return &apiError{http.StatusInsufficientStorage, serverapi.ErrorQuotaExceeded, err.Error()}
+}
+
 func repositoryNotWritableError() *apiError {
 	return internalServerError(errors.Errorf("repository is not writable"))
 }
@@ -0,0 +1,120 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/pkg/errors"
+
+	"github.com/kopia/kopia/internal/ctxutil"
+	"github.com/kopia/kopia/internal/serverapi"
+	"github.com/kopia/kopia/internal/uitask"
+	"github.com/kopia/kopia/repo"
+	"github.com/kopia/kopia/repo/maintenance"
+)
+
+func (s *Server) handleMaintenanceInfo(ctx context.Context, r *http.Request, body []byte) (interface{}, *apiError) {
+	dr, ok := s.rep.(repo.DirectRepository)
+	if !ok {
+		return nil, requestError(serverapi.ErrorNotConnected, "no direct repository connection")
+	}
+
+	p, err := maintenance.GetParams(ctx, dr)
+	if err != nil {
+		return nil, internalServerError(err)
+	}
+
+	sched, err := maintenance.GetSchedule(ctx, dr)
+	if err != nil {
+		return nil, internalServerError(err)
+	}
+
+	return &serverapi.MaintenanceInfo{
+		Params:   *p,
+		Schedule: *sched,
+		IsOwner:  p.Owner == dr.ClientOptions().UsernameAtHost(),
+	}, nil
+}
+
+func (s *Server) handleMaintenanceSetParams(ctx context.Context, r *http.Request, body []byte) (interface{}, *apiError) {
+	var req maintenance.Params
+
+	if err := json.Unmarshal(body, &req); err != nil {
+		return nil, requestError(serverapi.ErrorMalformedRequest, "malformed request body")
+	}
+
+	if _, ok := s.rep.(repo.DirectRepository); !ok {
+		return nil, requestError(serverapi.ErrorNotConnected, "no direct repository connection")
+	}
+
+	if (req.QuickCycle.Enabled && req.QuickCycle.Interval <= 0) || (req.FullCycle.Enabled && req.FullCycle.Interval <= 0) {
+		return nil, requestError(serverapi.ErrorMalformedRequest, "maintenance interval must be positive")
+	}
+
+	if err := repo.WriteSession(ctx, s.rep, repo.WriteSessionOptions{
+		Purpose: "handleMaintenanceSetParams",
+	}, func(w repo.RepositoryWriter) error {
+		// keep the current owner unless a new one is explicitly provided.
+		if req.Owner == "" {
+			cur, err := maintenance.GetParams(ctx, w)
+			if err != nil {
+				return errors.Wrap(err, "unable to get current maintenance parameters")
+			}
+
+			req.Owner = cur.Owner
+		}
+
+		return maintenance.SetParams(ctx, w, &req)
+	}); err != nil {
+		return nil, internalServerError(err)
+	}
+
+	return s.handleMaintenanceInfo(ctx, r, nil)
+}
+
+func (s *Server) handleMaintenanceRun(ctx context.Context, r *http.Request, body []byte) (interface{}, *apiError) {
+	var req serverapi.RunMaintenanceRequest
+
+	if err := json.Unmarshal(body, &req); err != nil {
+		return nil, requestError(serverapi.ErrorMalformedRequest, "malformed request body")
+	}
+
+	if _, ok := s.rep.(repo.DirectRepository); !ok {
+		return nil, requestError(serverapi.ErrorNotConnected, "no direct repository connection")
+	}
+
+	mode := maintenance.ModeQuick
+	if req.Full {
+		mode = maintenance.ModeFull
+	}
+
+	ctx = ctxutil.Detach(ctx)
+	rep := s.rep
+
+	taskIDChan := make(chan string)
+
+	// launch a goroutine that will run the maintenance and can be observed in the Tasks UI.
+
+	// nolint:errcheck
+	go s.taskmgr.Run(ctx, "Maintenance", fmt.Sprintf("Run %v maintenance", mode), func(ctx context.Context, ctrl uitask.Controller) error {
+		taskIDChan <- ctrl.CurrentTaskID()
+
+		maintenancectx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		ctrl.OnCancel(cancel)
+
+		return runMaintenance(maintenancectx, rep, mode, req.Force)
+	})
+
+	taskID := <-taskIDChan
+
+	task, ok := s.taskmgr.GetTask(taskID)
+	if !ok {
+		return nil, internalServerError(errors.Errorf("task not found"))
+	}
+
+	return task, nil
+}
@@ -0,0 +1,103 @@
+package server_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kopia/kopia/internal/apiclient"
+	"github.com/kopia/kopia/internal/serverapi"
+	"github.com/kopia/kopia/internal/testlogging"
+	"github.com/kopia/kopia/internal/uitask"
+)
+
+func TestMaintenance(t *testing.T) {
+	ctx := testlogging.Context(t)
+	si := startServer(ctx, t)
+
+	uiUserClient, err := apiclient.NewKopiaAPIClient(apiclient.Options{
+		BaseURL:                             si.BaseURL,
+		TrustedServerCertificateFingerprint: si.TrustedServerCertificateFingerprint,
+		Username:                            testUIUsername,
+		Password:                            testUIPassword,
+	})
+	require.NoError(t, err)
+
+	remoteUserClient, err := apiclient.NewKopiaAPIClient(apiclient.Options{
+		BaseURL:                             si.BaseURL,
+		TrustedServerCertificateFingerprint: si.TrustedServerCertificateFingerprint,
+		Username:                            testUsername + "@" + testHostname,
+		Password:                            testPassword,
+	})
+	require.NoError(t, err)
+
+	_, err = serverapi.GetMaintenanceInfo(ctx, remoteUserClient)
+	require.Error(t, err)
+
+	info, err := serverapi.GetMaintenanceInfo(ctx, uiUserClient)
+	require.NoError(t, err)
+	require.True(t, info.Schedule.NextQuickMaintenanceTime.IsZero())
+
+	p := info.Params
+	p.FullCycle.Interval = 0
+
+	_, err = serverapi.SetMaintenanceParams(ctx, uiUserClient, &p)
+	require.Error(t, err)
+
+	p.FullCycle.Interval = 48 * time.Hour
+
+	info, err = serverapi.SetMaintenanceParams(ctx, uiUserClient, &p)
+	require.NoError(t, err)
+	require.Equal(t, 48*time.Hour, info.Params.FullCycle.Interval)
+
+	// omitted owner keeps the current one.
+	p.Owner = "someone@somehost"
+
+	info, err = serverapi.SetMaintenanceParams(ctx, uiUserClient, &p)
+	require.NoError(t, err)
+	require.Equal(t, "someone@somehost", info.Params.Owner)
+
+	p.Owner = ""
+	p.QuickCycle.Interval = 2 * time.Hour
+
+	info, err = serverapi.SetMaintenanceParams(ctx, uiUserClient, &p)
+	require.NoError(t, err)
+	require.Equal(t, "someone@somehost", info.Params.Owner)
+	require.Equal(t, 2*time.Hour, info.Params.QuickCycle.Interval)
+
+	// maintenance is not owned by the server user, it will only run when forced.
+	task, err := serverapi.RunMaintenance(ctx, uiUserClient, &serverapi.RunMaintenanceRequest{})
+	require.NoError(t, err)
+	require.Equal(t, uitask.StatusFailed, waitForTask(t, uiUserClient, task.TaskID).Status)
+
+	task, err = serverapi.RunMaintenance(ctx, uiUserClient, &serverapi.RunMaintenanceRequest{Force: true})
+	require.NoError(t, err)
+	require.Equal(t, "Maintenance", task.Kind)
+	require.Equal(t, uitask.StatusSuccess, waitForTask(t, uiUserClient, task.TaskID).Status)
+
+	info, err = serverapi.GetMaintenanceInfo(ctx, uiUserClient)
+	require.NoError(t, err)
+	require.False(t, info.Schedule.NextQuickMaintenanceTime.IsZero())
+}
+
+// nolint:thelper
+func waitForTask(t *testing.T, cli *apiclient.KopiaAPIClient, taskID string) *uitask.Info {
+	ctx := testlogging.Context(t)
+	deadline := time.Now().Add(30 * time.Second)
+
+	for {
+		ti := &uitask.Info{}
+		require.NoError(t, cli.Get(ctx, "tasks/"+taskID, nil, ti))
+
+		if ti.Status != uitask.StatusRunning && ti.Status != uitask.StatusCanceling {
+			return ti
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("task %v did not finish in time", taskID)
+		}
+
+		time.Sleep(100 * time.Millisecond)
+	}
+}
@@ -12,6 +12,7 @@
 	"github.com/kopia/kopia/internal/serverapi"
 	"github.com/kopia/kopia/repo"
 	"github.com/kopia/kopia/repo/blob"
+	"github.com/kopia/kopia/repo/blob/throttling"
 	"github.com/kopia/kopia/repo/compression"
 	"github.com/kopia/kopia/repo/encryption"
 	"github.com/kopia/kopia/repo/hashing"
@@ -214,6 +215,42 @@ func (s *Server) handleRepoSetDescription(ctx context.Context, r *http.Request,
 	return s.handleRepoStatus(ctx, r, nil)
 }
 
+func (s *Server) handleRepoGetThrottle(ctx context.Context, r *http.Request, body []byte) (interface{}, *apiError) {
+	dr, ok := s.rep.(repo.DirectRepository)
+	if !ok {
+		return nil, requestError(serverapi.ErrorNotConnected, "no direct repository connection")
+	}
+
+	return dr.Throttler().Limits(), nil
+}
+
+func (s *Server) handleRepoSetThrottle(ctx context.Context, r *http.Request, body []byte) (interface{}, *apiError) {
+	dr, ok := s.rep.(repo.DirectRepository)
+	if !ok {
+		return nil, requestError(serverapi.ErrorNotConnected, "no direct repository connection")
+	}
+
+	var req throttling.Limits
+
+	if err := json.Unmarshal(body, &req); err != nil {
+		return nil, requestError(serverapi.ErrorMalformedRequest, "unable to decode request: "+err.Error())
+	}
+
+	if err := req.Validate(); err != nil {
+		return nil, requestError(serverapi.ErrorMalformedRequest, "invalid limits: "+err.Error())
+	}
+
+	if err := repo.SetThrottlingLimits(ctx, s.options.ConfigFile, req); err != nil {
+		return nil, internalServerError(err)
+	}
+
+	if err := dr.Throttler().SetLimits(req); err != nil {
+		return nil, internalServerError(err)
+	}
+
+	return dr.Throttler().Limits(), nil
+}
+
 func (s *Server) handleRepoSupportedAlgorithms(ctx context.Context, r *http.Request, body []byte) (interface{}, *apiError) {
 	res := &serverapi.SupportedAlgorithmsResponse{
 		DefaultHashAlgorithm: hashing.DefaultAlgorithm,
@@ -0,0 +1,46 @@
+package server_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kopia/kopia/internal/apiclient"
+	"github.com/kopia/kopia/internal/serverapi"
+	"github.com/kopia/kopia/internal/testlogging"
+	"github.com/kopia/kopia/repo/blob/throttling"
+)
+
+func TestRepoThrottle(t *testing.T) {
+	ctx := testlogging.Context(t)
+	si := startServer(ctx, t)
+
+	cli, err := apiclient.NewKopiaAPIClient(apiclient.Options{
+		BaseURL:                             si.BaseURL,
+		TrustedServerCertificateFingerprint: si.TrustedServerCertificateFingerprint,
+		Username:                            testUIUsername,
+		Password:                            testUIPassword,
+	})
+	require.NoError(t, err)
+
+	limits, err := serverapi.GetThrottlingLimits(ctx, cli)
+	require.NoError(t, err)
+	require.Equal(t, throttling.Limits{}, limits)
+
+	want := throttling.Limits{
+		ReadsPerSecond:       100,
+		UploadBytesPerSecond: 1e6,
+	}
+
+	require.NoError(t, serverapi.SetThrottlingLimits(ctx, cli, want))
+
+	limits, err = serverapi.GetThrottlingLimits(ctx, cli)
+	require.NoError(t, err)
+	require.Equal(t, want, limits)
+
+	require.Error(t, serverapi.SetThrottlingLimits(ctx, cli, throttling.Limits{WritesPerSecond: -1}))
+
+	limits, err = serverapi.GetThrottlingLimits(ctx, cli)
+	require.NoError(t, err)
+	require.Equal(t, want, limits)
+}
@@ -2,16 +2,27 @@
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/url"
 
+	"github.com/pkg/errors"
+
 	"github.com/kopia/kopia/internal/serverapi"
+	"github.com/kopia/kopia/repo"
+	"github.com/kopia/kopia/repo/manifest"
 	"github.com/kopia/kopia/snapshot"
 	"github.com/kopia/kopia/snapshot/policy"
 )
 
 func (s *Server) handleSnapshotList(ctx context.Context, r *http.Request, body []byte) (interface{}, *apiError) {
-	manifestIDs, err := snapshot.ListSnapshotManifests(ctx, s.rep, nil)
+	tags, err := snapshot.ParseTags(r.URL.Query()["tags"])
+	if err != nil {
+		return nil, requestError(serverapi.ErrorMalformedRequest, "invalid tags: "+err.Error())
+	}
+
+	manifestIDs, err := snapshot.ListSnapshotManifestsWithTags(ctx, s.rep, nil, tags)
 	if err != nil {
 		return nil, internalServerError(err)
 	}
@@ -45,6 +56,226 @@ func (s *Server) handleSnapshotList(ctx context.Context, r *http.Request, body [
 	return resp, nil
 }
 
+func (s *Server) handleSnapshotPin(ctx context.Context, r *http.Request, body []byte) (interface{}, *apiError) {
+	var req serverapi.PinSnapshotsRequest
+
+	if err := json.Unmarshal(body, &req); err != nil {
+		return nil, requestError(serverapi.ErrorMalformedRequest, "malformed request body")
+	}
+
+	if len(req.Add) == 0 && len(req.Remove) == 0 {
+		return nil, requestError(serverapi.ErrorMalformedRequest, "no pins to add or remove")
+	}
+
+	for _, p := range append(append([]string(nil), req.Add...), req.Remove...) {
+		if p == "" {
+			return nil, requestError(serverapi.ErrorMalformedRequest, "pin name must not be empty")
+		}
+	}
+
+	manifests, aerr := s.loadSnapshotsForUpdate(ctx, r, req.Snapshots)
+	if aerr != nil {
+		return nil, aerr
+	}
+
+	return s.updateSnapshots(ctx, "handleSnapshotPin", manifests, func(m *snapshot.Manifest) bool {
+		return m.UpdatePins(req.Add, req.Remove)
+	})
+}
+
+func (s *Server) handleSnapshotEdit(ctx context.Context, r *http.Request, body []byte) (interface{}, *apiError) {
+	var req serverapi.EditSnapshotsRequest
+
+	if err := json.Unmarshal(body, &req); err != nil {
+		return nil, requestError(serverapi.ErrorMalformedRequest, "malformed request body")
+	}
+
+	if req.NewDescription == nil && len(req.AddTags) == 0 && len(req.RemoveTags) == 0 {
+		return nil, requestError(serverapi.ErrorMalformedRequest, "no changes requested")
+	}
+
+	if err := snapshot.ValidateTags(req.AddTags); err != nil {
+		return nil, requestError(serverapi.ErrorMalformedRequest, "invalid tags: "+err.Error())
+	}
+
+	manifests, aerr := s.loadSnapshotsForUpdate(ctx, r, req.Snapshots)
+	if aerr != nil {
+		return nil, aerr
+	}
+
+	return s.updateSnapshots(ctx, "handleSnapshotEdit", manifests, func(m *snapshot.Manifest) bool {
+		if req.NewDescription != nil {
+			m.Description = *req.NewDescription
+		}
+
+		if m.Tags == nil {
+			m.Tags = map[string]string{}
+		}
+
+		for _, k := range req.RemoveTags {
+			delete(m.Tags, k)
+		}
+
+		for k, v := range req.AddTags {
+			m.Tags[k] = v
+		}
+
+		return true
+	})
+}
+
+func (s *Server) handleSnapshotDelete(ctx context.Context, r *http.Request, body []byte) (interface{}, *apiError) {
+	var req serverapi.DeleteSnapshotsRequest
+
+	if err := json.Unmarshal(body, &req); err != nil {
+		return nil, requestError(serverapi.ErrorMalformedRequest, "malformed request body")
+	}
+
+	manifests, aerr := s.loadSnapshotsForUpdate(ctx, r, req.Snapshots)
+	if aerr != nil {
+		return nil, aerr
+	}
+
+	if err := repo.WriteSession(ctx, s.rep, repo.WriteSessionOptions{
+		Purpose: "handleSnapshotDelete",
+	}, func(w repo.RepositoryWriter) error {
+		for _, m := range manifests {
+			if err := w.DeleteManifest(ctx, m.ID); err != nil {
+				return errors.Wrapf(err, "unable to delete snapshot %v", m.ID)
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return nil, internalServerError(err)
+	}
+
+	return &serverapi.Empty{}, nil
+}
+
+func (s *Server) handleSnapshotExpire(ctx context.Context, r *http.Request, body []byte) (interface{}, *apiError) {
+	var req serverapi.ExpireSnapshotsRequest
+
+	if err := json.Unmarshal(body, &req); err != nil {
+		return nil, requestError(serverapi.ErrorMalformedRequest, "malformed request body")
+	}
+
+	if req.Source.Host == "" || req.Source.UserName == "" || req.Source.Path == "" {
+		return nil, requestError(serverapi.ErrorMalformedRequest, "source must specify host, user name and path")
+	}
+
+	if !canManageSnapshots(s, r, snapshotSourceLabels(req.Source)) {
+		return nil, accessDeniedError()
+	}
+
+	resp := &serverapi.ExpireSnapshotsResponse{
+		Deleted: []*serverapi.Snapshot{},
+	}
+
+	if req.DryRun {
+		decisions, err := policy.ComputeRetentionDecisions(ctx, s.rep, req.Source)
+		if err != nil {
+			return nil, internalServerError(err)
+		}
+
+		for _, d := range decisions {
+			if d.Expire {
+				resp.Deleted = append(resp.Deleted, convertSnapshotManifest(d.Manifest))
+			}
+		}
+
+		return resp, nil
+	}
+
+	if err := repo.WriteSession(ctx, s.rep, repo.WriteSessionOptions{
+		Purpose: "handleSnapshotExpire",
+	}, func(w repo.RepositoryWriter) error {
+		deleted, err := policy.ApplyRetentionPolicy(ctx, w, req.Source, true)
+		for _, m := range deleted {
+			resp.Deleted = append(resp.Deleted, convertSnapshotManifest(m))
+		}
+
+		return err
+	}); err != nil {
+		return nil, internalServerError(err)
+	}
+
+	return resp, nil
+}
+
+// loadSnapshotsForUpdate loads snapshot manifests with the provided IDs, ensuring that the user
+// making the request is allowed to modify them.
+func (s *Server) loadSnapshotsForUpdate(ctx context.Context, r *http.Request, ids []manifest.ID) ([]*snapshot.Manifest, *apiError) {
+	if len(ids) == 0 {
+		return nil, requestError(serverapi.ErrorMalformedRequest, "no snapshots provided")
+	}
+
+	var result []*snapshot.Manifest
+
+	for _, id := range ids {
+		m := &snapshot.Manifest{}
+
+		em, err := s.rep.GetManifest(ctx, id, m)
+		if errors.Is(err, manifest.ErrNotFound) {
+			return nil, notFoundError(fmt.Sprintf("snapshot %v not found", id))
+		}
+
+		if err != nil {
+			return nil, internalServerError(err)
+		}
+
+		if em.Labels[manifest.TypeLabelKey] != snapshot.ManifestType {
+			return nil, requestError(serverapi.ErrorMalformedRequest, fmt.Sprintf("manifest %v is not a snapshot", id))
+		}
+
+		if !canManageSnapshots(s, r, em.Labels) {
+			return nil, accessDeniedError()
+		}
+
+		m.ID = id
+		result = append(result, m)
+	}
+
+	return result, nil
+}
+
+// updateSnapshots applies the provided function to all manifests and saves the ones that have changed.
+func (s *Server) updateSnapshots(ctx context.Context, purpose string, manifests []*snapshot.Manifest, update func(m *snapshot.Manifest) bool) (interface{}, *apiError) {
+	resp := &serverapi.SnapshotsResponse{
+		Snapshots: []*serverapi.Snapshot{},
+	}
+
+	if err := repo.WriteSession(ctx, s.rep, repo.WriteSessionOptions{
+		Purpose: purpose,
+	}, func(w repo.RepositoryWriter) error {
+		for _, m := range manifests {
+			if update(m) {
+				if _, err := snapshot.UpdateSnapshot(ctx, w, m); err != nil {
+					return errors.Wrapf(err, "unable to update snapshot %v", m.ID)
+				}
+			}
+
+			resp.Snapshots = append(resp.Snapshots, convertSnapshotManifest(m))
+		}
+
+		return nil
+	}); err != nil {
+		return nil, internalServerError(err)
+	}
+
+	return resp, nil
+}
+
+// snapshotSourceLabels returns labels of snapshot manifests of the provided source, used for authorization.
+func snapshotSourceLabels(src snapshot.SourceInfo) map[string]string {
+	return map[string]string{
+		manifest.TypeLabelKey:  snapshot.ManifestType,
+		snapshot.HostnameLabel: src.Host,
+		snapshot.UsernameLabel: src.UserName,
+		snapshot.PathLabel:     src.Path,
+	}
+}
+
 func sourceMatchesURLFilter(src snapshot.SourceInfo, query url.Values) bool {
 	if v := query.Get("host"); v != "" && src.Host != v {
 		return false
@@ -66,6 +297,8 @@ func convertSnapshotManifest(m *snapshot.Manifest) *serverapi.Snapshot {
 		ID:               m.ID,
 		Source:           m.Source,
 		Description:      m.Description,
+		Tags:             m.Tags,
+		Pins:             m.Pins,
 		StartTime:        m.StartTime,
 		EndTime:          m.EndTime,
 		IncompleteReason: m.IncompleteReason,
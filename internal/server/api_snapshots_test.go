@@ -0,0 +1,284 @@
+package server_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kopia/kopia/internal/apiclient"
+	"github.com/kopia/kopia/internal/serverapi"
+	"github.com/kopia/kopia/internal/testlogging"
+	"github.com/kopia/kopia/internal/testutil"
+	"github.com/kopia/kopia/repo"
+	"github.com/kopia/kopia/repo/content"
+	"github.com/kopia/kopia/repo/manifest"
+	"github.com/kopia/kopia/snapshot"
+	"github.com/kopia/kopia/snapshot/policy"
+)
+
+func TestSnapshotListWithTags(t *testing.T) {
+	ctx := testlogging.Context(t)
+	si := startServer(ctx, t)
+
+	rep, err := repo.OpenAPIServer(ctx, si, repo.ClientOptions{
+		Username: testUsername,
+		Hostname: testHostname,
+	}, &content.CachingOptions{
+		CacheDirectory:    testutil.TempDirectory(t),
+		MaxCacheSizeBytes: maxCacheSizeBytes,
+	}, testPassword)
+	require.NoError(t, err)
+
+	defer rep.Close(ctx)
+
+	src := snapshot.SourceInfo{
+		Host:     testHostname,
+		UserName: testUsername,
+		Path:     testPathname,
+	}
+
+	require.NoError(t, repo.WriteSession(ctx, rep, repo.WriteSessionOptions{}, func(w repo.RepositoryWriter) error {
+		for _, tags := range []map[string]string{
+			{"purpose": "pre-upgrade", "ticket": "1234"},
+			{"purpose": "release-1.4"},
+			nil,
+		} {
+			if _, err := snapshot.SaveSnapshot(ctx, w, &snapshot.Manifest{Source: src, Tags: tags}); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}))
+
+	uiUserClient, err := apiclient.NewKopiaAPIClient(apiclient.Options{
+		BaseURL:                             si.BaseURL,
+		TrustedServerCertificateFingerprint: si.TrustedServerCertificateFingerprint,
+		Username:                            testUIUsername,
+		Password:                            testUIPassword,
+	})
+	require.NoError(t, err)
+
+	cases := map[string]int{
+		"snapshots":                                  3,
+		"snapshots?tags=purpose:pre-upgrade":         1,
+		"snapshots?tags=purpose:release-1.4":         1,
+		"snapshots?tags=ticket:1234&tags=purpose:x":  0,
+		"snapshots?tags=ticket:1234&path=/tmp/path":  1,
+		"snapshots?tags=ticket:1234&path=/tmp/other": 0,
+	}
+
+	for u, want := range cases {
+		var resp serverapi.SnapshotsResponse
+
+		require.NoError(t, uiUserClient.Get(ctx, u, nil, &resp), u)
+		require.Len(t, resp.Snapshots, want, u)
+	}
+
+	var resp serverapi.SnapshotsResponse
+
+	require.NoError(t, uiUserClient.Get(ctx, "snapshots?tags=purpose:release-1.4", nil, &resp))
+	require.Equal(t, map[string]string{"purpose": "release-1.4"}, resp.Snapshots[0].Tags)
+
+	require.Error(t, uiUserClient.Get(ctx, "snapshots?tags=invalid", nil, &serverapi.SnapshotsResponse{}))
+}
+
+func TestSnapshotPin(t *testing.T) {
+	ctx := testlogging.Context(t)
+	si := startServer(ctx, t)
+
+	rep, err := repo.OpenAPIServer(ctx, si, repo.ClientOptions{
+		Username: testUsername,
+		Hostname: testHostname,
+	}, &content.CachingOptions{
+		CacheDirectory:    testutil.TempDirectory(t),
+		MaxCacheSizeBytes: maxCacheSizeBytes,
+	}, testPassword)
+	require.NoError(t, err)
+
+	defer rep.Close(ctx)
+
+	src := snapshot.SourceInfo{
+		Host:     testHostname,
+		UserName: testUsername,
+		Path:     testPathname,
+	}
+
+	var snapshotID manifest.ID
+
+	require.NoError(t, repo.WriteSession(ctx, rep, repo.WriteSessionOptions{}, func(w repo.RepositoryWriter) error {
+		snapshotID, err = snapshot.SaveSnapshot(ctx, w, &snapshot.Manifest{Source: src})
+		return err
+	}))
+
+	uiUserClient, err := apiclient.NewKopiaAPIClient(apiclient.Options{
+		BaseURL:                             si.BaseURL,
+		TrustedServerCertificateFingerprint: si.TrustedServerCertificateFingerprint,
+		Username:                            testUIUsername,
+		Password:                            testUIPassword,
+	})
+	require.NoError(t, err)
+
+	resp, err := serverapi.PinSnapshots(ctx, uiUserClient, &serverapi.PinSnapshotsRequest{
+		Snapshots: []manifest.ID{snapshotID},
+		Add:       []string{"release", "audit"},
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Snapshots, 1)
+	require.Equal(t, []string{"audit", "release"}, resp.Snapshots[0].Pins)
+
+	snapshotID = resp.Snapshots[0].ID
+
+	list, err := serverapi.ListSnapshots(ctx, uiUserClient, &src)
+	require.NoError(t, err)
+	require.Len(t, list.Snapshots, 1)
+	require.Equal(t, snapshotID, list.Snapshots[0].ID)
+	require.Equal(t, []string{"audit", "release"}, list.Snapshots[0].Pins)
+	require.Contains(t, list.Snapshots[0].RetentionReasons, "pinned")
+
+	resp, err = serverapi.PinSnapshots(ctx, uiUserClient, &serverapi.PinSnapshotsRequest{
+		Snapshots: []manifest.ID{snapshotID},
+		Remove:    []string{"audit"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"release"}, resp.Snapshots[0].Pins)
+
+	_, err = serverapi.PinSnapshots(ctx, uiUserClient, &serverapi.PinSnapshotsRequest{
+		Snapshots: []manifest.ID{"no-such-snapshot"},
+		Add:       []string{"x"},
+	})
+	require.Error(t, err)
+
+	_, err = serverapi.PinSnapshots(ctx, uiUserClient, &serverapi.PinSnapshotsRequest{
+		Snapshots: []manifest.ID{snapshotID},
+	})
+	require.Error(t, err)
+}
+
+func TestSnapshotManagement(t *testing.T) {
+	ctx := testlogging.Context(t)
+	si := startServer(ctx, t)
+
+	rep, err := repo.OpenAPIServer(ctx, si, repo.ClientOptions{
+		Username: testUsername,
+		Hostname: testHostname,
+	}, &content.CachingOptions{
+		CacheDirectory:    testutil.TempDirectory(t),
+		MaxCacheSizeBytes: maxCacheSizeBytes,
+	}, testPassword)
+	require.NoError(t, err)
+
+	defer rep.Close(ctx)
+
+	src := snapshot.SourceInfo{Host: testHostname, UserName: testUsername, Path: testPathname}
+	otherSrc := snapshot.SourceInfo{Host: testHostname, UserName: testOtherUsername, Path: testPathname}
+	keepLatest := 2
+
+	var ids []manifest.ID
+
+	require.NoError(t, repo.WriteSession(ctx, rep, repo.WriteSessionOptions{}, func(w repo.RepositoryWriter) error {
+		for i := 0; i < 4; i++ {
+			id, err := snapshot.SaveSnapshot(ctx, w, &snapshot.Manifest{
+				Source:    src,
+				StartTime: time.Date(2021, 1, 1, 12, i, 0, 0, time.UTC),
+			})
+			if err != nil {
+				return err
+			}
+
+			ids = append(ids, id)
+		}
+
+		return policy.SetPolicy(ctx, w, src, &policy.Policy{
+			RetentionPolicy: policy.RetentionPolicy{KeepLatest: &keepLatest},
+		})
+	}))
+
+	uiUserClient, err := apiclient.NewKopiaAPIClient(apiclient.Options{
+		BaseURL:                             si.BaseURL,
+		TrustedServerCertificateFingerprint: si.TrustedServerCertificateFingerprint,
+		Username:                            testUIUsername,
+		Password:                            testUIPassword,
+	})
+	require.NoError(t, err)
+
+	otherRep, err := repo.OpenAPIServer(ctx, si, repo.ClientOptions{
+		Username: testOtherUsername,
+		Hostname: testHostname,
+	}, &content.CachingOptions{
+		CacheDirectory:    testutil.TempDirectory(t),
+		MaxCacheSizeBytes: maxCacheSizeBytes,
+	}, testPassword)
+	require.NoError(t, err)
+
+	defer otherRep.Close(ctx)
+
+	var otherID manifest.ID
+
+	require.NoError(t, repo.WriteSession(ctx, otherRep, repo.WriteSessionOptions{}, func(w repo.RepositoryWriter) error {
+		otherID, err = snapshot.SaveSnapshot(ctx, w, &snapshot.Manifest{Source: otherSrc})
+		return err
+	}))
+
+	remoteUserClient, err := apiclient.NewKopiaAPIClient(apiclient.Options{
+		BaseURL:                             si.BaseURL,
+		TrustedServerCertificateFingerprint: si.TrustedServerCertificateFingerprint,
+		Username:                            testUsername + "@" + testHostname,
+		Password:                            testPassword,
+	})
+	require.NoError(t, err)
+
+	desc := "before upgrade"
+
+	resp, err := serverapi.EditSnapshots(ctx, remoteUserClient, &serverapi.EditSnapshotsRequest{
+		Snapshots:      []manifest.ID{ids[0]},
+		NewDescription: &desc,
+		AddTags:        map[string]string{"ticket": "1234"},
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Snapshots, 1)
+	require.Equal(t, desc, resp.Snapshots[0].Description)
+	require.Equal(t, map[string]string{"ticket": "1234"}, resp.Snapshots[0].Tags)
+
+	// remote users can't manage snapshots of other users.
+	_, err = serverapi.EditSnapshots(ctx, remoteUserClient, &serverapi.EditSnapshotsRequest{
+		Snapshots:      []manifest.ID{otherID},
+		NewDescription: &desc,
+	})
+	require.Error(t, err)
+
+	require.Error(t, serverapi.DeleteSnapshots(ctx, remoteUserClient, &serverapi.DeleteSnapshotsRequest{
+		Snapshots: []manifest.ID{otherID},
+	}))
+
+	_, err = serverapi.ExpireSnapshots(ctx, remoteUserClient, &serverapi.ExpireSnapshotsRequest{Source: otherSrc})
+	require.Error(t, err)
+
+	require.NoError(t, serverapi.DeleteSnapshots(ctx, remoteUserClient, &serverapi.DeleteSnapshotsRequest{
+		Snapshots: []manifest.ID{ids[3]},
+	}))
+
+	// of 3 remaining snapshots, 2 are retained.
+	expired, err := serverapi.ExpireSnapshots(ctx, remoteUserClient, &serverapi.ExpireSnapshotsRequest{Source: src, DryRun: true})
+	require.NoError(t, err)
+	require.Len(t, expired.Deleted, 1)
+
+	list, err := serverapi.ListSnapshots(ctx, uiUserClient, &src)
+	require.NoError(t, err)
+	require.Len(t, list.Snapshots, 3)
+
+	expired, err = serverapi.ExpireSnapshots(ctx, remoteUserClient, &serverapi.ExpireSnapshotsRequest{Source: src})
+	require.NoError(t, err)
+	require.Len(t, expired.Deleted, 1)
+	require.Equal(t, desc, expired.Deleted[0].Description)
+
+	list, err = serverapi.ListSnapshots(ctx, uiUserClient, &src)
+	require.NoError(t, err)
+	require.Len(t, list.Snapshots, 2)
+
+	// UI user can manage all snapshots.
+	require.NoError(t, serverapi.DeleteSnapshots(ctx, uiUserClient, &serverapi.DeleteSnapshotsRequest{
+		Snapshots: []manifest.ID{otherID},
+	}))
+}
@@ -2,6 +2,7 @@
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"net/http"
 	"runtime"
@@ -12,6 +13,7 @@
 	"golang.org/x/sync/semaphore"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
@@ -46,24 +48,61 @@ func (s *Server) send(srv grpcapi.KopiaRepository_SessionServer, requestID int64
 	return nil
 }
 
-func (s *Server) authenticateGRPCSession(ctx context.Context) (string, error) {
+// grpcTLSConnectionState returns the state of the TLS connection the GRPC session is using, if any.
+func grpcTLSConnectionState(ctx context.Context) *tls.ConnectionState {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil
+	}
+
+	ti, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok {
+		return nil
+	}
+
+	return &ti.State
+}
+
+// authenticateGRPCSession verifies client certificate or credentials passed in the metadata, which are either
+// kopia username, hostname and password or a bearer token, and returns the identity of the user and the credential used.
+func (s *Server) authenticateGRPCSession(ctx context.Context) (id auth.Identity, password string, err error) {
+	if cid, ok := s.clientCertificateIdentity(ctx, grpcTLSConnectionState(ctx)); ok {
+		return cid, "", nil
+	}
+
 	md, ok := metadata.FromIncomingContext(ctx)
 	if !ok {
-		return "", status.Errorf(codes.PermissionDenied, "metadata not found in context")
+		return auth.Identity{}, "", status.Errorf(codes.PermissionDenied, "metadata not found in context")
+	}
+
+	if a := md.Get("authorization"); len(a) == 1 && strings.HasPrefix(a[0], "Bearer ") {
+		token := strings.TrimPrefix(a[0], "Bearer ")
+
+		if bid, valid := auth.AuthenticateBearer(ctx, s.authenticator, s.rep, token); valid {
+			return bid, token, nil
+		}
+
+		return auth.Identity{}, "", status.Errorf(codes.PermissionDenied, "invalid bearer token")
 	}
 
 	if u, h, p := md.Get("kopia-username"), md.Get("kopia-hostname"), md.Get("kopia-password"); len(u) == 1 && len(p) == 1 && len(h) == 1 {
 		username := u[0] + "@" + h[0]
-		password := p[0]
+		password = p[0]
+
+		if !s.authenticator.IsValid(ctx, s.rep, username, password) {
+			return auth.Identity{}, "", status.Errorf(codes.PermissionDenied, "access denied for %v", username)
+		}
 
-		if s.authenticator.IsValid(ctx, s.rep, username, password) {
-			return username, nil
+		// when the password is a bearer token issued to the user, it also carries user groups and
+		// the canonical username, which replaces the provided one.
+		if bid, valid := auth.AuthenticateBearer(ctx, s.authenticator, s.rep, password); valid && strings.EqualFold(bid.Username, username) {
+			return bid, password, nil
 		}
 
-		return "", status.Errorf(codes.PermissionDenied, "access denied for %v", username)
+		return auth.Identity{Username: username}, password, nil
 	}
 
-	return "", status.Errorf(codes.PermissionDenied, "missing credentials")
+	return auth.Identity{}, "", status.Errorf(codes.PermissionDenied, "missing credentials")
 }
 
 // Session handles GRPC session from a repository client.
@@ -75,16 +114,21 @@ func (s *Server) Session(srv grpcapi.KopiaRepository_SessionServer) error {
 		return status.Errorf(codes.Unavailable, "not connected to a direct repository")
 	}
 
-	username, err := s.authenticateGRPCSession(ctx)
+	id, password, err := s.authenticateGRPCSession(ctx)
 	if err != nil {
 		return err
 	}
 
+	username := id.Username
+	ctx = auth.ContextWithGroups(ctx, id.Groups)
+
 	authz := s.authorizer.Authorize(ctx, dr, username)
 	if authz == nil {
 		authz = auth.NoAccess()
 	}
 
+	authz = auth.LimitAccess(ctx, s.authenticator, dr, username, password, authz)
+
 	p, ok := peer.FromContext(ctx)
 	if !ok {
 		return status.Errorf(codes.PermissionDenied, "peer not found in context")
@@ -123,7 +167,7 @@ func (s *Server) Session(srv grpcapi.KopiaRepository_SessionServer) error {
 			go func() {
 				defer s.grpcServerState.sem.Release(1)
 
-				resp := handleSessionRequest(ctx, dw, authz, req)
+				resp := s.handleSessionRequest(ctx, dw, authz, username, req)
 
 				if err := s.send(srv, req.RequestId, resp); err != nil {
 					select {
@@ -138,7 +182,7 @@ func (s *Server) Session(srv grpcapi.KopiaRepository_SessionServer) error {
 	})
 }
 
-func handleSessionRequest(ctx context.Context, dw repo.DirectRepositoryWriter, authz auth.AuthorizationInfo, req *grpcapi.SessionRequest) *grpcapi.SessionResponse {
+func (s *Server) handleSessionRequest(ctx context.Context, dw repo.DirectRepositoryWriter, authz auth.AuthorizationInfo, username string, req *grpcapi.SessionRequest) *grpcapi.SessionResponse {
 	switch inner := req.GetRequest().(type) {
 	case *grpcapi.SessionRequest_GetContentInfo:
 		return handleGetContentInfoRequest(ctx, dw, authz, inner.GetContentInfo)
@@ -147,7 +191,7 @@ func handleSessionRequest(ctx context.Context, dw repo.DirectRepositoryWriter, a
 		return handleGetContentRequest(ctx, dw, authz, inner.GetContent)
 
 	case *grpcapi.SessionRequest_WriteContent:
-		return handleWriteContentRequest(ctx, dw, authz, inner.WriteContent)
+		return s.handleWriteContentRequest(ctx, dw, authz, username, inner.WriteContent)
 
 	case *grpcapi.SessionRequest_Flush:
 		return handleFlushRequest(ctx, dw, authz, inner.Flush)
@@ -219,7 +263,7 @@ func handleGetContentRequest(ctx context.Context, dw repo.DirectRepositoryWriter
 	}
 }
 
-func handleWriteContentRequest(ctx context.Context, dw repo.DirectRepositoryWriter, authz auth.AuthorizationInfo, req *grpcapi.WriteContentRequest) *grpcapi.SessionResponse {
+func (s *Server) handleWriteContentRequest(ctx context.Context, dw repo.DirectRepositoryWriter, authz auth.AuthorizationInfo, username string, req *grpcapi.WriteContentRequest) *grpcapi.SessionResponse {
 	if authz.ContentAccessLevel() < auth.AccessLevelAppend {
 		return accessDeniedResponse()
 	}
@@ -229,7 +273,7 @@ func handleWriteContentRequest(ctx context.Context, dw repo.DirectRepositoryWrit
 		return accessDeniedResponse()
 	}
 
-	contentID, err := dw.ContentManager().WriteContent(ctx, req.GetData(), content.ID(req.GetPrefix()))
+	contentID, err := s.writeContentWithQuota(ctx, dw, username, req.GetData(), content.ID(req.GetPrefix()))
 	if err != nil {
 		return errorResponse(err)
 	}
@@ -371,6 +415,8 @@ func errorResponse(err error) *grpcapi.SessionResponse {
 		errorCode = grpcapi.ErrorResponse_MANIFEST_NOT_FOUND
 	case errors.Is(err, object.ErrObjectNotFound):
 		errorCode = grpcapi.ErrorResponse_OBJECT_NOT_FOUND
+	case errors.Is(err, repo.ErrQuotaExceeded):
+		errorCode = grpcapi.ErrorResponse_QUOTA_EXCEEDED
 	default:
 		errorCode = grpcapi.ErrorResponse_UNKNOWN_ERROR
 	}
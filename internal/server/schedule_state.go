@@ -0,0 +1,103 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/kopia/kopia/internal/atomicfile"
+	"github.com/kopia/kopia/snapshot"
+)
+
+// scheduleStateFileSuffix is appended to the name of the config file to get the name of the file
+// storing times when sources last ran scheduled snapshots, so that snapshots missed while
+// the server was not running can be caught up after restart.
+const scheduleStateFileSuffix = ".schedule.json"
+
+type scheduleState struct {
+	LastScheduledRun map[string]time.Time `json:"lastScheduledRun"`
+}
+
+func (s *Server) scheduleStateFile() string {
+	if s.options.ConfigFile == "" {
+		return ""
+	}
+
+	return s.options.ConfigFile + scheduleStateFileSuffix
+}
+
+func (s *Server) readScheduleStateLocked() (*scheduleState, error) {
+	st := &scheduleState{
+		LastScheduledRun: map[string]time.Time{},
+	}
+
+	b, err := ioutil.ReadFile(s.scheduleStateFile())
+	if os.IsNotExist(err) {
+		return st, nil
+	}
+
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read schedule state")
+	}
+
+	if err := json.Unmarshal(b, st); err != nil {
+		return nil, errors.Wrap(err, "invalid schedule state")
+	}
+
+	if st.LastScheduledRun == nil {
+		st.LastScheduledRun = map[string]time.Time{}
+	}
+
+	return st, nil
+}
+
+// lastScheduledRun returns the persisted time when the provided source last started a snapshot.
+func (s *Server) lastScheduledRun(ctx context.Context, src snapshot.SourceInfo) time.Time {
+	if s.scheduleStateFile() == "" {
+		return time.Time{}
+	}
+
+	s.scheduleStateMutex.Lock()
+	defer s.scheduleStateMutex.Unlock()
+
+	st, err := s.readScheduleStateLocked()
+	if err != nil {
+		log(ctx).Errorf("%v", err)
+		return time.Time{}
+	}
+
+	return st.LastScheduledRun[src.String()]
+}
+
+// setLastScheduledRun persists the time when the provided source last started a snapshot.
+func (s *Server) setLastScheduledRun(ctx context.Context, src snapshot.SourceInfo, t time.Time) {
+	if s.scheduleStateFile() == "" {
+		return
+	}
+
+	s.scheduleStateMutex.Lock()
+	defer s.scheduleStateMutex.Unlock()
+
+	st, err := s.readScheduleStateLocked()
+	if err != nil {
+		log(ctx).Errorf("%v", err)
+		return
+	}
+
+	st.LastScheduledRun[src.String()] = t.UTC()
+
+	b, err := json.Marshal(st)
+	if err != nil {
+		log(ctx).Errorf("unable to marshal schedule state: %v", err)
+		return
+	}
+
+	if err := atomicfile.Write(s.scheduleStateFile(), bytes.NewReader(b)); err != nil {
+		log(ctx).Errorf("unable to write schedule state: %v", err)
+	}
+}
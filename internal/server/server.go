@@ -3,10 +3,13 @@
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"strings"
 	"sync"
 	"time"
 
@@ -15,10 +18,15 @@
 	"github.com/gorilla/mux"
 	"github.com/pkg/errors"
 
+	"github.com/kopia/kopia/internal/apitoken"
 	"github.com/kopia/kopia/internal/auth"
 	"github.com/kopia/kopia/internal/clock"
+	"github.com/kopia/kopia/internal/quota"
 	"github.com/kopia/kopia/internal/serverapi"
 	"github.com/kopia/kopia/internal/uitask"
+	"github.com/kopia/kopia/internal/user"
+	"github.com/kopia/kopia/notification"
+	"github.com/kopia/kopia/notification/sender"
 	"github.com/kopia/kopia/repo"
 	"github.com/kopia/kopia/repo/logging"
 	"github.com/kopia/kopia/repo/maintenance"
@@ -31,12 +39,19 @@
 
 const (
 	maintenanceAttemptFrequency = 10 * time.Minute
+	minMaintenanceAttemptDelay  = 1 * time.Minute
 	kopiaAuthCookie             = "Kopia-Auth"
 	kopiaAuthCookieTTL          = 1 * time.Minute
 	kopiaAuthCookieAudience     = "kopia"
 	kopiaAuthCookieIssuer       = "kopia-server"
 )
 
+type contextKey string
+
+// identityContextKey is the request context key holding the identity of the user authenticated
+// with a bearer token or a client certificate.
+const identityContextKey contextKey = "identity"
+
 type apiRequestFunc func(ctx context.Context, r *http.Request, body []byte) (interface{}, *apiError)
 
 // Server exposes simple HTTP API for programmatically accessing Kopia features.
@@ -50,6 +65,9 @@ type Server struct {
 	authenticator auth.Authenticator
 	authorizer    auth.Authorizer
 
+	// enforces storage quotas of users writing contents through the server.
+	quota quota.Tracker
+
 	// all API requests run with shared lock on this mutex
 	// administrative actions run with an exclusive lock and block API calls.
 	mu              sync.RWMutex
@@ -57,6 +75,8 @@ type Server struct {
 	mounts          sync.Map // object.ID -> mount.Controller
 	uploadSemaphore chan struct{}
 
+	scheduleStateMutex sync.Mutex
+
 	taskmgr *uitask.Manager
 
 	authCookieSigningKey []byte
@@ -76,6 +96,10 @@ func (s *Server) APIHandlers(legacyAPI bool) http.Handler {
 
 	// snapshots
 	m.HandleFunc("/api/v1/snapshots", s.handleAPI(requireUIUser, s.handleSnapshotList)).Methods(http.MethodGet)
+	m.HandleFunc("/api/v1/snapshots/pin", s.handleAPI(handlerWillCheckAuthorization, s.handleSnapshotPin)).Methods(http.MethodPost)
+	m.HandleFunc("/api/v1/snapshots/edit", s.handleAPI(handlerWillCheckAuthorization, s.handleSnapshotEdit)).Methods(http.MethodPost)
+	m.HandleFunc("/api/v1/snapshots/delete", s.handleAPI(handlerWillCheckAuthorization, s.handleSnapshotDelete)).Methods(http.MethodPost)
+	m.HandleFunc("/api/v1/snapshots/expire", s.handleAPI(handlerWillCheckAuthorization, s.handleSnapshotExpire)).Methods(http.MethodPost)
 
 	m.HandleFunc("/api/v1/policy", s.handleAPI(requireUIUser, s.handlePolicyGet)).Methods(http.MethodGet)
 	m.HandleFunc("/api/v1/policy", s.handleAPI(requireUIUser, s.handlePolicyPut)).Methods(http.MethodPut)
@@ -99,6 +123,8 @@ func (s *Server) APIHandlers(legacyAPI bool) http.Handler {
 	m.HandleFunc("/api/v1/repo/exists", s.handleAPIPossiblyNotConnected(requireUIUser, s.handleRepoExists)).Methods(http.MethodPost)
 	m.HandleFunc("/api/v1/repo/create", s.handleAPIPossiblyNotConnected(requireUIUser, s.handleRepoCreate)).Methods(http.MethodPost)
 	m.HandleFunc("/api/v1/repo/description", s.handleAPI(requireUIUser, s.handleRepoSetDescription)).Methods(http.MethodPost)
+	m.HandleFunc("/api/v1/repo/throttle", s.handleAPI(requireUIUser, s.handleRepoGetThrottle)).Methods(http.MethodGet)
+	m.HandleFunc("/api/v1/repo/throttle", s.handleAPI(requireUIUser, s.handleRepoSetThrottle)).Methods(http.MethodPut)
 
 	m.HandleFunc("/api/v1/repo/disconnect", s.handleAPI(requireUIUser, s.handleRepoDisconnect)).Methods(http.MethodPost)
 	m.HandleFunc("/api/v1/repo/algorithms", s.handleAPIPossiblyNotConnected(requireUIUser, s.handleRepoSupportedAlgorithms)).Methods(http.MethodGet)
@@ -116,6 +142,10 @@ func (s *Server) APIHandlers(legacyAPI bool) http.Handler {
 		m.HandleFunc("/api/v1/manifests", s.handleAPI(handlerWillCheckAuthorization, s.handleManifestList)).Methods(http.MethodGet)
 	}
 
+	m.HandleFunc("/api/v1/maintenance", s.handleAPI(requireUIUser, s.handleMaintenanceInfo)).Methods(http.MethodGet)
+	m.HandleFunc("/api/v1/maintenance/params", s.handleAPI(requireUIUser, s.handleMaintenanceSetParams)).Methods(http.MethodPut)
+	m.HandleFunc("/api/v1/maintenance/run", s.handleAPI(requireUIUser, s.handleMaintenanceRun)).Methods(http.MethodPost)
+
 	m.HandleFunc("/api/v1/mounts", s.handleAPI(requireUIUser, s.handleMountCreate)).Methods(http.MethodPost)
 	m.HandleFunc("/api/v1/mounts/{rootObjectID}", s.handleAPI(requireUIUser, s.handleMountDelete)).Methods(http.MethodDelete)
 	m.HandleFunc("/api/v1/mounts/{rootObjectID}", s.handleAPI(requireUIUser, s.handleMountGet)).Methods(http.MethodGet)
@@ -132,9 +162,28 @@ func (s *Server) APIHandlers(legacyAPI bool) http.Handler {
 	return m
 }
 
-func (s *Server) isAuthenticated(w http.ResponseWriter, r *http.Request) bool {
+// authenticate verifies credentials presented with the request and returns the request
+// with the context carrying the identity of the authenticated user.
+func (s *Server) authenticate(w http.ResponseWriter, r *http.Request) (*http.Request, bool) {
 	if s.authenticator == nil {
-		return true
+		return r, true
+	}
+
+	// client certificate verified during TLS handshake identifies the user without further credentials.
+	if id, ok := s.clientCertificateIdentity(r.Context(), r.TLS); ok {
+		return withIdentity(r, id, ""), true
+	}
+
+	if token, ok := bearerToken(r); ok {
+		id, valid := auth.AuthenticateBearer(r.Context(), s.authenticator, s.rep, token)
+		if !valid {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="Kopia"`)
+			http.Error(w, "Access denied.\n", http.StatusUnauthorized)
+
+			return nil, false
+		}
+
+		return withIdentity(r, id, token), true
 	}
 
 	username, password, ok := r.BasicAuth()
@@ -142,14 +191,19 @@ func (s *Server) isAuthenticated(w http.ResponseWriter, r *http.Request) bool {
 		w.Header().Set("WWW-Authenticate", `Basic realm="Kopia"`)
 		http.Error(w, "Missing credentials.\n", http.StatusUnauthorized)
 
-		return false
+		return nil, false
 	}
 
-	if c, err := r.Cookie(kopiaAuthCookie); err == nil && c != nil {
+	// API tokens may grant limited access, which is applied based on the password presented with each request,
+	// while the cookie is only bound to the username and would let any password through with full access,
+	// so the cookie is neither trusted nor issued for them.
+	_, _, isAPIToken := apitoken.Parse(password)
+
+	if c, err := r.Cookie(kopiaAuthCookie); err == nil && c != nil && !isAPIToken {
 		if s.isAuthCookieValid(username, c.Value) {
 			// found a short-term JWT cookie that matches given username, trust it.
 			// this avoids potentially expensive password hashing inside the authenticator.
-			return true
+			return s.withPasswordGroups(r, username, password), true
 		}
 	}
 
@@ -157,7 +211,11 @@ func (s *Server) isAuthenticated(w http.ResponseWriter, r *http.Request) bool {
 		w.Header().Set("WWW-Authenticate", `Basic realm="Kopia"`)
 		http.Error(w, "Access denied.\n", http.StatusUnauthorized)
 
-		return false
+		return nil, false
+	}
+
+	if isAPIToken {
+		return s.withPasswordGroups(r, username, password), true
 	}
 
 	now := clock.Now()
@@ -173,7 +231,74 @@ func (s *Server) isAuthenticated(w http.ResponseWriter, r *http.Request) bool {
 		})
 	}
 
-	return true
+	return s.withPasswordGroups(r, username, password), true
+}
+
+// clientCertificateIdentity returns the identity of the user from the client certificate verified during TLS handshake,
+// provided the user profile still exists in the repository. This allows access of individual users to be revoked
+// by deleting their profiles, without having to rotate the CA.
+func (s *Server) clientCertificateIdentity(ctx context.Context, cs *tls.ConnectionState) (auth.Identity, bool) {
+	id, ok := auth.VerifiedClientIdentity(cs)
+	if !ok {
+		return auth.Identity{}, false
+	}
+
+	if _, err := user.GetUserProfile(ctx, s.rep, id.Username); err != nil {
+		log(ctx).Debugf("not accepting client certificate of %v: %v", id.Username, err)
+		return auth.Identity{}, false
+	}
+
+	return id, true
+}
+
+// authenticatedIdentity is the identity of the user authenticated with a bearer token (possibly presented
+// as the password) or a client certificate.
+type authenticatedIdentity struct {
+	auth.Identity
+	credential string // bearer token, empty for client certificates
+}
+
+// withIdentity returns the request with the context carrying the identity of the authenticated user.
+func withIdentity(r *http.Request, id auth.Identity, credential string) *http.Request {
+	ctx := context.WithValue(r.Context(), identityContextKey, authenticatedIdentity{id, credential})
+
+	return r.WithContext(auth.ContextWithGroups(ctx, id.Groups))
+}
+
+// withPasswordGroups attaches the identity of the user to the request context when the password
+// is a bearer token (such as JWT) issued to the user. The identity carries groups of the user and
+// replaces the username presented with the request, which may differ from the canonical one in case.
+func (s *Server) withPasswordGroups(r *http.Request, username, password string) *http.Request {
+	id, ok := auth.AuthenticateBearer(r.Context(), s.authenticator, s.rep, password)
+	if !ok || !strings.EqualFold(id.Username, username) {
+		return r
+	}
+
+	return withIdentity(r, id, password)
+}
+
+// bearerToken returns the token from 'Authorization: Bearer' header.
+func bearerToken(r *http.Request) (string, bool) {
+	const prefix = "Bearer "
+
+	h := r.Header.Get("Authorization")
+	if len(h) <= len(prefix) || !strings.EqualFold(h[0:len(prefix)], prefix) {
+		return "", false
+	}
+
+	return h[len(prefix):], true
+}
+
+// requestCredentials returns the username and password (or bearer token) of the authenticated user.
+// The password is empty for users authenticated with client certificates.
+func requestCredentials(r *http.Request) (username, password string) {
+	if id, ok := r.Context().Value(identityContextKey).(authenticatedIdentity); ok {
+		return id.Username, id.credential
+	}
+
+	username, password, _ = r.BasicAuth()
+
+	return username, password
 }
 
 func (s *Server) isAuthCookieValid(username, cookieValue string) bool {
@@ -206,7 +331,8 @@ func (s *Server) generateShortTermAuthCookie(username string, now time.Time) (st
 
 func (s *Server) requireAuth(f http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		if !s.isAuthenticated(w, r) {
+		r, ok := s.authenticate(w, r)
+		if !ok {
 			return
 		}
 
@@ -216,14 +342,14 @@ func (s *Server) requireAuth(f http.HandlerFunc) http.HandlerFunc {
 
 func (s *Server) httpAuthorizationInfo(r *http.Request) auth.AuthorizationInfo {
 	// authentication already done
-	userAtHost, _, _ := r.BasicAuth()
+	userAtHost, password := requestCredentials(r)
 
 	authz := s.authorizer.Authorize(r.Context(), s.rep, userAtHost)
 	if authz == nil {
 		authz = auth.NoAccess()
 	}
 
-	return authz
+	return auth.LimitAccess(r.Context(), s.authenticator, s.rep, userAtHost, password, authz)
 }
 
 type isAuthorizedFunc func(s *Server, r *http.Request) bool
@@ -432,6 +558,10 @@ func (s *Server) SetRepository(ctx context.Context, rep repo.Repository) error {
 		s.stopAllSourceManagersLocked(ctx)
 		log(ctx).Infof("stopped all source managers")
 
+		if err := s.flushQuotaUsage(ctx, s.rep); err != nil {
+			log(ctx).Errorf("unable to persist quota usage: %v", err)
+		}
+
 		if err := s.rep.Close(ctx); err != nil {
 			return errors.Wrap(err, "unable to close previous repository")
 		}
@@ -456,6 +586,13 @@ func (s *Server) SetRepository(ctx context.Context, rep repo.Repository) error {
 		return err
 	}
 
+	if err := s.quota.Load(ctx, rep); err != nil {
+		s.stopAllSourceManagersLocked(ctx)
+		s.rep = nil
+
+		return errors.Wrap(err, "unable to load quotas")
+	}
+
 	ctx, s.cancelRep = context.WithCancel(ctx)
 	go s.refreshPeriodically(ctx, rep)
 	go s.periodicMaintenance(ctx, rep)
@@ -464,6 +601,9 @@ func (s *Server) SetRepository(ctx context.Context, rep repo.Repository) error {
 }
 
 func (s *Server) refreshPeriodically(ctx context.Context, r repo.Repository) {
+	// only notify about the first of consecutive refresh failures.
+	var refreshFailing bool
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -472,22 +612,42 @@ func (s *Server) refreshPeriodically(ctx context.Context, r repo.Repository) {
 		case <-time.After(s.options.RefreshInterval):
 			if err := r.Refresh(ctx); err != nil {
 				log(ctx).Errorf("error refreshing repository: %v", err)
+
+				if !refreshFailing {
+					notification.Send(ctx, r, notification.NewMessage(r, notification.EventStorage, sender.SeverityError,
+						"Unable to refresh repository",
+						fmt.Sprintf("Repository: %v\nError: %v\n", r.ClientOptions().Description, err)))
+				}
+
+				refreshFailing = true
+			} else {
+				refreshFailing = false
 			}
 
 			if err := s.SyncSources(ctx); err != nil {
 				log(ctx).Errorf("unable to sync sources: %v", err)
 			}
+
+			if err := s.refreshQuotas(ctx, r); err != nil {
+				log(ctx).Errorf("unable to refresh quotas: %v", err)
+			}
 		}
 	}
 }
 
 func (s *Server) periodicMaintenance(ctx context.Context, rep repo.Repository) {
+	if s.options.MaintenanceOwner {
+		if err := claimMaintenanceOwnership(ctx, rep); err != nil {
+			log(ctx).Errorf("unable to take over maintenance ownership: %v", err)
+		}
+	}
+
 	for {
 		select {
 		case <-ctx.Done():
 			return
 
-		case <-time.After(maintenanceAttemptFrequency):
+		case <-time.After(nextMaintenanceAttemptDelay(ctx, rep)):
 			if owned, err := maintenance.IsOwnedByThisUser(ctx, rep); err == nil && !owned {
 				// maintenance not owned by this user, don't run, but keep trying because
 				// maintenance ownership MAY change to this user in the future.
@@ -495,7 +655,7 @@ func (s *Server) periodicMaintenance(ctx context.Context, rep repo.Repository) {
 			}
 
 			if err := s.taskmgr.Run(ctx, "Maintenance", "Periodic maintenance", func(ctx context.Context, _ uitask.Controller) error {
-				return periodicMaintenanceOnce(ctx, rep)
+				return runMaintenance(ctx, rep, maintenance.ModeAuto, false)
 			}); err != nil {
 				log(ctx).Errorf("unable to run maintenance: %v", err)
 			}
@@ -503,16 +663,82 @@ func (s *Server) periodicMaintenance(ctx context.Context, rep repo.Repository) {
 	}
 }
 
-func periodicMaintenanceOnce(ctx context.Context, rep repo.Repository) error {
+// nextMaintenanceAttemptDelay returns the time until the next scheduled maintenance, bounded by
+// minMaintenanceAttemptDelay and maintenanceAttemptFrequency.
+func nextMaintenanceAttemptDelay(ctx context.Context, rep repo.Repository) time.Duration {
+	dr, ok := rep.(repo.DirectRepository)
+	if !ok {
+		return maintenanceAttemptFrequency
+	}
+
+	p, err := maintenance.GetParams(ctx, dr)
+	if err != nil {
+		return maintenanceAttemptFrequency
+	}
+
+	sched, err := maintenance.GetSchedule(ctx, dr)
+	if err != nil {
+		return maintenanceAttemptFrequency
+	}
+
+	delay := maintenanceAttemptFrequency
+
+	for _, c := range []struct {
+		enabled bool
+		next    time.Time
+	}{
+		{p.QuickCycle.Enabled, sched.NextQuickMaintenanceTime},
+		{p.FullCycle.Enabled, sched.NextFullMaintenanceTime},
+	} {
+		if d := clock.Until(c.next); c.enabled && d < delay {
+			delay = d
+		}
+	}
+
+	if delay < minMaintenanceAttemptDelay {
+		delay = minMaintenanceAttemptDelay
+	}
+
+	return delay
+}
+
+// claimMaintenanceOwnership makes the user the server is connected as the owner of repository maintenance.
+func claimMaintenanceOwnership(ctx context.Context, rep repo.Repository) error {
+	owned, err := maintenance.IsOwnedByThisUser(ctx, rep)
+	if err != nil {
+		return errors.Wrap(err, "unable to determine maintenance owner")
+	}
+
+	if owned {
+		return nil
+	}
+
+	return repo.WriteSession(ctx, rep, repo.WriteSessionOptions{
+		Purpose: "claimMaintenanceOwnership",
+	}, func(w repo.RepositoryWriter) error {
+		p, err := maintenance.GetParams(ctx, w)
+		if err != nil {
+			return errors.Wrap(err, "unable to get maintenance params")
+		}
+
+		log(ctx).Infof("taking over maintenance ownership from %q, maintenance will be performed by %v", p.Owner, w.ClientOptions().UsernameAtHost())
+
+		p.Owner = w.ClientOptions().UsernameAtHost()
+
+		return maintenance.SetParams(ctx, w, p)
+	})
+}
+
+func runMaintenance(ctx context.Context, rep repo.Repository, mode maintenance.Mode, force bool) error {
 	dr, ok := rep.(repo.DirectRepository)
 	if !ok {
 		return errors.Errorf("not a direct repository")
 	}
 
 	return repo.DirectWriteSession(ctx, dr, repo.WriteSessionOptions{
-		Purpose: "periodicMaintenanceOnce",
+		Purpose: "runMaintenance",
 	}, func(w repo.DirectRepositoryWriter) error {
-		return snapshotmaintenance.Run(ctx, w, maintenance.ModeAuto, false, maintenance.SafetyFull)
+		return snapshotmaintenance.Run(ctx, w, mode, force, maintenance.SafetyFull)
 	})
 }
 
@@ -612,6 +838,7 @@ type Options struct {
 	Authorizer           auth.Authorizer
 	AuthCookieSigningKey string
 	UIUser               string // name of the user allowed to access the UI
+	MaintenanceOwner     bool   // take over maintenance ownership and run it on the configured intervals
 }
 
 // New creates a Server.
@@ -0,0 +1,76 @@
+package server_test
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kopia/kopia/internal/acl"
+	"github.com/kopia/kopia/internal/apitoken"
+	"github.com/kopia/kopia/internal/auth"
+	"github.com/kopia/kopia/internal/repotesting"
+	"github.com/kopia/kopia/internal/testlogging"
+	"github.com/kopia/kopia/internal/tlsutil"
+	"github.com/kopia/kopia/internal/user"
+)
+
+func TestServer_APITokenLoginDoesNotGrantFullAccess(t *testing.T) {
+	ctx := testlogging.Context(t)
+	_, env := repotesting.NewEnvironment(t)
+
+	username := testUsername + "@" + testHostname
+
+	p := &user.Profile{Username: username}
+	require.NoError(t, p.SetPassword(testPassword))
+	require.NoError(t, user.SetUserProfile(ctx, env.RepositoryWriter, p))
+
+	_, readToken, err := apitoken.Create(ctx, env.RepositoryWriter, username, "read-only", time.Hour, acl.AccessLevelRead)
+	require.NoError(t, err)
+	require.NoError(t, env.RepositoryWriter.Flush(ctx))
+	require.NoError(t, env.Repository.Refresh(ctx))
+
+	si := startServerForEnvironment(ctx, t, env, auth.CombineAuthenticators(
+		auth.AuthenticateRepositoryUsers(),
+		auth.AuthenticateRepositoryTokens(),
+	), nil)
+
+	cli := &http.Client{
+		Transport: tlsutil.TransportTrustingSingleCertificate(si.TrustedServerCertificateFingerprint),
+	}
+
+	do := func(method, urlSuffix, password string, cookies []*http.Cookie) *http.Response {
+		t.Helper()
+
+		req, err := http.NewRequestWithContext(ctx, method, si.BaseURL+"/api/v1/"+urlSuffix, bytes.NewReader([]byte("content")))
+		require.NoError(t, err)
+
+		req.SetBasicAuth(username, password)
+
+		for _, c := range cookies {
+			req.AddCookie(c)
+		}
+
+		resp, err := cli.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		return resp
+	}
+
+	resp := do(http.MethodGet, "repo/parameters", readToken, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	for _, c := range resp.Cookies() {
+		require.NotEqual(t, "Kopia-Auth", c.Name, "auth cookie issued for API token login")
+	}
+
+	// the token only grants read access.
+	require.Equal(t, http.StatusForbidden, do(http.MethodPut, "contents/abcdef0123456789", readToken, nil).StatusCode)
+
+	// the cookie, if any, must not be usable with a different password to escape limits of the token.
+	require.Equal(t, http.StatusUnauthorized, do(http.MethodPut, "contents/abcdef0123456789", "wrong-password", resp.Cookies()).StatusCode)
+	require.Equal(t, http.StatusUnauthorized, do(http.MethodGet, "repo/parameters", "wrong-password", resp.Cookies()).StatusCode)
+}
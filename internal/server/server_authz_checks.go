@@ -11,7 +11,7 @@ func requireUIUser(s *Server, r *http.Request) bool {
 		return true
 	}
 
-	user, _, _ := r.BasicAuth()
+	user, _ := requestCredentials(r)
 
 	return user == s.options.UIUser
 }
@@ -34,6 +34,12 @@ func hasManifestAccess(s *Server, r *http.Request, labels map[string]string, lev
 	return s.httpAuthorizationInfo(r).ManifestAccessLevel(labels) >= level
 }
 
+// canManageSnapshots determines whether the user can modify or delete snapshot manifests with the provided labels.
+// UI user can manage all snapshots, other users must have full access to the manifests.
+func canManageSnapshots(s *Server, r *http.Request, labels map[string]string) bool {
+	return requireUIUser(s, r) || hasManifestAccess(s, r, labels, auth.AccessLevelFull)
+}
+
 var (
 	_ isAuthorizedFunc = requireUIUser
 	_ isAuthorizedFunc = anyAuthenticatedUser
@@ -0,0 +1,137 @@
+package server_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kopia/kopia/internal/auth"
+	"github.com/kopia/kopia/internal/testlogging"
+	"github.com/kopia/kopia/internal/testutil"
+	"github.com/kopia/kopia/internal/tlsutil"
+	"github.com/kopia/kopia/repo"
+	"github.com/kopia/kopia/repo/content"
+	"github.com/kopia/kopia/repo/manifest"
+	"github.com/kopia/kopia/snapshot"
+)
+
+func TestServer_JWTAuthentication(t *testing.T) {
+	ctx := testlogging.Context(t)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	jwks, err := json.Marshal(map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "key1",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}},
+	})
+	require.NoError(t, err)
+
+	jwksFile := filepath.Join(testutil.TempDirectory(t), "jwks.json")
+	require.NoError(t, ioutil.WriteFile(jwksFile, jwks, 0o600))
+
+	a, err := auth.AuthenticateJWT(ctx, auth.JWTOptions{
+		JWKSFile:        jwksFile,
+		DefaultHostname: testHostname,
+	})
+	require.NoError(t, err)
+
+	tok := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"sub": testUsername,
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+	tok.Header["kid"] = "key1"
+
+	token, err := tok.SignedString(key)
+	require.NoError(t, err)
+
+	// JWT can be used in place of the password by both REST and GRPC clients.
+	for _, disableGRPC := range []bool{true, false} {
+		si := startServerWithAuthenticator(ctx, t, a, nil)
+		si.DisableGRPC = disableGRPC
+
+		rep, err := repo.OpenAPIServer(ctx, si, repo.ClientOptions{
+			Username: testUsername,
+			Hostname: testHostname,
+		}, &content.CachingOptions{
+			CacheDirectory:    testutil.TempDirectory(t),
+			MaxCacheSizeBytes: maxCacheSizeBytes,
+		}, token)
+		require.NoError(t, err)
+
+		remoteRepositoryTest(ctx, t, rep)
+		require.NoError(t, rep.Close(ctx))
+	}
+
+	// username presented with the JWT is replaced with the canonical one from the token,
+	// so that the user is authorized as such.
+	for _, disableGRPC := range []bool{true, false} {
+		si := startServerWithAuthenticator(ctx, t, a, nil)
+		si.DisableGRPC = disableGRPC
+
+		rep, err := repo.OpenAPIServer(ctx, si, repo.ClientOptions{
+			Username: strings.ToUpper(testUsername),
+			Hostname: testHostname,
+		}, &content.CachingOptions{
+			CacheDirectory:    testutil.TempDirectory(t),
+			MaxCacheSizeBytes: maxCacheSizeBytes,
+		}, token)
+		require.NoError(t, err)
+
+		require.NoError(t, repo.WriteSession(ctx, rep, repo.WriteSessionOptions{}, func(w repo.RepositoryWriter) error {
+			_, err := w.PutManifest(ctx, map[string]string{
+				manifest.TypeLabelKey:  snapshot.ManifestType,
+				snapshot.UsernameLabel: testUsername,
+				snapshot.HostnameLabel: testHostname,
+			}, map[string]string{})
+
+			return err
+		}))
+
+		require.NoError(t, rep.Close(ctx))
+	}
+
+	// JWT can be passed as a bearer token.
+	si := startServerWithAuthenticator(ctx, t, a, nil)
+
+	cli := &http.Client{
+		Transport: tlsutil.TransportTrustingSingleCertificate(si.TrustedServerCertificateFingerprint),
+	}
+
+	for _, tc := range []struct {
+		urlSuffix  string
+		token      string
+		wantStatus int
+	}{
+		{"repo/parameters", token, http.StatusOK},
+		{"repo/parameters", token + "x", http.StatusUnauthorized},
+		{"repo/parameters", testPassword, http.StatusUnauthorized},
+		{"mounts", token, http.StatusForbidden},
+	} {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, si.BaseURL+"/api/v1/"+tc.urlSuffix, nil)
+		require.NoError(t, err)
+
+		req.Header.Set("Authorization", "Bearer "+tc.token)
+
+		resp, err := cli.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		require.Equal(t, tc.wantStatus, resp.StatusCode, tc.urlSuffix)
+	}
+}
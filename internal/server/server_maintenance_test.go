@@ -0,0 +1,35 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kopia/kopia/internal/repotesting"
+	"github.com/kopia/kopia/repo/maintenance"
+)
+
+func TestClaimMaintenanceOwnership(t *testing.T) {
+	ctx, env := repotesting.NewEnvironment(t)
+
+	owned, err := maintenance.IsOwnedByThisUser(ctx, env.Repository)
+	require.NoError(t, err)
+	require.False(t, owned)
+
+	// no maintenance schedule yet, so the server attempts maintenance soon.
+	require.Equal(t, minMaintenanceAttemptDelay, nextMaintenanceAttemptDelay(ctx, env.Repository))
+
+	require.NoError(t, claimMaintenanceOwnership(ctx, env.Repository))
+
+	owned, err = maintenance.IsOwnedByThisUser(ctx, env.Repository)
+	require.NoError(t, err)
+	require.True(t, owned)
+
+	// claiming again is a no-op.
+	require.NoError(t, claimMaintenanceOwnership(ctx, env.Repository))
+
+	require.NoError(t, runMaintenance(ctx, env.Repository, maintenance.ModeFull, false))
+
+	// after maintenance has run, the next attempt is scheduled later.
+	require.Greater(t, int64(nextMaintenanceAttemptDelay(ctx, env.Repository)), int64(minMaintenanceAttemptDelay))
+}
@@ -0,0 +1,145 @@
+package server_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kopia/kopia/internal/auth"
+	"github.com/kopia/kopia/internal/repotesting"
+	"github.com/kopia/kopia/internal/testlogging"
+	"github.com/kopia/kopia/internal/testutil"
+	"github.com/kopia/kopia/internal/tlsutil"
+	"github.com/kopia/kopia/internal/user"
+	"github.com/kopia/kopia/repo"
+	"github.com/kopia/kopia/repo/content"
+)
+
+func TestServer_ClientCertificateAuthentication(t *testing.T) {
+	ctx := testlogging.Context(t)
+
+	caCert, caKey := mustCreateCertificate(t, &x509.Certificate{
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}, nil, nil)
+
+	// client certificate issued by the trusted CA.
+	clientCert, clientKey := mustCreateCertificate(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: testUsername + "@" + testHostname},
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, caCert, caKey)
+
+	// self-signed client certificate, which is not trusted.
+	untrustedCert, untrustedKey := mustCreateCertificate(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: testUsername + "@" + testHostname},
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, nil, nil)
+
+	dir := testutil.TempDirectory(t)
+
+	writeCertAndKey := func(name string, cert *x509.Certificate, key *rsa.PrivateKey) (certFile, keyFile string) {
+		certFile = filepath.Join(dir, name+".crt")
+		keyFile = filepath.Join(dir, name+".key")
+
+		require.NoError(t, tlsutil.WriteCertificateToFile(certFile, cert))
+		require.NoError(t, tlsutil.WritePrivateKeyToFile(keyFile, key))
+
+		return certFile, keyFile
+	}
+
+	clientCertFile, clientKeyFile := writeCertAndKey("client", clientCert, clientKey)
+	untrustedCertFile, untrustedKeyFile := writeCertAndKey("untrusted", untrustedCert, untrustedKey)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(caCert)
+
+	a := auth.AuthenticateSingleUser(testOtherUsername+"@"+testHostname, testPassword)
+
+	// both REST and GRPC clients can authenticate using client certificate without a password.
+	for _, disableGRPC := range []bool{true, false} {
+		_, env := repotesting.NewEnvironment(t)
+
+		// certificates are only accepted for users with profiles in the repository.
+		p := &user.Profile{Username: testUsername + "@" + testHostname}
+		require.NoError(t, p.SetPassword(testPassword))
+		require.NoError(t, user.SetUserProfile(ctx, env.RepositoryWriter, p))
+		require.NoError(t, env.RepositoryWriter.Flush(ctx))
+		require.NoError(t, env.Repository.Refresh(ctx))
+
+		si := startServerForEnvironment(ctx, t, env, a, pool)
+		si.DisableGRPC = disableGRPC
+		si.ClientCertificateFile = clientCertFile
+		si.ClientKeyFile = clientKeyFile
+
+		rep, err := repo.OpenAPIServer(ctx, si, repo.ClientOptions{
+			Username: testUsername,
+			Hostname: testHostname,
+		}, &content.CachingOptions{
+			CacheDirectory:    testutil.TempDirectory(t),
+			MaxCacheSizeBytes: maxCacheSizeBytes,
+		}, "")
+		require.NoError(t, err)
+
+		mustListSnapshotCount(ctx, t, rep, 0)
+		mustGetManifestNotFound(ctx, t, rep, "mnosuchmanifest")
+		require.NoError(t, rep.Close(ctx))
+
+		// untrusted certificate is rejected during TLS handshake.
+		si.ClientCertificateFile = untrustedCertFile
+		si.ClientKeyFile = untrustedKeyFile
+
+		_, err = repo.OpenAPIServer(ctx, si, repo.ClientOptions{
+			Username: testUsername,
+			Hostname: testHostname,
+		}, nil, "")
+		require.Error(t, err)
+
+		// certificate of a deleted user is no longer accepted.
+		require.NoError(t, user.DeleteUserProfile(ctx, env.RepositoryWriter, testUsername+"@"+testHostname))
+		require.NoError(t, env.RepositoryWriter.Flush(ctx))
+		require.NoError(t, env.Repository.Refresh(ctx))
+
+		si.ClientCertificateFile = clientCertFile
+		si.ClientKeyFile = clientKeyFile
+
+		_, err = repo.OpenAPIServer(ctx, si, repo.ClientOptions{
+			Username: testUsername,
+			Hostname: testHostname,
+		}, nil, "")
+		require.Error(t, err)
+	}
+}
+
+func mustCreateCertificate(t *testing.T, template, parent *x509.Certificate, parentKey *rsa.PrivateKey) (*x509.Certificate, *rsa.PrivateKey) {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	template.SerialNumber = big.NewInt(time.Now().UnixNano())
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return cert, key
+}
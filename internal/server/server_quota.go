@@ -0,0 +1,93 @@
+package server
+
+import (
+	"context"
+	"encoding/hex"
+
+	"github.com/pkg/errors"
+
+	"github.com/kopia/kopia/repo"
+	"github.com/kopia/kopia/repo/content"
+	"github.com/kopia/kopia/repo/hashing"
+)
+
+// writeContentWithQuota writes the content on behalf of the user, enforcing storage quotas of the user. It returns
+// an error wrapping repo.ErrQuotaExceeded if writing the content would exceed any of them.
+//
+// Consistent with quota.Recompute(), the packed length of each new content is charged to the user writing it
+// and contents that already exist in the repository or are being written concurrently are not charged again.
+func (s *Server) writeContentWithQuota(ctx context.Context, dw repo.DirectRepositoryWriter, usernameAtHostname string, data []byte, prefix content.ID) (content.ID, error) {
+	if !s.quota.HasLimits(usernameAtHostname) {
+		// nolint:wrapcheck
+		return dw.ContentManager().WriteContent(ctx, data, prefix, content.NoCompression)
+	}
+
+	f := dw.ContentReader().ContentFormat()
+
+	hf, err := hashing.CreateHashFunc(&f)
+	if err != nil {
+		return "", errors.Wrap(err, "unable to create hash function")
+	}
+
+	var hashOutput [128]byte
+
+	contentID := prefix + content.ID(hex.EncodeToString(hf(hashOutput[:0], data)))
+
+	if ci, err := dw.ContentReader().ContentInfo(ctx, contentID); err == nil && !ci.GetDeleted() {
+		// nolint:wrapcheck
+		return dw.ContentManager().WriteContent(ctx, data, prefix, content.NoCompression)
+	}
+
+	// the packed length is not known until the content is written, but it's never smaller than the data.
+	res, err := s.quota.Reserve(usernameAtHostname, string(contentID), int64(len(data)))
+	if err != nil {
+		// nolint:wrapcheck
+		return "", err
+	}
+
+	// the content may have been written by a concurrent request since it was checked above.
+	if ci, err := dw.ContentReader().ContentInfo(ctx, contentID); err == nil && !ci.GetDeleted() {
+		res.Cancel()
+
+		// nolint:wrapcheck
+		return dw.ContentManager().WriteContent(ctx, data, prefix, content.NoCompression)
+	}
+
+	contentID, err = dw.ContentManager().WriteContent(ctx, data, prefix, content.NoCompression)
+	if err != nil {
+		res.Cancel()
+
+		// nolint:wrapcheck
+		return "", err
+	}
+
+	ci, err := dw.ContentReader().ContentInfo(ctx, contentID)
+	if err != nil {
+		// keep the reservation as an estimate of the usage.
+		res.Commit(int64(len(data)))
+
+		return "", errors.Wrapf(err, "unable to get info of written content %v", contentID)
+	}
+
+	res.Commit(int64(ci.GetPackedLength()))
+
+	return contentID, nil
+}
+
+// refreshQuotas persists storage usage accounted since the last refresh and reloads quota limits and
+// usage, which may have been changed or recomputed by other clients.
+func (s *Server) refreshQuotas(ctx context.Context, rep repo.Repository) error {
+	if err := s.flushQuotaUsage(ctx, rep); err != nil {
+		return err
+	}
+
+	return errors.Wrap(s.quota.Load(ctx, rep), "unable to load quotas")
+}
+
+func (s *Server) flushQuotaUsage(ctx context.Context, rep repo.Repository) error {
+	return errors.Wrap(repo.WriteSession(ctx, rep, repo.WriteSessionOptions{
+		Purpose: "flushQuotaUsage",
+	}, func(w repo.RepositoryWriter) error {
+		return s.quota.Flush(ctx, w)
+	}), "unable to persist quota usage")
+}
@@ -0,0 +1,76 @@
+package server_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kopia/kopia/internal/auth"
+	"github.com/kopia/kopia/internal/mockfs"
+	"github.com/kopia/kopia/internal/quota"
+	"github.com/kopia/kopia/internal/repotesting"
+	"github.com/kopia/kopia/internal/testutil"
+	"github.com/kopia/kopia/repo"
+	"github.com/kopia/kopia/repo/content"
+	"github.com/kopia/kopia/snapshot"
+	"github.com/kopia/kopia/snapshot/policy"
+	"github.com/kopia/kopia/snapshot/snapshotfs"
+)
+
+func TestServer_Quota(t *testing.T) {
+	for _, disableGRPC := range []bool{true, false} {
+		ctx, env := repotesting.NewEnvironment(t)
+
+		require.NoError(t, quota.SetLimit(ctx, env.RepositoryWriter, &quota.Limit{
+			Subject:  testUsername + "@" + testHostname,
+			MaxBytes: 10000,
+		}))
+		require.NoError(t, env.RepositoryWriter.Flush(ctx))
+
+		si := startServerForEnvironment(ctx, t, env, auth.AuthenticateSingleUser(testUsername+"@"+testHostname, testPassword), nil)
+		si.DisableGRPC = disableGRPC
+
+		rep, err := repo.OpenAPIServer(ctx, si, repo.ClientOptions{
+			Username: testUsername,
+			Hostname: testHostname,
+		}, &content.CachingOptions{
+			CacheDirectory:    testutil.TempDirectory(t),
+			MaxCacheSizeBytes: maxCacheSizeBytes,
+		}, testPassword)
+		require.NoError(t, err)
+
+		sourceInfo := snapshot.SourceInfo{
+			UserName: testUsername,
+			Host:     testHostname,
+			Path:     testPathname,
+		}
+
+		dir := mockfs.NewDirectory()
+		dir.AddFile("small", bytes.Repeat([]byte{1}, 1000), 0o644)
+
+		upload := func() error {
+			return repo.WriteSession(ctx, rep, repo.WriteSessionOptions{
+				Purpose:  "test",
+				OnUpload: func(int64) {},
+			}, func(w repo.RepositoryWriter) error {
+				_, err := snapshotfs.NewUploader(w).Upload(ctx, dir, policy.BuildTree(nil, policy.DefaultPolicy), sourceInfo)
+				return err
+			})
+		}
+
+		// snapshot fits within the quota.
+		require.NoError(t, upload())
+
+		// re-uploading the same contents does not use any more storage.
+		require.NoError(t, upload())
+
+		dir.AddFile("large", bytes.Repeat([]byte{2}, 20000), 0o644)
+
+		err = upload()
+		require.True(t, errors.Is(err, repo.ErrQuotaExceeded), "unexpected error: %v", err)
+
+		require.NoError(t, rep.Close(ctx))
+	}
+}
@@ -3,6 +3,8 @@
 import (
 	"context"
 	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/hex"
 	"errors"
 	"io/ioutil"
@@ -28,10 +30,11 @@
 )
 
 const (
-	testUsername = "foo"
-	testHostname = "bar"
-	testPassword = "123"
-	testPathname = "/tmp/path"
+	testUsername      = "foo"
+	testOtherUsername = "baz"
+	testHostname      = "bar"
+	testPassword      = "123"
+	testPathname      = "/tmp/path"
 
 	testUIUsername = "ui-user"
 	testUIPassword = "123456"
@@ -41,15 +44,29 @@
 
 // nolint:thelper
 func startServer(ctx context.Context, t *testing.T) *repo.APIServerInfo {
+	return startServerWithAuthenticator(ctx, t, auth.CombineAuthenticators(
+		auth.AuthenticateSingleUser(testUsername+"@"+testHostname, testPassword),
+		auth.AuthenticateSingleUser(testOtherUsername+"@"+testHostname, testPassword),
+		auth.AuthenticateSingleUser(testUIUsername, testUIPassword),
+	), nil)
+}
+
+// startServerWithAuthenticator starts the server using the provided authenticator, which also accepts
+// TLS client certificates signed by the provided CAs, if any.
+// nolint:thelper
+func startServerWithAuthenticator(ctx context.Context, t *testing.T, authenticator auth.Authenticator, clientCAs *x509.CertPool) *repo.APIServerInfo {
 	_, env := repotesting.NewEnvironment(t)
 
+	return startServerForEnvironment(ctx, t, env, authenticator, clientCAs)
+}
+
+// startServerForEnvironment starts the server connected to the repository of the provided environment.
+// nolint:thelper
+func startServerForEnvironment(ctx context.Context, t *testing.T, env *repotesting.Environment, authenticator auth.Authenticator, clientCAs *x509.CertPool) *repo.APIServerInfo {
 	s, err := server.New(ctx, server.Options{
-		ConfigFile: env.ConfigFile(),
-		Authorizer: auth.LegacyAuthorizer(),
-		Authenticator: auth.CombineAuthenticators(
-			auth.AuthenticateSingleUser(testUsername+"@"+testHostname, testPassword),
-			auth.AuthenticateSingleUser(testUIUsername, testUIPassword),
-		),
+		ConfigFile:      env.ConfigFile(),
+		Authorizer:      auth.LegacyAuthorizer(),
+		Authenticator:   authenticator,
 		RefreshInterval: 1 * time.Minute,
 		UIUser:          testUIUsername,
 	})
@@ -65,6 +82,15 @@ func startServer(ctx context.Context, t *testing.T) *repo.APIServerInfo {
 
 	hs := httptest.NewUnstartedServer(s.GRPCRouterHandler(s.APIHandlers(true)))
 	hs.EnableHTTP2 = true
+
+	if clientCAs != nil {
+		hs.TLS = &tls.Config{
+			MinVersion: tls.VersionTLS12,
+			ClientCAs:  clientCAs,
+			ClientAuth: tls.VerifyClientCertIfGiven,
+		}
+	}
+
 	hs.StartTLS()
 
 	t.Cleanup(hs.Close)
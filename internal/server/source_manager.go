@@ -49,6 +49,8 @@ type sourceManager struct {
 	pol                                policy.SchedulingPolicy
 	state                              string
 	nextSnapshotTime                   *time.Time
+	missedSnapshotTime                 *time.Time
+	lastScheduledRun                   time.Time
 	lastSnapshot                       *snapshot.Manifest
 	lastCompleteSnapshot               *snapshot.Manifest
 	manifestsSinceLastCompleteSnapshot []*snapshot.Manifest
@@ -62,11 +64,13 @@ func (s *sourceManager) Status() *serverapi.SourceStatus {
 	defer s.mu.RUnlock()
 
 	st := &serverapi.SourceStatus{
-		Source:           s.src,
-		Status:           s.state,
-		NextSnapshotTime: s.nextSnapshotTime,
-		SchedulingPolicy: s.pol,
-		LastSnapshot:     s.lastSnapshot,
+		Source:             s.src,
+		Status:             s.state,
+		NextSnapshotTime:   s.nextSnapshotTime,
+		Overdue:            s.missedSnapshotTime != nil,
+		MissedSnapshotTime: s.missedSnapshotTime,
+		SchedulingPolicy:   s.pol,
+		LastSnapshot:       s.lastSnapshot,
 	}
 
 	if st.Status == "UPLOADING" {
@@ -112,6 +116,11 @@ func (s *sourceManager) run(ctx context.Context) {
 
 	if s.server.rep.ClientOptions().Hostname == s.src.Host && !s.server.rep.ClientOptions().ReadOnly {
 		log(ctx).Debugf("starting local source manager for %v", s.src)
+
+		s.mu.Lock()
+		s.lastScheduledRun = s.server.lastScheduledRun(ctx, s.src)
+		s.mu.Unlock()
+
 		s.runLocal(ctx)
 	} else {
 		log(ctx).Debugf("starting read-only source manager for %v", s.src)
@@ -226,6 +235,16 @@ func (s *sourceManager) waitUntilStopped(ctx context.Context) {
 func (s *sourceManager) snapshot(ctx context.Context) error {
 	s.setStatus("PENDING")
 
+	// remember when the snapshot was started, so that it's not considered missed after restart.
+	now := clock.Now()
+
+	s.mu.Lock()
+	s.lastScheduledRun = now
+	s.missedSnapshotTime = nil
+	s.mu.Unlock()
+
+	s.server.setLastScheduledRun(ctx, s.src, now)
+
 	s.server.beginUpload(ctx, s.src)
 	defer s.server.endUpload(ctx, s.src)
 
@@ -271,6 +290,10 @@ func (s *sourceManager) snapshotInternal(ctx context.Context, ctrl uitask.Contro
 
 		ctrl.OnCancel(u.Cancel)
 
+		u.BandwidthSchedule = func(now time.Time) (float64, time.Time) {
+			return s.uploadBandwidthAt(ctx, now)
+		}
+
 		policyTree, err := policy.TreeForSource(ctx, w, s.src)
 		if err != nil {
 			return errors.Wrap(err, "unable to create policy getter")
@@ -309,6 +332,43 @@ func (s *sourceManager) snapshotInternal(ctx context.Context, ctrl uitask.Contro
 	})
 }
 
+// uploadBandwidthAt returns the upload bandwidth in effect at the provided time according to the
+// current scheduling policy of the source, so that policy changes apply to uploads in progress.
+func (s *sourceManager) uploadBandwidthAt(ctx context.Context, now time.Time) (float64, time.Time) {
+	ctx, cancel := context.WithTimeout(ctx, refreshTimeout)
+	defer cancel()
+
+	pol, _, err := policy.GetEffectivePolicy(ctx, s.server.rep, s.src)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err == nil {
+		s.pol = pol.SchedulingPolicy
+	} else {
+		log(ctx).Errorf("unable to get effective policy for %v, using last known bandwidth schedule: %v", s.src, err)
+	}
+
+	return s.pol.UploadBandwidthAt(now)
+}
+
+// findMissedSnapshotTime returns the earliest time when a snapshot was scheduled using times of day or cron
+// expressions since the most recent snapshot, which did not run because the server was not running or
+// the machine was asleep.
+func (s *sourceManager) findMissedSnapshotTime() *time.Time {
+	lastRun := s.lastSnapshot.StartTime
+	if s.lastScheduledRun.After(lastRun) {
+		lastRun = s.lastScheduledRun
+	}
+
+	t, ok := s.pol.MissedScheduledTime(lastRun.Local(), clock.Now().Local())
+	if !ok {
+		return nil
+	}
+
+	return &t
+}
+
 func (s *sourceManager) findClosestNextSnapshotTime() *time.Time {
 	var nextSnapshotTime *time.Time
 
@@ -332,6 +392,13 @@ func (s *sourceManager) findClosestNextSnapshotTime() *time.Time {
 		}
 	}
 
+	// cron expressions are evaluated in local time.
+	if cronSnapshotTime, ok := s.pol.NextCronSnapshotTime(s.src.String(), clock.Now().Local()); ok {
+		if nextSnapshotTime == nil || cronSnapshotTime.Before(*nextSnapshotTime) {
+			nextSnapshotTime = &cronSnapshotTime
+		}
+	}
+
 	return nextSnapshotTime
 }
 
@@ -372,8 +439,17 @@ func (s *sourceManager) refreshStatus(ctx context.Context) {
 		}
 
 		s.nextSnapshotTime = s.findClosestNextSnapshotTime()
+		s.missedSnapshotTime = s.findMissedSnapshotTime()
+
+		if s.missedSnapshotTime != nil && s.pol.RunMissedOrDefault(false) {
+			log(ctx).Infof("catching up on snapshot of %v missed at %v", s.src, s.missedSnapshotTime)
+
+			nt := clock.Now()
+			s.nextSnapshotTime = &nt
+		}
 	} else {
 		s.nextSnapshotTime = nil
+		s.missedSnapshotTime = nil
 		s.lastSnapshot = nil
 	}
 }
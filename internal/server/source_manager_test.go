@@ -0,0 +1,65 @@
+package server
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kopia/kopia/internal/clock"
+	"github.com/kopia/kopia/internal/testlogging"
+	"github.com/kopia/kopia/internal/testutil"
+	"github.com/kopia/kopia/snapshot"
+	"github.com/kopia/kopia/snapshot/policy"
+)
+
+func TestScheduleStatePersistence(t *testing.T) {
+	ctx := testlogging.Context(t)
+
+	s := &Server{options: Options{ConfigFile: filepath.Join(testutil.TempDirectory(t), "repository.config")}}
+	src1 := snapshot.SourceInfo{UserName: "user", Host: "host", Path: "/path1"}
+	src2 := snapshot.SourceInfo{UserName: "user", Host: "host", Path: "/path2"}
+
+	require.True(t, s.lastScheduledRun(ctx, src1).IsZero())
+
+	t1 := time.Date(2021, 6, 7, 10, 0, 0, 0, time.UTC)
+	t2 := time.Date(2021, 6, 8, 10, 0, 0, 0, time.UTC)
+
+	s.setLastScheduledRun(ctx, src1, t1)
+	s.setLastScheduledRun(ctx, src2, t2)
+
+	// new server instance using the same config file sees the same state.
+	s2 := &Server{options: s.options}
+	require.Equal(t, t1, s2.lastScheduledRun(ctx, src1))
+	require.Equal(t, t2, s2.lastScheduledRun(ctx, src2))
+
+	// no config file - no persistence.
+	s3 := &Server{}
+	s3.setLastScheduledRun(ctx, src1, t1)
+	require.True(t, s3.lastScheduledRun(ctx, src1).IsZero())
+}
+
+func TestSourceManager_FindMissedSnapshotTime(t *testing.T) {
+	m := &sourceManager{
+		pol: policy.SchedulingPolicy{
+			Cron: []string{"* * * * *"},
+		},
+		lastSnapshot: &snapshot.Manifest{
+			StartTime: clock.Now().Add(-10 * time.Minute),
+		},
+	}
+
+	missed := m.findMissedSnapshotTime()
+	require.NotNil(t, missed)
+	require.True(t, missed.After(m.lastSnapshot.StartTime))
+
+	// snapshot started after the last one in the repository, but did not finish.
+	m.lastScheduledRun = clock.Now()
+	require.Nil(t, m.findMissedSnapshotTime())
+
+	m.lastScheduledRun = time.Time{}
+	m.pol.Cron = nil
+	m.pol.IntervalSeconds = 60
+	require.Nil(t, m.findMissedSnapshotTime())
+}
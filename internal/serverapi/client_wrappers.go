@@ -7,6 +7,9 @@
 	"github.com/pkg/errors"
 
 	"github.com/kopia/kopia/internal/apiclient"
+	"github.com/kopia/kopia/internal/uitask"
+	"github.com/kopia/kopia/repo/blob/throttling"
+	"github.com/kopia/kopia/repo/maintenance"
 	"github.com/kopia/kopia/repo/object"
 	"github.com/kopia/kopia/snapshot"
 )
@@ -71,6 +74,25 @@ func Status(ctx context.Context, c *apiclient.KopiaAPIClient) (*StatusResponse,
 	return resp, nil
 }
 
+// GetThrottlingLimits gets the throttling limits of the repository connected to the server.
+func GetThrottlingLimits(ctx context.Context, c *apiclient.KopiaAPIClient) (throttling.Limits, error) {
+	var resp throttling.Limits
+	if err := c.Get(ctx, "repo/throttle", nil, &resp); err != nil {
+		return throttling.Limits{}, errors.Wrap(err, "GetThrottlingLimits")
+	}
+
+	return resp, nil
+}
+
+// SetThrottlingLimits changes the throttling limits of the repository connected to the server.
+func SetThrottlingLimits(ctx context.Context, c *apiclient.KopiaAPIClient, limits throttling.Limits) error {
+	if err := c.Put(ctx, "repo/throttle", &limits, &throttling.Limits{}); err != nil {
+		return errors.Wrap(err, "SetThrottlingLimits")
+	}
+
+	return nil
+}
+
 // ListSources lists the snapshot sources managed by the server.
 func ListSources(ctx context.Context, c *apiclient.KopiaAPIClient, match *snapshot.SourceInfo) (*SourcesResponse, error) {
 	resp := &SourcesResponse{}
@@ -91,6 +113,45 @@ func ListSnapshots(ctx context.Context, c *apiclient.KopiaAPIClient, match *snap
 	return resp, nil
 }
 
+// PinSnapshots adds and removes named pins of the provided snapshots and returns the updated snapshots.
+func PinSnapshots(ctx context.Context, c *apiclient.KopiaAPIClient, req *PinSnapshotsRequest) (*SnapshotsResponse, error) {
+	resp := &SnapshotsResponse{}
+	if err := c.Post(ctx, "snapshots/pin", req, resp); err != nil {
+		return nil, errors.Wrap(err, "PinSnapshots")
+	}
+
+	return resp, nil
+}
+
+// DeleteSnapshots deletes the provided snapshot manifests.
+func DeleteSnapshots(ctx context.Context, c *apiclient.KopiaAPIClient, req *DeleteSnapshotsRequest) error {
+	if err := c.Post(ctx, "snapshots/delete", req, &Empty{}); err != nil {
+		return errors.Wrap(err, "DeleteSnapshots")
+	}
+
+	return nil
+}
+
+// EditSnapshots changes description and tags of the provided snapshots and returns the updated snapshots.
+func EditSnapshots(ctx context.Context, c *apiclient.KopiaAPIClient, req *EditSnapshotsRequest) (*SnapshotsResponse, error) {
+	resp := &SnapshotsResponse{}
+	if err := c.Post(ctx, "snapshots/edit", req, resp); err != nil {
+		return nil, errors.Wrap(err, "EditSnapshots")
+	}
+
+	return resp, nil
+}
+
+// ExpireSnapshots applies retention policy to snapshots of the provided source.
+func ExpireSnapshots(ctx context.Context, c *apiclient.KopiaAPIClient, req *ExpireSnapshotsRequest) (*ExpireSnapshotsResponse, error) {
+	resp := &ExpireSnapshotsResponse{}
+	if err := c.Post(ctx, "snapshots/expire", req, resp); err != nil {
+		return nil, errors.Wrap(err, "ExpireSnapshots")
+	}
+
+	return resp, nil
+}
+
 // ListPolicies lists the policies managed by the server for a given target filter.
 func ListPolicies(ctx context.Context, c *apiclient.KopiaAPIClient, match *snapshot.SourceInfo) (*PoliciesResponse, error) {
 	resp := &PoliciesResponse{}
@@ -101,6 +162,36 @@ func ListPolicies(ctx context.Context, c *apiclient.KopiaAPIClient, match *snaps
 	return resp, nil
 }
 
+// GetMaintenanceInfo returns maintenance parameters and schedule of the repository.
+func GetMaintenanceInfo(ctx context.Context, c *apiclient.KopiaAPIClient) (*MaintenanceInfo, error) {
+	resp := &MaintenanceInfo{}
+	if err := c.Get(ctx, "maintenance", nil, resp); err != nil {
+		return nil, errors.Wrap(err, "GetMaintenanceInfo")
+	}
+
+	return resp, nil
+}
+
+// SetMaintenanceParams changes maintenance parameters of the repository.
+func SetMaintenanceParams(ctx context.Context, c *apiclient.KopiaAPIClient, p *maintenance.Params) (*MaintenanceInfo, error) {
+	resp := &MaintenanceInfo{}
+	if err := c.Put(ctx, "maintenance/params", p, resp); err != nil {
+		return nil, errors.Wrap(err, "SetMaintenanceParams")
+	}
+
+	return resp, nil
+}
+
+// RunMaintenance starts repository maintenance and returns the task running it.
+func RunMaintenance(ctx context.Context, c *apiclient.KopiaAPIClient, req *RunMaintenanceRequest) (*uitask.Info, error) {
+	resp := &uitask.Info{}
+	if err := c.Post(ctx, "maintenance/run", req, resp); err != nil {
+		return nil, errors.Wrap(err, "RunMaintenance")
+	}
+
+	return resp, nil
+}
+
 // GetObject returns the object payload.
 func GetObject(ctx context.Context, c *apiclient.KopiaAPIClient, objectID string) ([]byte, error) {
 	var b []byte
@@ -123,7 +214,7 @@ func matchSourceParameters(match *snapshot.SourceInfo) string {
 	}
 
 	if v := match.UserName; v != "" {
-		clauses = append(clauses, "username="+v)
+		clauses = append(clauses, "userName="+v)
 	}
 
 	if v := match.Path; v != "" {
@@ -8,6 +8,7 @@
 	"github.com/kopia/kopia/internal/uitask"
 	"github.com/kopia/kopia/repo"
 	"github.com/kopia/kopia/repo/blob"
+	"github.com/kopia/kopia/repo/maintenance"
 	"github.com/kopia/kopia/repo/manifest"
 	"github.com/kopia/kopia/repo/object"
 	"github.com/kopia/kopia/snapshot"
@@ -43,13 +44,15 @@ type SourcesResponse struct {
 
 // SourceStatus describes the status of a single source.
 type SourceStatus struct {
-	Source           snapshot.SourceInfo        `json:"source"`
-	Status           string                     `json:"status"`
-	SchedulingPolicy policy.SchedulingPolicy    `json:"schedule"`
-	LastSnapshot     *snapshot.Manifest         `json:"lastSnapshot,omitempty"`
-	NextSnapshotTime *time.Time                 `json:"nextSnapshotTime,omitempty"`
-	UploadCounters   *snapshotfs.UploadCounters `json:"upload,omitempty"`
-	CurrentTask      string                     `json:"currentTask,omitempty"`
+	Source             snapshot.SourceInfo        `json:"source"`
+	Status             string                     `json:"status"`
+	SchedulingPolicy   policy.SchedulingPolicy    `json:"schedule"`
+	LastSnapshot       *snapshot.Manifest         `json:"lastSnapshot,omitempty"`
+	NextSnapshotTime   *time.Time                 `json:"nextSnapshotTime,omitempty"`
+	Overdue            bool                       `json:"overdue,omitempty"`            // a scheduled snapshot was missed
+	MissedSnapshotTime *time.Time                 `json:"missedSnapshotTime,omitempty"` // earliest missed snapshot time
+	UploadCounters     *snapshotfs.UploadCounters `json:"upload,omitempty"`
+	CurrentTask        string                     `json:"currentTask,omitempty"`
 }
 
 // PolicyListEntry describes single policy.
@@ -84,6 +87,7 @@ type Empty struct{
 	ErrorPathNotFound       APIErrorCode = "PATH_NOT_FOUND"
 	ErrorStorageConnection  APIErrorCode = "STORAGE_CONNECTION"
 	ErrorAccessDenied       APIErrorCode = "ACCESS_DENIED"
+	ErrorQuotaExceeded      APIErrorCode = "QUOTA_EXCEEDED"
 )
 
 // ErrorResponse represents error response.
@@ -151,6 +155,8 @@ type Snapshot struct {
 	ID               manifest.ID          `json:"id"`
 	Source           snapshot.SourceInfo  `json:"source"`
 	Description      string               `json:"description"`
+	Tags             map[string]string    `json:"tags,omitempty"`
+	Pins             []string             `json:"pins,omitempty"`
 	StartTime        time.Time            `json:"startTime"`
 	EndTime          time.Time            `json:"endTime"`
 	IncompleteReason string               `json:"incomplete,omitempty"`
@@ -164,6 +170,37 @@ type SnapshotsResponse struct {
 	Snapshots []*Snapshot `json:"snapshots"`
 }
 
+// PinSnapshotsRequest contains request to add or remove named pins of snapshots.
+type PinSnapshotsRequest struct {
+	Snapshots []manifest.ID `json:"snapshots"`
+	Add       []string      `json:"add,omitempty"`
+	Remove    []string      `json:"remove,omitempty"`
+}
+
+// DeleteSnapshotsRequest contains request to delete snapshot manifests.
+type DeleteSnapshotsRequest struct {
+	Snapshots []manifest.ID `json:"snapshots"`
+}
+
+// EditSnapshotsRequest contains request to change description and tags of snapshots.
+type EditSnapshotsRequest struct {
+	Snapshots      []manifest.ID     `json:"snapshots"`
+	NewDescription *string           `json:"description,omitempty"`
+	AddTags        map[string]string `json:"addTags,omitempty"`
+	RemoveTags     []string          `json:"removeTags,omitempty"`
+}
+
+// ExpireSnapshotsRequest contains request to apply retention policy to snapshots of a source.
+type ExpireSnapshotsRequest struct {
+	Source snapshot.SourceInfo `json:"source"`
+	DryRun bool                `json:"dryRun,omitempty"`
+}
+
+// ExpireSnapshotsResponse contains snapshots deleted (or in dry-run mode, to be deleted) by retention policy.
+type ExpireSnapshotsResponse struct {
+	Deleted []*Snapshot `json:"deleted"`
+}
+
 // MountSnapshotRequest contains request to mount a snapshot.
 type MountSnapshotRequest struct {
 	Root string `json:"root"`
@@ -191,6 +228,19 @@ type CurrentUserResponse struct {
 	Hostname string `json:"hostname"`
 }
 
+// MaintenanceInfo contains maintenance parameters and schedule of the repository.
+type MaintenanceInfo struct {
+	Params   maintenance.Params   `json:"params"`
+	Schedule maintenance.Schedule `json:"schedule"`
+	IsOwner  bool                 `json:"isOwner"`
+}
+
+// RunMaintenanceRequest contains request to start repository maintenance.
+type RunMaintenanceRequest struct {
+	Full  bool `json:"full"`
+	Force bool `json:"force,omitempty"`
+}
+
 // TaskListResponse contains a list of tasks.
 type TaskListResponse struct {
 	Tasks []uitask.Info `json:"tasks"`
@@ -11,6 +11,7 @@
 	"crypto/x509/pkix"
 	"encoding/hex"
 	"encoding/pem"
+	"io/ioutil"
 	"math/big"
 	"net"
 	"net/http"
@@ -126,12 +127,54 @@ func TLSConfigTrustingSingleCertificate(sha256Fingerprint string) *tls.Config {
 // TransportTrustingSingleCertificate return http.RoundTripper which trusts exactly one TLS certificate with
 // provided SHA256 fingerprint.
 func TransportTrustingSingleCertificate(sha256Fingerprint string) http.RoundTripper {
+	return TransportWithTLSConfig(TLSConfigTrustingSingleCertificate(sha256Fingerprint))
+}
+
+// TransportWithTLSConfig returns http.RoundTripper which uses the provided TLS configuration.
+func TransportWithTLSConfig(c *tls.Config) http.RoundTripper {
 	t2 := http.DefaultTransport.(*http.Transport).Clone()
-	t2.TLSClientConfig = TLSConfigTrustingSingleCertificate(sha256Fingerprint)
+	t2.TLSClientConfig = c
 
 	return t2
 }
 
+// ClientTLSConfig returns tls.Config for connecting to a server, which optionally trusts only a certificate
+// with provided SHA256 fingerprint and presents the client certificate loaded from the provided PEM files.
+func ClientTLSConfig(sha256Fingerprint, clientCertFile, clientKeyFile string) (*tls.Config, error) {
+	c := &tls.Config{}
+	if sha256Fingerprint != "" {
+		c = TLSConfigTrustingSingleCertificate(sha256Fingerprint)
+	}
+
+	if clientCertFile == "" && clientKeyFile == "" {
+		return c, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(clientCertFile, clientKeyFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to load client certificate")
+	}
+
+	c.Certificates = []tls.Certificate{cert}
+
+	return c, nil
+}
+
+// LoadCertPool loads PEM-encoded CA certificates from the provided file.
+func LoadCertPool(fname string) (*x509.CertPool, error) {
+	b, err := ioutil.ReadFile(fname) //nolint:gosec
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read CA certificates")
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(b) {
+		return nil, errors.Errorf("no valid certificates found in %v", fname)
+	}
+
+	return pool, nil
+}
+
 func verifyPeerCertificate(sha256Fingerprint string) func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error {
 	sha256Fingerprint = strings.ToLower(sha256Fingerprint)
 
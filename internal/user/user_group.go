@@ -0,0 +1,215 @@
+package user
+
+import (
+	"context"
+	"regexp"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/kopia/kopia/repo"
+	"github.com/kopia/kopia/repo/manifest"
+)
+
+// GroupManifestType is the type of the manifest used to represent user groups.
+const GroupManifestType = "usergroup"
+
+// GroupNameLabel is the manifest label identifying user groups by name.
+const GroupNameLabel = "group"
+
+// ErrGroupNotFound is returned to indicate that a group was not found in the system.
+var ErrGroupNotFound = errors.New("group not found")
+
+// Group is a named set of users, which can be referenced by ACL entries.
+type Group struct {
+	ManifestID manifest.ID `json:"-"`
+
+	Name    string   `json:"name"`
+	Members []string `json:"members"` // username@hostname
+}
+
+// HasMember returns true if the provided username@hostname is a member of the group.
+func (g *Group) HasMember(username string) bool {
+	for _, m := range g.Members {
+		if m == username {
+			return true
+		}
+	}
+
+	return false
+}
+
+// validGroupNameRegexp matches group names consisting of lowercase letters, digits or dashes,
+// underscores or period characters.
+var validGroupNameRegexp = regexp.MustCompile(`^[a-z0-9\-_.]+$`)
+
+// ValidateGroupName returns an error if the given group name is invalid.
+func ValidateGroupName(name string) error {
+	if name == "" {
+		return errors.Errorf("group name is required")
+	}
+
+	if !validGroupNameRegexp.MatchString(name) {
+		return errors.Errorf("group name must consist of lowercase letters, digits, dashes, underscores or periods")
+	}
+
+	return nil
+}
+
+// LoadGroupMap returns the map of all user groups in the repository by name, using old map as a cache.
+func LoadGroupMap(ctx context.Context, rep repo.Repository, old map[string]*Group) (map[string]*Group, error) {
+	if rep == nil {
+		return nil, nil
+	}
+
+	entries, err := rep.FindManifests(ctx, map[string]string{manifest.TypeLabelKey: GroupManifestType})
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing group manifests")
+	}
+
+	result := map[string]*Group{}
+
+	for _, m := range manifest.DedupeEntryMetadataByLabel(entries, GroupNameLabel) {
+		name := m.Labels[GroupNameLabel]
+
+		// same group as before
+		if o := old[name]; o != nil && o.ManifestID == m.ID {
+			result[name] = o
+			continue
+		}
+
+		g := &Group{}
+		if _, err := rep.GetManifest(ctx, m.ID, g); err != nil {
+			return nil, errors.Wrapf(err, "error loading group manifest %v", name)
+		}
+
+		g.ManifestID = m.ID
+
+		result[name] = g
+	}
+
+	return result, nil
+}
+
+// GroupsForUser returns sorted names of groups in the provided map that the user is a member of.
+func GroupsForUser(groups map[string]*Group, username string) []string {
+	var result []string
+
+	for name, g := range groups {
+		if g.HasMember(username) {
+			result = append(result, name)
+		}
+	}
+
+	sort.Strings(result)
+
+	return result
+}
+
+// ListGroups gets the list of all user groups in the system.
+func ListGroups(ctx context.Context, rep repo.Repository) ([]*Group, error) {
+	var result []*Group
+
+	groups, err := LoadGroupMap(ctx, rep, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, v := range groups {
+		result = append(result, v)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+
+	return result, nil
+}
+
+// GetGroup returns the user group with a given name.
+func GetGroup(ctx context.Context, r repo.Repository, name string) (*Group, error) {
+	manifests, err := r.FindManifests(ctx, map[string]string{
+		manifest.TypeLabelKey: GroupManifestType,
+		GroupNameLabel:        name,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "error looking for group")
+	}
+
+	if len(manifests) == 0 {
+		return nil, errors.Wrap(ErrGroupNotFound, name)
+	}
+
+	g := &Group{}
+
+	id := manifest.PickLatestID(manifests)
+	if _, err := r.GetManifest(ctx, id, g); err != nil {
+		return nil, errors.Wrap(err, "error loading group")
+	}
+
+	g.ManifestID = id
+
+	return g, nil
+}
+
+// SetGroup creates or updates user group.
+func SetGroup(ctx context.Context, w repo.RepositoryWriter, g *Group) error {
+	if err := ValidateGroupName(g.Name); err != nil {
+		return err
+	}
+
+	for _, m := range g.Members {
+		if err := ValidateUsername(m); err != nil {
+			return errors.Wrapf(err, "invalid member %q", m)
+		}
+	}
+
+	manifests, err := w.FindManifests(ctx, map[string]string{
+		manifest.TypeLabelKey: GroupManifestType,
+		GroupNameLabel:        g.Name,
+	})
+	if err != nil {
+		return errors.Wrap(err, "error looking for group")
+	}
+
+	id, err := w.PutManifest(ctx, map[string]string{
+		manifest.TypeLabelKey: GroupManifestType,
+		GroupNameLabel:        g.Name,
+	}, g)
+	if err != nil {
+		return errors.Wrap(err, "error writing group")
+	}
+
+	for _, m := range manifests {
+		if err := w.DeleteManifest(ctx, m.ID); err != nil {
+			return errors.Wrapf(err, "error deleting group %v", g.Name)
+		}
+	}
+
+	g.ManifestID = id
+
+	return nil
+}
+
+// DeleteGroup removes user group with a given name.
+func DeleteGroup(ctx context.Context, w repo.RepositoryWriter, name string) error {
+	manifests, err := w.FindManifests(ctx, map[string]string{
+		manifest.TypeLabelKey: GroupManifestType,
+		GroupNameLabel:        name,
+	})
+	if err != nil {
+		return errors.Wrap(err, "error looking for group")
+	}
+
+	if len(manifests) == 0 {
+		return errors.Wrap(ErrGroupNotFound, name)
+	}
+
+	for _, m := range manifests {
+		if err := w.DeleteManifest(ctx, m.ID); err != nil {
+			return errors.Wrapf(err, "error deleting group %v", name)
+		}
+	}
+
+	return nil
+}
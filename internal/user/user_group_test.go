@@ -0,0 +1,52 @@
+package user_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kopia/kopia/internal/repotesting"
+	"github.com/kopia/kopia/internal/user"
+)
+
+func TestGroups(t *testing.T) {
+	ctx, env := repotesting.NewEnvironment(t)
+
+	_, err := user.GetGroup(ctx, env.RepositoryWriter, "ops")
+	require.True(t, errors.Is(err, user.ErrGroupNotFound), "unexpected error: %v", err)
+
+	require.Error(t, user.SetGroup(ctx, env.RepositoryWriter, &user.Group{Name: "Ops"}))
+	require.Error(t, user.SetGroup(ctx, env.RepositoryWriter, &user.Group{Name: "ops", Members: []string{"alice"}}))
+
+	require.NoError(t, user.SetGroup(ctx, env.RepositoryWriter, &user.Group{Name: "ops", Members: []string{"alice@host1"}}))
+	require.NoError(t, user.SetGroup(ctx, env.RepositoryWriter, &user.Group{Name: "admins", Members: []string{"bob@host1"}}))
+
+	// replace group members
+	require.NoError(t, user.SetGroup(ctx, env.RepositoryWriter, &user.Group{Name: "ops", Members: []string{"alice@host1", "bob@host1"}}))
+
+	g, err := user.GetGroup(ctx, env.RepositoryWriter, "ops")
+	require.NoError(t, err)
+	require.Equal(t, []string{"alice@host1", "bob@host1"}, g.Members)
+	require.True(t, g.HasMember("bob@host1"))
+	require.False(t, g.HasMember("carol@host1"))
+
+	groups, err := user.ListGroups(ctx, env.RepositoryWriter)
+	require.NoError(t, err)
+	require.Len(t, groups, 2)
+	require.Equal(t, "admins", groups[0].Name)
+	require.Equal(t, "ops", groups[1].Name)
+
+	m, err := user.LoadGroupMap(ctx, env.RepositoryWriter, nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{"admins", "ops"}, user.GroupsForUser(m, "bob@host1"))
+	require.Equal(t, []string{"ops"}, user.GroupsForUser(m, "alice@host1"))
+	require.Empty(t, user.GroupsForUser(m, "carol@host1"))
+
+	require.NoError(t, user.DeleteGroup(ctx, env.RepositoryWriter, "ops"))
+	require.True(t, errors.Is(user.DeleteGroup(ctx, env.RepositoryWriter, "ops"), user.ErrGroupNotFound))
+
+	groups, err = user.ListGroups(ctx, env.RepositoryWriter)
+	require.NoError(t, err)
+	require.Len(t, groups, 1)
+}
@@ -0,0 +1,83 @@
+// Package notification sends notifications about snapshot, maintenance and storage events
+// using notification profiles configured in the repository.
+package notification
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/kopia/kopia/internal/clock"
+	"github.com/kopia/kopia/notification/notifyprofile"
+	"github.com/kopia/kopia/notification/sender"
+	"github.com/kopia/kopia/notification/sender/command"
+	"github.com/kopia/kopia/repo"
+	"github.com/kopia/kopia/repo/logging"
+
+	// register supported notification methods.
+	_ "github.com/kopia/kopia/notification/sender/email"
+	_ "github.com/kopia/kopia/notification/sender/webhook"
+)
+
+var log = logging.GetContextLoggerFunc("notification")
+
+// ErrCommandsNotEnabled is returned when sending to a notification profile that runs a local command
+// from a client that has not enabled notification commands.
+var ErrCommandsNotEnabled = errors.New("notification commands are not enabled for this repository connection")
+
+// Supported notification events.
+const (
+	EventSnapshot    = "snapshot"
+	EventMaintenance = "maintenance"
+	EventStorage     = "storage"
+	EventTest        = "test"
+)
+
+// NewMessage returns a new notification message originating from the provided repository client.
+func NewMessage(rep repo.Repository, event string, severity sender.Severity, subject, body string) *sender.Message {
+	return &sender.Message{
+		Event:    event,
+		Subject:  subject,
+		Body:     body,
+		Severity: severity,
+		Hostname: rep.ClientOptions().Hostname,
+		Time:     clock.Now(),
+	}
+}
+
+// Send sends the message to all notification profiles configured in the repository
+// whose minimum severity does not exceed the severity of the message.
+// Failures are logged and not returned, so that notifications never affect the reported operation.
+func Send(ctx context.Context, rep repo.Repository, msg *sender.Message) {
+	profiles, err := notifyprofile.ListProfiles(ctx, rep)
+	if err != nil {
+		log(ctx).Debugf("unable to list notification profiles: %v", err)
+		return
+	}
+
+	for _, pc := range profiles {
+		if msg.Severity < pc.MinSeverity {
+			continue
+		}
+
+		if err := SendToProfile(ctx, rep, pc, msg); err != nil {
+			log(ctx).Errorf("unable to send notification to profile %q: %v", pc.ProfileName, err)
+		}
+	}
+}
+
+// SendToProfile sends the message to the provided notification profile regardless of its minimum severity.
+// Because notification profiles are stored in the repository, profiles that run local commands are refused
+// unless the client has enabled notification commands in its ClientOptions.
+func SendToProfile(ctx context.Context, rep repo.Repository, pc *notifyprofile.Config, msg *sender.Message) error {
+	if pc.MethodConfig.Type == command.MethodCommand && !rep.ClientOptions().EnableNotificationCommands {
+		return ErrCommandsNotEnabled
+	}
+
+	s, err := sender.GetSender(ctx, pc.MethodConfig)
+	if err != nil {
+		return errors.Wrap(err, "unable to create notification sender")
+	}
+
+	return errors.Wrap(s.Send(ctx, msg), "unable to send notification")
+}
@@ -0,0 +1,124 @@
+package notification_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kopia/kopia/internal/repotesting"
+	"github.com/kopia/kopia/internal/testutil"
+	"github.com/kopia/kopia/notification"
+	"github.com/kopia/kopia/notification/notifyprofile"
+	"github.com/kopia/kopia/notification/sender"
+	"github.com/kopia/kopia/notification/sender/command"
+	"github.com/kopia/kopia/notification/sender/webhook"
+	"github.com/kopia/kopia/repo"
+)
+
+func TestSend(t *testing.T) {
+	ctx, env := repotesting.NewEnvironment(t)
+
+	var (
+		mu       sync.Mutex
+		received = map[string][]string{}
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg struct {
+			Subject string `json:"subject"`
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		received[r.URL.Path] = append(received[r.URL.Path], msg.Subject)
+	}))
+	defer srv.Close()
+
+	for _, p := range []struct {
+		name        string
+		minSeverity sender.Severity
+	}{
+		{"all", sender.SeverityVerbose},
+		{"warnings", sender.SeverityWarning},
+		{"errors", sender.SeverityError},
+	} {
+		require.NoError(t, notifyprofile.SaveProfile(ctx, env.RepositoryWriter, &notifyprofile.Config{
+			ProfileName: p.name,
+			MethodConfig: sender.MethodConfig{
+				Type:   webhook.MethodWebhook,
+				Config: &webhook.Options{Endpoint: srv.URL + "/" + p.name},
+			},
+			MinSeverity: p.minSeverity,
+		}))
+	}
+
+	notification.Send(ctx, env.RepositoryWriter, notification.NewMessage(env.RepositoryWriter, notification.EventSnapshot, sender.SeveritySuccess, "success", ""))
+	notification.Send(ctx, env.RepositoryWriter, notification.NewMessage(env.RepositoryWriter, notification.EventSnapshot, sender.SeverityWarning, "warning", ""))
+	notification.Send(ctx, env.RepositoryWriter, notification.NewMessage(env.RepositoryWriter, notification.EventMaintenance, sender.SeverityError, "error", ""))
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	require.Equal(t, map[string][]string{
+		"/all":      {"success", "warning", "error"},
+		"/warnings": {"warning", "error"},
+		"/errors":   {"error"},
+	}, received)
+}
+
+// repositoryWithClientOptions overrides client options of the underlying repository.
+type repositoryWithClientOptions struct {
+	repo.Repository
+
+	opt repo.ClientOptions
+}
+
+func (r repositoryWithClientOptions) ClientOptions() repo.ClientOptions {
+	return r.opt
+}
+
+func TestSendToProfile_CommandRequiresOptIn(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test requires POSIX shell")
+	}
+
+	ctx, env := repotesting.NewEnvironment(t)
+
+	out := filepath.Join(testutil.TempDirectory(t), "out.txt")
+
+	pc := &notifyprofile.Config{
+		ProfileName: "cmd",
+		MethodConfig: sender.MethodConfig{
+			Type:   command.MethodCommand,
+			Config: &command.Options{Command: "/bin/sh", Args: []string{"-c", `cat > "$0"`, out}},
+		},
+	}
+
+	msg := notification.NewMessage(env.RepositoryWriter, notification.EventTest, sender.SeverityError, "subject", "body")
+
+	// commands from the repository are not run unless enabled on the client.
+	require.ErrorIs(t, notification.SendToProfile(ctx, env.RepositoryWriter, pc, msg), notification.ErrCommandsNotEnabled)
+	require.NoFileExists(t, out)
+
+	opt := env.RepositoryWriter.ClientOptions()
+	opt.EnableNotificationCommands = true
+
+	require.NoError(t, notification.SendToProfile(ctx, repositoryWithClientOptions{env.RepositoryWriter, opt}, pc, msg))
+
+	b, err := ioutil.ReadFile(out)
+	require.NoError(t, err)
+	require.Equal(t, "body", string(b))
+}
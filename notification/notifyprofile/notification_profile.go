@@ -0,0 +1,131 @@
+// Package notifyprofile manages notification profiles stored in the repository.
+package notifyprofile
+
+import (
+	"context"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/kopia/kopia/notification/sender"
+	"github.com/kopia/kopia/repo"
+	"github.com/kopia/kopia/repo/manifest"
+)
+
+// ManifestType is the type of the manifest that represents a notification profile.
+const ManifestType = "notificationProfile"
+
+const profileNameKey = "profile"
+
+// ErrNotFound is returned when a notification profile is not found.
+var ErrNotFound = errors.New("notification profile not found")
+
+// Config is a notification profile configuration.
+type Config struct {
+	ProfileName  string              `json:"profile"`
+	MethodConfig sender.MethodConfig `json:"method"`
+	MinSeverity  sender.Severity     `json:"minSeverity"`
+}
+
+func labelsForProfile(profileName string) map[string]string {
+	return map[string]string{
+		manifest.TypeLabelKey: ManifestType,
+		profileNameKey:        profileName,
+	}
+}
+
+// GetProfile returns the notification profile with the given name.
+func GetProfile(ctx context.Context, rep repo.Repository, profileName string) (*Config, error) {
+	entries, err := rep.FindManifests(ctx, labelsForProfile(profileName))
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to list notification profiles")
+	}
+
+	if len(entries) == 0 {
+		return nil, ErrNotFound
+	}
+
+	var pc Config
+
+	if _, err := rep.GetManifest(ctx, manifest.PickLatestID(entries), &pc); err != nil {
+		return nil, errors.Wrap(err, "unable to load notification profile")
+	}
+
+	return &pc, nil
+}
+
+// ListProfiles returns all notification profiles sorted by name.
+func ListProfiles(ctx context.Context, rep repo.Repository) ([]*Config, error) {
+	entries, err := rep.FindManifests(ctx, map[string]string{
+		manifest.TypeLabelKey: ManifestType,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to list notification profiles")
+	}
+
+	var profiles []*Config
+
+	for _, e := range entries {
+		var pc Config
+
+		if _, err := rep.GetManifest(ctx, e.ID, &pc); err != nil {
+			return nil, errors.Wrap(err, "unable to load notification profile")
+		}
+
+		profiles = append(profiles, &pc)
+	}
+
+	sort.Slice(profiles, func(i, j int) bool {
+		return profiles[i].ProfileName < profiles[j].ProfileName
+	})
+
+	return profiles, nil
+}
+
+// SaveProfile saves the notification profile, replacing any existing profile with the same name.
+func SaveProfile(ctx context.Context, rep repo.RepositoryWriter, pc *Config) error {
+	if pc.ProfileName == "" {
+		return errors.Errorf("profile name must be provided")
+	}
+
+	if _, err := sender.GetSender(ctx, pc.MethodConfig); err != nil {
+		return errors.Wrap(err, "invalid notification method configuration")
+	}
+
+	entries, err := rep.FindManifests(ctx, labelsForProfile(pc.ProfileName))
+	if err != nil {
+		return errors.Wrap(err, "unable to list notification profiles")
+	}
+
+	if _, err := rep.PutManifest(ctx, labelsForProfile(pc.ProfileName), pc); err != nil {
+		return errors.Wrap(err, "error writing notification profile")
+	}
+
+	for _, e := range entries {
+		if err := rep.DeleteManifest(ctx, e.ID); err != nil {
+			return errors.Wrap(err, "unable to delete previous notification profile")
+		}
+	}
+
+	return nil
+}
+
+// DeleteProfile deletes the notification profile with the given name.
+func DeleteProfile(ctx context.Context, rep repo.RepositoryWriter, profileName string) error {
+	entries, err := rep.FindManifests(ctx, labelsForProfile(profileName))
+	if err != nil {
+		return errors.Wrap(err, "unable to list notification profiles")
+	}
+
+	if len(entries) == 0 {
+		return ErrNotFound
+	}
+
+	for _, e := range entries {
+		if err := rep.DeleteManifest(ctx, e.ID); err != nil {
+			return errors.Wrap(err, "unable to delete notification profile")
+		}
+	}
+
+	return nil
+}
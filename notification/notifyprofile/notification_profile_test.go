@@ -0,0 +1,77 @@
+package notifyprofile_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kopia/kopia/internal/repotesting"
+	"github.com/kopia/kopia/notification/notifyprofile"
+	"github.com/kopia/kopia/notification/sender"
+	"github.com/kopia/kopia/notification/sender/webhook"
+)
+
+func TestNotificationProfiles(t *testing.T) {
+	ctx, env := repotesting.NewEnvironment(t)
+
+	profiles, err := notifyprofile.ListProfiles(ctx, env.RepositoryWriter)
+	require.NoError(t, err)
+	require.Empty(t, profiles)
+
+	_, err = notifyprofile.GetProfile(ctx, env.RepositoryWriter, "no-such-profile")
+	require.ErrorIs(t, err, notifyprofile.ErrNotFound)
+
+	p1 := &notifyprofile.Config{
+		ProfileName: "p1",
+		MethodConfig: sender.MethodConfig{
+			Type:   webhook.MethodWebhook,
+			Config: &webhook.Options{Endpoint: "http://localhost/p1"},
+		},
+		MinSeverity: sender.SeverityError,
+	}
+
+	require.NoError(t, notifyprofile.SaveProfile(ctx, env.RepositoryWriter, p1))
+
+	// replace existing profile with the same name.
+	p1.MethodConfig.Config = &webhook.Options{Endpoint: "http://localhost/p1-new"}
+	require.NoError(t, notifyprofile.SaveProfile(ctx, env.RepositoryWriter, p1))
+
+	require.NoError(t, notifyprofile.SaveProfile(ctx, env.RepositoryWriter, &notifyprofile.Config{
+		ProfileName: "p0",
+		MethodConfig: sender.MethodConfig{
+			Type:   webhook.MethodWebhook,
+			Config: &webhook.Options{Endpoint: "http://localhost/p0"},
+		},
+	}))
+
+	// invalid configurations are rejected.
+	require.Error(t, notifyprofile.SaveProfile(ctx, env.RepositoryWriter, &notifyprofile.Config{
+		ProfileName: "p2",
+		MethodConfig: sender.MethodConfig{
+			Type:   webhook.MethodWebhook,
+			Config: &webhook.Options{},
+		},
+	}))
+	require.Error(t, notifyprofile.SaveProfile(ctx, env.RepositoryWriter, &notifyprofile.Config{
+		MethodConfig: p1.MethodConfig,
+	}))
+
+	got, err := notifyprofile.GetProfile(ctx, env.RepositoryWriter, "p1")
+	require.NoError(t, err)
+	require.Equal(t, sender.SeverityError, got.MinSeverity)
+	require.Equal(t, webhook.MethodWebhook, got.MethodConfig.Type)
+	require.Equal(t, "http://localhost/p1-new", got.MethodConfig.Config.(*webhook.Options).Endpoint)
+
+	profiles, err = notifyprofile.ListProfiles(ctx, env.RepositoryWriter)
+	require.NoError(t, err)
+	require.Len(t, profiles, 2)
+	require.Equal(t, "p0", profiles[0].ProfileName)
+	require.Equal(t, "p1", profiles[1].ProfileName)
+
+	require.NoError(t, notifyprofile.DeleteProfile(ctx, env.RepositoryWriter, "p1"))
+	require.ErrorIs(t, notifyprofile.DeleteProfile(ctx, env.RepositoryWriter, "p1"), notifyprofile.ErrNotFound)
+
+	profiles, err = notifyprofile.ListProfiles(ctx, env.RepositoryWriter)
+	require.NoError(t, err)
+	require.Len(t, profiles, 1)
+}
@@ -0,0 +1,84 @@
+// Package command implements notification sender that delivers messages by running a local command.
+package command
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/kopia/kopia/notification/sender"
+)
+
+// MethodCommand is the name of the command notification method.
+const MethodCommand = sender.Method("command")
+
+const defaultTimeout = time.Minute
+
+// Options defines command notification options.
+//
+// The command receives message body on standard input and the remaining message
+// fields in KOPIA_NOTIFICATION_* environment variables.
+// Because profiles are stored in the repository, commands only run on clients which
+// have enabled notification commands when connecting to the repository.
+type Options struct {
+	Command string        `json:"command"`
+	Args    []string      `json:"args,omitempty"`
+	Timeout time.Duration `json:"timeout,omitempty"`
+}
+
+type commandSender struct {
+	opt Options
+}
+
+func (s *commandSender) Send(ctx context.Context, msg *sender.Message) error {
+	ctx, cancel := context.WithTimeout(ctx, s.opt.Timeout)
+	defer cancel()
+
+	c := exec.CommandContext(ctx, s.opt.Command, s.opt.Args...) //nolint:gosec
+	c.Stdin = strings.NewReader(msg.Body)
+	c.Env = append(os.Environ(),
+		"KOPIA_NOTIFICATION_EVENT="+msg.Event,
+		"KOPIA_NOTIFICATION_SUBJECT="+msg.Subject,
+		"KOPIA_NOTIFICATION_SEVERITY="+msg.Severity.String(),
+		"KOPIA_NOTIFICATION_HOSTNAME="+msg.Hostname,
+		"KOPIA_NOTIFICATION_TIME="+msg.Time.Format(time.RFC3339),
+	)
+
+	if out, err := c.CombinedOutput(); err != nil {
+		return errors.Wrapf(err, "notification command failed: %v", strings.TrimSpace(string(out)))
+	}
+
+	return nil
+}
+
+func (s *commandSender) Summary() string {
+	return fmt.Sprintf("Command: %v %v", s.opt.Command, strings.Join(s.opt.Args, " "))
+}
+
+// New creates new command notification sender.
+func New(ctx context.Context, opt *Options) (sender.Sender, error) {
+	if opt.Command == "" {
+		return nil, errors.Errorf("command must be provided")
+	}
+
+	o := *opt
+	if o.Timeout <= 0 {
+		o.Timeout = defaultTimeout
+	}
+
+	return &commandSender{o}, nil
+}
+
+func init() {
+	sender.Register(
+		MethodCommand,
+		func() interface{} { return &Options{} },
+		func(ctx context.Context, o interface{}) (sender.Sender, error) {
+			return New(ctx, o.(*Options))
+		})
+}
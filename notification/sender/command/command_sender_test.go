@@ -0,0 +1,53 @@
+package command_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kopia/kopia/internal/testlogging"
+	"github.com/kopia/kopia/internal/testutil"
+	"github.com/kopia/kopia/notification/sender"
+	"github.com/kopia/kopia/notification/sender/command"
+)
+
+func TestCommandSender(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test requires POSIX shell")
+	}
+
+	ctx := testlogging.Context(t)
+	out := filepath.Join(testutil.TempDirectory(t), "out.txt")
+
+	s, err := command.New(ctx, &command.Options{
+		Command: "/bin/sh",
+		Args:    []string{"-c", `(echo "$KOPIA_NOTIFICATION_SEVERITY $KOPIA_NOTIFICATION_SUBJECT"; cat) > "$0"`, out},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, s.Send(ctx, &sender.Message{
+		Subject:  "hello",
+		Body:     "the body\n",
+		Severity: sender.SeverityWarning,
+	}))
+
+	b, err := ioutil.ReadFile(out)
+	require.NoError(t, err)
+	require.Equal(t, "warning hello\nthe body\n", string(b))
+
+	failing, err := command.New(ctx, &command.Options{
+		Command: "/bin/sh",
+		Args:    []string{"-c", "echo oops; exit 1"},
+	})
+	require.NoError(t, err)
+
+	err = failing.Send(ctx, &sender.Message{})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "oops")
+
+	_, err = command.New(ctx, &command.Options{})
+	require.Error(t, err)
+}
@@ -0,0 +1,194 @@
+// Package email implements notification sender that delivers messages using SMTP.
+package email
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/kopia/kopia/notification/sender"
+)
+
+// MethodEmail is the name of the email notification method.
+const MethodEmail = sender.Method("email")
+
+const (
+	defaultSMTPPort = 587
+	defaultTimeout  = time.Minute
+)
+
+// Options defines email notification options.
+type Options struct {
+	SMTPServer   string `json:"smtpServer"`
+	SMTPPort     int    `json:"smtpPort"`
+	SMTPUsername string `json:"smtpUsername,omitempty"`
+	SMTPPassword string `json:"smtpPassword,omitempty"`
+	From         string `json:"from"`
+	To           string `json:"to"`
+
+	// Timeout limits the total time of connecting to the SMTP server and sending the message.
+	Timeout time.Duration `json:"timeout,omitempty"`
+}
+
+// Redacted implements sender.Redactor.
+func (o *Options) Redacted() interface{} {
+	r := *o
+	if r.SMTPPassword != "" {
+		r.SMTPPassword = sender.RedactedSecret
+	}
+
+	return &r
+}
+
+// recipients returns the list of recipients, which are separated by commas.
+func (o *Options) recipients() []string {
+	var result []string
+
+	for _, r := range strings.Split(o.To, ",") {
+		if r = strings.TrimSpace(r); r != "" {
+			result = append(result, r)
+		}
+	}
+
+	return result
+}
+
+type emailSender struct {
+	opt Options
+}
+
+func (s *emailSender) Send(ctx context.Context, msg *sender.Message) error {
+	ctx, cancel := context.WithTimeout(ctx, s.opt.Timeout)
+	defer cancel()
+
+	var body bytes.Buffer
+
+	fmt.Fprintf(&body, "From: %v\r\n", s.opt.From)
+	fmt.Fprintf(&body, "To: %v\r\n", strings.Join(s.opt.recipients(), ", "))
+	fmt.Fprintf(&body, "Subject: %v\r\n", msg.Subject)
+	fmt.Fprintf(&body, "Content-Type: text/plain; charset=UTF-8\r\n")
+	fmt.Fprintf(&body, "\r\n")
+	body.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+
+	addr := net.JoinHostPort(s.opt.SMTPServer, strconv.Itoa(s.opt.SMTPPort))
+
+	var d net.Dialer
+
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return errors.Wrap(err, "unable to connect to SMTP server")
+	}
+
+	defer conn.Close() //nolint:errcheck
+
+	// smtp.Client does not support contexts, bound the entire conversation by the deadline instead.
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return errors.Wrap(err, "unable to set connection deadline")
+		}
+	}
+
+	if err := s.sendMail(conn, body.Bytes()); err != nil {
+		return errors.Wrap(err, "unable to send email")
+	}
+
+	return nil
+}
+
+// sendMail sends the message over the provided connection, equivalent to smtp.SendMail.
+func (s *emailSender) sendMail(conn net.Conn, body []byte) error {
+	c, err := smtp.NewClient(conn, s.opt.SMTPServer)
+	if err != nil {
+		return errors.Wrap(err, "unable to create SMTP client")
+	}
+
+	defer c.Close() //nolint:errcheck
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: s.opt.SMTPServer, MinVersion: tls.VersionTLS12}); err != nil {
+			return errors.Wrap(err, "STARTTLS failed")
+		}
+	}
+
+	if s.opt.SMTPUsername != "" {
+		if ok, _ := c.Extension("AUTH"); !ok {
+			return errors.Errorf("SMTP server does not support authentication")
+		}
+
+		if err := c.Auth(smtp.PlainAuth("", s.opt.SMTPUsername, s.opt.SMTPPassword, s.opt.SMTPServer)); err != nil {
+			return errors.Wrap(err, "authentication failed")
+		}
+	}
+
+	if err := c.Mail(s.opt.From); err != nil {
+		return errors.Wrap(err, "MAIL FROM failed")
+	}
+
+	for _, r := range s.opt.recipients() {
+		if err := c.Rcpt(r); err != nil {
+			return errors.Wrapf(err, "RCPT TO %v failed", r)
+		}
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return errors.Wrap(err, "DATA failed")
+	}
+
+	if _, err := w.Write(body); err != nil {
+		return errors.Wrap(err, "unable to write message")
+	}
+
+	if err := w.Close(); err != nil {
+		return errors.Wrap(err, "unable to finish message")
+	}
+
+	return errors.Wrap(c.Quit(), "QUIT failed")
+}
+
+func (s *emailSender) Summary() string {
+	return fmt.Sprintf("SMTP server: %q, Mail from: %q Mail to: %q", s.opt.SMTPServer, s.opt.From, s.opt.To)
+}
+
+// New creates new email notification sender.
+func New(ctx context.Context, opt *Options) (sender.Sender, error) {
+	if opt.SMTPServer == "" {
+		return nil, errors.Errorf("SMTP server must be provided")
+	}
+
+	if opt.From == "" {
+		return nil, errors.Errorf("sender address must be provided")
+	}
+
+	if len(opt.recipients()) == 0 {
+		return nil, errors.Errorf("at least one recipient must be provided")
+	}
+
+	o := *opt
+	if o.SMTPPort == 0 {
+		o.SMTPPort = defaultSMTPPort
+	}
+
+	if o.Timeout <= 0 {
+		o.Timeout = defaultTimeout
+	}
+
+	return &emailSender{o}, nil
+}
+
+func init() {
+	sender.Register(
+		MethodEmail,
+		func() interface{} { return &Options{} },
+		func(ctx context.Context, o interface{}) (sender.Sender, error) {
+			return New(ctx, o.(*Options))
+		})
+}
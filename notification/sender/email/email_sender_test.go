@@ -0,0 +1,185 @@
+package email_test
+
+import (
+	"encoding/json"
+	"net"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kopia/kopia/internal/testlogging"
+	"github.com/kopia/kopia/notification/sender"
+	"github.com/kopia/kopia/notification/sender/email"
+)
+
+type receivedMail struct {
+	from string
+	to   []string
+	data string
+}
+
+// startFakeSMTPServer starts minimal SMTP server accepting a single message per connection.
+func startFakeSMTPServer(t *testing.T) (host string, port int, mails chan receivedMail) {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	t.Cleanup(func() { l.Close() })
+
+	mails = make(chan receivedMail, 10)
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+
+			handleSMTPConnection(textproto.NewConn(conn), mails)
+		}
+	}()
+
+	addr := l.Addr().(*net.TCPAddr) // nolint:forcetypeassert
+
+	return addr.IP.String(), addr.Port, mails
+}
+
+func handleSMTPConnection(c *textproto.Conn, mails chan receivedMail) {
+	defer c.Close()
+
+	var m receivedMail
+
+	c.PrintfLine("220 localhost ready") //nolint:errcheck
+
+	for {
+		line, err := c.ReadLine()
+		if err != nil {
+			return
+		}
+
+		cmd := strings.ToUpper(line)
+
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+			c.PrintfLine("250 localhost") //nolint:errcheck
+		case strings.HasPrefix(cmd, "MAIL FROM:"):
+			m.from = strings.Trim(line[len("MAIL FROM:"):], "<>")
+			c.PrintfLine("250 OK") //nolint:errcheck
+		case strings.HasPrefix(cmd, "RCPT TO:"):
+			m.to = append(m.to, strings.Trim(line[len("RCPT TO:"):], "<>"))
+			c.PrintfLine("250 OK") //nolint:errcheck
+		case cmd == "DATA":
+			c.PrintfLine("354 go ahead") //nolint:errcheck
+
+			data, err := c.ReadDotBytes()
+			if err != nil {
+				return
+			}
+
+			m.data = string(data)
+			mails <- m
+
+			c.PrintfLine("250 OK") //nolint:errcheck
+		case cmd == "QUIT":
+			c.PrintfLine("221 bye") //nolint:errcheck
+			return
+		default:
+			c.PrintfLine("250 OK") //nolint:errcheck
+		}
+	}
+}
+
+func TestEmailSender(t *testing.T) {
+	ctx := testlogging.Context(t)
+	host, port, mails := startFakeSMTPServer(t)
+
+	s, err := email.New(ctx, &email.Options{
+		SMTPServer: host,
+		SMTPPort:   port,
+		From:       "kopia@example.com",
+		To:         "a@example.com, b@example.com",
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, s.Send(ctx, &sender.Message{
+		Subject:  "Snapshot failed",
+		Body:     "line1\nline2\n",
+		Severity: sender.SeverityError,
+	}))
+
+	m := <-mails
+	require.Equal(t, "kopia@example.com", m.from)
+	require.Equal(t, []string{"a@example.com", "b@example.com"}, m.to)
+	require.Contains(t, m.data, "Subject: Snapshot failed\n")
+	require.Contains(t, m.data, "To: a@example.com, b@example.com\n")
+	require.Contains(t, m.data, "\nline1\nline2\n")
+}
+
+func TestEmailSender_Timeout(t *testing.T) {
+	ctx := testlogging.Context(t)
+
+	// server that accepts connections but never responds.
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	t.Cleanup(func() { l.Close() })
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+
+			t.Cleanup(func() { conn.Close() })
+		}
+	}()
+
+	addr := l.Addr().(*net.TCPAddr) // nolint:forcetypeassert
+
+	s, err := email.New(ctx, &email.Options{
+		SMTPServer: addr.IP.String(),
+		SMTPPort:   addr.Port,
+		From:       "kopia@example.com",
+		To:         "a@example.com",
+		Timeout:    100 * time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	t0 := time.Now()
+
+	require.Error(t, s.Send(ctx, &sender.Message{Subject: "hello"}))
+	require.Less(t, time.Since(t0), 10*time.Second)
+}
+
+func TestEmailSender_InvalidOptions(t *testing.T) {
+	ctx := testlogging.Context(t)
+
+	cases := []*email.Options{
+		{From: "a@example.com", To: "b@example.com"},
+		{SMTPServer: "localhost", To: "b@example.com"},
+		{SMTPServer: "localhost", From: "a@example.com", To: " , "},
+	}
+
+	for i, opt := range cases {
+		_, err := email.New(ctx, opt)
+		require.Error(t, err, strconv.Itoa(i))
+	}
+}
+
+func TestOptionsRedacted(t *testing.T) {
+	opt := &email.Options{SMTPServer: "localhost", SMTPUsername: "user", SMTPPassword: "secret"}
+
+	b, err := json.Marshal(sender.MethodConfig{Type: email.MethodEmail, Config: opt}.Redacted())
+	require.NoError(t, err)
+	require.NotContains(t, string(b), "secret")
+	require.Contains(t, string(b), sender.RedactedSecret)
+
+	// original options are not modified.
+	require.Equal(t, "secret", opt.SMTPPassword)
+}
@@ -0,0 +1,68 @@
+package sender
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+)
+
+// RedactedSecret replaces secrets in redacted notification method options.
+const RedactedSecret = "***"
+
+// Redactor is implemented by notification method options that contain secrets, such as passwords.
+type Redactor interface {
+	// Redacted returns a copy of the options with secrets replaced by RedactedSecret.
+	Redacted() interface{}
+}
+
+// MethodConfig represents JSON-serializable configuration of a notification method.
+type MethodConfig struct {
+	Type   Method
+	Config interface{}
+}
+
+// Redacted returns a copy of the configuration with secrets removed, which is suitable for display.
+func (c MethodConfig) Redacted() MethodConfig {
+	if r, ok := c.Config.(Redactor); ok {
+		c.Config = r.Redacted()
+	}
+
+	return c
+}
+
+// UnmarshalJSON parses the JSON-encoded data into MethodConfig.
+func (c *MethodConfig) UnmarshalJSON(b []byte) error {
+	raw := struct {
+		Type Method          `json:"type"`
+		Data json.RawMessage `json:"config"`
+	}{}
+
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return errors.Wrap(err, "error unmarshaling notification method JSON")
+	}
+
+	c.Type = raw.Type
+
+	f := factories[raw.Type]
+	if f == nil {
+		return errors.Errorf("notification method '%v' not registered", raw.Type)
+	}
+
+	c.Config = f.defaultOptionsFunc()
+	if err := json.Unmarshal(raw.Data, c.Config); err != nil {
+		return errors.Wrap(err, "unable to unmarshal config")
+	}
+
+	return nil
+}
+
+// MarshalJSON returns JSON-encoded notification method configuration.
+func (c MethodConfig) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type Method      `json:"type"`
+		Data interface{} `json:"config"`
+	}{
+		Type: c.Type,
+		Data: c.Config,
+	})
+}
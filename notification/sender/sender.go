@@ -0,0 +1,61 @@
+// Package sender defines the interface of notification senders and a registry of supported notification methods.
+package sender
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Message represents a notification message.
+type Message struct {
+	Event    string    `json:"event"`
+	Subject  string    `json:"subject"`
+	Body     string    `json:"body"`
+	Severity Severity  `json:"severity"`
+	Hostname string    `json:"hostname"`
+	Time     time.Time `json:"time"`
+}
+
+// Sender is an interface implemented by notification methods.
+type Sender interface {
+	// Send delivers the provided message.
+	Send(ctx context.Context, msg *Message) error
+
+	// Summary returns human-readable description of the sender.
+	Summary() string
+}
+
+// Method identifies a notification method.
+type Method string
+
+var factories = map[Method]*senderFactory{}
+
+type senderFactory struct {
+	defaultOptionsFunc func() interface{}
+	createSenderFunc   func(context.Context, interface{}) (Sender, error)
+}
+
+// Register registers factory function to create senders for a given notification method.
+func Register(
+	method Method,
+	defaultOptionsFunc func() interface{},
+	createSenderFunc func(context.Context, interface{}) (Sender, error),
+) {
+	factories[method] = &senderFactory{
+		defaultOptionsFunc: defaultOptionsFunc,
+		createSenderFunc:   createSenderFunc,
+	}
+}
+
+// GetSender creates a sender based on the provided method configuration.
+// The method must be previously registered using Register.
+func GetSender(ctx context.Context, mc MethodConfig) (Sender, error) {
+	f := factories[mc.Type]
+	if f == nil {
+		return nil, errors.Errorf("unknown notification method: %v", mc.Type)
+	}
+
+	return f.createSenderFunc(ctx, mc.Config)
+}
@@ -0,0 +1,50 @@
+package sender
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Severity represents the severity of a notification message.
+type Severity int
+
+// Supported severities, in increasing order.
+const (
+	SeverityVerbose Severity = 0
+	SeveritySuccess Severity = 10
+	SeverityWarning Severity = 20
+	SeverityError   Severity = 30
+)
+
+// nolint:gochecknoglobals
+var severityNames = map[Severity]string{
+	SeverityVerbose: "verbose",
+	SeveritySuccess: "success",
+	SeverityWarning: "warning",
+	SeverityError:   "error",
+}
+
+// SeverityNames returns the names of all supported severities, in increasing order.
+func SeverityNames() []string {
+	return []string{"verbose", "success", "warning", "error"}
+}
+
+func (s Severity) String() string {
+	if n, ok := severityNames[s]; ok {
+		return n
+	}
+
+	return "unknown"
+}
+
+// ParseSeverity parses the name of the severity.
+func ParseSeverity(s string) (Severity, error) {
+	for sev, n := range severityNames {
+		if strings.EqualFold(n, s) {
+			return sev, nil
+		}
+	}
+
+	return 0, errors.Errorf("unknown severity %q, must be one of %v", s, strings.Join(SeverityNames(), ", "))
+}
@@ -0,0 +1,129 @@
+// Package webhook implements notification sender that delivers messages using HTTP requests.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"text/template"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/kopia/kopia/notification/sender"
+)
+
+// MethodWebhook is the name of the webhook notification method.
+const MethodWebhook = sender.Method("webhook")
+
+const defaultTimeout = time.Minute
+
+// DefaultTemplate is the default template of the request body.
+const DefaultTemplate = `{"event":{{json .Event}},"subject":{{json .Subject}},"body":{{json .Body}},"severity":{{json .Severity.String}},"hostname":{{json .Hostname}},"time":{{json .Time}}}`
+
+// Options defines webhook notification options.
+type Options struct {
+	Endpoint string            `json:"endpoint"`
+	Method   string            `json:"method,omitempty"`
+	Headers  map[string]string `json:"headers,omitempty"`
+
+	// Template is a Go text/template rendering request body from the notification message,
+	// with a 'json' function available to encode values.
+	Template string `json:"template,omitempty"`
+
+	// Timeout limits the total time of the request including reading the response.
+	Timeout time.Duration `json:"timeout,omitempty"`
+}
+
+type webhookSender struct {
+	opt    Options
+	tmpl   *template.Template
+	client *http.Client
+}
+
+func (s *webhookSender) Send(ctx context.Context, msg *sender.Message) error {
+	var body bytes.Buffer
+
+	if err := s.tmpl.Execute(&body, msg); err != nil {
+		return errors.Wrap(err, "unable to render request body")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, s.opt.Method, s.opt.Endpoint, &body)
+	if err != nil {
+		return errors.Wrap(err, "unable to create request")
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	for k, v := range s.opt.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "unable to send request")
+	}
+
+	defer resp.Body.Close() //nolint:errcheck
+
+	io.Copy(ioutil.Discard, resp.Body) //nolint:errcheck
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return errors.Errorf("webhook returned %v", resp.Status)
+	}
+
+	return nil
+}
+
+func (s *webhookSender) Summary() string {
+	return fmt.Sprintf("Webhook: %v %v", s.opt.Method, s.opt.Endpoint)
+}
+
+func jsonEncode(v interface{}) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", errors.Wrap(err, "unable to encode JSON")
+	}
+
+	return string(b), nil
+}
+
+// New creates new webhook notification sender.
+func New(ctx context.Context, opt *Options) (sender.Sender, error) {
+	if opt.Endpoint == "" {
+		return nil, errors.Errorf("endpoint must be provided")
+	}
+
+	o := *opt
+	if o.Method == "" {
+		o.Method = http.MethodPost
+	}
+
+	if o.Template == "" {
+		o.Template = DefaultTemplate
+	}
+
+	if o.Timeout <= 0 {
+		o.Timeout = defaultTimeout
+	}
+
+	tmpl, err := template.New("webhook").Funcs(template.FuncMap{"json": jsonEncode}).Parse(o.Template)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid template")
+	}
+
+	return &webhookSender{o, tmpl, &http.Client{Timeout: o.Timeout}}, nil
+}
+
+func init() {
+	sender.Register(
+		MethodWebhook,
+		func() interface{} { return &Options{} },
+		func(ctx context.Context, o interface{}) (sender.Sender, error) {
+			return New(ctx, o.(*Options))
+		})
+}
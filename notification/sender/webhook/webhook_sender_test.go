@@ -0,0 +1,134 @@
+package webhook_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kopia/kopia/internal/testlogging"
+	"github.com/kopia/kopia/notification/sender"
+	"github.com/kopia/kopia/notification/sender/webhook"
+)
+
+type receivedRequest struct {
+	method string
+	header http.Header
+	body   []byte
+}
+
+func newTestServer(t *testing.T) (*httptest.Server, chan receivedRequest) {
+	t.Helper()
+
+	ch := make(chan receivedRequest, 10)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+
+		ch <- receivedRequest{r.Method, r.Header, b}
+
+		if r.URL.Path == "/fail" {
+			http.Error(w, "failed", http.StatusInternalServerError)
+		}
+	}))
+
+	t.Cleanup(srv.Close)
+
+	return srv, ch
+}
+
+func TestWebhookSender(t *testing.T) {
+	ctx := testlogging.Context(t)
+	srv, ch := newTestServer(t)
+
+	s, err := webhook.New(ctx, &webhook.Options{
+		Endpoint: srv.URL + "/notify",
+		Headers:  map[string]string{"X-Token": "secret"},
+	})
+	require.NoError(t, err)
+
+	msg := &sender.Message{
+		Event:    "snapshot",
+		Subject:  "Snapshot \"failed\"",
+		Body:     "some body\n",
+		Severity: sender.SeverityError,
+		Hostname: "host",
+		Time:     time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	require.NoError(t, s.Send(ctx, msg))
+
+	req := <-ch
+	require.Equal(t, http.MethodPost, req.method)
+	require.Equal(t, "secret", req.header.Get("X-Token"))
+
+	var got map[string]string
+
+	require.NoError(t, json.Unmarshal(req.body, &got))
+	require.Equal(t, map[string]string{
+		"event":    "snapshot",
+		"subject":  "Snapshot \"failed\"",
+		"body":     "some body\n",
+		"severity": "error",
+		"hostname": "host",
+		"time":     "2021-01-01T00:00:00Z",
+	}, got)
+}
+
+func TestWebhookSender_CustomTemplate(t *testing.T) {
+	ctx := testlogging.Context(t)
+	srv, ch := newTestServer(t)
+
+	s, err := webhook.New(ctx, &webhook.Options{
+		Endpoint: srv.URL + "/notify",
+		Method:   http.MethodPut,
+		Template: `{"text":{{json .Subject}}}`,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, s.Send(ctx, &sender.Message{Subject: "hello"}))
+
+	req := <-ch
+	require.Equal(t, http.MethodPut, req.method)
+	require.JSONEq(t, `{"text":"hello"}`, string(req.body))
+}
+
+func TestWebhookSender_Errors(t *testing.T) {
+	ctx := testlogging.Context(t)
+	srv, _ := newTestServer(t)
+
+	_, err := webhook.New(ctx, &webhook.Options{})
+	require.Error(t, err)
+
+	_, err = webhook.New(ctx, &webhook.Options{Endpoint: srv.URL, Template: "{{"})
+	require.Error(t, err)
+
+	s, err := webhook.New(ctx, &webhook.Options{Endpoint: srv.URL + "/fail"})
+	require.NoError(t, err)
+	require.Error(t, s.Send(ctx, &sender.Message{}))
+}
+
+func TestWebhookSender_Timeout(t *testing.T) {
+	ctx := testlogging.Context(t)
+
+	unblock := make(chan struct{})
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-unblock
+	}))
+
+	t.Cleanup(srv.Close)
+	t.Cleanup(func() { close(unblock) })
+
+	s, err := webhook.New(ctx, &webhook.Options{Endpoint: srv.URL, Timeout: 100 * time.Millisecond})
+	require.NoError(t, err)
+
+	t0 := time.Now()
+
+	require.Error(t, s.Send(ctx, &sender.Message{}))
+	require.Less(t, time.Since(t0), 10*time.Second)
+}
@@ -5,7 +5,9 @@
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"net/url"
+	"sync/atomic"
 	"time"
 
 	"github.com/pkg/errors"
@@ -14,6 +16,7 @@
 	"github.com/kopia/kopia/internal/cache"
 	"github.com/kopia/kopia/internal/clock"
 	"github.com/kopia/kopia/internal/remoterepoapi"
+	"github.com/kopia/kopia/repo/compression"
 	"github.com/kopia/kopia/repo/content"
 	"github.com/kopia/kopia/repo/hashing"
 	"github.com/kopia/kopia/repo/manifest"
@@ -25,11 +28,23 @@ type APIServerInfo struct {
 	BaseURL                             string `json:"url"`
 	TrustedServerCertificateFingerprint string `json:"serverCertFingerprint"`
 	DisableGRPC                         bool   `json:"disableGRPC,omitempty"`
+
+	// PEM files with the client certificate and key presented to servers that authenticate clients using TLS certificates.
+	ClientCertificateFile string `json:"clientCertFile,omitempty"`
+	ClientKeyFile         string `json:"clientKeyFile,omitempty"`
 }
 
+// ErrQuotaExceeded is returned when writing content to the repository server would exceed
+// the storage quota of the user.
+var ErrQuotaExceeded = errors.Errorf("storage quota exceeded")
+
+var errContentCompressionNotSupported = errors.Errorf("content-level compression is not supported by the repository server")
+
 // remoteRepository is an implementation of Repository that connects to an instance of
 // API server hosted by `kopia server`, instead of directly manipulating files in the BLOB storage.
 type apiServerRepository struct {
+	uploadedBytes int64 // must be 64-bit aligned due to atomic access on ARM
+
 	cli          *apiclient.KopiaAPIClient
 	h            hashing.HashFunc
 	objectFormat object.Format
@@ -129,6 +144,10 @@ func (r *apiServerRepository) Refresh(ctx context.Context) error {
 	return nil
 }
 
+func (r *apiServerRepository) UploadedBytes() int64 {
+	return atomic.LoadInt64(&r.uploadedBytes)
+}
+
 func (r *apiServerRepository) Flush(ctx context.Context) error {
 	return errors.Wrap(r.cli.Post(ctx, "flush", nil, nil), "Flush")
 }
@@ -146,6 +165,7 @@ func (r *apiServerRepository) NewWriter(ctx context.Context, opt WriteSessionOpt
 
 	w.omgr = omgr
 	w.wso = opt
+	w.uploadedBytes = 0
 	w.isSharedReadOnlySession = false
 
 	return w, nil
@@ -173,11 +193,25 @@ func (r *apiServerRepository) GetContent(ctx context.Context, contentID content.
 	})
 }
 
-func (r *apiServerRepository) WriteContent(ctx context.Context, data []byte, prefix content.ID) (content.ID, error) {
+// SupportsContentCompression returns false, because the API does not carry content compression.
+func (r *apiServerRepository) SupportsContentCompression() bool {
+	return false
+}
+
+// SupportsSparseObjects returns false, because the repository format of the server is not known.
+func (r *apiServerRepository) SupportsSparseObjects() bool {
+	return false
+}
+
+func (r *apiServerRepository) WriteContent(ctx context.Context, data []byte, prefix content.ID, comp compression.HeaderID) (content.ID, error) {
 	if err := content.ValidatePrefix(prefix); err != nil {
 		return "", errors.Wrap(err, "invalid prefix")
 	}
 
+	if comp != content.NoCompression {
+		return "", errContentCompressionNotSupported
+	}
+
 	var hashOutput [128]byte
 
 	contentID := prefix + content.ID(hex.EncodeToString(r.h(hashOutput[:0], data)))
@@ -188,9 +222,15 @@ func (r *apiServerRepository) WriteContent(ctx context.Context, data []byte, pre
 		return contentID, nil
 	}
 
+	atomic.AddInt64(&r.uploadedBytes, int64(len(data)))
 	r.wso.OnUpload(int64(len(data)))
 
 	if err := r.cli.Put(ctx, "contents/"+string(contentID), data, nil); err != nil {
+		var hse apiclient.HTTPStatusError
+		if errors.As(err, &hse) && hse.HTTPStatusCode == http.StatusInsufficientStorage {
+			return "", errors.Wrapf(ErrQuotaExceeded, "error writing content %v", contentID)
+		}
+
 		return "", errors.Wrapf(err, "error writing content %v", contentID)
 	}
 
@@ -231,6 +271,8 @@ func openRestAPIRepository(ctx context.Context, si *APIServerInfo, cliOpts Clien
 	cli, err := apiclient.NewKopiaAPIClient(apiclient.Options{
 		BaseURL:                             si.BaseURL,
 		TrustedServerCertificateFingerprint: si.TrustedServerCertificateFingerprint,
+		ClientCertificateFile:               si.ClientCertificateFile,
+		ClientKeyFile:                       si.ClientKeyFile,
 		Username:                            cliOpts.UsernameAtHost(),
 		Password:                            password,
 		LogRequests:                         true,
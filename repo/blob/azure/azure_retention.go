@@ -0,0 +1,141 @@
+package azure
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"path"
+	"time"
+
+	"github.com/Azure/azure-pipeline-go/pipeline"
+	"github.com/pkg/errors"
+
+	"github.com/kopia/kopia/repo/blob"
+)
+
+const (
+	// immutabilityAPIVersion is the first version of Azure Blob Storage REST API that supports
+	// version-level immutability policies.
+	immutabilityAPIVersion = "2020-10-02"
+
+	immutabilityUntilHeader = "x-ms-immutability-policy-until-date"
+	immutabilityModeHeader  = "x-ms-immutability-policy-mode"
+)
+
+// immutabilityClient manages version-level immutability policies of blobs using Azure REST API directly,
+// which requires version-level immutability support to be enabled on the container.
+type immutabilityClient struct {
+	containerURL url.URL
+	pipeline     pipeline.Pipeline
+}
+
+func (c immutabilityClient) blobURL(name string, query string) url.URL {
+	u := c.containerURL
+	u.Path = path.Join(u.Path, name)
+	u.RawQuery = query
+
+	return u
+}
+
+func (c immutabilityClient) do(ctx context.Context, method string, u url.URL, headers map[string]string) (*http.Response, error) {
+	req, err := pipeline.NewRequest(method, u, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to create request")
+	}
+
+	req.Header.Set("x-ms-version", immutabilityAPIVersion)
+
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := c.pipeline.Do(ctx, nil, req)
+	if err != nil {
+		return nil, errors.Wrap(err, "request failed")
+	}
+
+	hr := resp.Response()
+	hr.Body.Close() //nolint:errcheck
+
+	switch {
+	case hr.StatusCode == http.StatusNotFound:
+		return nil, blob.ErrBlobNotFound
+	case hr.StatusCode >= http.StatusBadRequest:
+		return nil, errors.Errorf("unexpected status %v: %v", hr.Status, hr.Header.Get("x-ms-error-code"))
+	default:
+		return hr, nil
+	}
+}
+
+// setPolicy sets the immutability policy of the provided blob.
+func (c immutabilityClient) setPolicy(ctx context.Context, name string, mode blob.RetentionMode, retainUntil time.Time) error {
+	// "Unlocked" policies can be removed or shortened by privileged users, "Locked" ones can only be extended.
+	policyMode := "Unlocked"
+	if mode == blob.RetentionModeCompliance {
+		policyMode = "Locked"
+	}
+
+	_, err := c.do(ctx, http.MethodPut, c.blobURL(name, "comp=immutabilityPolicies"), map[string]string{
+		immutabilityUntilHeader: retainUntil.UTC().Format(http.TimeFormat),
+		immutabilityModeHeader:  policyMode,
+	})
+
+	return err
+}
+
+// getPolicyExpiration returns the expiration time of the immutability policy of the provided blob.
+func (c immutabilityClient) getPolicyExpiration(ctx context.Context, name string) (time.Time, error) {
+	hr, err := c.do(ctx, http.MethodHead, c.blobURL(name, ""), nil)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	v := hr.Header.Get(immutabilityUntilHeader)
+	if v == "" {
+		return time.Time{}, nil
+	}
+
+	t, err := http.ParseTime(v)
+	if err != nil {
+		return time.Time{}, errors.Wrapf(err, "invalid immutability policy expiration: %q", v)
+	}
+
+	return t, nil
+}
+
+// PutBlobWithRetention implements blob.RetentionStorage.
+func (az *azStorage) PutBlobWithRetention(ctx context.Context, b blob.ID, data blob.Bytes, mode blob.RetentionMode, retainUntil time.Time) error {
+	if !mode.IsValid() {
+		return errors.Errorf("invalid retention mode: %q", mode)
+	}
+
+	if err := az.PutBlob(ctx, b, data); err != nil {
+		return err
+	}
+
+	return az.immutability.setPolicy(ctx, az.getObjectNameString(b), mode, retainUntil)
+}
+
+// ExtendBlobRetention implements blob.RetentionStorage.
+func (az *azStorage) ExtendBlobRetention(ctx context.Context, b blob.ID, mode blob.RetentionMode, retainUntil time.Time) error {
+	if !mode.IsValid() {
+		return errors.Errorf("invalid retention mode: %q", mode)
+	}
+
+	current, err := az.GetBlobRetention(ctx, b)
+	if err != nil {
+		return err
+	}
+
+	if !current.Before(retainUntil) {
+		// never shorten the retention.
+		return nil
+	}
+
+	return az.immutability.setPolicy(ctx, az.getObjectNameString(b), mode, retainUntil)
+}
+
+// GetBlobRetention implements blob.RetentionStorage.
+func (az *azStorage) GetBlobRetention(ctx context.Context, b blob.ID) (time.Time, error) {
+	return az.immutability.getPolicyExpiration(ctx, az.getObjectNameString(b))
+}
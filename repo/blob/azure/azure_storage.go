@@ -7,6 +7,7 @@
 	"io"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"time"
 
 	"github.com/Azure/azure-storage-blob-go/azblob"
@@ -24,6 +25,8 @@
 
 const (
 	azStorageType = "azureBlob"
+
+	defaultStorageDomain = "blob.core.windows.net"
 )
 
 type azStorage struct {
@@ -33,6 +36,9 @@ type azStorage struct {
 
 	bucket *gblob.Bucket
 
+	// immutability manages blob immutability policies, which are not supported by the bucket.
+	immutability immutabilityClient
+
 	downloadThrottler *iothrottler.IOThrottlerPool
 	uploadThrottler   *iothrottler.IOThrottlerPool
 }
@@ -231,6 +237,11 @@ func New(ctx context.Context, opt *Options) (blob.Storage, error) {
 		return nil, errors.Wrap(err, "unable to open bucket")
 	}
 
+	containerURL, err := url.Parse(fmt.Sprintf("https://%v.%v/%v", opt.StorageAccount, defaultStorageDomain, opt.Container))
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid container URL")
+	}
+
 	downloadThrottler := iothrottler.NewIOThrottlerPool(toBandwidth(opt.MaxDownloadSpeedBytesPerSecond))
 	uploadThrottler := iothrottler.NewIOThrottlerPool(toBandwidth(opt.MaxUploadSpeedBytesPerSecond))
 
@@ -238,6 +249,7 @@ func New(ctx context.Context, opt *Options) (blob.Storage, error) {
 		Options:           *opt,
 		ctx:               ctx,
 		bucket:            bucket,
+		immutability:      immutabilityClient{containerURL: *containerURL, pipeline: pipeline},
 		downloadThrottler: downloadThrottler,
 		uploadThrottler:   uploadThrottler,
 	})
@@ -0,0 +1,302 @@
+package ecc
+
+import (
+	"encoding/binary"
+	"hash/crc32"
+
+	"github.com/pkg/errors"
+)
+
+// Encoded blob layout:
+//
+//   [data][parity shards of all stripes][CRC32 of all shards][footer][footer]
+//
+// The original data is stored verbatim at the beginning of the blob, which allows
+// ranges of the original blob to be fetched directly from the underlying storage.
+// The data is split into stripes of dataShards*shardSize bytes, each protected with
+// parityShards parity shards. The last stripe uses smaller shards, just large enough
+// to hold the remaining data. The footer is stored twice, so that a corruption of one copy
+// does not make the entire blob unreadable.
+
+const (
+	footerMagic   = "KECC"
+	footerVersion = 1
+	footerLength  = 24
+	crcLength     = 4
+
+	// trailerLength is the length of both copies of the footer.
+	trailerLength = 2 * footerLength
+)
+
+// layout describes the position of data, parity and checksums in an encoded blob.
+type layout struct {
+	length       int64
+	dataShards   int
+	parityShards int
+	shardSize    int
+}
+
+func (l layout) stripeLength() int64 {
+	return int64(l.dataShards) * int64(l.shardSize)
+}
+
+func (l layout) stripeCount() int {
+	return int((l.length + l.stripeLength() - 1) / l.stripeLength())
+}
+
+// stripeShardSize returns the shard size of the provided stripe.
+func (l layout) stripeShardSize(stripe int) int {
+	if remaining := l.length - int64(stripe)*l.stripeLength(); remaining < l.stripeLength() {
+		return int((remaining + int64(l.dataShards) - 1) / int64(l.dataShards))
+	}
+
+	return l.shardSize
+}
+
+// stripeRange returns the range of data bytes [start,end) covered by the provided stripe.
+func (l layout) stripeRange(stripe int) (start, end int64) {
+	start = int64(stripe) * l.stripeLength()
+	end = start + l.stripeLength()
+
+	if end > l.length {
+		end = l.length
+	}
+
+	return start, end
+}
+
+// shardRange returns the range of data bytes [start,end) covered by the provided data shard.
+func (l layout) shardRange(stripe, shard int) (start, end int64) {
+	stripeStart, stripeEnd := l.stripeRange(stripe)
+	ss := int64(l.stripeShardSize(stripe))
+
+	start = stripeStart + int64(shard)*ss
+	end = start + ss
+
+	if start > stripeEnd {
+		start = stripeEnd
+	}
+
+	if end > stripeEnd {
+		end = stripeEnd
+	}
+
+	return start, end
+}
+
+// parityOffset returns the offset of the first parity shard of the provided stripe.
+func (l layout) parityOffset(stripe int) int64 {
+	return l.length + int64(stripe)*int64(l.parityShards)*int64(l.shardSize)
+}
+
+func (l layout) crcOffset() int64 {
+	n := l.stripeCount()
+	if n == 0 {
+		return l.length
+	}
+
+	return l.parityOffset(n-1) + int64(l.parityShards)*int64(l.stripeShardSize(n-1))
+}
+
+// crcIndex returns the index of the checksum of the provided shard in the checksum table.
+func (l layout) crcIndex(stripe, shard int) int {
+	return stripe*(l.dataShards+l.parityShards) + shard
+}
+
+func (l layout) crcTableLength() int64 {
+	return int64(l.stripeCount()) * int64(l.dataShards+l.parityShards) * crcLength
+}
+
+func (l layout) encodedLength() int64 {
+	return l.crcOffset() + l.crcTableLength() + trailerLength
+}
+
+func (l layout) appendFooter(b []byte) []byte {
+	var f [footerLength]byte
+
+	copy(f[0:4], footerMagic)
+	f[4] = footerVersion
+	f[5] = byte(l.dataShards)
+	f[6] = byte(l.parityShards)
+	binary.BigEndian.PutUint32(f[8:12], uint32(l.shardSize))
+	binary.BigEndian.PutUint64(f[12:20], uint64(l.length))
+	binary.BigEndian.PutUint32(f[20:24], crc32.ChecksumIEEE(f[0:20]))
+
+	return append(b, f[:]...)
+}
+
+func parseFooter(f []byte) (layout, bool) {
+	if len(f) != footerLength || string(f[0:4]) != footerMagic || f[4] != footerVersion {
+		return layout{}, false
+	}
+
+	if binary.BigEndian.Uint32(f[20:24]) != crc32.ChecksumIEEE(f[0:20]) {
+		return layout{}, false
+	}
+
+	l := layout{
+		dataShards:   int(f[5]),
+		parityShards: int(f[6]),
+		shardSize:    int(binary.BigEndian.Uint32(f[8:12])),
+		length:       int64(binary.BigEndian.Uint64(f[12:20])),
+	}
+
+	if l.dataShards == 0 || l.parityShards == 0 || l.shardSize == 0 || l.length < 0 || l.dataShards+l.parityShards > maxTotalShards {
+		return layout{}, false
+	}
+
+	return l, true
+}
+
+// parseTrailer returns the layout stored in the provided blob trailer, which must be the last trailerLength bytes
+// of the blob. Returns false if the blob was not encoded.
+func parseTrailer(trailer []byte, blobLength int64) (layout, bool) {
+	if len(trailer) != trailerLength {
+		return layout{}, false
+	}
+
+	for _, f := range [][]byte{trailer[footerLength:], trailer[0:footerLength]} {
+		if l, ok := parseFooter(f); ok && l.length <= blobLength && l.encodedLength() == blobLength {
+			return l, true
+		}
+	}
+
+	return layout{}, false
+}
+
+// encode returns the data with appended parity shards, checksums and footer.
+func encode(data []byte, opt *Options) ([]byte, error) {
+	l := layout{
+		length:       int64(len(data)),
+		dataShards:   opt.DataShards,
+		parityShards: opt.ParityShards,
+		shardSize:    opt.ShardSize,
+	}
+
+	rs, err := newReedSolomon(l.dataShards, l.parityShards)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, l.encodedLength()-trailerLength, l.encodedLength())
+	copy(out, data)
+
+	shards := make([][]byte, l.dataShards+l.parityShards)
+
+	for stripe, n := 0, l.stripeCount(); stripe < n; stripe++ {
+		ss := l.stripeShardSize(stripe)
+		po := l.parityOffset(stripe)
+
+		for i := range shards {
+			if i < l.dataShards {
+				shards[i] = paddedShard(data, l, stripe, i)
+			} else {
+				shards[i] = out[po : po+int64(ss)]
+				po += int64(ss)
+			}
+		}
+
+		rs.encode(shards)
+
+		for i, s := range shards {
+			binary.BigEndian.PutUint32(out[l.crcOffset()+int64(l.crcIndex(stripe, i))*crcLength:], crc32.ChecksumIEEE(s))
+		}
+	}
+
+	out = l.appendFooter(out)
+	out = l.appendFooter(out)
+
+	return out, nil
+}
+
+// paddedShard returns the contents of a data shard padded with zeroes to the stripe shard size.
+func paddedShard(data []byte, l layout, stripe, shard int) []byte {
+	start, end := l.shardRange(stripe, shard)
+
+	if s := data[start:end]; len(s) == l.stripeShardSize(stripe) {
+		return s
+	}
+
+	b := make([]byte, l.stripeShardSize(stripe))
+	copy(b, data[start:end])
+
+	return b
+}
+
+// decode verifies the checksums of all shards of the encoded blob and returns the original data, repairing
+// any corrupted shards. Returns the number of shards that were found to be corrupted.
+// When the blob was not encoded, it is returned as-is.
+func decode(b []byte) (data []byte, corrupted int, err error) {
+	if len(b) < trailerLength {
+		return b, 0, nil
+	}
+
+	l, ok := parseTrailer(b[len(b)-trailerLength:], int64(len(b)))
+	if !ok {
+		return b, 0, nil
+	}
+
+	rs, err := newReedSolomon(l.dataShards, l.parityShards)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	data = b[0:l.length]
+	crcs := b[l.crcOffset() : l.crcOffset()+l.crcTableLength()]
+	shards := make([][]byte, l.dataShards+l.parityShards)
+	present := make([]bool, len(shards))
+
+	for stripe, n := 0, l.stripeCount(); stripe < n; stripe++ {
+		ss := l.stripeShardSize(stripe)
+		po := l.parityOffset(stripe)
+		bad := 0
+
+		for i := range shards {
+			if i < l.dataShards {
+				shards[i] = paddedShard(data, l, stripe, i)
+			} else {
+				shards[i] = b[po : po+int64(ss)]
+				po += int64(ss)
+			}
+
+			present[i] = crc32.ChecksumIEEE(shards[i]) == binary.BigEndian.Uint32(crcs[l.crcIndex(stripe, i)*crcLength:])
+			if !present[i] {
+				bad++
+			}
+		}
+
+		if bad == 0 {
+			continue
+		}
+
+		if bad > l.parityShards {
+			return nil, corrupted + bad, errors.Errorf("unable to repair stripe %v, %v shards are corrupted, at most %v can be repaired", stripe, bad, l.parityShards)
+		}
+
+		if corrupted == 0 {
+			// do not modify the buffer provided by the caller.
+			data = append([]byte(nil), data...)
+		}
+
+		for i := range shards {
+			if !present[i] {
+				shards[i] = make([]byte, ss)
+			}
+		}
+
+		if err := rs.reconstruct(shards, present); err != nil {
+			return nil, corrupted + bad, errors.Wrapf(err, "unable to repair stripe %v", stripe)
+		}
+
+		for i := 0; i < l.dataShards; i++ {
+			if !present[i] {
+				start, end := l.shardRange(stripe, i)
+				copy(data[start:end], shards[i])
+			}
+		}
+
+		corrupted += bad
+	}
+
+	return data, corrupted, nil
+}
@@ -0,0 +1,425 @@
+// Package ecc implements wrapper around Storage that protects blobs with Reed-Solomon error correction codes.
+package ecc
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"hash/crc32"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/kopia/kopia/internal/gather"
+	"github.com/kopia/kopia/repo/blob"
+	"github.com/kopia/kopia/repo/logging"
+)
+
+var log = logging.GetContextLoggerFunc("ecc")
+
+const (
+	// DefaultDataShards is the default number of data shards in each stripe.
+	DefaultDataShards = 16
+
+	// DefaultParityShards is the default number of parity shards in each stripe.
+	DefaultParityShards = 2
+
+	// DefaultShardSize is the default size of each shard.
+	DefaultShardSize = 4096
+
+	maxShardSize = 16 << 20
+
+	// maximum number of blobs for which layout information is cached.
+	maxCachedLayouts = 1000
+)
+
+// ErrNotEnabled is returned when attempting to repair blobs in a storage that's not protected with error correction.
+var ErrNotEnabled = errors.Errorf("error correction is not enabled")
+
+// Options specifies the parameters of error correction.
+type Options struct {
+	DataShards   int `json:"dataShards"`
+	ParityShards int `json:"parityShards"`
+	ShardSize    int `json:"shardSize"`
+}
+
+// ApplyDefaults returns a copy of Options with defaults filled out.
+func (o Options) ApplyDefaults() Options {
+	if o.DataShards == 0 {
+		o.DataShards = DefaultDataShards
+	}
+
+	if o.ParityShards == 0 {
+		o.ParityShards = DefaultParityShards
+	}
+
+	if o.ShardSize == 0 {
+		o.ShardSize = DefaultShardSize
+	}
+
+	return o
+}
+
+// Validate returns an error if the options are invalid.
+func (o *Options) Validate() error {
+	if o.DataShards <= 0 || o.ParityShards <= 0 {
+		return errors.Errorf("number of data and parity shards must be positive")
+	}
+
+	if o.DataShards+o.ParityShards > maxTotalShards {
+		return errors.Errorf("total number of shards must not exceed %v", maxTotalShards)
+	}
+
+	if o.ShardSize <= 0 || o.ShardSize > maxShardSize {
+		return errors.Errorf("shard size must be between 1 and %v", maxShardSize)
+	}
+
+	return nil
+}
+
+// blobLayout is the cached information about the encoding of a single blob.
+type blobLayout struct {
+	encoded bool
+	layout  layout
+	crcs    []byte
+}
+
+// eccStorage adds Reed-Solomon parity shards to all blobs except the excluded ones.
+type eccStorage struct {
+	base    blob.Storage
+	opt     Options
+	exclude []blob.ID
+
+	mu      sync.Mutex
+	layouts map[blob.ID]*blobLayout
+}
+
+func (s *eccStorage) isExcluded(id blob.ID) bool {
+	for _, e := range s.exclude {
+		if id == e {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *eccStorage) GetBlob(ctx context.Context, id blob.ID, offset, length int64) ([]byte, error) {
+	if s.isExcluded(id) {
+		return s.base.GetBlob(ctx, id, offset, length)
+	}
+
+	if length < 0 {
+		data, _, err := s.getAndDecode(ctx, id)
+
+		return data, err
+	}
+
+	bl, err := s.getLayout(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !bl.encoded {
+		return s.base.GetBlob(ctx, id, offset, length)
+	}
+
+	l := bl.layout
+
+	if offset < 0 || offset+length > l.length {
+		return nil, errors.Wrapf(blob.ErrInvalidRange, "invalid range %v+%v of blob %v with length %v", offset, length, id, l.length)
+	}
+
+	if length == 0 {
+		return []byte{}, nil
+	}
+
+	firstStripe, firstShard := l.shardForOffset(offset)
+	lastStripe, lastShard := l.shardForOffset(offset + length - 1)
+	start, _ := l.shardRange(firstStripe, firstShard)
+	_, end := l.shardRange(lastStripe, lastShard)
+
+	b, err := s.base.GetBlob(ctx, id, start, end-start)
+	if err != nil {
+		// nolint:wrapcheck
+		return nil, err
+	}
+
+	if bl.verifyRange(b, start, firstStripe, firstShard, lastStripe, lastShard) {
+		return b[offset-start : offset-start+length], nil
+	}
+
+	log(ctx).Infof("detected corruption in blob %v, attempting to repair", id)
+
+	// some of the shards are corrupted, fall back to decoding the entire blob.
+	data, _, err := s.getAndDecode(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return data[offset : offset+length], nil
+}
+
+// getAndDecode fetches the entire blob and returns its decoded contents along with the number of corrupted shards.
+func (s *eccStorage) getAndDecode(ctx context.Context, id blob.ID) ([]byte, int, error) {
+	b, err := s.base.GetBlob(ctx, id, 0, -1)
+	if err != nil {
+		// nolint:wrapcheck
+		return nil, 0, err
+	}
+
+	data, corrupted, err := decode(b)
+	if err != nil {
+		return nil, corrupted, errors.Wrapf(err, "unable to decode blob %v", id)
+	}
+
+	if corrupted > 0 {
+		log(ctx).Infof("repaired %v corrupted shards of blob %v", corrupted, id)
+	}
+
+	return data, corrupted, nil
+}
+
+// getLayout returns the layout of the provided blob, reading it from the blob trailer if not cached.
+func (s *eccStorage) getLayout(ctx context.Context, id blob.ID) (*blobLayout, error) {
+	s.mu.Lock()
+	bl := s.layouts[id]
+	s.mu.Unlock()
+
+	if bl != nil {
+		return bl, nil
+	}
+
+	bm, err := s.base.GetMetadata(ctx, id)
+	if err != nil {
+		// nolint:wrapcheck
+		return nil, err
+	}
+
+	bl = &blobLayout{}
+
+	if bm.Length >= trailerLength {
+		trailer, err := s.base.GetBlob(ctx, id, bm.Length-trailerLength, trailerLength)
+		if err != nil {
+			// nolint:wrapcheck
+			return nil, err
+		}
+
+		bl.layout, bl.encoded = parseTrailer(trailer, bm.Length)
+	}
+
+	if bl.encoded {
+		bl.crcs, err = s.base.GetBlob(ctx, id, bl.layout.crcOffset(), bl.layout.crcTableLength())
+		if err != nil {
+			// nolint:wrapcheck
+			return nil, err
+		}
+	}
+
+	s.mu.Lock()
+	if len(s.layouts) >= maxCachedLayouts {
+		s.layouts = map[blob.ID]*blobLayout{}
+	}
+	s.layouts[id] = bl
+	s.mu.Unlock()
+
+	return bl, nil
+}
+
+func (s *eccStorage) invalidateLayout(id blob.ID) {
+	s.mu.Lock()
+	delete(s.layouts, id)
+	s.mu.Unlock()
+}
+
+func (s *eccStorage) GetMetadata(ctx context.Context, id blob.ID) (blob.Metadata, error) {
+	return s.base.GetMetadata(ctx, id)
+}
+
+func (s *eccStorage) SetTime(ctx context.Context, id blob.ID, t time.Time) error {
+	return s.base.SetTime(ctx, id, t)
+}
+
+// encodeBlob returns the data to be written to the underlying storage for the provided blob.
+func (s *eccStorage) encodeBlob(id blob.ID, data blob.Bytes) (blob.Bytes, error) {
+	if s.isExcluded(id) {
+		return data, nil
+	}
+
+	var tmp bytes.Buffer
+
+	if _, err := data.WriteTo(&tmp); err != nil {
+		return nil, errors.Wrap(err, "error reading blob data")
+	}
+
+	encoded, err := encode(tmp.Bytes(), &s.opt)
+	if err != nil {
+		return nil, errors.Wrap(err, "error encoding blob")
+	}
+
+	s.invalidateLayout(id)
+
+	return gather.FromSlice(encoded), nil
+}
+
+func (s *eccStorage) PutBlob(ctx context.Context, id blob.ID, data blob.Bytes) error {
+	encoded, err := s.encodeBlob(id, data)
+	if err != nil {
+		return err
+	}
+
+	return s.base.PutBlob(ctx, id, encoded)
+}
+
+func (s *eccStorage) PutBlobWithRetention(ctx context.Context, id blob.ID, data blob.Bytes, mode blob.RetentionMode, retainUntil time.Time) error {
+	encoded, err := s.encodeBlob(id, data)
+	if err != nil {
+		return err
+	}
+
+	return blob.PutBlobWithRetention(ctx, s.base, id, encoded, mode, retainUntil)
+}
+
+func (s *eccStorage) ExtendBlobRetention(ctx context.Context, id blob.ID, mode blob.RetentionMode, retainUntil time.Time) error {
+	return blob.ExtendBlobRetention(ctx, s.base, id, mode, retainUntil)
+}
+
+func (s *eccStorage) GetBlobRetention(ctx context.Context, id blob.ID) (time.Time, error) {
+	return blob.GetBlobRetention(ctx, s.base, id)
+}
+
+func (s *eccStorage) DeleteBlob(ctx context.Context, id blob.ID) error {
+	s.invalidateLayout(id)
+
+	return s.base.DeleteBlob(ctx, id)
+}
+
+func (s *eccStorage) ListBlobs(ctx context.Context, prefix blob.ID, callback func(blob.Metadata) error) error {
+	return s.base.ListBlobs(ctx, prefix, callback)
+}
+
+func (s *eccStorage) Close(ctx context.Context) error {
+	return s.base.Close(ctx)
+}
+
+func (s *eccStorage) ConnectionInfo() blob.ConnectionInfo {
+	return s.base.ConnectionInfo()
+}
+
+func (s *eccStorage) DisplayName() string {
+	return s.base.DisplayName()
+}
+
+// shardForOffset returns the stripe and shard containing the provided data offset.
+func (l layout) shardForOffset(offset int64) (stripe, shard int) {
+	stripe = int(offset / l.stripeLength())
+	stripeStart, _ := l.stripeRange(stripe)
+
+	return stripe, int((offset - stripeStart) / int64(l.stripeShardSize(stripe)))
+}
+
+// verifyRange verifies checksums of all data shards between the provided ones (inclusive),
+// whose contents were read into b starting at the provided data offset.
+func (bl *blobLayout) verifyRange(b []byte, offset int64, firstStripe, firstShard, lastStripe, lastShard int) bool {
+	l := bl.layout
+
+	for stripe := firstStripe; stripe <= lastStripe; stripe++ {
+		shard0, shard1 := 0, l.dataShards-1
+
+		if stripe == firstStripe {
+			shard0 = firstShard
+		}
+
+		if stripe == lastStripe {
+			shard1 = lastShard
+		}
+
+		for shard := shard0; shard <= shard1; shard++ {
+			start, end := l.shardRange(stripe, shard)
+			ss := l.stripeShardSize(stripe)
+
+			h := crc32.NewIEEE()
+			h.Write(b[start-offset : end-offset]) //nolint:errcheck
+
+			if pad := ss - int(end-start); pad > 0 {
+				h.Write(make([]byte, pad)) //nolint:errcheck
+			}
+
+			if h.Sum32() != binary.BigEndian.Uint32(bl.crcs[l.crcIndex(stripe, shard)*crcLength:]) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// Repair reads the provided blob and, if any of its shards are found to be corrupted,
+// rewrites it with the repaired contents. Returns true if the blob has been rewritten.
+func Repair(ctx context.Context, st blob.Storage, id blob.ID) (bool, error) {
+	s, ok := st.(*eccStorage)
+	if !ok {
+		return false, ErrNotEnabled
+	}
+
+	b, err := s.base.GetBlob(ctx, id, 0, -1)
+	if err != nil {
+		return false, errors.Wrapf(err, "error reading blob %v", id)
+	}
+
+	if len(b) < trailerLength {
+		return false, nil
+	}
+
+	l, ok := parseTrailer(b[len(b)-trailerLength:], int64(len(b)))
+	if !ok {
+		return false, nil
+	}
+
+	data, corrupted, err := decode(b)
+	if err != nil {
+		return false, errors.Wrapf(err, "unable to repair blob %v", id)
+	}
+
+	if corrupted == 0 {
+		return false, nil
+	}
+
+	// re-encode using the parameters originally used for the blob.
+	encoded, err := encode(data, &Options{
+		DataShards:   l.dataShards,
+		ParityShards: l.parityShards,
+		ShardSize:    l.shardSize,
+	})
+	if err != nil {
+		return false, errors.Wrap(err, "error encoding blob")
+	}
+
+	s.invalidateLayout(id)
+
+	if err := s.base.PutBlob(ctx, id, gather.FromSlice(encoded)); err != nil {
+		return false, errors.Wrapf(err, "error writing repaired blob %v", id)
+	}
+
+	log(ctx).Infof("repaired blob %v (%v corrupted shards)", id, corrupted)
+
+	return true, nil
+}
+
+// NewWrapper returns a Storage wrapper that protects all blobs written to the underlying storage
+// with Reed-Solomon parity shards and transparently repairs them when reading.
+// Blobs with provided IDs are excluded and passed through to the underlying storage unchanged.
+// Blobs that were not written by the wrapper are also returned unchanged.
+func NewWrapper(wrapped blob.Storage, opt Options, exclude ...blob.ID) (blob.Storage, error) {
+	if err := opt.Validate(); err != nil {
+		return nil, errors.Wrap(err, "invalid error correction options")
+	}
+
+	return &eccStorage{
+		base:    wrapped,
+		opt:     opt,
+		exclude: exclude,
+		layouts: map[blob.ID]*blobLayout{},
+	}, nil
+}
@@ -0,0 +1,198 @@
+package ecc
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kopia/kopia/internal/blobtesting"
+	"github.com/kopia/kopia/internal/gather"
+	"github.com/kopia/kopia/internal/testlogging"
+	"github.com/kopia/kopia/repo/blob"
+)
+
+var testOptions = Options{
+	DataShards:   4,
+	ParityShards: 2,
+	ShardSize:    64,
+}
+
+func TestECCStorage(t *testing.T) {
+	ctx := testlogging.Context(t)
+
+	data := blobtesting.DataMap{}
+	st, err := NewWrapper(blobtesting.NewMapStorage(data, nil, nil), testOptions, "excluded")
+	require.NoError(t, err)
+
+	blobtesting.VerifyStorage(ctx, t, st)
+	require.NoError(t, st.Close(ctx))
+}
+
+func TestECCStorage_RoundTrip(t *testing.T) {
+	ctx := testlogging.Context(t)
+
+	data := blobtesting.DataMap{}
+	st, err := NewWrapper(blobtesting.NewMapStorage(data, nil, nil), testOptions, "excluded")
+	require.NoError(t, err)
+
+	for _, size := range []int{0, 1, 63, 64, 255, 256, 257, 1000, 4096, 10000} {
+		payload := randomBytes(size)
+
+		require.NoError(t, st.PutBlob(ctx, "blob", gather.FromSlice(payload)))
+		require.Greater(t, len(data["blob"]), size)
+		require.Equal(t, payload, data["blob"][0:size], "data must be stored verbatim")
+
+		got, err := st.GetBlob(ctx, "blob", 0, -1)
+		require.NoError(t, err)
+		require.Equal(t, payload, got)
+
+		for _, r := range [][2]int{{0, size}, {0, size / 2}, {size / 3, size / 3}, {size - size/5, size / 5}} {
+			got, err := st.GetBlob(ctx, "blob", int64(r[0]), int64(r[1]))
+			require.NoError(t, err)
+			require.Equal(t, payload[r[0]:r[0]+r[1]], got, "size %v range %v", size, r)
+		}
+
+		_, err = st.GetBlob(ctx, "blob", int64(size), 1)
+		require.True(t, errors.Is(err, blob.ErrInvalidRange), "unexpected error %v", err)
+	}
+
+	// excluded blobs are stored unchanged.
+	require.NoError(t, st.PutBlob(ctx, "excluded", gather.FromSlice([]byte{1, 2, 3})))
+	require.Equal(t, []byte{1, 2, 3}, data["excluded"])
+
+	// blobs not written by the wrapper are returned unchanged.
+	data["plain"] = []byte("some plain data that was written without error correction")
+	got, err := st.GetBlob(ctx, "plain", 0, -1)
+	require.NoError(t, err)
+	require.Equal(t, data["plain"], got)
+
+	got, err = st.GetBlob(ctx, "plain", 5, 5)
+	require.NoError(t, err)
+	require.Equal(t, data["plain"][5:10], got)
+}
+
+func TestECCStorage_Repair(t *testing.T) {
+	ctx := testlogging.Context(t)
+
+	data := blobtesting.DataMap{}
+	st, err := NewWrapper(blobtesting.NewMapStorage(data, nil, nil), testOptions)
+	require.NoError(t, err)
+
+	payload := randomBytes(5000)
+	require.NoError(t, st.PutBlob(ctx, "blob", gather.FromSlice(payload)))
+
+	original := append([]byte(nil), data["blob"]...)
+
+	// nothing to repair.
+	repaired, err := Repair(ctx, st, "blob")
+	require.NoError(t, err)
+	require.False(t, repaired)
+
+	// corrupt one byte in each stripe, plus parity, checksum and one copy of the footer.
+	for _, off := range []int{0, 300, 600, 4999, 5001, len(original) - 100, len(original) - 1} {
+		data["blob"][off] ^= 0xff
+	}
+
+	got, err := st.GetBlob(ctx, "blob", 0, -1)
+	require.NoError(t, err)
+	require.Equal(t, payload, got)
+
+	got, err = st.GetBlob(ctx, "blob", 290, 20)
+	require.NoError(t, err)
+	require.Equal(t, payload[290:310], got)
+
+	repaired, err = Repair(ctx, st, "blob")
+	require.NoError(t, err)
+	require.True(t, repaired)
+	require.Equal(t, original, data["blob"])
+
+	_, err = Repair(ctx, blobtesting.NewMapStorage(data, nil, nil), "blob")
+	require.ErrorIs(t, err, ErrNotEnabled)
+}
+
+func TestECCStorage_TooManyErrors(t *testing.T) {
+	ctx := testlogging.Context(t)
+
+	data := blobtesting.DataMap{}
+	st, err := NewWrapper(blobtesting.NewMapStorage(data, nil, nil), testOptions)
+	require.NoError(t, err)
+
+	payload := randomBytes(1000)
+	require.NoError(t, st.PutBlob(ctx, "blob", gather.FromSlice(payload)))
+
+	// corrupt 3 shards of the first stripe, which is more than parity can repair.
+	for _, off := range []int{0, 64, 128} {
+		data["blob"][off] ^= 0xff
+	}
+
+	_, err = st.GetBlob(ctx, "blob", 0, -1)
+	require.Error(t, err)
+
+	_, err = st.GetBlob(ctx, "blob", 10, 10)
+	require.Error(t, err)
+
+	// other stripes are still readable.
+	got, err := st.GetBlob(ctx, "blob", 300, 10)
+	require.NoError(t, err)
+	require.Equal(t, payload[300:310], got)
+
+	_, err = Repair(ctx, st, "blob")
+	require.Error(t, err)
+}
+
+func TestOptionsValidate(t *testing.T) {
+	o := Options{}.ApplyDefaults()
+	require.NoError(t, o.Validate())
+	require.Error(t, (&Options{DataShards: 1, ParityShards: 0, ShardSize: 1}).Validate())
+	require.Error(t, (&Options{DataShards: 200, ParityShards: 57, ShardSize: 1}).Validate())
+	require.Error(t, (&Options{DataShards: 1, ParityShards: 1}).Validate())
+}
+
+func TestReedSolomon(t *testing.T) {
+	for _, tc := range [][2]int{{1, 1}, {4, 2}, {10, 4}, {16, 2}, {200, 56}} {
+		rs, err := newReedSolomon(tc[0], tc[1])
+		require.NoError(t, err)
+
+		shards := make([][]byte, tc[0]+tc[1])
+		for i := range shards {
+			shards[i] = make([]byte, 32)
+			if i < tc[0] {
+				rand.Read(shards[i]) //nolint:errcheck,gosec
+			}
+		}
+
+		rs.encode(shards)
+
+		original := make([][]byte, len(shards))
+		for i := range shards {
+			original[i] = append([]byte(nil), shards[i]...)
+		}
+
+		// remove parityShards random shards and reconstruct.
+		present := make([]bool, len(shards))
+		for i := range present {
+			present[i] = true
+		}
+
+		for _, i := range rand.Perm(len(shards))[0:tc[1]] { //nolint:gosec
+			present[i] = false
+			shards[i] = make([]byte, 32)
+		}
+
+		require.NoError(t, rs.reconstruct(shards, present))
+
+		for i := range shards {
+			require.True(t, bytes.Equal(original[i], shards[i]), "shard %v", i)
+		}
+	}
+}
+
+func randomBytes(n int) []byte {
+	b := make([]byte, n)
+	rand.Read(b) //nolint:errcheck,gosec
+
+	return b
+}
@@ -0,0 +1,109 @@
+package ecc
+
+import "github.com/pkg/errors"
+
+// primitive polynomial x^8 + x^4 + x^3 + x^2 + 1 used to generate GF(2^8).
+const gfPolynomial = 0x11d
+
+var (
+	gfExp [512]byte
+	gfLog [256]byte
+)
+
+func init() {
+	x := 1
+
+	for i := 0; i < 255; i++ {
+		gfExp[i] = byte(x)
+		gfLog[x] = byte(i)
+
+		x <<= 1
+		if x&0x100 != 0 {
+			x ^= gfPolynomial
+		}
+	}
+
+	// duplicate the table so that gfMul() does not need to reduce modulo 255.
+	for i := 255; i < len(gfExp); i++ {
+		gfExp[i] = gfExp[i-255]
+	}
+}
+
+func gfMul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+
+	return gfExp[int(gfLog[a])+int(gfLog[b])]
+}
+
+func gfInv(a byte) byte {
+	return gfExp[255-int(gfLog[a])]
+}
+
+// gfMulAdd computes out[i] ^= c * in[i] for all elements of the input.
+func gfMulAdd(c byte, in, out []byte) {
+	if c == 0 {
+		return
+	}
+
+	logc := int(gfLog[c])
+
+	for i, v := range in {
+		if v != 0 {
+			out[i] ^= gfExp[logc+int(gfLog[v])]
+		}
+	}
+}
+
+// gfInvertMatrix inverts the provided square matrix in place using Gauss-Jordan elimination.
+func gfInvertMatrix(m [][]byte) error {
+	n := len(m)
+
+	inv := make([][]byte, n)
+	for i := range inv {
+		inv[i] = make([]byte, n)
+		inv[i][i] = 1
+	}
+
+	for col := 0; col < n; col++ {
+		pivot := -1
+
+		for row := col; row < n; row++ {
+			if m[row][col] != 0 {
+				pivot = row
+				break
+			}
+		}
+
+		if pivot < 0 {
+			return errors.Errorf("matrix is singular")
+		}
+
+		m[col], m[pivot] = m[pivot], m[col]
+		inv[col], inv[pivot] = inv[pivot], inv[col]
+
+		if c := m[col][col]; c != 1 {
+			ic := gfInv(c)
+
+			for j := 0; j < n; j++ {
+				m[col][j] = gfMul(m[col][j], ic)
+				inv[col][j] = gfMul(inv[col][j], ic)
+			}
+		}
+
+		for row := 0; row < n; row++ {
+			if row == col || m[row][col] == 0 {
+				continue
+			}
+
+			c := m[row][col]
+			gfMulAdd(c, m[col], m[row])
+			gfMulAdd(c, inv[col], inv[row])
+		}
+	}
+
+	copy(m, inv)
+
+	return nil
+}
@@ -0,0 +1,116 @@
+package ecc
+
+import "github.com/pkg/errors"
+
+// maxTotalShards is the maximum number of data and parity shards supported by GF(2^8).
+const maxTotalShards = 256
+
+// reedSolomon implements systematic Reed-Solomon erasure coding over GF(2^8) using Cauchy matrix,
+// which guarantees that any dataShards out of (dataShards+parityShards) shards are sufficient
+// to reconstruct the original data.
+type reedSolomon struct {
+	dataShards   int
+	parityShards int
+
+	// parity[i][j] is the coefficient of data shard j in parity shard i.
+	parity [][]byte
+}
+
+// encode computes parity shards (shards[dataShards:]) from data shards (shards[:dataShards]).
+// All shards must have the same length.
+func (r *reedSolomon) encode(shards [][]byte) {
+	for i, coef := range r.parity {
+		out := shards[r.dataShards+i]
+
+		for j := range out {
+			out[j] = 0
+		}
+
+		for j, c := range coef {
+			gfMulAdd(c, shards[j], out)
+		}
+	}
+}
+
+// reconstruct fills in the contents of shards that are not marked as present, which must
+// be pre-allocated to the same length as other shards.
+func (r *reedSolomon) reconstruct(shards [][]byte, present []bool) error {
+	var rows []int
+
+	for i := range shards {
+		if present[i] && len(rows) < r.dataShards {
+			rows = append(rows, i)
+		}
+	}
+
+	if len(rows) < r.dataShards {
+		return errors.Errorf("too many missing shards, have %v, need %v", len(rows), r.dataShards)
+	}
+
+	m := make([][]byte, r.dataShards)
+
+	for i, row := range rows {
+		if row < r.dataShards {
+			m[i] = make([]byte, r.dataShards)
+			m[i][row] = 1
+		} else {
+			m[i] = append([]byte(nil), r.parity[row-r.dataShards]...)
+		}
+	}
+
+	if err := gfInvertMatrix(m); err != nil {
+		return errors.Wrap(err, "unable to invert decoding matrix")
+	}
+
+	missingParity := false
+
+	for d := range shards {
+		if present[d] {
+			continue
+		}
+
+		if d >= r.dataShards {
+			missingParity = true
+			continue
+		}
+
+		out := shards[d]
+		for j := range out {
+			out[j] = 0
+		}
+
+		for j, row := range rows {
+			gfMulAdd(m[d][j], shards[row], out)
+		}
+	}
+
+	if missingParity {
+		r.encode(shards)
+	}
+
+	return nil
+}
+
+func newReedSolomon(dataShards, parityShards int) (*reedSolomon, error) {
+	if dataShards <= 0 || parityShards <= 0 || dataShards+parityShards > maxTotalShards {
+		return nil, errors.Errorf("invalid number of shards: %v data, %v parity", dataShards, parityShards)
+	}
+
+	r := &reedSolomon{
+		dataShards:   dataShards,
+		parityShards: parityShards,
+	}
+
+	for i := 0; i < parityShards; i++ {
+		row := make([]byte, dataShards)
+
+		for j := range row {
+			// Cauchy matrix element 1/(x_i + y_j) where x_i = dataShards+i, y_j = j are all distinct.
+			row[j] = gfInv(byte((dataShards + i) ^ j))
+		}
+
+		r.parity = append(r.parity, row)
+	}
+
+	return r, nil
+}
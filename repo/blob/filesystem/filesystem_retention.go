@@ -0,0 +1,136 @@
+package filesystem
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/kopia/kopia/internal/atomicfile"
+	"github.com/kopia/kopia/internal/clock"
+	"github.com/kopia/kopia/repo/blob"
+)
+
+// retentionFileSuffix is the suffix of the sidecar file that stores the retention of a blob.
+// Because it does not end with fsStorageChunkSuffix, sidecar files are not reported by ListBlobs().
+const retentionFileSuffix = ".retention"
+
+// blobRetention is the JSON-serialized contents of the retention sidecar file.
+type blobRetention struct {
+	Mode        blob.RetentionMode `json:"mode"`
+	RetainUntil time.Time          `json:"retainUntil"`
+}
+
+func (fs *fsStorage) retentionFile(blobID blob.ID) string {
+	_, path := fs.Storage.GetShardedPathAndFilePath(blobID)
+
+	return path + retentionFileSuffix
+}
+
+func (fs *fsStorage) readRetention(blobID blob.ID) (blobRetention, error) {
+	var r blobRetention
+
+	b, err := ioutil.ReadFile(fs.retentionFile(blobID))
+	if os.IsNotExist(err) {
+		return r, nil
+	}
+
+	if err != nil {
+		return r, errors.Wrap(err, "error reading retention")
+	}
+
+	return r, errors.Wrap(json.Unmarshal(b, &r), "invalid retention")
+}
+
+// ensureNotLocked returns ErrBlobLocked if the blob is currently locked.
+func (fs *fsStorage) ensureNotLocked(blobID blob.ID) error {
+	r, err := fs.readRetention(blobID)
+	if err != nil {
+		return err
+	}
+
+	if r.RetainUntil.After(clock.Now()) {
+		return errors.Wrapf(blob.ErrBlobLocked, "%v is locked in %v mode until %v", blobID, r.Mode, r.RetainUntil)
+	}
+
+	return nil
+}
+
+// PutBlob implements blob.Storage and prevents overwriting of locked blobs.
+func (fs *fsStorage) PutBlob(ctx context.Context, blobID blob.ID, data blob.Bytes) error {
+	if err := fs.ensureNotLocked(blobID); err != nil {
+		return err
+	}
+
+	return fs.Storage.PutBlob(ctx, blobID, data)
+}
+
+// DeleteBlob implements blob.Storage and prevents deletion of locked blobs.
+func (fs *fsStorage) DeleteBlob(ctx context.Context, blobID blob.ID) error {
+	if err := fs.ensureNotLocked(blobID); err != nil {
+		return err
+	}
+
+	if err := fs.Storage.DeleteBlob(ctx, blobID); err != nil {
+		return err
+	}
+
+	if err := os.Remove(fs.retentionFile(blobID)); err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "unable to remove retention")
+	}
+
+	return nil
+}
+
+// PutBlobWithRetention implements blob.RetentionStorage.
+func (fs *fsStorage) PutBlobWithRetention(ctx context.Context, blobID blob.ID, data blob.Bytes, mode blob.RetentionMode, retainUntil time.Time) error {
+	if err := fs.PutBlob(ctx, blobID, data); err != nil {
+		return err
+	}
+
+	return fs.ExtendBlobRetention(ctx, blobID, mode, retainUntil)
+}
+
+// ExtendBlobRetention implements blob.RetentionStorage.
+func (fs *fsStorage) ExtendBlobRetention(ctx context.Context, blobID blob.ID, mode blob.RetentionMode, retainUntil time.Time) error {
+	if !mode.IsValid() {
+		return errors.Errorf("invalid retention mode: %q", mode)
+	}
+
+	if _, err := fs.GetMetadata(ctx, blobID); err != nil {
+		return err
+	}
+
+	r, err := fs.readRetention(blobID)
+	if err != nil {
+		return err
+	}
+
+	if r.RetainUntil.After(retainUntil) {
+		// never shorten the retention.
+		retainUntil = r.RetainUntil
+	}
+
+	if r.Mode == blob.RetentionModeCompliance {
+		// compliance mode can't be relaxed.
+		mode = r.Mode
+	}
+
+	b, err := json.Marshal(blobRetention{mode, retainUntil.UTC()})
+	if err != nil {
+		return errors.Wrap(err, "unable to marshal retention")
+	}
+
+	return errors.Wrap(atomicfile.Write(fs.retentionFile(blobID), bytes.NewReader(b)), "unable to write retention")
+}
+
+// GetBlobRetention implements blob.RetentionStorage.
+func (fs *fsStorage) GetBlobRetention(ctx context.Context, blobID blob.ID) (time.Time, error) {
+	r, err := fs.readRetention(blobID)
+
+	return r.RetainUntil, err
+}
@@ -6,7 +6,10 @@
 	"testing"
 	"time"
 
+	"github.com/stretchr/testify/require"
+
 	"github.com/kopia/kopia/internal/blobtesting"
+	"github.com/kopia/kopia/internal/clock"
 	"github.com/kopia/kopia/internal/gather"
 	"github.com/kopia/kopia/internal/testlogging"
 	"github.com/kopia/kopia/internal/testutil"
@@ -154,3 +157,45 @@ func assertNoError(t *testing.T, err error) {
 		t.Errorf("err: %v", err)
 	}
 }
+
+func TestFileStorageRetention(t *testing.T) {
+	t.Parallel()
+
+	ctx := testlogging.Context(t)
+
+	r, err := New(ctx, &Options{
+		Path: testutil.TempDirectory(t),
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, blob.PutBlobWithRetention(ctx, r, t1, gather.FromSlice([]byte{1}), blob.RetentionModeGovernance, clock.Now().Add(time.Hour)))
+	require.NoError(t, r.PutBlob(ctx, t2, gather.FromSlice([]byte{2})))
+
+	// sidecar files are not listed.
+	blobtesting.AssertListResults(ctx, t, r, "", t2, t1)
+
+	until, err := blob.GetBlobRetention(ctx, r, t1)
+	require.NoError(t, err)
+	require.True(t, until.After(clock.Now()))
+
+	until2, err := blob.GetBlobRetention(ctx, r, t2)
+	require.NoError(t, err)
+	require.True(t, until2.IsZero())
+
+	require.ErrorIs(t, r.DeleteBlob(ctx, t1), blob.ErrBlobLocked)
+	require.ErrorIs(t, r.PutBlob(ctx, t1, gather.FromSlice([]byte{3})), blob.ErrBlobLocked)
+	require.NoError(t, r.DeleteBlob(ctx, t2))
+
+	// retention can't be shortened.
+	require.NoError(t, blob.ExtendBlobRetention(ctx, r, t1, blob.RetentionModeGovernance, clock.Now()))
+	until3, err := blob.GetBlobRetention(ctx, r, t1)
+	require.NoError(t, err)
+	require.Equal(t, until, until3)
+
+	require.Error(t, blob.ExtendBlobRetention(ctx, r, t2, blob.RetentionModeGovernance, clock.Now().Add(time.Hour)))
+
+	// expired retention no longer prevents deletion.
+	require.NoError(t, blob.PutBlobWithRetention(ctx, r, t3, gather.FromSlice([]byte{1}), blob.RetentionModeCompliance, clock.Now().Add(-time.Second)))
+	require.NoError(t, r.DeleteBlob(ctx, t3))
+	blobtesting.AssertListResults(ctx, t, r, "", t1)
+}
@@ -0,0 +1,147 @@
+package gcs
+
+import (
+	"context"
+	"time"
+
+	gcsclient "cloud.google.com/go/storage"
+	"github.com/pkg/errors"
+
+	"github.com/kopia/kopia/internal/clock"
+	"github.com/kopia/kopia/internal/iocopy"
+	"github.com/kopia/kopia/repo/blob"
+)
+
+// retainUntilMetadataKey is the name of object metadata that stores the time until which the object must be retained.
+//
+// GCS does not support per-object retention periods, so the object is instead placed under temporary hold,
+// which prevents it from being overwritten or deleted, and the hold is released when the retention expires
+// and the blob is being deleted. In compliance mode the bucket must additionally have a locked retention policy,
+// which prevents anyone from deleting objects before the retention period of the bucket.
+const retainUntilMetadataKey = "kopia-retain-until"
+
+// ensureCompliancePolicy verifies that the bucket has a locked retention policy.
+func (gcs *gcsStorage) ensureCompliancePolicy(ctx context.Context) error {
+	attrs, err := gcs.bucket.Attrs(ctx)
+	if err != nil {
+		return errors.Wrap(err, "unable to get bucket attributes")
+	}
+
+	if attrs.RetentionPolicy == nil || !attrs.RetentionPolicy.IsLocked {
+		return errors.Errorf("compliance mode requires a locked retention policy on bucket %v", gcs.BucketName)
+	}
+
+	return nil
+}
+
+// PutBlobWithRetention implements blob.RetentionStorage.
+func (gcs *gcsStorage) PutBlobWithRetention(ctx context.Context, b blob.ID, data blob.Bytes, mode blob.RetentionMode, retainUntil time.Time) error {
+	if !mode.IsValid() {
+		return errors.Errorf("invalid retention mode: %q", mode)
+	}
+
+	if mode == blob.RetentionModeCompliance {
+		if err := gcs.ensureCompliancePolicy(ctx); err != nil {
+			return err
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+
+	obj := gcs.bucket.Object(gcs.getObjectNameString(b))
+	writer := obj.NewWriter(ctx)
+	writer.ChunkSize = writerChunkSize
+	writer.ContentType = "application/x-kopia"
+	writer.TemporaryHold = true
+	writer.Metadata = map[string]string{
+		retainUntilMetadataKey: retainUntil.UTC().Format(time.RFC3339),
+	}
+
+	_, err := iocopy.Copy(writer, data.Reader())
+	if err != nil {
+		// cancel context before closing the writer causes it to abandon the upload.
+		cancel()
+
+		_ = writer.Close() // failing already, ignore the error
+
+		return translateError(err)
+	}
+
+	defer cancel()
+
+	// calling close before cancel() causes it to commit the upload.
+	return translateError(writer.Close())
+}
+
+// ExtendBlobRetention implements blob.RetentionStorage.
+func (gcs *gcsStorage) ExtendBlobRetention(ctx context.Context, b blob.ID, mode blob.RetentionMode, retainUntil time.Time) error {
+	if !mode.IsValid() {
+		return errors.Errorf("invalid retention mode: %q", mode)
+	}
+
+	current, err := gcs.GetBlobRetention(ctx, b)
+	if err != nil {
+		return err
+	}
+
+	if !current.Before(retainUntil) {
+		// never shorten the retention.
+		return nil
+	}
+
+	_, err = gcs.bucket.Object(gcs.getObjectNameString(b)).Update(ctx, gcsclient.ObjectAttrsToUpdate{
+		TemporaryHold: true,
+		Metadata: map[string]string{
+			retainUntilMetadataKey: retainUntil.UTC().Format(time.RFC3339),
+		},
+	})
+
+	return translateError(err)
+}
+
+// GetBlobRetention implements blob.RetentionStorage.
+func (gcs *gcsStorage) GetBlobRetention(ctx context.Context, b blob.ID) (time.Time, error) {
+	attrs, err := gcs.bucket.Object(gcs.getObjectNameString(b)).Attrs(ctx)
+	if err != nil {
+		return time.Time{}, errors.Wrap(translateError(err), "Attrs")
+	}
+
+	return retainUntilFromAttrs(attrs), nil
+}
+
+func retainUntilFromAttrs(attrs *gcsclient.ObjectAttrs) time.Time {
+	// objects are also retained by the retention policy of the bucket.
+	result := attrs.RetentionExpirationTime
+
+	if attrs.TemporaryHold {
+		if t, err := time.Parse(time.RFC3339, attrs.Metadata[retainUntilMetadataKey]); err == nil && t.After(result) {
+			result = t
+		}
+	}
+
+	return result
+}
+
+// releaseExpiredHold releases the temporary hold on the provided object if its retention has expired.
+func (gcs *gcsStorage) releaseExpiredHold(ctx context.Context, b blob.ID) error {
+	obj := gcs.bucket.Object(gcs.getObjectNameString(b))
+
+	attrs, err := obj.Attrs(ctx)
+	if err != nil {
+		return translateError(err)
+	}
+
+	if !attrs.TemporaryHold {
+		return nil
+	}
+
+	if until := retainUntilFromAttrs(attrs); until.After(clock.Now()) {
+		return errors.Wrapf(blob.ErrBlobLocked, "%v is locked until %v", b, until)
+	}
+
+	_, err = obj.Update(ctx, gcsclient.ObjectAttrsToUpdate{
+		TemporaryHold: false,
+	})
+
+	return translateError(err)
+}
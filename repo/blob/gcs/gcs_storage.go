@@ -125,7 +125,20 @@ func (gcs *gcsStorage) SetTime(ctx context.Context, b blob.ID, t time.Time) erro
 }
 
 func (gcs *gcsStorage) DeleteBlob(ctx context.Context, b blob.ID) error {
-	err := translateError(gcs.bucket.Object(gcs.getObjectNameString(b)).Delete(gcs.ctx))
+	err := gcs.bucket.Object(gcs.getObjectNameString(b)).Delete(gcs.ctx)
+
+	var ae *googleapi.Error
+
+	if errors.As(err, &ae) && ae.Code == http.StatusForbidden {
+		// the object may be under temporary hold, release it if the retention has expired and try again.
+		if herr := gcs.releaseExpiredHold(ctx, b); herr != nil {
+			return herr
+		}
+
+		err = gcs.bucket.Object(gcs.getObjectNameString(b)).Delete(gcs.ctx)
+	}
+
+	err = translateError(err)
 	if errors.Is(err, blob.ErrBlobNotFound) {
 		return nil
 	}
@@ -53,6 +53,36 @@ func (s *loggingStorage) PutBlob(ctx context.Context, id blob.ID, data blob.Byte
 	return err
 }
 
+func (s *loggingStorage) PutBlobWithRetention(ctx context.Context, id blob.ID, data blob.Bytes, mode blob.RetentionMode, retainUntil time.Time) error {
+	t0 := clock.Now()
+	err := blob.PutBlobWithRetention(ctx, s.base, id, data, mode, retainUntil)
+	dt := clock.Since(t0)
+	s.printf(s.prefix+"PutBlobWithRetention(%q,len=%v,%v,%v)=%#v took %v", id, data.Length(), mode, retainUntil, err, dt)
+
+	// nolint:wrapcheck
+	return err
+}
+
+func (s *loggingStorage) ExtendBlobRetention(ctx context.Context, id blob.ID, mode blob.RetentionMode, retainUntil time.Time) error {
+	t0 := clock.Now()
+	err := blob.ExtendBlobRetention(ctx, s.base, id, mode, retainUntil)
+	dt := clock.Since(t0)
+	s.printf(s.prefix+"ExtendBlobRetention(%q,%v,%v)=%#v took %v", id, mode, retainUntil, err, dt)
+
+	// nolint:wrapcheck
+	return err
+}
+
+func (s *loggingStorage) GetBlobRetention(ctx context.Context, id blob.ID) (time.Time, error) {
+	t0 := clock.Now()
+	result, err := blob.GetBlobRetention(ctx, s.base, id)
+	dt := clock.Since(t0)
+	s.printf(s.prefix+"GetBlobRetention(%q)=(%v, %#v) took %v", id, result, err, dt)
+
+	// nolint:wrapcheck
+	return result, err
+}
+
 func (s *loggingStorage) SetTime(ctx context.Context, id blob.ID, t time.Time) error {
 	t0 := clock.Now()
 	err := s.base.SetTime(ctx, id, t)
@@ -36,6 +36,20 @@ func (s readonlyStorage) PutBlob(ctx context.Context, id blob.ID, data blob.Byte
 	return ErrReadonly
 }
 
+func (s readonlyStorage) PutBlobWithRetention(ctx context.Context, id blob.ID, data blob.Bytes, mode blob.RetentionMode, retainUntil time.Time) error {
+	// nolint:wrapcheck
+	return ErrReadonly
+}
+
+func (s readonlyStorage) ExtendBlobRetention(ctx context.Context, id blob.ID, mode blob.RetentionMode, retainUntil time.Time) error {
+	// nolint:wrapcheck
+	return ErrReadonly
+}
+
+func (s readonlyStorage) GetBlobRetention(ctx context.Context, id blob.ID) (time.Time, error) {
+	return blob.GetBlobRetention(ctx, s.base, id)
+}
+
 func (s readonlyStorage) DeleteBlob(ctx context.Context, id blob.ID) error {
 	// nolint:wrapcheck
 	return ErrReadonly
@@ -0,0 +1,76 @@
+package blob
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// RetentionMode specifies the mode of immutable blob retention (also known as object lock).
+type RetentionMode string
+
+// Supported retention modes.
+const (
+	// RetentionModeGovernance prevents locked blobs from being overwritten or deleted, except by users with special permissions.
+	RetentionModeGovernance RetentionMode = "governance"
+
+	// RetentionModeCompliance prevents locked blobs from being overwritten or deleted by anyone until the retention expires.
+	RetentionModeCompliance RetentionMode = "compliance"
+)
+
+// IsValid returns true if the retention mode is supported.
+func (m RetentionMode) IsValid() bool {
+	return m == RetentionModeGovernance || m == RetentionModeCompliance
+}
+
+// ErrRetentionUnsupported is returned by implementations of Storage that don't support immutable blob retention.
+var ErrRetentionUnsupported = errors.Errorf("blob retention is not supported by the storage")
+
+// ErrBlobLocked is returned when attempting to overwrite or delete a blob that's locked by retention.
+var ErrBlobLocked = errors.Errorf("blob is locked by retention")
+
+// RetentionStorage is implemented by Storage providers that support immutable blob retention.
+type RetentionStorage interface {
+	// PutBlobWithRetention uploads the blob and locks it using the specified mode until the provided time.
+	PutBlobWithRetention(ctx context.Context, blobID ID, data Bytes, mode RetentionMode, retainUntil time.Time) error
+
+	// ExtendBlobRetention locks an existing blob until the provided time. Retention is never shortened.
+	ExtendBlobRetention(ctx context.Context, blobID ID, mode RetentionMode, retainUntil time.Time) error
+
+	// GetBlobRetention returns the time until which the blob is locked or zero time if it's not locked.
+	GetBlobRetention(ctx context.Context, blobID ID) (time.Time, error)
+}
+
+// PutBlobWithRetention uploads the blob to the provided storage and locks it until the provided time,
+// returns ErrRetentionUnsupported if the storage does not support retention.
+func PutBlobWithRetention(ctx context.Context, st Storage, blobID ID, data Bytes, mode RetentionMode, retainUntil time.Time) error {
+	rs, ok := st.(RetentionStorage)
+	if !ok {
+		return ErrRetentionUnsupported
+	}
+
+	return rs.PutBlobWithRetention(ctx, blobID, data, mode, retainUntil)
+}
+
+// ExtendBlobRetention locks the blob in the provided storage until the provided time,
+// returns ErrRetentionUnsupported if the storage does not support retention.
+func ExtendBlobRetention(ctx context.Context, st Storage, blobID ID, mode RetentionMode, retainUntil time.Time) error {
+	rs, ok := st.(RetentionStorage)
+	if !ok {
+		return ErrRetentionUnsupported
+	}
+
+	return rs.ExtendBlobRetention(ctx, blobID, mode, retainUntil)
+}
+
+// GetBlobRetention returns the time until which the blob in the provided storage is locked,
+// returns ErrRetentionUnsupported if the storage does not support retention.
+func GetBlobRetention(ctx context.Context, st Reader, blobID ID) (time.Time, error) {
+	rs, ok := st.(RetentionStorage)
+	if !ok {
+		return time.Time{}, ErrRetentionUnsupported
+	}
+
+	return rs.GetBlobRetention(ctx, blobID)
+}
@@ -54,6 +54,33 @@ func (s retryingStorage) PutBlob(ctx context.Context, id blob.ID, data blob.Byte
 	return err // nolint:wrapcheck
 }
 
+func (s retryingStorage) PutBlobWithRetention(ctx context.Context, id blob.ID, data blob.Bytes, mode blob.RetentionMode, retainUntil time.Time) error {
+	_, err := retry.WithExponentialBackoff(ctx, "PutBlobWithRetention("+string(id)+")", func() (interface{}, error) {
+		return true, blob.PutBlobWithRetention(ctx, s.Storage, id, data, mode, retainUntil)
+	}, isRetriable)
+
+	return err // nolint:wrapcheck
+}
+
+func (s retryingStorage) ExtendBlobRetention(ctx context.Context, id blob.ID, mode blob.RetentionMode, retainUntil time.Time) error {
+	_, err := retry.WithExponentialBackoff(ctx, "ExtendBlobRetention("+string(id)+")", func() (interface{}, error) {
+		return true, blob.ExtendBlobRetention(ctx, s.Storage, id, mode, retainUntil)
+	}, isRetriable)
+
+	return err // nolint:wrapcheck
+}
+
+func (s retryingStorage) GetBlobRetention(ctx context.Context, id blob.ID) (time.Time, error) {
+	v, err := retry.WithExponentialBackoff(ctx, "GetBlobRetention("+string(id)+")", func() (interface{}, error) {
+		return blob.GetBlobRetention(ctx, s.Storage, id)
+	}, isRetriable)
+	if err != nil {
+		return time.Time{}, err // nolint:wrapcheck
+	}
+
+	return v.(time.Time), nil
+}
+
 func (s retryingStorage) DeleteBlob(ctx context.Context, id blob.ID) error {
 	_, err := retry.WithExponentialBackoff(ctx, "DeleteBlob("+string(id)+")", func() (interface{}, error) {
 		return true, s.Storage.DeleteBlob(ctx, id)
@@ -78,6 +105,9 @@ func isRetriable(err error) bool {
 	case errors.Is(err, blob.ErrSetTimeUnsupported):
 		return false
 
+	case errors.Is(err, blob.ErrRetentionUnsupported), errors.Is(err, blob.ErrBlobLocked):
+		return false
+
 	default:
 		return true
 	}
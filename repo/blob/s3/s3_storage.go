@@ -111,15 +111,32 @@ func (s *s3Storage) GetMetadata(ctx context.Context, b blob.ID) (blob.Metadata,
 }
 
 func (s *s3Storage) PutBlob(ctx context.Context, b blob.ID, data blob.Bytes) error {
+	return s.putBlob(ctx, b, data, minio.PutObjectOptions{})
+}
+
+// PutBlobWithRetention implements blob.RetentionStorage.
+func (s *s3Storage) PutBlobWithRetention(ctx context.Context, b blob.ID, data blob.Bytes, mode blob.RetentionMode, retainUntil time.Time) error {
+	m, err := toMinioRetentionMode(mode)
+	if err != nil {
+		return err
+	}
+
+	return s.putBlob(ctx, b, data, minio.PutObjectOptions{
+		Mode:            m,
+		RetainUntilDate: retainUntil.UTC(),
+	})
+}
+
+func (s *s3Storage) putBlob(ctx context.Context, b blob.ID, data blob.Bytes, opt minio.PutObjectOptions) error {
 	throttled, err := s.uploadThrottler.AddReader(ioutil.NopCloser(data.Reader()))
 	if err != nil {
 		return errors.Wrap(err, "AddReader")
 	}
 
-	uploadInfo, err := s.cli.PutObject(ctx, s.BucketName, s.getObjectNameString(b), throttled, int64(data.Length()), minio.PutObjectOptions{
-		ContentType:    "application/x-kopia",
-		SendContentMd5: atomic.LoadInt32(&s.sendMD5) > 0,
-	})
+	opt.ContentType = "application/x-kopia"
+	opt.SendContentMd5 = atomic.LoadInt32(&s.sendMD5) > 0 || opt.Mode != ""
+
+	uploadInfo, err := s.cli.PutObject(ctx, s.BucketName, s.getObjectNameString(b), throttled, int64(data.Length()), opt)
 
 	var er minio.ErrorResponse
 
@@ -132,7 +149,10 @@ func (s *s3Storage) PutBlob(ctx context.Context, b blob.ID, data blob.Bytes) err
 	if errors.Is(err, io.EOF) && uploadInfo.Size == 0 {
 		// special case empty stream
 		_, err = s.cli.PutObject(ctx, s.BucketName, s.getObjectNameString(b), bytes.NewBuffer(nil), 0, minio.PutObjectOptions{
-			ContentType: "application/x-kopia",
+			ContentType:     "application/x-kopia",
+			SendContentMd5:  opt.SendContentMd5,
+			Mode:            opt.Mode,
+			RetainUntilDate: opt.RetainUntilDate,
 		})
 	}
 
@@ -140,6 +160,64 @@ func (s *s3Storage) PutBlob(ctx context.Context, b blob.ID, data blob.Bytes) err
 	return err
 }
 
+// ExtendBlobRetention implements blob.RetentionStorage.
+func (s *s3Storage) ExtendBlobRetention(ctx context.Context, b blob.ID, mode blob.RetentionMode, retainUntil time.Time) error {
+	m, err := toMinioRetentionMode(mode)
+	if err != nil {
+		return err
+	}
+
+	current, err := s.GetBlobRetention(ctx, b)
+	if err != nil {
+		return err
+	}
+
+	if !current.Before(retainUntil) {
+		// never shorten the retention.
+		return nil
+	}
+
+	retainUntil = retainUntil.UTC()
+
+	return translateError(s.cli.PutObjectRetention(ctx, s.BucketName, s.getObjectNameString(b), minio.PutObjectRetentionOptions{
+		Mode:            &m,
+		RetainUntilDate: &retainUntil,
+	}))
+}
+
+// GetBlobRetention implements blob.RetentionStorage.
+func (s *s3Storage) GetBlobRetention(ctx context.Context, b blob.ID) (time.Time, error) {
+	_, retainUntil, err := s.cli.GetObjectRetention(ctx, s.BucketName, s.getObjectNameString(b), "")
+
+	var me minio.ErrorResponse
+
+	if errors.As(err, &me) && me.Code == "NoSuchObjectLockConfiguration" {
+		// object is not locked.
+		return time.Time{}, nil
+	}
+
+	if err != nil {
+		return time.Time{}, errors.Wrap(translateError(err), "GetObjectRetention")
+	}
+
+	if retainUntil == nil {
+		return time.Time{}, nil
+	}
+
+	return *retainUntil, nil
+}
+
+func toMinioRetentionMode(mode blob.RetentionMode) (minio.RetentionMode, error) {
+	switch mode {
+	case blob.RetentionModeGovernance:
+		return minio.Governance, nil
+	case blob.RetentionModeCompliance:
+		return minio.Compliance, nil
+	default:
+		return "", errors.Errorf("invalid retention mode: %q", mode)
+	}
+}
+
 func (s *s3Storage) SetTime(ctx context.Context, b blob.ID, t time.Time) error {
 	return blob.ErrSetTimeUnsupported
 }
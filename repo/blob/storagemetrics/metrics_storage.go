@@ -0,0 +1,188 @@
+// Package storagemetrics implements wrapper around Storage that records latency and error metrics of all operations.
+package storagemetrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+
+	"github.com/kopia/kopia/internal/clock"
+	"github.com/kopia/kopia/repo/blob"
+)
+
+// storage metrics tags.
+var (
+	tagKeyOperation = tag.MustNewKey("operation")
+	tagKeyProvider  = tag.MustNewKey("provider")
+)
+
+// storage metrics.
+var (
+	metricLatency = stats.Float64(
+		"kopia/blob/latency",
+		"Latency of storage operations",
+		stats.UnitMilliseconds,
+	)
+
+	metricErrorCount = stats.Int64(
+		"kopia/blob/error_count",
+		"Number of storage operations that returned an error",
+		stats.UnitDimensionless,
+	)
+)
+
+// latencyBucketsMillis are the bucket boundaries of the storage latency histogram.
+// nolint:gochecknoglobals,gomnd
+var latencyBucketsMillis = []float64{1, 2, 5, 10, 20, 50, 100, 200, 500, 1000, 2000, 5000, 10000, 30000, 60000}
+
+func init() {
+	if err := view.Register(
+		&view.View{
+			Name:        metricLatency.Name(),
+			Description: metricLatency.Description(),
+			Measure:     metricLatency,
+			Aggregation: view.Distribution(latencyBucketsMillis...),
+			TagKeys:     []tag.Key{tagKeyOperation, tagKeyProvider},
+		},
+		&view.View{
+			Name:        metricErrorCount.Name(),
+			Description: metricErrorCount.Description(),
+			Measure:     metricErrorCount,
+			Aggregation: view.Count(),
+			TagKeys:     []tag.Key{tagKeyOperation, tagKeyProvider},
+		},
+	); err != nil {
+		panic("unable to register opencensus views: " + err.Error())
+	}
+}
+
+type metricsStorage struct {
+	base     blob.Storage
+	provider string
+}
+
+func (s *metricsStorage) GetBlob(ctx context.Context, id blob.ID, offset, length int64) ([]byte, error) {
+	t0 := clock.Now()
+	result, err := s.base.GetBlob(ctx, id, offset, length)
+	s.record(ctx, "GetBlob", t0, err)
+
+	// nolint:wrapcheck
+	return result, err
+}
+
+func (s *metricsStorage) GetMetadata(ctx context.Context, id blob.ID) (blob.Metadata, error) {
+	t0 := clock.Now()
+	result, err := s.base.GetMetadata(ctx, id)
+	s.record(ctx, "GetMetadata", t0, err)
+
+	// nolint:wrapcheck
+	return result, err
+}
+
+func (s *metricsStorage) PutBlob(ctx context.Context, id blob.ID, data blob.Bytes) error {
+	t0 := clock.Now()
+	err := s.base.PutBlob(ctx, id, data)
+	s.record(ctx, "PutBlob", t0, err)
+
+	// nolint:wrapcheck
+	return err
+}
+
+func (s *metricsStorage) PutBlobWithRetention(ctx context.Context, id blob.ID, data blob.Bytes, mode blob.RetentionMode, retainUntil time.Time) error {
+	t0 := clock.Now()
+	err := blob.PutBlobWithRetention(ctx, s.base, id, data, mode, retainUntil)
+	s.record(ctx, "PutBlobWithRetention", t0, err)
+
+	// nolint:wrapcheck
+	return err
+}
+
+func (s *metricsStorage) ExtendBlobRetention(ctx context.Context, id blob.ID, mode blob.RetentionMode, retainUntil time.Time) error {
+	t0 := clock.Now()
+	err := blob.ExtendBlobRetention(ctx, s.base, id, mode, retainUntil)
+	s.record(ctx, "ExtendBlobRetention", t0, err)
+
+	// nolint:wrapcheck
+	return err
+}
+
+func (s *metricsStorage) GetBlobRetention(ctx context.Context, id blob.ID) (time.Time, error) {
+	t0 := clock.Now()
+	result, err := blob.GetBlobRetention(ctx, s.base, id)
+	s.record(ctx, "GetBlobRetention", t0, err)
+
+	// nolint:wrapcheck
+	return result, err
+}
+
+func (s *metricsStorage) SetTime(ctx context.Context, id blob.ID, t time.Time) error {
+	t0 := clock.Now()
+	err := s.base.SetTime(ctx, id, t)
+	s.record(ctx, "SetTime", t0, err)
+
+	// nolint:wrapcheck
+	return err
+}
+
+func (s *metricsStorage) DeleteBlob(ctx context.Context, id blob.ID) error {
+	t0 := clock.Now()
+	err := s.base.DeleteBlob(ctx, id)
+	s.record(ctx, "DeleteBlob", t0, err)
+
+	// nolint:wrapcheck
+	return err
+}
+
+func (s *metricsStorage) ListBlobs(ctx context.Context, prefix blob.ID, callback func(blob.Metadata) error) error {
+	t0 := clock.Now()
+	err := s.base.ListBlobs(ctx, prefix, callback)
+	s.record(ctx, "ListBlobs", t0, err)
+
+	// nolint:wrapcheck
+	return err
+}
+
+func (s *metricsStorage) Close(ctx context.Context) error {
+	t0 := clock.Now()
+	err := s.base.Close(ctx)
+	s.record(ctx, "Close", t0, err)
+
+	// nolint:wrapcheck
+	return err
+}
+
+func (s *metricsStorage) ConnectionInfo() blob.ConnectionInfo {
+	return s.base.ConnectionInfo()
+}
+
+func (s *metricsStorage) DisplayName() string {
+	return s.base.DisplayName()
+}
+
+// record records latency of the operation that started at t0 and counts its error, if any.
+// Blob not found is not considered an error since it is a regular outcome of many operations.
+func (s *metricsStorage) record(ctx context.Context, operation string, t0 time.Time, err error) {
+	ms := []stats.Measurement{
+		metricLatency.M(float64(clock.Since(t0)) / float64(time.Millisecond)),
+	}
+
+	if err != nil && !errors.Is(err, blob.ErrBlobNotFound) {
+		ms = append(ms, metricErrorCount.M(1))
+	}
+
+	// nolint:errcheck
+	stats.RecordWithTags(ctx,
+		[]tag.Mutator{tag.Upsert(tagKeyOperation, operation), tag.Upsert(tagKeyProvider, s.provider)},
+		ms...)
+}
+
+// NewWrapper returns a Storage wrapper that records latency and error metrics of all operations.
+func NewWrapper(wrapped blob.Storage) blob.Storage {
+	return &metricsStorage{base: wrapped, provider: wrapped.ConnectionInfo().Type}
+}
+
+var _ blob.RetentionStorage = (*metricsStorage)(nil)
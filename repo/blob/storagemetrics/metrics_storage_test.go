@@ -0,0 +1,70 @@
+package storagemetrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opencensus.io/stats/view"
+
+	"github.com/kopia/kopia/internal/blobtesting"
+	"github.com/kopia/kopia/internal/testlogging"
+	"github.com/kopia/kopia/repo/blob"
+)
+
+func TestMetricsStorage(t *testing.T) {
+	ctx := testlogging.Context(t)
+
+	data := blobtesting.DataMap{}
+	kt := map[blob.ID]time.Time{}
+	underlying := blobtesting.NewMapStorage(data, kt, nil)
+
+	st := NewWrapper(underlying)
+	blobtesting.VerifyStorage(ctx, t, st)
+
+	errorsBefore := countByOperation(t, metricErrorCount.Name())["GetBlob"]
+
+	// blob not found is not counted as an error.
+	_, err := st.GetBlob(ctx, "no-such-blob", 0, -1)
+	require.ErrorIs(t, err, blob.ErrBlobNotFound)
+	require.Equal(t, errorsBefore, countByOperation(t, metricErrorCount.Name())["GetBlob"])
+
+	require.NoError(t, st.Close(ctx))
+	require.Equal(t, underlying.ConnectionInfo().Type, st.ConnectionInfo().Type)
+
+	ops := countByOperation(t, metricLatency.Name())
+
+	require.Greater(t, ops["PutBlob"], int64(0))
+	require.Greater(t, ops["GetBlob"], int64(0))
+	require.Greater(t, ops["ListBlobs"], int64(0))
+	require.Greater(t, ops["DeleteBlob"], int64(0))
+	require.Equal(t, int64(1), ops["Close"])
+}
+
+func countByOperation(t *testing.T, viewName string) map[string]int64 {
+	t.Helper()
+
+	rows, err := view.RetrieveData(viewName)
+	require.NoError(t, err)
+
+	result := map[string]int64{}
+
+	for _, r := range rows {
+		var op string
+
+		for _, tg := range r.Tags {
+			if tg.Key == tagKeyOperation {
+				op = tg.Value
+			}
+		}
+
+		switch d := r.Data.(type) {
+		case *view.DistributionData:
+			result[op] += d.Count
+		case *view.CountData:
+			result[op] += d.Value
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,171 @@
+// Package throttling implements wrapper around Storage that limits the rate of operations and transferred bytes.
+package throttling
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Limits encapsulates all limits for a Throttler. Zero value means no limit.
+type Limits struct {
+	ReadsPerSecond         float64 `json:"readsPerSecond,omitempty"`
+	WritesPerSecond        float64 `json:"writesPerSecond,omitempty"`
+	ListsPerSecond         float64 `json:"listsPerSecond,omitempty"`
+	UploadBytesPerSecond   float64 `json:"maxUploadSpeedBytesPerSecond,omitempty"`
+	DownloadBytesPerSecond float64 `json:"maxDownloadSpeedBytesPerSecond,omitempty"`
+}
+
+// Validate validates the limits.
+func (l Limits) Validate() error {
+	for name, v := range map[string]float64{
+		"reads per second":          l.ReadsPerSecond,
+		"writes per second":         l.WritesPerSecond,
+		"lists per second":          l.ListsPerSecond,
+		"upload bytes per second":   l.UploadBytesPerSecond,
+		"download bytes per second": l.DownloadBytesPerSecond,
+	} {
+		if v < 0 {
+			return errors.Errorf("invalid %v: %v", name, v)
+		}
+	}
+
+	return nil
+}
+
+// operation categories used by the throttler.
+const (
+	operationRead  = "read"
+	operationWrite = "write"
+	operationList  = "list"
+)
+
+// Throttler throttles storage operations and transferred bytes.
+type Throttler interface {
+	BeforeOperation(ctx context.Context, op string) error
+	BeforeUpload(ctx context.Context, numBytes int64) error
+	AfterDownload(ctx context.Context, numBytes int64) error
+}
+
+// SettableThrottler is a Throttler whose limits can be changed at runtime.
+type SettableThrottler interface {
+	Throttler
+
+	Limits() Limits
+	SetLimits(limits Limits) error
+
+	// UploadCap returns the additional cap on the upload rate in bytes per second, zero means no cap.
+	UploadCap() float64
+
+	// SetUploadCap sets the additional cap on the upload rate, such as the one imposed by a bandwidth schedule.
+	// The effective upload rate is the lower of the cap and the upload limit, which remains unchanged.
+	SetUploadCap(bytesPerSecond float64) error
+}
+
+type tokenBucketBasedThrottler struct {
+	mu        sync.Mutex
+	limits    Limits
+	uploadCap float64
+
+	readOps  *tokenBucket
+	writeOps *tokenBucket
+	listOps  *tokenBucket
+	upload   *tokenBucket
+	download *tokenBucket
+}
+
+func (t *tokenBucketBasedThrottler) BeforeOperation(ctx context.Context, op string) error {
+	switch op {
+	case operationRead:
+		return t.readOps.Take(ctx, 1)
+	case operationWrite:
+		return t.writeOps.Take(ctx, 1)
+	case operationList:
+		return t.listOps.Take(ctx, 1)
+	default:
+		return nil
+	}
+}
+
+func (t *tokenBucketBasedThrottler) BeforeUpload(ctx context.Context, numBytes int64) error {
+	return t.upload.Take(ctx, float64(numBytes))
+}
+
+func (t *tokenBucketBasedThrottler) AfterDownload(ctx context.Context, numBytes int64) error {
+	return t.download.Take(ctx, float64(numBytes))
+}
+
+func (t *tokenBucketBasedThrottler) Limits() Limits {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.limits
+}
+
+// SetLimits overrides limits.
+func (t *tokenBucketBasedThrottler) SetLimits(limits Limits) error {
+	if err := limits.Validate(); err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.limits = limits
+
+	t.readOps.SetRate(limits.ReadsPerSecond)
+	t.writeOps.SetRate(limits.WritesPerSecond)
+	t.listOps.SetRate(limits.ListsPerSecond)
+	t.upload.SetRate(lowerRate(limits.UploadBytesPerSecond, t.uploadCap))
+	t.download.SetRate(limits.DownloadBytesPerSecond)
+
+	return nil
+}
+
+func (t *tokenBucketBasedThrottler) UploadCap() float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.uploadCap
+}
+
+func (t *tokenBucketBasedThrottler) SetUploadCap(bytesPerSecond float64) error {
+	if bytesPerSecond < 0 {
+		return errors.Errorf("invalid upload cap: %v", bytesPerSecond)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.uploadCap = bytesPerSecond
+	t.upload.SetRate(lowerRate(t.limits.UploadBytesPerSecond, t.uploadCap))
+
+	return nil
+}
+
+// lowerRate returns the lower of the provided rates, where zero means unlimited.
+func lowerRate(r1, r2 float64) float64 {
+	if r1 == 0 || (r2 != 0 && r2 < r1) {
+		return r2
+	}
+
+	return r1
+}
+
+// NewThrottler returns a Throttler with provided limits.
+func NewThrottler(limits Limits) (SettableThrottler, error) {
+	t := &tokenBucketBasedThrottler{
+		readOps:  newTokenBucket(),
+		writeOps: newTokenBucket(),
+		listOps:  newTokenBucket(),
+		upload:   newTokenBucket(),
+		download: newTokenBucket(),
+	}
+
+	if err := t.SetLimits(limits); err != nil {
+		return nil, errors.Wrap(err, "invalid limits")
+	}
+
+	return t, nil
+}
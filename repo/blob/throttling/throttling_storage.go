@@ -0,0 +1,132 @@
+package throttling
+
+import (
+	"context"
+	"time"
+
+	"github.com/kopia/kopia/repo/blob"
+)
+
+// throttlingStorage limits the rate of operations and transferred bytes of the underlying storage.
+type throttlingStorage struct {
+	base      blob.Storage
+	throttler Throttler
+}
+
+func (s *throttlingStorage) GetBlob(ctx context.Context, id blob.ID, offset, length int64) ([]byte, error) {
+	if err := s.throttler.BeforeOperation(ctx, operationRead); err != nil {
+		return nil, err
+	}
+
+	result, err := s.base.GetBlob(ctx, id, offset, length)
+	if err != nil {
+		// nolint:wrapcheck
+		return nil, err
+	}
+
+	if err := s.throttler.AfterDownload(ctx, int64(len(result))); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *throttlingStorage) GetMetadata(ctx context.Context, id blob.ID) (blob.Metadata, error) {
+	if err := s.throttler.BeforeOperation(ctx, operationRead); err != nil {
+		return blob.Metadata{}, err
+	}
+
+	// nolint:wrapcheck
+	return s.base.GetMetadata(ctx, id)
+}
+
+func (s *throttlingStorage) PutBlob(ctx context.Context, id blob.ID, data blob.Bytes) error {
+	if err := s.beforeWrite(ctx, data); err != nil {
+		return err
+	}
+
+	// nolint:wrapcheck
+	return s.base.PutBlob(ctx, id, data)
+}
+
+func (s *throttlingStorage) PutBlobWithRetention(ctx context.Context, id blob.ID, data blob.Bytes, mode blob.RetentionMode, retainUntil time.Time) error {
+	if err := s.beforeWrite(ctx, data); err != nil {
+		return err
+	}
+
+	// nolint:wrapcheck
+	return blob.PutBlobWithRetention(ctx, s.base, id, data, mode, retainUntil)
+}
+
+func (s *throttlingStorage) ExtendBlobRetention(ctx context.Context, id blob.ID, mode blob.RetentionMode, retainUntil time.Time) error {
+	if err := s.throttler.BeforeOperation(ctx, operationWrite); err != nil {
+		return err
+	}
+
+	// nolint:wrapcheck
+	return blob.ExtendBlobRetention(ctx, s.base, id, mode, retainUntil)
+}
+
+func (s *throttlingStorage) GetBlobRetention(ctx context.Context, id blob.ID) (time.Time, error) {
+	if err := s.throttler.BeforeOperation(ctx, operationRead); err != nil {
+		return time.Time{}, err
+	}
+
+	// nolint:wrapcheck
+	return blob.GetBlobRetention(ctx, s.base, id)
+}
+
+func (s *throttlingStorage) SetTime(ctx context.Context, id blob.ID, t time.Time) error {
+	if err := s.throttler.BeforeOperation(ctx, operationWrite); err != nil {
+		return err
+	}
+
+	// nolint:wrapcheck
+	return s.base.SetTime(ctx, id, t)
+}
+
+func (s *throttlingStorage) DeleteBlob(ctx context.Context, id blob.ID) error {
+	if err := s.throttler.BeforeOperation(ctx, operationWrite); err != nil {
+		return err
+	}
+
+	// nolint:wrapcheck
+	return s.base.DeleteBlob(ctx, id)
+}
+
+func (s *throttlingStorage) ListBlobs(ctx context.Context, prefix blob.ID, callback func(blob.Metadata) error) error {
+	if err := s.throttler.BeforeOperation(ctx, operationList); err != nil {
+		return err
+	}
+
+	// nolint:wrapcheck
+	return s.base.ListBlobs(ctx, prefix, callback)
+}
+
+func (s *throttlingStorage) Close(ctx context.Context) error {
+	// nolint:wrapcheck
+	return s.base.Close(ctx)
+}
+
+func (s *throttlingStorage) ConnectionInfo() blob.ConnectionInfo {
+	return s.base.ConnectionInfo()
+}
+
+func (s *throttlingStorage) DisplayName() string {
+	return s.base.DisplayName()
+}
+
+func (s *throttlingStorage) beforeWrite(ctx context.Context, data blob.Bytes) error {
+	if err := s.throttler.BeforeOperation(ctx, operationWrite); err != nil {
+		return err
+	}
+
+	return s.throttler.BeforeUpload(ctx, int64(data.Length()))
+}
+
+// NewWrapper returns a Storage wrapper that throttles operations and transferred bytes using the provided throttler.
+func NewWrapper(wrapped blob.Storage, throttler Throttler) blob.Storage {
+	return &throttlingStorage{base: wrapped, throttler: throttler}
+}
+
+var _ blob.RetentionStorage = (*throttlingStorage)(nil)
@@ -0,0 +1,94 @@
+package throttling_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kopia/kopia/internal/blobtesting"
+	"github.com/kopia/kopia/internal/gather"
+	"github.com/kopia/kopia/internal/testlogging"
+	"github.com/kopia/kopia/repo/blob"
+	"github.com/kopia/kopia/repo/blob/throttling"
+)
+
+type countingThrottler struct {
+	ops        map[string]int
+	uploaded   int64
+	downloaded int64
+}
+
+func (c *countingThrottler) BeforeOperation(ctx context.Context, op string) error {
+	c.ops[op]++
+	return nil
+}
+
+func (c *countingThrottler) BeforeUpload(ctx context.Context, numBytes int64) error {
+	c.uploaded += numBytes
+	return nil
+}
+
+func (c *countingThrottler) AfterDownload(ctx context.Context, numBytes int64) error {
+	c.downloaded += numBytes
+	return nil
+}
+
+func TestThrottlingStorage(t *testing.T) {
+	ctx := testlogging.Context(t)
+
+	data := blobtesting.DataMap{}
+	st := blobtesting.NewMapStorage(data, map[blob.ID]time.Time{}, nil)
+
+	th, err := throttling.NewThrottler(throttling.Limits{})
+	require.NoError(t, err)
+
+	blobtesting.VerifyStorage(ctx, t, throttling.NewWrapper(st, th))
+
+	ct := &countingThrottler{ops: map[string]int{}}
+	wrapped := throttling.NewWrapper(st, ct)
+
+	require.NoError(t, wrapped.PutBlob(ctx, "blob1", gather.FromSlice([]byte{1, 2, 3, 4})))
+	require.NoError(t, wrapped.PutBlob(ctx, "blob2", gather.FromSlice([]byte{1, 2})))
+
+	_, err = wrapped.GetBlob(ctx, "blob1", 0, -1)
+	require.NoError(t, err)
+
+	_, err = wrapped.GetBlob(ctx, "blob1", 1, 2)
+	require.NoError(t, err)
+
+	_, err = wrapped.GetMetadata(ctx, "blob2")
+	require.NoError(t, err)
+
+	_, err = blob.ListAllBlobs(ctx, wrapped, "")
+	require.NoError(t, err)
+
+	require.NoError(t, wrapped.DeleteBlob(ctx, "blob2"))
+
+	require.Equal(t, map[string]int{"read": 3, "write": 3, "list": 1}, ct.ops)
+	require.Equal(t, int64(6), ct.uploaded)
+	require.Equal(t, int64(6), ct.downloaded)
+}
+
+func TestThrottler_SetLimits(t *testing.T) {
+	th, err := throttling.NewThrottler(throttling.Limits{ReadsPerSecond: 10})
+	require.NoError(t, err)
+	require.Equal(t, throttling.Limits{ReadsPerSecond: 10}, th.Limits())
+
+	require.NoError(t, th.SetLimits(throttling.Limits{UploadBytesPerSecond: 1e6}))
+	require.Equal(t, throttling.Limits{UploadBytesPerSecond: 1e6}, th.Limits())
+
+	require.Error(t, th.SetLimits(throttling.Limits{ListsPerSecond: -1}))
+
+	// upload cap does not change the limits.
+	require.NoError(t, th.SetUploadCap(5e5))
+	require.Equal(t, 5e5, th.UploadCap())
+	require.Equal(t, throttling.Limits{UploadBytesPerSecond: 1e6}, th.Limits())
+	require.NoError(t, th.SetLimits(throttling.Limits{UploadBytesPerSecond: 2e6}))
+	require.Equal(t, 5e5, th.UploadCap())
+	require.Error(t, th.SetUploadCap(-1))
+
+	_, err = throttling.NewThrottler(throttling.Limits{DownloadBytesPerSecond: -1})
+	require.Error(t, err)
+}
@@ -0,0 +1,96 @@
+package throttling
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/kopia/kopia/internal/clock"
+)
+
+// tokenBucket implements a token bucket which refills at a given rate (tokens per second) and holds
+// up to one second worth of tokens. Callers may take more tokens than available, in which case
+// they will wait until the bucket refills, which makes it suitable for throttling bytes.
+type tokenBucket struct {
+	now   func() time.Time
+	sleep func(ctx context.Context, d time.Duration) error
+
+	mu        sync.Mutex
+	rate      float64 // tokens per second, zero means unlimited
+	numTokens float64
+	lastTime  time.Time
+}
+
+func (b *tokenBucket) replenishLocked(now time.Time) {
+	if !b.lastTime.IsZero() {
+		b.numTokens += now.Sub(b.lastTime).Seconds() * b.rate
+		if b.numTokens > b.rate {
+			b.numTokens = b.rate
+		}
+	}
+
+	b.lastTime = now
+}
+
+// Take takes the provided number of tokens from the bucket, waiting if necessary.
+func (b *tokenBucket) Take(ctx context.Context, n float64) error {
+	b.mu.Lock()
+
+	if b.rate == 0 {
+		b.mu.Unlock()
+		return nil
+	}
+
+	b.replenishLocked(b.now())
+	b.numTokens -= n
+
+	var wait time.Duration
+	if b.numTokens < 0 {
+		wait = time.Duration(-b.numTokens / b.rate * float64(time.Second))
+	}
+
+	b.mu.Unlock()
+
+	if wait <= 0 {
+		return nil
+	}
+
+	return b.sleep(ctx, wait)
+}
+
+// SetRate changes the rate of the bucket.
+func (b *tokenBucket) SetRate(rate float64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.replenishLocked(b.now())
+	b.rate = rate
+
+	if b.numTokens > rate {
+		b.numTokens = rate
+	}
+
+	if rate == 0 {
+		// forget any outstanding debt.
+		b.numTokens = 0
+	}
+}
+
+func sleepWithContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+func newTokenBucket() *tokenBucket {
+	return &tokenBucket{
+		now:   clock.Now,
+		sleep: sleepWithContext,
+	}
+}
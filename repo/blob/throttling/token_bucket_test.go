@@ -0,0 +1,80 @@
+package throttling
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kopia/kopia/internal/faketime"
+	"github.com/kopia/kopia/internal/testlogging"
+)
+
+func newTestTokenBucket(ta *faketime.TimeAdvance, sleeps *[]time.Duration) *tokenBucket {
+	b := newTokenBucket()
+	b.now = ta.NowFunc()
+	b.sleep = func(ctx context.Context, d time.Duration) error {
+		*sleeps = append(*sleeps, d)
+		ta.Advance(d)
+
+		return nil
+	}
+
+	return b
+}
+
+func TestTokenBucket(t *testing.T) {
+	ctx := testlogging.Context(t)
+	ta := faketime.NewTimeAdvance(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), 0)
+
+	var sleeps []time.Duration
+
+	b := newTestTokenBucket(ta, &sleeps)
+
+	// unlimited
+	require.NoError(t, b.Take(ctx, 1e9))
+	require.Empty(t, sleeps)
+
+	b.SetRate(100)
+
+	// bucket starts empty, taking 50 tokens requires waiting half a second.
+	require.NoError(t, b.Take(ctx, 50))
+	require.Equal(t, []time.Duration{500 * time.Millisecond}, sleeps)
+
+	// after 2 seconds the bucket is full again, but only holds 1 second worth of tokens.
+	ta.Advance(2 * time.Second)
+
+	sleeps = nil
+
+	require.NoError(t, b.Take(ctx, 100))
+	require.Empty(t, sleeps)
+
+	require.NoError(t, b.Take(ctx, 300))
+	require.Equal(t, []time.Duration{3 * time.Second}, sleeps)
+
+	// lowering the rate takes effect immediately.
+	sleeps = nil
+
+	b.SetRate(10)
+	require.NoError(t, b.Take(ctx, 5))
+	require.Equal(t, []time.Duration{500 * time.Millisecond}, sleeps)
+
+	// removing the limit forgets any debt.
+	sleeps = nil
+
+	require.NoError(t, b.Take(ctx, 1000))
+	b.SetRate(0)
+	require.NoError(t, b.Take(ctx, 1000))
+	require.Len(t, sleeps, 1)
+}
+
+func TestTokenBucket_ContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(testlogging.Context(t))
+	cancel()
+
+	b := newTokenBucket()
+	b.SetRate(1)
+
+	require.ErrorIs(t, b.Take(ctx, 1000), context.Canceled)
+}
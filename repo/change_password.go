@@ -0,0 +1,144 @@
+package repo
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+
+	"github.com/kopia/kopia/internal/gather"
+	"github.com/kopia/kopia/repo/blob"
+)
+
+// FormatBlobBackupID is the identifier of a BLOB that holds the copy of the format blob
+// while the password is being changed.
+const FormatBlobBackupID = "kopia.repository.backup"
+
+// PasswordChangeHook is invoked before the master key of the repository changes as a result of changing
+// the password and returns the function to invoke after the change. It allows data encrypted with keys
+// returned by DeriveKey() to be re-encrypted using the new master key.
+type PasswordChangeHook func(ctx context.Context, rep DirectRepositoryWriter) (afterChange func(ctx context.Context) error, err error)
+
+var passwordChangeHooks []PasswordChangeHook
+
+// RegisterPasswordChangeHook registers the hook to be invoked when the password of any repository is changed.
+func RegisterPasswordChangeHook(h PasswordChangeHook) {
+	passwordChangeHooks = append(passwordChangeHooks, h)
+}
+
+// ChangePassword re-encrypts the repository format blob using the key derived from the new password.
+// A copy of the previous format blob is kept as FormatBlobBackupID until the updated format blob has been
+// verified, after which it's deleted, since it can still be decrypted using the old password.
+func (r *directRepository) ChangePassword(ctx context.Context, newPassword string) error {
+	// always start from the latest format blob in the storage, not the locally cached copy.
+	oldBytes, err := r.blobs.GetBlob(ctx, FormatBlobID, 0, -1)
+	if err != nil {
+		return errors.Wrap(err, "unable to read format blob")
+	}
+
+	f, err := parseFormatBlob(oldBytes)
+	if err != nil {
+		return errors.Wrap(err, "can't parse format blob")
+	}
+
+	if !bytes.Equal(f.UniqueID, r.uniqueID) {
+		return errors.Errorf("format blob belongs to a different repository")
+	}
+
+	repoConfig, err := f.decryptFormatBytes(r.masterKey)
+	if err != nil {
+		return errors.Wrap(err, "unable to decrypt repository config, was the password changed by another client?")
+	}
+
+	newMasterKey, err := f.deriveMasterKeyFromPassword(newPassword)
+	if err != nil {
+		return errors.Wrap(err, "unable to derive master key")
+	}
+
+	if err := encryptFormatBytes(f, repoConfig, newMasterKey, f.UniqueID); err != nil {
+		return errors.Wrap(err, "unable to encrypt format bytes")
+	}
+
+	var afterChange []func(ctx context.Context) error
+
+	for _, h := range passwordChangeHooks {
+		after, err := h(ctx, r)
+		if err != nil {
+			return errors.Wrap(err, "unable to prepare for password change")
+		}
+
+		afterChange = append(afterChange, after)
+	}
+
+	log(ctx).Debugf("writing format blob backup...")
+
+	if err := r.blobs.PutBlob(ctx, FormatBlobBackupID, gather.FromSlice(oldBytes)); err != nil {
+		return errors.Wrap(err, "unable to write format blob backup")
+	}
+
+	log(ctx).Debugf("writing updated format blob...")
+
+	if err := writeFormatBlob(ctx, r.blobs, f); err != nil {
+		return err
+	}
+
+	if err := verifyFormatBlobPassword(ctx, r, newPassword); err != nil {
+		return errors.Wrapf(err, "unable to verify updated format blob, previous format blob is preserved in %v", FormatBlobBackupID)
+	}
+
+	log(ctx).Debugf("deleting format blob backup...")
+
+	if err := r.blobs.DeleteBlob(ctx, FormatBlobBackupID); err != nil && !errors.Is(err, blob.ErrBlobNotFound) {
+		return errors.Wrapf(err, "unable to delete %v, which can still be decrypted using the old password", FormatBlobBackupID)
+	}
+
+	r.formatBlob = f
+	r.masterKey = newMasterKey
+
+	for _, after := range afterChange {
+		if err := after(ctx); err != nil {
+			return errors.Wrap(err, "password changed, but unable to re-encrypt data using the new key")
+		}
+	}
+
+	// remove locally cached copy of the format blob so that it's re-read on next open.
+	if cd := r.cachingOptions.CacheDirectory; cd != "" {
+		if err := os.Remove(filepath.Join(cd, FormatBlobID)); err != nil && !os.IsNotExist(err) {
+			log(ctx).Errorf("unable to remove cached format blob: %v", err)
+		}
+	}
+
+	if _, ok := GetPersistedPassword(ctx, r.configFile); ok {
+		if err := SetPersistedPassword(ctx, r.configFile, newPassword); err != nil {
+			return errors.Wrap(err, "unable to persist new password")
+		}
+	}
+
+	return nil
+}
+
+// verifyFormatBlobPassword ensures that the format blob in the storage can be decrypted using the provided password.
+func verifyFormatBlobPassword(ctx context.Context, r *directRepository, password string) error {
+	b, err := r.blobs.GetBlob(ctx, FormatBlobID, 0, -1)
+	if err != nil {
+		return errors.Wrap(err, "unable to read format blob")
+	}
+
+	f, err := parseFormatBlob(b)
+	if err != nil {
+		return errors.Wrap(err, "can't parse format blob")
+	}
+
+	masterKey, err := f.deriveMasterKeyFromPassword(password)
+	if err != nil {
+		return errors.Wrap(err, "unable to derive master key")
+	}
+
+	if _, err := f.decryptFormatBytes(masterKey); err != nil {
+		return errors.Wrap(err, "unable to decrypt format blob")
+	}
+
+	return nil
+}
@@ -75,7 +75,7 @@ func verifyConnect(ctx context.Context, configFile, password string, persist boo
 	}
 
 	if persist {
-		if err := persistPassword(ctx, configFile, password); err != nil {
+		if err := SetPersistedPassword(ctx, configFile, password); err != nil {
 			return errors.Wrap(err, "unable to persist password")
 		}
 	} else {
@@ -3,6 +3,8 @@
 import (
 	"io"
 	"sort"
+
+	"github.com/pkg/errors"
 )
 
 // packIndexBuilder prepares and writes content index.
@@ -45,7 +47,16 @@ func (b packIndexBuilder) sortedContents() []Info {
 	return allContents
 }
 
-// Build writes the pack index to the provided output.
-func (b packIndexBuilder) Build(output io.Writer) error {
-	return b.buildV1(output)
+// Build writes the pack index in the provided format version to the provided output.
+func (b packIndexBuilder) Build(output io.Writer, version int) error {
+	switch version {
+	case IndexVersion1:
+		return b.buildV1(output)
+
+	case IndexVersion2:
+		return b.buildV2(output)
+
+	default:
+		return errors.Errorf("unsupported index version: %v", version)
+	}
 }
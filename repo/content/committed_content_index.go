@@ -186,7 +186,8 @@ func (c *committedContentIndex) combineSmallIndexes(m mergedIndex) (mergedIndex,
 
 	var buf bytes.Buffer
 
-	if err := b.Build(&buf); err != nil {
+	// merged index is only stored locally, so it can always use the latest format.
+	if err := b.Build(&buf, IndexVersion2); err != nil {
 		return nil, errors.Wrap(err, "error building combined in-memory index")
 	}
 
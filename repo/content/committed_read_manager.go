@@ -1,6 +1,7 @@
 package content
 
 import (
+	"bytes"
 	"context"
 	"os"
 	"sync"
@@ -12,7 +13,9 @@
 	"github.com/kopia/kopia/internal/buf"
 	"github.com/kopia/kopia/internal/cache"
 	"github.com/kopia/kopia/internal/clock"
+	"github.com/kopia/kopia/internal/epoch"
 	"github.com/kopia/kopia/repo/blob"
+	"github.com/kopia/kopia/repo/compression"
 	"github.com/kopia/kopia/repo/encryption"
 	"github.com/kopia/kopia/repo/hashing"
 )
@@ -36,6 +39,7 @@ type SharedManager struct {
 	checkInvariantsOnUnlock bool
 	writeFormatVersion      int32 // format version to write
 	maxPackSize             int
+	indexVersion            int
 	minPreambleLength       int
 	maxPreambleLength       int
 	paddingUnit             int
@@ -222,9 +226,44 @@ func (sm *SharedManager) decryptContentAndVerify(payload []byte, bi Info) ([]byt
 		return nil, errors.Wrapf(err, "invalid checksum at %v offset %v length %v", bi.GetPackBlobID(), bi.GetPackOffset(), len(payload))
 	}
 
+	if h := bi.GetCompressionHeaderID(); h != NoCompression {
+		return decompressContent(decrypted, h)
+	}
+
 	return decrypted, nil
 }
 
+func decompressContent(data []byte, h compression.HeaderID) ([]byte, error) {
+	c := compression.ByHeaderID[h]
+	if c == nil {
+		return nil, errors.Errorf("unsupported compressor %x", h)
+	}
+
+	var out bytes.Buffer
+
+	if err := c.Decompress(&out, data); err != nil {
+		return nil, errors.Wrap(err, "error decompressing")
+	}
+
+	return out.Bytes(), nil
+}
+
+// SupportsContentCompression returns true if content manager supports content-level compression.
+func (sm *SharedManager) SupportsContentCompression() bool {
+	return sm.indexVersion >= IndexVersion2
+}
+
+// SupportsSparseObjects returns true if the repository format allows objects with holes, which clients
+// that only understand older formats would not be able to read.
+func (sm *SharedManager) SupportsSparseObjects() bool {
+	return sm.format.Version >= FormatVersion2
+}
+
+// BlobRetention returns the retention mode and period of pack blobs or empty mode if retention is not enabled.
+func (sm *SharedManager) BlobRetention() (blob.RetentionMode, time.Duration) {
+	return sm.format.RetentionMode, sm.format.RetentionPeriod
+}
+
 func (sm *SharedManager) decryptAndVerify(encrypted, iv []byte) ([]byte, error) {
 	decrypted, err := sm.encryptor.Decrypt(nil, encrypted, iv)
 	if err != nil {
@@ -288,16 +327,63 @@ func (sm *SharedManager) setupReadManagerCaches(ctx context.Context, caching *Ca
 	sm.metadataCache = metadataCache
 	sm.committedContents = contentIndex
 
-	sm.indexBlobManager = &indexBlobManagerImpl{
+	if sm.format.EpochParameters.Enabled {
+		sm.indexBlobManager = &indexBlobManagerV1{
+			st:             sm.st,
+			encryptor:      sm.encryptor,
+			hasher:         sm.hasher,
+			epochMgr:       epoch.NewManager(sm.st, sm.format.EpochParameters, sm.timeNow),
+			indexBlobCache: metadataCache,
+			indexVersion:   sm.indexVersion,
+		}
+	} else {
+		sm.indexBlobManager = &indexBlobManagerV0{
+			st:             sm.st,
+			encryptor:      sm.encryptor,
+			hasher:         sm.hasher,
+			timeNow:        sm.timeNow,
+			ownWritesCache: owc,
+			listCache:      listCache,
+			indexBlobCache: metadataCache,
+			maxPackSize:    sm.maxPackSize,
+			indexVersion:   sm.indexVersion,
+		}
+	}
+
+	return nil
+}
+
+// UpgradeFormat switches the manager to the provided upgraded format, so that subsequent writes use the new
+// content format and epoch-based index blob management. It must not be called concurrently with other operations
+// and all pending writes must be flushed before calling it.
+func (sm *SharedManager) UpgradeFormat(ctx context.Context, f *FormattingOptions) error {
+	if err := f.Validate(); err != nil {
+		return errors.Wrap(err, "invalid formatting options")
+	}
+
+	if !f.EpochParameters.Enabled {
+		return errors.Errorf("upgraded format must use epoch-based index management")
+	}
+
+	indexVersion := f.IndexVersion
+	if indexVersion == 0 {
+		indexVersion = defaultIndexVersion
+	}
+
+	sm.format = *f
+	sm.indexVersion = indexVersion
+	sm.writeFormatVersion = int32(f.Version)
+	sm.indexBlobManager = &indexBlobManagerV1{
 		st:             sm.st,
 		encryptor:      sm.encryptor,
 		hasher:         sm.hasher,
-		timeNow:        sm.timeNow,
-		ownWritesCache: owc,
-		listCache:      listCache,
-		indexBlobCache: metadataCache,
+		epochMgr:       epoch.NewManager(sm.st, sm.format.EpochParameters, sm.timeNow),
+		indexBlobCache: sm.metadataCache,
+		indexVersion:   sm.indexVersion,
 	}
 
+	log(ctx).Debugf("switched to format version %v with index version %v", f.Version, indexVersion)
+
 	return nil
 }
 
@@ -348,11 +434,20 @@ func NewSharedManager(ctx context.Context, st blob.Storage, f *FormattingOptions
 		return nil, errors.Errorf("can't handle repositories created using version %v (min supported %v, max supported %v)", f.Version, minSupportedWriteVersion, maxSupportedWriteVersion)
 	}
 
+	if err := f.Validate(); err != nil {
+		return nil, errors.Wrap(err, "invalid formatting options")
+	}
+
 	hasher, encryptor, err := CreateHashAndEncryptor(f)
 	if err != nil {
 		return nil, err
 	}
 
+	indexVersion := f.IndexVersion
+	if indexVersion == 0 {
+		indexVersion = defaultIndexVersion
+	}
+
 	sm := &SharedManager{
 		st:                      st,
 		encryptor:               encryptor,
@@ -361,6 +456,7 @@ func NewSharedManager(ctx context.Context, st blob.Storage, f *FormattingOptions
 		timeNow:                 opts.TimeNow,
 		format:                  *f,
 		maxPackSize:             f.MaxPackSize,
+		indexVersion:            indexVersion,
 		minPreambleLength:       defaultMinPreambleLength,
 		maxPreambleLength:       defaultMaxPreambleLength,
 		paddingUnit:             defaultPaddingUnit,
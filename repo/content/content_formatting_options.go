@@ -1,5 +1,14 @@
 package content
 
+import (
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/kopia/kopia/internal/epoch"
+	"github.com/kopia/kopia/repo/blob"
+)
+
 // FormattingOptions describes the rules for formatting contents in repository.
 type FormattingOptions struct {
 	Version     int    `json:"version,omitempty"`     // version number, must be "1"
@@ -8,6 +17,42 @@ type FormattingOptions struct {
 	HMACSecret  []byte `json:"secret,omitempty"`      // HMAC secret used to generate encryption keys
 	MasterKey   []byte `json:"masterKey,omitempty"`   // master encryption key (SIV-mode encryption only)
 	MaxPackSize int    `json:"maxPackSize,omitempty"` // maximum size of a pack object
+
+	IndexVersion int `json:"indexVersion,omitempty"` // version of pack index format to write, defaults to v1
+
+	EpochParameters epoch.Parameters `json:"epochParameters,omitempty"` // parameters of epoch-based index management
+
+	RetentionMode   blob.RetentionMode `json:"retentionMode,omitempty"`   // immutable retention mode of pack blobs
+	RetentionPeriod time.Duration      `json:"retentionPeriod,omitempty"` // immutable retention period of pack blobs
+}
+
+// Validate validates the formatting options.
+func (f *FormattingOptions) Validate() error {
+	if f.EpochParameters.Enabled && f.Version < FormatVersion2 {
+		return errors.Errorf("epoch-based index management requires format version %v or above", FormatVersion2)
+	}
+
+	switch f.IndexVersion {
+	case 0, IndexVersion1:
+	case IndexVersion2:
+		if f.Version < FormatVersion2 {
+			return errors.Errorf("index version %v requires format version %v or above", IndexVersion2, FormatVersion2)
+		}
+	default:
+		return errors.Errorf("unsupported index version: %v", f.IndexVersion)
+	}
+
+	if f.RetentionMode != "" || f.RetentionPeriod != 0 {
+		if !f.RetentionMode.IsValid() {
+			return errors.Errorf("invalid retention mode: %q", f.RetentionMode)
+		}
+
+		if f.RetentionPeriod <= 0 {
+			return errors.Errorf("retention period must be positive")
+		}
+	}
+
+	return errors.Wrap(f.EpochParameters.Validate(), "invalid epoch parameters")
 }
 
 // GetEncryptionAlgorithm implements encryption.Parameters.
@@ -18,6 +18,7 @@
 	"github.com/kopia/kopia/internal/clock"
 	"github.com/kopia/kopia/internal/gather"
 	"github.com/kopia/kopia/repo/blob"
+	"github.com/kopia/kopia/repo/compression"
 	"github.com/kopia/kopia/repo/logging"
 )
 
@@ -37,6 +38,9 @@
 	defaultEncryptionBufferPoolSegmentSize = 8 << 20 // 8 MB
 )
 
+// NoCompression is a compression header ID that indicates that the content is not compressed.
+const NoCompression compression.HeaderID = 0
+
 // PackBlobIDPrefixes contains all possible prefixes for pack blobs.
 var PackBlobIDPrefixes = []blob.ID{
 	PackBlobIDPrefixRegular,
@@ -51,7 +55,7 @@
 	defaultMaxPreambleLength = 32
 	defaultPaddingUnit       = 4096
 
-	currentWriteVersion = 1
+	currentWriteVersion = FormatVersion2
 
 	minSupportedWriteVersion = 1
 	maxSupportedWriteVersion = currentWriteVersion
@@ -62,6 +66,15 @@
 	indexLoadAttempts = 10
 )
 
+// Content manager format versions.
+const (
+	// FormatVersion1 is the original format version, which uses compaction logs to manage index blobs.
+	FormatVersion1 = 1
+
+	// FormatVersion2 adds support for epoch-based index blob management and sparse objects.
+	FormatVersion2 = 2
+)
+
 // ErrContentNotFound is returned when content is not found.
 var ErrContentNotFound = errors.New("content not found")
 
@@ -73,7 +86,8 @@ type IndexBlobInfo struct {
 
 // WriteManager builds content-addressable storage with encryption, deduplication and packaging on top of BLOB store.
 type WriteManager struct {
-	revision int64 // changes on each local write
+	revision      int64 // changes on each local write
+	uploadedBytes int64 // bytes uploaded to the storage in this session
 
 	mu       *sync.RWMutex
 	cond     *sync.Cond
@@ -106,6 +120,17 @@ type pendingPackInfo struct {
 	finalized        bool                // indicates whether currentPackData has local index appended to it
 }
 
+// reportUpload accounts for bytes about to be uploaded to the storage in this session.
+func (bm *WriteManager) reportUpload(numBytes int64) {
+	atomic.AddInt64(&bm.uploadedBytes, numBytes)
+	bm.onUpload(numBytes)
+}
+
+// UploadedBytes returns the number of bytes uploaded to the storage in this session.
+func (bm *WriteManager) UploadedBytes() int64 {
+	return atomic.LoadInt64(&bm.uploadedBytes)
+}
+
 // Revision returns data revision number that changes on each write or refresh.
 func (bm *WriteManager) Revision() int64 {
 	return atomic.LoadInt64(&bm.revision) + bm.committedContents.revision()
@@ -208,7 +233,7 @@ func (bm *WriteManager) maybeRetryWritingFailedPacksUnlocked(ctx context.Context
 	return nil
 }
 
-func (bm *WriteManager) addToPackUnlocked(ctx context.Context, contentID ID, data []byte, isDeleted bool) error {
+func (bm *WriteManager) addToPackUnlocked(ctx context.Context, contentID ID, data []byte, isDeleted bool, comp compression.HeaderID) error {
 	// see if the current index is old enough to cause automatic flush.
 	if err := bm.maybeFlushBasedOnTimeUnlocked(ctx); err != nil {
 		return errors.Wrap(err, "unable to flush old pending writes")
@@ -251,17 +276,21 @@ func (bm *WriteManager) addToPackUnlocked(ctx context.Context, contentID ID, dat
 		Deleted:          isDeleted,
 		ContentID:        contentID,
 		PackBlobID:       pp.packBlobID,
-		PackOffset:       uint32(pp.currentPackData.Length()),
+		PackOffset:       uint64(pp.currentPackData.Length()),
 		TimestampSeconds: bm.timeNow().Unix(),
 		FormatVersion:    byte(bm.writeFormatVersion),
 		OriginalLength:   uint32(len(data)),
 	}
 
-	if err := bm.maybeEncryptContentDataForPacking(pp.currentPackData, data, contentID); err != nil {
+	actualComp, err := bm.maybeCompressAndEncryptDataForPacking(pp.currentPackData, data, contentID, comp)
+	if err != nil {
+		bm.unlock()
 		return errors.Wrapf(err, "unable to encrypt %q", contentID)
 	}
 
-	info.PackedLength = uint32(pp.currentPackData.Length()) - info.PackOffset
+	info.CompressionHeaderID = actualComp
+
+	info.PackedLength = uint32(uint64(pp.currentPackData.Length()) - info.PackOffset)
 
 	pp.currentPackItems[contentID] = info
 
@@ -358,14 +387,14 @@ func (bm *WriteManager) flushPackIndexesLocked(ctx context.Context) error {
 	if len(bm.packIndexBuilder) > 0 {
 		var b bytes.Buffer
 
-		if err := bm.packIndexBuilder.Build(&b); err != nil {
+		if err := bm.packIndexBuilder.Build(&b, bm.indexVersion); err != nil {
 			return errors.Wrap(err, "unable to build pack index")
 		}
 
 		data := b.Bytes()
 		dataCopy := append([]byte(nil), data...)
 
-		bm.onUpload(int64(len(data)))
+		bm.reportUpload(int64(len(data)))
 
 		indexBlobMD, err := bm.indexBlobManager.writeIndexBlob(ctx, data, bm.currentSessionInfo.ID)
 		if err != nil {
@@ -541,7 +570,7 @@ func (bm *WriteManager) RewriteContent(ctx context.Context, contentID ID) error
 		return err
 	}
 
-	return bm.addToPackUnlocked(ctx, contentID, data, bi.GetDeleted())
+	return bm.addToPackUnlocked(ctx, contentID, data, bi.GetDeleted(), bi.GetCompressionHeaderID())
 }
 
 // UndeleteContent rewrites the content with the given ID if the content exists
@@ -564,7 +593,7 @@ func (bm *WriteManager) UndeleteContent(ctx context.Context, contentID ID) error
 		return err
 	}
 
-	return bm.addToPackUnlocked(ctx, contentID, data, false)
+	return bm.addToPackUnlocked(ctx, contentID, data, false, bi.GetCompressionHeaderID())
 }
 
 func packPrefixForContentID(contentID ID) blob.ID {
@@ -610,8 +639,9 @@ func (bm *WriteManager) getOrCreatePendingPackInfoLocked(ctx context.Context, pr
 }
 
 // WriteContent saves a given content of data to a pack group with a provided name and returns a contentID
-// that's based on the contents of data written.
-func (bm *WriteManager) WriteContent(ctx context.Context, data []byte, prefix ID) (ID, error) {
+// that's based on the contents of data written. The content is compressed using the provided compressor
+// unless comp is NoCompression, content ID is always based on uncompressed data.
+func (bm *WriteManager) WriteContent(ctx context.Context, data []byte, prefix ID, comp compression.HeaderID) (ID, error) {
 	if err := bm.maybeRetryWritingFailedPacksUnlocked(ctx); err != nil {
 		return "", err
 	}
@@ -639,7 +669,7 @@ func (bm *WriteManager) WriteContent(ctx context.Context, data []byte, prefix ID
 		formatLog(ctx).Debugf("write-content %v new", contentID)
 	}
 
-	err := bm.addToPackUnlocked(ctx, contentID, data, false)
+	err := bm.addToPackUnlocked(ctx, contentID, data, false, comp)
 
 	return contentID, err
 }
@@ -10,8 +10,6 @@
 	"github.com/kopia/kopia/repo/blob"
 )
 
-const verySmallContentFraction = 20 // blobs less than 1/verySmallContentFraction of maxPackSize are considered 'very small'
-
 // CompactOptions provides options for compaction.
 type CompactOptions struct {
 	MaxSmallBlobs                    int
@@ -36,22 +34,15 @@ func (bm *WriteManager) CompactIndexes(ctx context.Context, opt CompactOptions)
 	bm.lock()
 	defer bm.unlock()
 
-	indexBlobs, _, err := bm.loadPackIndexesUnlocked(ctx)
-	if err != nil {
+	if _, _, err := bm.loadPackIndexesUnlocked(ctx); err != nil {
 		return errors.Wrap(err, "error loading indexes")
 	}
 
-	blobsToCompact := bm.getBlobsToCompact(ctx, indexBlobs, opt)
-
-	if err := bm.compactIndexBlobs(ctx, blobsToCompact, opt); err != nil {
+	if err := bm.indexBlobManager.compact(ctx, opt); err != nil {
 		return errors.Wrap(err, "error performing compaction")
 	}
 
-	if err := bm.indexBlobManager.cleanup(ctx, opt.maxEventualConsistencySettleTime()); err != nil {
-		return errors.Wrap(err, "error cleaning up index blobs")
-	}
-
-	// reload indexes after cleanup.
+	// reload indexes after compaction.
 	if _, _, err := bm.loadPackIndexesUnlocked(ctx); err != nil {
 		return errors.Wrap(err, "error re-loading indexes")
 	}
@@ -59,97 +50,6 @@ func (bm *WriteManager) CompactIndexes(ctx context.Context, opt CompactOptions)
 	return nil
 }
 
-func (sm *SharedManager) getBlobsToCompact(ctx context.Context, indexBlobs []IndexBlobInfo, opt CompactOptions) []IndexBlobInfo {
-	var nonCompactedBlobs, verySmallBlobs []IndexBlobInfo
-
-	var totalSizeNonCompactedBlobs, totalSizeVerySmallBlobs, totalSizeMediumSizedBlobs int64
-
-	var mediumSizedBlobCount int
-
-	for _, b := range indexBlobs {
-		if b.Length > int64(sm.maxPackSize) && !opt.AllIndexes {
-			continue
-		}
-
-		nonCompactedBlobs = append(nonCompactedBlobs, b)
-		totalSizeNonCompactedBlobs += b.Length
-
-		if b.Length < int64(sm.maxPackSize/verySmallContentFraction) {
-			verySmallBlobs = append(verySmallBlobs, b)
-			totalSizeVerySmallBlobs += b.Length
-		} else {
-			mediumSizedBlobCount++
-			totalSizeMediumSizedBlobs += b.Length
-		}
-	}
-
-	if len(nonCompactedBlobs) < opt.MaxSmallBlobs {
-		// current count is below min allowed - nothing to do
-		log(ctx).Debugf("no small contents to compact")
-		return nil
-	}
-
-	if len(verySmallBlobs) > len(nonCompactedBlobs)/2 && mediumSizedBlobCount+1 < opt.MaxSmallBlobs {
-		log(ctx).Debugf("compacting %v very small contents", len(verySmallBlobs))
-		return verySmallBlobs
-	}
-
-	log(ctx).Debugf("compacting all %v non-compacted contents", len(nonCompactedBlobs))
-
-	return nonCompactedBlobs
-}
-
-func (sm *SharedManager) compactIndexBlobs(ctx context.Context, indexBlobs []IndexBlobInfo, opt CompactOptions) error {
-	if len(indexBlobs) <= 1 && opt.DropDeletedBefore.IsZero() && len(opt.DropContents) == 0 {
-		return nil
-	}
-
-	bld := make(packIndexBuilder)
-
-	var inputs, outputs []blob.Metadata
-
-	for i, indexBlob := range indexBlobs {
-		formatLog(ctx).Debugf("compacting-entries[%v/%v] %v", i, len(indexBlobs), indexBlob)
-
-		if err := sm.addIndexBlobsToBuilder(ctx, bld, indexBlob); err != nil {
-			return errors.Wrap(err, "error adding index to builder")
-		}
-
-		inputs = append(inputs, indexBlob.Metadata)
-	}
-
-	// after we built index map in memory, drop contents from it
-	// we must do it after all input blobs have been merged, otherwise we may resurrect contents.
-	dropContentsFromBuilder(ctx, bld, opt)
-
-	var buf bytes.Buffer
-	if err := bld.Build(&buf); err != nil {
-		return errors.Wrap(err, "unable to build an index")
-	}
-
-	compactedIndexBlob, err := sm.indexBlobManager.writeIndexBlob(ctx, buf.Bytes(), "")
-	if err != nil {
-		return errors.Wrap(err, "unable to write compacted indexes")
-	}
-
-	// compaction wrote index blob that's the same as one of the sources
-	// it must be a no-op.
-	for _, indexBlob := range indexBlobs {
-		if indexBlob.BlobID == compactedIndexBlob.BlobID {
-			formatLog(ctx).Debugf("compaction-noop")
-			return nil
-		}
-	}
-
-	outputs = append(outputs, compactedIndexBlob)
-
-	if err := sm.indexBlobManager.registerCompaction(ctx, inputs, outputs, opt.maxEventualConsistencySettleTime()); err != nil {
-		return errors.Wrap(err, "unable to register compaction")
-	}
-
-	return nil
-}
-
 func dropContentsFromBuilder(ctx context.Context, bld packIndexBuilder, opt CompactOptions) {
 	for _, dc := range opt.DropContents {
 		if _, ok := bld[dc]; ok {
@@ -172,15 +72,15 @@ func dropContentsFromBuilder(ctx context.Context, bld packIndexBuilder, opt Comp
 	}
 }
 
-func (sm *SharedManager) addIndexBlobsToBuilder(ctx context.Context, bld packIndexBuilder, indexBlob IndexBlobInfo) error {
-	data, err := sm.indexBlobManager.getIndexBlob(ctx, indexBlob.BlobID)
+func addIndexBlobsToBuilder(ctx context.Context, m indexBlobManager, encryptorOverhead uint32, bld packIndexBuilder, indexBlobID blob.ID) error {
+	data, err := m.getIndexBlob(ctx, indexBlobID)
 	if err != nil {
-		return errors.Wrapf(err, "error getting index %q", indexBlob.BlobID)
+		return errors.Wrapf(err, "error getting index %q", indexBlobID)
 	}
 
-	index, err := openPackIndex(bytes.NewReader(data), uint32(sm.encryptor.Overhead()))
+	index, err := openPackIndex(bytes.NewReader(data), encryptorOverhead)
 	if err != nil {
-		return errors.Wrapf(err, "unable to open index blob %q", indexBlob)
+		return errors.Wrapf(err, "unable to open index blob %q", indexBlobID)
 	}
 
 	_ = index.Iterate(AllIDs, func(i Info) error {
@@ -1,6 +1,7 @@
 package content
 
 import (
+	"bytes"
 	"context"
 	"crypto/aes"
 	cryptorand "crypto/rand"
@@ -11,18 +12,45 @@
 
 	"github.com/kopia/kopia/internal/gather"
 	"github.com/kopia/kopia/repo/blob"
+	"github.com/kopia/kopia/repo/compression"
 	"github.com/kopia/kopia/repo/encryption"
 	"github.com/kopia/kopia/repo/hashing"
 )
 
 const indexBlobCompactionWarningThreshold = 1000
 
-func (sm *SharedManager) maybeEncryptContentDataForPacking(output *gather.WriteBuffer, data []byte, contentID ID) error {
+// maybeCompressAndEncryptDataForPacking compresses (if requested) and encrypts the provided data and appends it to the output.
+// Returns the compression header ID that was actually used, which is NoCompression when compression did not
+// reduce the size of data.
+func (sm *SharedManager) maybeCompressAndEncryptDataForPacking(output *gather.WriteBuffer, data []byte, contentID ID, comp compression.HeaderID) (compression.HeaderID, error) {
 	var hashOutput [maxHashSize]byte
 
 	iv, err := getPackedContentIV(hashOutput[:], contentID)
 	if err != nil {
-		return errors.Wrapf(err, "unable to get packed content IV for %q", contentID)
+		return NoCompression, errors.Wrapf(err, "unable to get packed content IV for %q", contentID)
+	}
+
+	if comp != NoCompression {
+		if !sm.SupportsContentCompression() {
+			return NoCompression, errors.Errorf("compression is not enabled for this repository")
+		}
+
+		c := compression.ByHeaderID[comp]
+		if c == nil {
+			return NoCompression, errors.Errorf("unsupported compressor %x", comp)
+		}
+
+		var tmp bytes.Buffer
+
+		if err := c.Compress(&tmp, data); err != nil {
+			return NoCompression, errors.Wrap(err, "compression error")
+		}
+
+		if tmp.Len() < len(data) {
+			data = tmp.Bytes()
+		} else {
+			comp = NoCompression
+		}
 	}
 
 	b := sm.encryptionBufferPool.Allocate(len(data) + sm.encryptor.Overhead())
@@ -30,14 +58,14 @@ func (sm *SharedManager) maybeEncryptContentDataForPacking(output *gather.WriteB
 
 	cipherText, err := sm.encryptor.Encrypt(b.Data[:0], data, iv)
 	if err != nil {
-		return errors.Wrap(err, "unable to encrypt")
+		return NoCompression, errors.Wrap(err, "unable to encrypt")
 	}
 
 	sm.Stats.encrypted(len(data))
 
 	output.Append(cipherText)
 
-	return nil
+	return comp, nil
 }
 
 func writeRandomBytesToBuffer(b *gather.WriteBuffer, count int) error {
@@ -138,7 +166,12 @@ func getPackedContentIV(output []byte, contentID ID) ([]byte, error) {
 
 func (bm *WriteManager) writePackFileNotLocked(ctx context.Context, packFile blob.ID, data gather.Bytes) error {
 	bm.Stats.wroteContent(data.Length())
-	bm.onUpload(int64(data.Length()))
+	bm.reportUpload(int64(data.Length()))
+
+	if mode := bm.format.RetentionMode; mode != "" {
+		// nolint:wrapcheck
+		return blob.PutBlobWithRetention(ctx, bm.st, packFile, data, mode, bm.timeNow().Add(bm.format.RetentionPeriod))
+	}
 
 	return bm.st.PutBlob(ctx, packFile, data)
 }
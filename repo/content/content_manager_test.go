@@ -21,11 +21,13 @@
 	"github.com/stretchr/testify/require"
 
 	"github.com/kopia/kopia/internal/blobtesting"
+	"github.com/kopia/kopia/internal/epoch"
 	"github.com/kopia/kopia/internal/faketime"
 	"github.com/kopia/kopia/internal/testlogging"
 	"github.com/kopia/kopia/internal/testutil"
 	"github.com/kopia/kopia/repo/blob"
 	"github.com/kopia/kopia/repo/blob/logging"
+	"github.com/kopia/kopia/repo/compression"
 )
 
 const (
@@ -261,7 +263,7 @@ func TestContentManagerWriteMultiple(t *testing.T) {
 	for i := 0; i < repeatCount; i++ {
 		b := seededRandomData(i, i%113)
 
-		blkID, err := bm.WriteContent(ctx, b, "")
+		blkID, err := bm.WriteContent(ctx, b, "", NoCompression)
 		if err != nil {
 			t.Errorf("err: %v", err)
 		}
@@ -332,12 +334,12 @@ func TestContentManagerFailedToWritePack(t *testing.T) {
 		},
 	}
 
-	_, err = bm.WriteContent(ctx, seededRandomData(1, 10), "")
+	_, err = bm.WriteContent(ctx, seededRandomData(1, 10), "", NoCompression)
 	if !errors.Is(err, sessionPutErr) {
 		t.Fatalf("can't create first content: %v", err)
 	}
 
-	b1, err := bm.WriteContent(ctx, seededRandomData(1, 10), "")
+	b1, err := bm.WriteContent(ctx, seededRandomData(1, 10), "", NoCompression)
 	if err != nil {
 		t.Fatalf("can't create content: %v", err)
 	}
@@ -345,7 +347,7 @@ func TestContentManagerFailedToWritePack(t *testing.T) {
 	// advance time enough to cause auto-flush, which will fail (firstPutErr)
 	ta.Advance(1 * time.Hour)
 
-	if _, err := bm.WriteContent(ctx, seededRandomData(2, 10), ""); !errors.Is(err, firstPutErr) {
+	if _, err := bm.WriteContent(ctx, seededRandomData(2, 10), "", NoCompression); !errors.Is(err, firstPutErr) {
 		t.Fatalf("can't create 2nd content: %v", err)
 	}
 
@@ -1796,7 +1798,7 @@ func verifyVersionCompat(t *testing.T, writeVersion int) {
 		data := make([]byte, i)
 		cryptorand.Read(data)
 
-		cid, err := mgr.WriteContent(ctx, data, "")
+		cid, err := mgr.WriteContent(ctx, data, "", NoCompression)
 		if err != nil {
 			t.Fatalf("unable to write %v bytes: %v", len(data), err)
 		}
@@ -1846,6 +1848,173 @@ func verifyVersionCompat(t *testing.T, writeVersion int) {
 	verifyContentManagerDataSet(ctx, t, mgr, dataSet)
 }
 
+func TestContentManagerWithIndexEpochs(t *testing.T) {
+	ctx := testlogging.Context(t)
+	data := blobtesting.DataMap{}
+	ta := faketime.NewTimeAdvance(fakeTime, 0)
+	st := blobtesting.NewMapStorage(data, nil, ta.NowFunc())
+
+	epochParams := epoch.DefaultParameters
+	epochParams.MinEpochDuration = 1 * time.Hour
+	epochParams.EpochRefreshFrequency = 10 * time.Minute
+	epochParams.CleanupSafetyMargin = 30 * time.Minute
+	epochParams.EpochAdvanceOnCountThreshold = 10
+
+	newManager := func() *WriteManager {
+		bm, err := NewManager(ctx, st, &FormattingOptions{
+			Hash:            "HMAC-SHA256",
+			Encryption:      "AES256-GCM-HMAC-SHA256",
+			HMACSecret:      hmacSecret,
+			MaxPackSize:     maxPackSize,
+			Version:         FormatVersion2,
+			EpochParameters: epochParams,
+		}, nil, &ManagerOptions{TimeNow: ta.NowFunc()})
+		require.NoError(t, err)
+
+		t.Cleanup(func() { bm.Close(ctx) })
+
+		return bm
+	}
+
+	bm := newManager()
+	dataSet := map[ID][]byte{}
+
+	for i := 0; i < 100; i++ {
+		b := seededRandomData(i, 100)
+		dataSet[writeContentAndVerify(ctx, t, bm, b)] = b
+
+		require.NoError(t, bm.Flush(ctx))
+		ta.Advance(5 * time.Minute)
+
+		if i%10 == 0 {
+			require.NoError(t, bm.CompactIndexes(ctx, CompactOptions{}))
+		}
+	}
+
+	verifyContentManagerDataSet(ctx, t, bm, dataSet)
+
+	for blobID := range data {
+		require.NotEqual(t, indexBlobPrefix, string(blobID[0:1]), "unexpected legacy index blob %v", blobID)
+	}
+
+	// open another manager and make sure all contents are visible.
+	verifyContentManagerDataSet(ctx, t, newManager(), dataSet)
+}
+
+func TestContentManagerWithIndexV2(t *testing.T) {
+	ctx := testlogging.Context(t)
+	data := blobtesting.DataMap{}
+	st := blobtesting.NewMapStorage(data, nil, faketime.Frozen(fakeTime))
+
+	newManager := func() *WriteManager {
+		bm, err := NewManager(ctx, st, &FormattingOptions{
+			Hash:         "HMAC-SHA256",
+			Encryption:   "AES256-GCM-HMAC-SHA256",
+			HMACSecret:   hmacSecret,
+			MaxPackSize:  maxPackSize,
+			Version:      FormatVersion2,
+			IndexVersion: IndexVersion2,
+		}, nil, nil)
+		require.NoError(t, err)
+
+		t.Cleanup(func() { bm.Close(ctx) })
+
+		return bm
+	}
+
+	bm := newManager()
+	dataSet := map[ID][]byte{}
+
+	for i := 0; i < 20; i++ {
+		b := seededRandomData(i, 100)
+		dataSet[writeContentAndVerify(ctx, t, bm, b)] = b
+
+		require.NoError(t, bm.Flush(ctx))
+	}
+
+	require.NoError(t, bm.CompactIndexes(ctx, CompactOptions{MaxSmallBlobs: 1}))
+
+	// v2 index stores original lengths explicitly.
+	for contentID, b := range dataSet {
+		ci, err := bm.ContentInfo(ctx, contentID)
+		require.NoError(t, err)
+		require.Equal(t, uint32(len(b)), ci.GetOriginalLength())
+	}
+
+	verifyContentManagerDataSet(ctx, t, newManager(), dataSet)
+}
+
+func TestContentManagerCompression(t *testing.T) {
+	ctx := testlogging.Context(t)
+	data := blobtesting.DataMap{}
+	st := blobtesting.NewMapStorage(data, nil, faketime.Frozen(fakeTime))
+
+	newManager := func(indexVersion int) *WriteManager {
+		bm, err := NewManager(ctx, st, &FormattingOptions{
+			Hash:         "HMAC-SHA256",
+			Encryption:   "AES256-GCM-HMAC-SHA256",
+			HMACSecret:   hmacSecret,
+			MaxPackSize:  maxPackSize,
+			Version:      FormatVersion2,
+			IndexVersion: indexVersion,
+		}, nil, nil)
+		require.NoError(t, err)
+
+		t.Cleanup(func() { bm.Close(ctx) })
+
+		return bm
+	}
+
+	comp := compression.ByName["gzip"].HeaderID()
+
+	// v1 index can't store compression information.
+	_, err := newManager(IndexVersion1).WriteContent(ctx, bytes.Repeat([]byte{1}, 1000), "", comp)
+	require.Error(t, err)
+
+	bm := newManager(IndexVersion2)
+	require.True(t, bm.SupportsContentCompression())
+
+	compressible := bytes.Repeat([]byte("compressible"), 100)
+	incompressible := seededRandomData(1, 100)
+
+	compressibleID, err := bm.WriteContent(ctx, compressible, "k", comp)
+	require.NoError(t, err)
+
+	// content ID does not depend on compression.
+	require.Equal(t, "k"+ID(hashValue(compressible)), compressibleID)
+
+	incompressibleID, err := bm.WriteContent(ctx, incompressible, "k", comp)
+	require.NoError(t, err)
+
+	verifyContent(ctx, t, bm, compressibleID, compressible)
+	verifyContent(ctx, t, bm, incompressibleID, incompressible)
+	require.NoError(t, bm.Flush(ctx))
+
+	ci, err := bm.ContentInfo(ctx, compressibleID)
+	require.NoError(t, err)
+	require.Equal(t, comp, ci.GetCompressionHeaderID())
+	require.Equal(t, uint32(len(compressible)), ci.GetOriginalLength())
+	require.Less(t, ci.GetPackedLength(), ci.GetOriginalLength())
+
+	// compression is skipped when it does not reduce the size.
+	ci, err = bm.ContentInfo(ctx, incompressibleID)
+	require.NoError(t, err)
+	require.Equal(t, NoCompression, ci.GetCompressionHeaderID())
+
+	// rewritten contents retain compression.
+	require.NoError(t, bm.RewriteContent(ctx, compressibleID))
+	require.NoError(t, bm.Flush(ctx))
+
+	bm2 := newManager(IndexVersion2)
+
+	ci, err = bm2.ContentInfo(ctx, compressibleID)
+	require.NoError(t, err)
+	require.Equal(t, comp, ci.GetCompressionHeaderID())
+
+	verifyContent(ctx, t, bm2, compressibleID, compressible)
+	verifyContent(ctx, t, bm2, incompressibleID, incompressible)
+}
+
 func TestReadsOwnWritesWithEventualConsistencyPersistentOwnWritesCache(t *testing.T) {
 	data := blobtesting.DataMap{}
 	timeNow := faketime.AutoAdvance(fakeTime, 1*time.Second)
@@ -2035,7 +2204,7 @@ func verifyContent(ctx context.Context, t *testing.T, bm *WriteManager, contentI
 func writeContentAndVerify(ctx context.Context, t *testing.T, bm *WriteManager, b []byte) ID {
 	t.Helper()
 
-	contentID, err := bm.WriteContent(ctx, b, "")
+	contentID, err := bm.WriteContent(ctx, b, "", NoCompression)
 	if err != nil {
 		t.Errorf("err: %v", err)
 	}
@@ -2073,13 +2242,13 @@ func writeContentWithRetriesAndVerify(ctx context.Context, t *testing.T, bm *Wri
 
 	log(ctx).Infof("*** starting writeContentWithRetriesAndVerify")
 
-	contentID, err := bm.WriteContent(ctx, b, "")
+	contentID, err := bm.WriteContent(ctx, b, "", NoCompression)
 	for i := 0; err != nil && i < maxRetries; i++ {
 		retryCount++
 
 		log(ctx).Infof("*** try %v", retryCount)
 
-		contentID, err = bm.WriteContent(ctx, b, "")
+		contentID, err = bm.WriteContent(ctx, b, "", NoCompression)
 	}
 
 	if err != nil {
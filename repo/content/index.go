@@ -13,6 +13,15 @@
 	unknownKeySize   = 255
 )
 
+// Supported pack index format versions.
+const (
+	IndexVersion1 = 1 // fixed-size entries with 31-bit pack offsets, no compression information
+	IndexVersion2 = 2 // shared pack and format tables, 47-bit pack offsets and per-content compression
+
+	// index version to use when FormattingOptions.IndexVersion is not set.
+	defaultIndexVersion = IndexVersion1
+)
+
 // packIndex is a read-only index of packed contents.
 type packIndex interface {
 	io.Closer
@@ -87,9 +96,14 @@ func openPackIndex(readerAt io.ReaderAt, v1PerContentOverhead uint32) (packIndex
 		return nil, errors.Wrap(err, "invalid header")
 	}
 
-	if h.version != 1 {
+	switch h.version {
+	case IndexVersion1:
+		return &indexV1{hdr: h, readerAt: readerAt, v1PerContentOverhead: v1PerContentOverhead}, nil
+
+	case IndexVersion2:
+		return openV2PackIndex(readerAt, h)
+
+	default:
 		return nil, errors.Errorf("invalid header format: %v", h.version)
 	}
-
-	return &indexV1{hdr: h, readerAt: readerAt, v1PerContentOverhead: v1PerContentOverhead}, nil
 }
@@ -1,6 +1,7 @@
 package content
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"time"
@@ -19,12 +20,12 @@ type indexBlobManager interface {
 	writeIndexBlob(ctx context.Context, data []byte, sessionID SessionID) (blob.Metadata, error)
 	listIndexBlobs(ctx context.Context, includeInactive bool) ([]IndexBlobInfo, error)
 	getIndexBlob(ctx context.Context, blobID blob.ID) ([]byte, error)
-	registerCompaction(ctx context.Context, inputs, outputs []blob.Metadata, maxEventualConsistencySettleTime time.Duration) error
-	cleanup(ctx context.Context, maxEventualConsistencySettleTime time.Duration) error
+	compact(ctx context.Context, opt CompactOptions) error
 	flushCache()
 }
 
 const (
+	verySmallContentFraction             = 20 // blobs less than 1/verySmallContentFraction of maxPackSize are considered 'very small'
 	defaultEventualConsistencySettleTime = 1 * time.Hour
 	compactionLogBlobPrefix              = "m"
 	cleanupBlobPrefix                    = "l"
@@ -55,7 +56,8 @@ type cleanupEntry struct {
 	age time.Duration // not serialized, computed on load
 }
 
-type indexBlobManagerImpl struct {
+// indexBlobManagerV0 manages index blobs using compaction logs stored in `m` blobs.
+type indexBlobManagerV0 struct {
 	st             blob.Storage
 	hasher         hashing.HashFunc
 	encryptor      encryption.Encryptor
@@ -63,9 +65,11 @@ type indexBlobManagerImpl struct {
 	ownWritesCache ownWritesCache
 	timeNow        func() time.Time
 	indexBlobCache contentCache
+	maxPackSize    int
+	indexVersion   int
 }
 
-func (m *indexBlobManagerImpl) listAndMergeOwnWrites(ctx context.Context, prefix blob.ID) ([]blob.Metadata, error) {
+func (m *indexBlobManagerV0) listAndMergeOwnWrites(ctx context.Context, prefix blob.ID) ([]blob.Metadata, error) {
 	found, err := m.listCache.listBlobs(ctx, prefix)
 	if err != nil {
 		return nil, errors.Wrapf(err, "error listing %v blobs", prefix)
@@ -79,7 +83,7 @@ func (m *indexBlobManagerImpl) listAndMergeOwnWrites(ctx context.Context, prefix
 	return merged, nil
 }
 
-func (m *indexBlobManagerImpl) listIndexBlobs(ctx context.Context, includeInactive bool) ([]IndexBlobInfo, error) {
+func (m *indexBlobManagerV0) listIndexBlobs(ctx context.Context, includeInactive bool) ([]IndexBlobInfo, error) {
 	var compactionLogMetadata, storageIndexBlobs []blob.Metadata
 
 	var eg errgroup.Group
@@ -132,12 +136,12 @@ func (m *indexBlobManagerImpl) listIndexBlobs(ctx context.Context, includeInacti
 	return results, nil
 }
 
-func (m *indexBlobManagerImpl) flushCache() {
+func (m *indexBlobManagerV0) flushCache() {
 	m.listCache.deleteListCache(indexBlobPrefix)
 	m.listCache.deleteListCache(compactionLogBlobPrefix)
 }
 
-func (m *indexBlobManagerImpl) registerCompaction(ctx context.Context, inputs, outputs []blob.Metadata, maxEventualConsistencySettleTime time.Duration) error {
+func (m *indexBlobManagerV0) registerCompaction(ctx context.Context, inputs, outputs []blob.Metadata, maxEventualConsistencySettleTime time.Duration) error {
 	logEntryBytes, err := json.Marshal(&compactionLogEntry{
 		InputMetadata:  inputs,
 		OutputMetadata: outputs,
@@ -168,11 +172,121 @@ func (m *indexBlobManagerImpl) registerCompaction(ctx context.Context, inputs, o
 	return nil
 }
 
-func (m *indexBlobManagerImpl) getIndexBlob(ctx context.Context, blobID blob.ID) ([]byte, error) {
+func (m *indexBlobManagerV0) compact(ctx context.Context, opt CompactOptions) error {
+	indexBlobs, err := m.listIndexBlobs(ctx, false)
+	if err != nil {
+		return errors.Wrap(err, "error listing active index blobs")
+	}
+
+	blobsToCompact := m.getBlobsToCompact(ctx, indexBlobs, opt)
+
+	if err := m.compactIndexBlobs(ctx, blobsToCompact, opt); err != nil {
+		return errors.Wrap(err, "error performing compaction")
+	}
+
+	if err := m.cleanup(ctx, opt.maxEventualConsistencySettleTime()); err != nil {
+		return errors.Wrap(err, "error cleaning up index blobs")
+	}
+
+	return nil
+}
+
+func (m *indexBlobManagerV0) getBlobsToCompact(ctx context.Context, indexBlobs []IndexBlobInfo, opt CompactOptions) []IndexBlobInfo {
+	var nonCompactedBlobs, verySmallBlobs []IndexBlobInfo
+
+	var totalSizeNonCompactedBlobs, totalSizeVerySmallBlobs, totalSizeMediumSizedBlobs int64
+
+	var mediumSizedBlobCount int
+
+	for _, b := range indexBlobs {
+		if b.Length > int64(m.maxPackSize) && !opt.AllIndexes {
+			continue
+		}
+
+		nonCompactedBlobs = append(nonCompactedBlobs, b)
+		totalSizeNonCompactedBlobs += b.Length
+
+		if b.Length < int64(m.maxPackSize/verySmallContentFraction) {
+			verySmallBlobs = append(verySmallBlobs, b)
+			totalSizeVerySmallBlobs += b.Length
+		} else {
+			mediumSizedBlobCount++
+			totalSizeMediumSizedBlobs += b.Length
+		}
+	}
+
+	if len(nonCompactedBlobs) < opt.MaxSmallBlobs {
+		// current count is below min allowed - nothing to do
+		log(ctx).Debugf("no small contents to compact")
+		return nil
+	}
+
+	if len(verySmallBlobs) > len(nonCompactedBlobs)/2 && mediumSizedBlobCount+1 < opt.MaxSmallBlobs {
+		log(ctx).Debugf("compacting %v very small contents", len(verySmallBlobs))
+		return verySmallBlobs
+	}
+
+	log(ctx).Debugf("compacting all %v non-compacted contents", len(nonCompactedBlobs))
+
+	return nonCompactedBlobs
+}
+
+func (m *indexBlobManagerV0) compactIndexBlobs(ctx context.Context, indexBlobs []IndexBlobInfo, opt CompactOptions) error {
+	if len(indexBlobs) <= 1 && opt.DropDeletedBefore.IsZero() && len(opt.DropContents) == 0 {
+		return nil
+	}
+
+	bld := make(packIndexBuilder)
+
+	var inputs, outputs []blob.Metadata
+
+	for i, indexBlob := range indexBlobs {
+		formatLog(ctx).Debugf("compacting-entries[%v/%v] %v", i, len(indexBlobs), indexBlob)
+
+		if err := addIndexBlobsToBuilder(ctx, m, uint32(m.encryptor.Overhead()), bld, indexBlob.BlobID); err != nil {
+			return errors.Wrap(err, "error adding index to builder")
+		}
+
+		inputs = append(inputs, indexBlob.Metadata)
+	}
+
+	// after we built index map in memory, drop contents from it
+	// we must do it after all input blobs have been merged, otherwise we may resurrect contents.
+	dropContentsFromBuilder(ctx, bld, opt)
+
+	var buf bytes.Buffer
+	if err := bld.Build(&buf, m.indexVersion); err != nil {
+		return errors.Wrap(err, "unable to build an index")
+	}
+
+	compactedIndexBlob, err := m.writeIndexBlob(ctx, buf.Bytes(), "")
+	if err != nil {
+		return errors.Wrap(err, "unable to write compacted indexes")
+	}
+
+	// compaction wrote index blob that's the same as one of the sources
+	// it must be a no-op.
+	for _, indexBlob := range indexBlobs {
+		if indexBlob.BlobID == compactedIndexBlob.BlobID {
+			formatLog(ctx).Debugf("compaction-noop")
+			return nil
+		}
+	}
+
+	outputs = append(outputs, compactedIndexBlob)
+
+	if err := m.registerCompaction(ctx, inputs, outputs, opt.maxEventualConsistencySettleTime()); err != nil {
+		return errors.Wrap(err, "unable to register compaction")
+	}
+
+	return nil
+}
+
+func (m *indexBlobManagerV0) getIndexBlob(ctx context.Context, blobID blob.ID) ([]byte, error) {
 	return m.getEncryptedBlob(ctx, blobID)
 }
 
-func (m *indexBlobManagerImpl) getEncryptedBlob(ctx context.Context, blobID blob.ID) ([]byte, error) {
+func (m *indexBlobManagerV0) getEncryptedBlob(ctx context.Context, blobID blob.ID) ([]byte, error) {
 	payload, err := m.indexBlobCache.getContent(ctx, cacheKey(blobID), blobID, 0, -1)
 	if err != nil {
 		return nil, errors.Wrap(err, "getContent")
@@ -181,11 +295,11 @@ func (m *indexBlobManagerImpl) getEncryptedBlob(ctx context.Context, blobID blob
 	return decryptFullBlob(m.hasher, m.encryptor, payload, blobID)
 }
 
-func (m *indexBlobManagerImpl) writeIndexBlob(ctx context.Context, data []byte, sessionID SessionID) (blob.Metadata, error) {
+func (m *indexBlobManagerV0) writeIndexBlob(ctx context.Context, data []byte, sessionID SessionID) (blob.Metadata, error) {
 	return m.encryptAndWriteBlob(ctx, data, indexBlobPrefix, sessionID)
 }
 
-func (m *indexBlobManagerImpl) encryptAndWriteBlob(ctx context.Context, data []byte, prefix blob.ID, sessionID SessionID) (blob.Metadata, error) {
+func (m *indexBlobManagerV0) encryptAndWriteBlob(ctx context.Context, data []byte, prefix blob.ID, sessionID SessionID) (blob.Metadata, error) {
 	blobID, data2, err := encryptFullBlob(m.hasher, m.encryptor, data, prefix, sessionID)
 	if err != nil {
 		return blob.Metadata{}, errors.Wrap(err, "error encrypting")
@@ -214,7 +328,7 @@ func (m *indexBlobManagerImpl) encryptAndWriteBlob(ctx context.Context, data []b
 	return bm, nil
 }
 
-func (m *indexBlobManagerImpl) getCompactionLogEntries(ctx context.Context, blobs []blob.Metadata) (map[blob.ID]*compactionLogEntry, error) {
+func (m *indexBlobManagerV0) getCompactionLogEntries(ctx context.Context, blobs []blob.Metadata) (map[blob.ID]*compactionLogEntry, error) {
 	results := map[blob.ID]*compactionLogEntry{}
 
 	for _, cb := range blobs {
@@ -242,7 +356,7 @@ func (m *indexBlobManagerImpl) getCompactionLogEntries(ctx context.Context, blob
 	return results, nil
 }
 
-func (m *indexBlobManagerImpl) getCleanupEntries(ctx context.Context, latestServerBlobTime time.Time, blobs []blob.Metadata) (map[blob.ID]*cleanupEntry, error) {
+func (m *indexBlobManagerV0) getCleanupEntries(ctx context.Context, latestServerBlobTime time.Time, blobs []blob.Metadata) (map[blob.ID]*cleanupEntry, error) {
 	results := map[blob.ID]*cleanupEntry{}
 
 	for _, cb := range blobs {
@@ -270,7 +384,7 @@ func (m *indexBlobManagerImpl) getCleanupEntries(ctx context.Context, latestServ
 	return results, nil
 }
 
-func (m *indexBlobManagerImpl) deleteOldBlobs(ctx context.Context, latestBlob blob.Metadata, maxEventualConsistencySettleTime time.Duration) error {
+func (m *indexBlobManagerV0) deleteOldBlobs(ctx context.Context, latestBlob blob.Metadata, maxEventualConsistencySettleTime time.Duration) error {
 	allCompactionLogBlobs, err := m.listCache.listBlobs(ctx, compactionLogBlobPrefix)
 	if err != nil {
 		return errors.Wrap(err, "error listing compaction log blobs")
@@ -305,7 +419,7 @@ func (m *indexBlobManagerImpl) deleteOldBlobs(ctx context.Context, latestBlob bl
 	return nil
 }
 
-func (m *indexBlobManagerImpl) findIndexBlobsToDelete(ctx context.Context, latestServerBlobTime time.Time, entries map[blob.ID]*compactionLogEntry, maxEventualConsistencySettleTime time.Duration) []blob.ID {
+func (m *indexBlobManagerV0) findIndexBlobsToDelete(ctx context.Context, latestServerBlobTime time.Time, entries map[blob.ID]*compactionLogEntry, maxEventualConsistencySettleTime time.Duration) []blob.ID {
 	tmp := map[blob.ID]bool{}
 
 	for _, cl := range entries {
@@ -331,7 +445,7 @@ func (m *indexBlobManagerImpl) findIndexBlobsToDelete(ctx context.Context, lates
 	return result
 }
 
-func (m *indexBlobManagerImpl) findCompactionLogBlobsToDelayCleanup(ctx context.Context, compactionBlobs []blob.Metadata) []blob.ID {
+func (m *indexBlobManagerV0) findCompactionLogBlobsToDelayCleanup(ctx context.Context, compactionBlobs []blob.Metadata) []blob.ID {
 	var result []blob.ID
 
 	for _, cb := range compactionBlobs {
@@ -342,7 +456,7 @@ func (m *indexBlobManagerImpl) findCompactionLogBlobsToDelayCleanup(ctx context.
 	return result
 }
 
-func (m *indexBlobManagerImpl) findBlobsToDelete(entries map[blob.ID]*cleanupEntry, maxEventualConsistencySettleTime time.Duration) (compactionLogs, cleanupBlobs []blob.ID) {
+func (m *indexBlobManagerV0) findBlobsToDelete(entries map[blob.ID]*cleanupEntry, maxEventualConsistencySettleTime time.Duration) (compactionLogs, cleanupBlobs []blob.ID) {
 	for k, e := range entries {
 		if e.age >= maxEventualConsistencySettleTime {
 			compactionLogs = append(compactionLogs, e.BlobIDs...)
@@ -353,7 +467,7 @@ func (m *indexBlobManagerImpl) findBlobsToDelete(entries map[blob.ID]*cleanupEnt
 	return
 }
 
-func (m *indexBlobManagerImpl) delayCleanupBlobs(ctx context.Context, blobIDs []blob.ID, cleanupScheduleTime time.Time) error {
+func (m *indexBlobManagerV0) delayCleanupBlobs(ctx context.Context, blobIDs []blob.ID, cleanupScheduleTime time.Time) error {
 	if len(blobIDs) == 0 {
 		return nil
 	}
@@ -373,7 +487,7 @@ func (m *indexBlobManagerImpl) delayCleanupBlobs(ctx context.Context, blobIDs []
 	return nil
 }
 
-func (m *indexBlobManagerImpl) deleteBlobsFromStorageAndCache(ctx context.Context, blobIDs []blob.ID) error {
+func (m *indexBlobManagerV0) deleteBlobsFromStorageAndCache(ctx context.Context, blobIDs []blob.ID) error {
 	for _, blobID := range blobIDs {
 		if err := m.st.DeleteBlob(ctx, blobID); err != nil && !errors.Is(err, blob.ErrBlobNotFound) {
 			formatLog(ctx).Debugf("delete-blob failed %v %v", blobID, err)
@@ -390,7 +504,7 @@ func (m *indexBlobManagerImpl) deleteBlobsFromStorageAndCache(ctx context.Contex
 	return nil
 }
 
-func (m *indexBlobManagerImpl) cleanup(ctx context.Context, maxEventualConsistencySettleTime time.Duration) error {
+func (m *indexBlobManagerV0) cleanup(ctx context.Context, maxEventualConsistencySettleTime time.Duration) error {
 	allCleanupBlobs, err := m.listCache.listBlobs(ctx, cleanupBlobPrefix)
 	if err != nil {
 		return errors.Wrap(err, "error listing cleanup blobs")
@@ -448,7 +448,7 @@ func verifyFakeContentsWritten(ctx context.Context, m indexBlobManager, numWritt
 	return nil
 }
 
-func fakeCompaction(ctx context.Context, m indexBlobManager, dropDeleted bool) error {
+func fakeCompaction(ctx context.Context, m *indexBlobManagerV0, dropDeleted bool) error {
 	log(ctx).Debugf("fakeCompaction(dropDeleted=%v)", dropDeleted)
 	defer log(ctx).Debugf("finished fakeCompaction(dropDeleted=%v)", dropDeleted)
 
@@ -697,7 +697,7 @@ func keysWithPrefix(data blobtesting.DataMap, prefix blob.ID) []blob.ID {
 	return res
 }
 
-func mustRegisterCompaction(t *testing.T, m indexBlobManager, inputs, outputs []blob.Metadata) {
+func mustRegisterCompaction(t *testing.T, m *indexBlobManagerV0, inputs, outputs []blob.Metadata) {
 	t.Helper()
 
 	t.Logf("compacting %v to %v", inputs, outputs)
@@ -744,7 +744,7 @@ func assertIndexBlobList(t *testing.T, m indexBlobManager, wantMD ...blob.Metada
 	require.ElementsMatch(t, got, want)
 }
 
-func newIndexBlobManagerForTesting(t *testing.T, st blob.Storage, localTimeNow func() time.Time) indexBlobManager {
+func newIndexBlobManagerForTesting(t *testing.T, st blob.Storage, localTimeNow func() time.Time) *indexBlobManagerV0 {
 	t.Helper()
 
 	p := &FormattingOptions{
@@ -767,7 +767,7 @@ func newIndexBlobManagerForTesting(t *testing.T, st blob.Storage, localTimeNow f
 		t.Fatalf("unable to create list cache: %v", err)
 	}
 
-	m := &indexBlobManagerImpl{
+	m := &indexBlobManagerV0{
 		st: st,
 		ownWritesCache: &persistentOwnWritesCache{
 			blobtesting.NewMapStorage(blobtesting.DataMap{}, nil, localTimeNow),
@@ -778,6 +778,7 @@ func newIndexBlobManagerForTesting(t *testing.T, st blob.Storage, localTimeNow f
 		hasher:         hf,
 		listCache:      lc,
 		timeNow:        localTimeNow,
+		indexVersion:   IndexVersion1,
 	}
 
 	return m
@@ -0,0 +1,133 @@
+package content
+
+import (
+	"bytes"
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/kopia/kopia/internal/epoch"
+	"github.com/kopia/kopia/internal/gather"
+	"github.com/kopia/kopia/repo/blob"
+	"github.com/kopia/kopia/repo/encryption"
+	"github.com/kopia/kopia/repo/hashing"
+)
+
+// indexBlobManagerV1 manages index blobs grouped into epochs using epoch.Manager.
+type indexBlobManagerV1 struct {
+	st             blob.Storage
+	hasher         hashing.HashFunc
+	encryptor      encryption.Encryptor
+	epochMgr       *epoch.Manager
+	indexBlobCache contentCache
+	indexVersion   int
+}
+
+func (m *indexBlobManagerV1) listIndexBlobs(ctx context.Context, includeInactive bool) ([]IndexBlobInfo, error) {
+	active, err := m.epochMgr.GetCompleteIndexSet(ctx, epoch.LatestEpoch)
+	if err != nil {
+		return nil, errors.Wrap(err, "error getting index set")
+	}
+
+	var result []IndexBlobInfo
+
+	for _, bm := range active {
+		result = append(result, IndexBlobInfo{Metadata: bm})
+	}
+
+	for i, res := range result {
+		formatLog(ctx).Debugf("active-index-blobs[%v] = %v", i, res)
+	}
+
+	return result, nil
+}
+
+func (m *indexBlobManagerV1) flushCache() {
+	m.epochMgr.Invalidate()
+}
+
+// compact compacts all settled epochs and deletes index blobs superseded by compactions.
+// Contents are dropped from the index (according to opt) only when writing range checkpoints,
+// since those are the only index blobs guaranteed to include all older entries.
+func (m *indexBlobManagerV1) compact(ctx context.Context, opt CompactOptions) error {
+	if err := m.epochMgr.Compact(ctx, func(ctx context.Context, blobIDs []blob.ID, outputPrefix blob.ID) error {
+		return m.compactEpoch(ctx, blobIDs, outputPrefix, opt)
+	}); err != nil {
+		return errors.Wrap(err, "error compacting epochs")
+	}
+
+	if err := m.epochMgr.CleanupSupersededIndexes(ctx); err != nil {
+		return errors.Wrap(err, "error cleaning up superseded index blobs")
+	}
+
+	return nil
+}
+
+func (m *indexBlobManagerV1) compactEpoch(ctx context.Context, blobIDs []blob.ID, outputPrefix blob.ID, opt CompactOptions) error {
+	bld := make(packIndexBuilder)
+
+	for i, indexBlobID := range blobIDs {
+		formatLog(ctx).Debugf("compacting-entries[%v/%v] %v", i, len(blobIDs), indexBlobID)
+
+		if err := addIndexBlobsToBuilder(ctx, m, uint32(m.encryptor.Overhead()), bld, indexBlobID); err != nil {
+			return errors.Wrap(err, "error adding index to builder")
+		}
+	}
+
+	if strings.HasPrefix(string(outputPrefix), string(epoch.RangeCheckpointIndexBlobPrefix)) {
+		dropContentsFromBuilder(ctx, bld, opt)
+	}
+
+	var buf bytes.Buffer
+	if err := bld.Build(&buf, m.indexVersion); err != nil {
+		return errors.Wrap(err, "unable to build an index")
+	}
+
+	blobID, data, err := encryptFullBlob(m.hasher, m.encryptor, buf.Bytes(), outputPrefix, "")
+	if err != nil {
+		return errors.Wrap(err, "error encrypting")
+	}
+
+	if err := m.st.PutBlob(ctx, blobID, gather.FromSlice(data)); err != nil {
+		return errors.Wrapf(err, "error writing compacted index blob %v", blobID)
+	}
+
+	formatLog(ctx).Debugf("write-compacted-index-blob %v %v", blobID, len(data))
+
+	return nil
+}
+
+func (m *indexBlobManagerV1) getIndexBlob(ctx context.Context, blobID blob.ID) ([]byte, error) {
+	payload, err := m.indexBlobCache.getContent(ctx, cacheKey(blobID), blobID, 0, -1)
+	if err != nil {
+		return nil, errors.Wrap(err, "getContent")
+	}
+
+	return decryptFullBlob(m.hasher, m.encryptor, payload, blobID)
+}
+
+func (m *indexBlobManagerV1) writeIndexBlob(ctx context.Context, data []byte, sessionID SessionID) (blob.Metadata, error) {
+	// the epoch manager will prepend the epoch-specific prefix.
+	unprefixedBlobID, data2, err := encryptFullBlob(m.hasher, m.encryptor, data, "", sessionID)
+	if err != nil {
+		return blob.Metadata{}, errors.Wrap(err, "error encrypting")
+	}
+
+	written, err := m.epochMgr.WriteIndex(ctx, map[blob.ID]blob.Bytes{
+		unprefixedBlobID: gather.FromSlice(data2),
+	})
+	if err != nil {
+		return blob.Metadata{}, errors.Wrap(err, "error writing index blob")
+	}
+
+	if len(written) != 1 {
+		return blob.Metadata{}, errors.Errorf("unexpected number of written blobs: %v", len(written))
+	}
+
+	formatLog(ctx).Debugf("write-index-blob %v %v %v", written[0].BlobID, written[0].Length, written[0].Timestamp)
+
+	return written[0], nil
+}
+
+var _ indexBlobManager = (*indexBlobManagerV1)(nil)
@@ -12,12 +12,15 @@
 	"github.com/pkg/errors"
 
 	"github.com/kopia/kopia/repo/blob"
+	"github.com/kopia/kopia/repo/compression"
 )
 
 const (
 	packHeaderSize = 8
 	deletedMarker  = 0x80000000
 
+	v1MaxPackOffset = 1<<31 - 1
+
 	entryFixedHeaderLength = 20
 	randomSuffixSize       = 32
 )
@@ -80,9 +83,8 @@ func (e indexEntryInfoV1) GetDeleted() bool {
 	return e.data[12]&0x80 != 0
 }
 
-func (e indexEntryInfoV1) GetPackOffset() uint32 {
-	const packOffsetMask = 1<<31 - 1
-	return decodeBigEndianUint32(e.data[12:]) & packOffsetMask
+func (e indexEntryInfoV1) GetPackOffset() uint64 {
+	return uint64(decodeBigEndianUint32(e.data[12:]) & v1MaxPackOffset)
 }
 
 // bytes 16..19: 4 bytes, big endian, content length.
@@ -94,6 +96,16 @@ func (e indexEntryInfoV1) GetOriginalLength() uint32 {
 	return e.GetPackedLength() - e.b.v1PerContentOverhead
 }
 
+// v1 index does not support compression.
+func (e indexEntryInfoV1) GetCompressionHeaderID() compression.HeaderID {
+	return NoCompression
+}
+
+// v1 index does not support multiple encryption keys.
+func (e indexEntryInfoV1) GetEncryptionKeyID() byte {
+	return 0
+}
+
 func (e indexEntryInfoV1) Timestamp() time.Time {
 	return time.Unix(e.GetTimestampSeconds(), 0)
 }
@@ -315,7 +327,7 @@ func (b packIndexBuilder) buildV1(output io.Writer) error {
 
 	// write header
 	header := make([]byte, packHeaderSize)
-	header[0] = 1 // version
+	header[0] = IndexVersion1
 	header[1] = byte(b1.keyLength)
 	binary.BigEndian.PutUint16(header[2:4], uint16(b1.entryLength))
 	binary.BigEndian.PutUint32(header[4:8], uint32(b1.entryCount))
@@ -408,12 +420,16 @@ func (b *indexBuilderV1) formatEntry(entry []byte, it Info) error {
 		return errors.Errorf("empty pack content ID for %v", it.GetContentID())
 	}
 
+	if it.GetPackOffset() > v1MaxPackOffset {
+		return errors.Errorf("pack offset %v of %v is too large for index v1", it.GetPackOffset(), it.GetContentID())
+	}
+
 	binary.BigEndian.PutUint32(entryPackFileOffset, b.extraDataOffset+b.packBlobIDOffsets[packBlobID])
 
 	if it.GetDeleted() {
-		binary.BigEndian.PutUint32(entryPackedOffset, it.GetPackOffset()|deletedMarker)
+		binary.BigEndian.PutUint32(entryPackedOffset, uint32(it.GetPackOffset())|deletedMarker)
 	} else {
-		binary.BigEndian.PutUint32(entryPackedOffset, it.GetPackOffset())
+		binary.BigEndian.PutUint32(entryPackedOffset, uint32(it.GetPackOffset()))
 	}
 
 	binary.BigEndian.PutUint32(entryPackedLength, it.GetPackedLength())
@@ -0,0 +1,618 @@
+package content
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/kopia/kopia/repo/blob"
+	"github.com/kopia/kopia/repo/compression"
+)
+
+const (
+	v2IndexHeaderSize      = 32 // size of fixed header at the beginning of index
+	v2PackInfoSize         = 8  // size of each pack information blob
+	v2FormatInfoSize       = 8  // size of each format information blob
+	v2EntryFixedLength     = 24 // fixed length of each entry (excluding key)
+	v2MaxFormatCount       = 1 << 16
+	v2MaxPackOffset        = 1<<47 - 1
+	v2DeletedMarker        = 0x80
+	v2MaxTimestampDelta    = 1<<32 - 1
+	v2PackNameLengthOffset = 4
+)
+
+// FormatV2 describes a format of a single pack index. The actual structure is not used,
+// it's purely for documentation purposes.
+// The struct is byte-aligned.
+type FormatV2 struct {
+	Header struct {
+		Version       byte   // format version number must be 0x02
+		KeySize       byte   // size of each key in bytes
+		EntrySize     uint16 // size of each entry in bytes, big-endian
+		EntryCount    uint32 // number of sorted (key,value) entries that follow
+		PackCount     uint32 // number of pack information entries
+		FormatCount   uint32 // number of format information entries
+		PacksOffset   uint32 // offset of pack information table within the index
+		FormatsOffset uint32 // offset of format information table within the index
+		BaseTimestamp uint32 // base timestamp in seconds since 1970/01/01 UTC, entries store delta
+		Reserved      uint32
+	}
+
+	Entries []struct {
+		Key   []byte // key bytes (KeySize)
+		Entry indexV2EntryInfo
+	}
+
+	// each entry contains offset+length of the name of the pack blob, so that each entry can refer to the index
+	// of it instead of storing the name.
+	PackInfos []struct {
+		NameOffset uint32 // offset of the name within the extra data
+		NameLength byte   // length of the name
+		Reserved   [3]byte
+	}
+
+	// each entry represents unique content format, entries refer to it by its index.
+	Formats []indexV2FormatInfo
+
+	ExtraData []byte // extra data
+}
+
+// indexV2FormatInfo describes a unique combination of content formatting options shared by multiple entries.
+type indexV2FormatInfo struct {
+	compressionHeaderID compression.HeaderID // 4 bytes, big-endian
+	formatVersion       byte
+	encryptionKeyID     byte
+	// 2 reserved bytes
+}
+
+type indexV2EntryInfo struct {
+	data      string // basically a byte array, but immutable
+	contentID ID
+	b         *indexV2
+}
+
+func (e indexV2EntryInfo) GetContentID() ID {
+	return e.contentID
+}
+
+// entry bytes 0..3: 32-bit big-endian timestamp delta in seconds relative to the base timestamp in the header.
+func (e indexV2EntryInfo) GetTimestampSeconds() int64 {
+	return int64(e.b.baseTimestamp) + int64(decodeBigEndianUint32(e.data))
+}
+
+// entry bytes 4..7: 32-bit big-endian index into the pack information table.
+func (e indexV2EntryInfo) GetPackBlobID() blob.ID {
+	packIndex := decodeBigEndianUint32(e.data[4:])
+	if int64(packIndex) >= int64(len(e.b.packBlobIDs)) {
+		return "-invalid-blob-id-"
+	}
+
+	return e.b.packBlobIDs[packIndex]
+}
+
+// entry bytes 8..13: deleted flag (MSBit) and 47 lower bits encode pack offset.
+func (e indexV2EntryInfo) GetDeleted() bool {
+	return e.data[8]&v2DeletedMarker != 0
+}
+
+func (e indexV2EntryInfo) GetPackOffset() uint64 {
+	return uint64(decodeBigEndianUint48(e.data[8:]) & v2MaxPackOffset)
+}
+
+// entry bytes 14..17: 4 bytes, big endian, packed content length.
+func (e indexV2EntryInfo) GetPackedLength() uint32 {
+	return decodeBigEndianUint32(e.data[14:])
+}
+
+// entry bytes 18..21: 4 bytes, big endian, original content length.
+func (e indexV2EntryInfo) GetOriginalLength() uint32 {
+	return decodeBigEndianUint32(e.data[18:])
+}
+
+// entry bytes 22..23: 16-bit big-endian index into the format information table.
+func (e indexV2EntryInfo) formatInfo() indexV2FormatInfo {
+	formatIndex := int(e.data[22])<<8 | int(e.data[23])
+	if formatIndex >= len(e.b.formats) {
+		return indexV2FormatInfo{}
+	}
+
+	return e.b.formats[formatIndex]
+}
+
+func (e indexV2EntryInfo) GetFormatVersion() byte {
+	return e.formatInfo().formatVersion
+}
+
+func (e indexV2EntryInfo) GetCompressionHeaderID() compression.HeaderID {
+	return e.formatInfo().compressionHeaderID
+}
+
+func (e indexV2EntryInfo) GetEncryptionKeyID() byte {
+	return e.formatInfo().encryptionKeyID
+}
+
+func (e indexV2EntryInfo) Timestamp() time.Time {
+	return time.Unix(e.GetTimestampSeconds(), 0)
+}
+
+var _ Info = indexV2EntryInfo{}
+
+type indexV2 struct {
+	hdr           headerInfo
+	baseTimestamp uint32
+	readerAt      io.ReaderAt
+	packBlobIDs   []blob.ID
+	formats       []indexV2FormatInfo
+}
+
+func (b *indexV2) ApproximateCount() int {
+	return b.hdr.entryCount
+}
+
+// Iterate invokes the provided callback function for a range of contents in the index, sorted alphabetically.
+// The iteration ends when the callback returns an error, which is propagated to the caller or when
+// all contents have been visited.
+func (b *indexV2) Iterate(r IDRange, cb func(Info) error) error {
+	startPos, err := b.findEntryPosition(r.StartID)
+	if err != nil {
+		return errors.Wrap(err, "could not find starting position")
+	}
+
+	stride := b.hdr.keySize + b.hdr.valueSize
+	entry := make([]byte, stride)
+
+	for i := startPos; i < b.hdr.entryCount; i++ {
+		n, err := b.readerAt.ReadAt(entry, int64(v2IndexHeaderSize+stride*i))
+		if err != nil || n != len(entry) {
+			return errors.Wrap(err, "unable to read from index")
+		}
+
+		key := entry[0:b.hdr.keySize]
+
+		contentID := bytesToContentID(key)
+		if contentID >= r.EndID {
+			break
+		}
+
+		i, err := b.entryToInfo(contentID, entry[b.hdr.keySize:])
+		if err != nil {
+			return errors.Wrap(err, "invalid index data")
+		}
+
+		if err := cb(i); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (b *indexV2) entryBuffer(arr *[maxEntrySize]byte) []byte {
+	stride := b.hdr.keySize + b.hdr.valueSize
+
+	if stride <= len(arr) {
+		return arr[0:stride]
+	}
+
+	return make([]byte, stride)
+}
+
+func (b *indexV2) findEntryPosition(contentID ID) (int, error) {
+	stride := b.hdr.keySize + b.hdr.valueSize
+
+	var entryArr [maxEntrySize]byte
+
+	entryBuf := b.entryBuffer(&entryArr)
+
+	var readErr error
+
+	pos := sort.Search(b.hdr.entryCount, func(p int) bool {
+		if readErr != nil {
+			return false
+		}
+		_, err := b.readerAt.ReadAt(entryBuf, int64(v2IndexHeaderSize+stride*p))
+		if err != nil {
+			readErr = err
+			return false
+		}
+
+		return bytesToContentID(entryBuf[0:b.hdr.keySize]) >= contentID
+	})
+
+	return pos, readErr
+}
+
+func (b *indexV2) findEntryPositionExact(idBytes, entryBuf []byte) (int, error) {
+	stride := b.hdr.keySize + b.hdr.valueSize
+
+	var readErr error
+
+	pos := sort.Search(b.hdr.entryCount, func(p int) bool {
+		if readErr != nil {
+			return false
+		}
+		_, err := b.readerAt.ReadAt(entryBuf, int64(v2IndexHeaderSize+stride*p))
+		if err != nil {
+			readErr = err
+			return false
+		}
+
+		return contentIDBytesGreaterOrEqual(entryBuf[0:b.hdr.keySize], idBytes)
+	})
+
+	return pos, readErr
+}
+
+func (b *indexV2) findEntry(output []byte, contentID ID) ([]byte, error) {
+	var hashBuf [maxContentIDSize]byte
+
+	key := contentIDToBytes(hashBuf[:0], contentID)
+
+	// empty index blob, this is possible when compaction removes exactly everything
+	if b.hdr.keySize == unknownKeySize {
+		return nil, nil
+	}
+
+	if len(key) != b.hdr.keySize {
+		return nil, errors.Errorf("invalid content ID: %q (%v vs %v)", contentID, len(key), b.hdr.keySize)
+	}
+
+	stride := b.hdr.keySize + b.hdr.valueSize
+
+	var entryArr [maxEntrySize]byte
+
+	entryBuf := b.entryBuffer(&entryArr)
+
+	position, err := b.findEntryPositionExact(key, entryBuf)
+	if err != nil {
+		return nil, err
+	}
+
+	if position >= b.hdr.entryCount {
+		return nil, nil
+	}
+
+	if _, err := b.readerAt.ReadAt(entryBuf, int64(v2IndexHeaderSize+stride*position)); err != nil {
+		return nil, errors.Wrap(err, "error reading header")
+	}
+
+	if bytes.Equal(entryBuf[0:len(key)], key) {
+		return append(output, entryBuf[len(key):]...), nil
+	}
+
+	return nil, nil
+}
+
+// GetInfo returns information about a given content. If a content is not found, nil is returned.
+func (b *indexV2) GetInfo(contentID ID) (Info, error) {
+	var entryBuf [maxEntrySize]byte
+
+	e, err := b.findEntry(entryBuf[:0], contentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if e == nil {
+		return nil, nil
+	}
+
+	return b.entryToInfo(contentID, e)
+}
+
+func (b *indexV2) entryToInfo(contentID ID, entryData []byte) (Info, error) {
+	if len(entryData) < v2EntryFixedLength {
+		return nil, errors.Errorf("invalid entry length: %v", len(entryData))
+	}
+
+	// convert to 'entryData' string to make it read-only
+	return indexV2EntryInfo{string(entryData), contentID, b}, nil
+}
+
+// Close closes the index and the underlying reader.
+func (b *indexV2) Close() error {
+	if closer, ok := b.readerAt.(io.Closer); ok {
+		return closer.Close()
+	}
+
+	return nil
+}
+
+// openV2PackIndex reads the v2-specific header and pre-loads pack and format information tables.
+func openV2PackIndex(readerAt io.ReaderAt, hi headerInfo) (packIndex, error) {
+	if hi.keySize != unknownKeySize && hi.valueSize < v2EntryFixedLength {
+		return nil, errors.Errorf("invalid entry size: %v", hi.valueSize)
+	}
+
+	var header [v2IndexHeaderSize]byte
+
+	if n, err := readerAt.ReadAt(header[:], 0); err != nil || n != len(header) {
+		return nil, errors.Wrap(err, "invalid v2 header")
+	}
+
+	packCount := binary.BigEndian.Uint32(header[8:12])
+	formatCount := binary.BigEndian.Uint32(header[12:16])
+	packsOffset := binary.BigEndian.Uint32(header[16:20])
+	formatsOffset := binary.BigEndian.Uint32(header[20:24])
+
+	// each pack and format must be referenced by at least one entry.
+	if int64(packCount) > int64(hi.entryCount) || int64(formatCount) > int64(hi.entryCount) || formatCount > v2MaxFormatCount {
+		return nil, errors.Errorf("invalid header")
+	}
+
+	// tables immediately follow the entries.
+	if hi.keySize != unknownKeySize {
+		if int64(packsOffset) != int64(v2IndexHeaderSize)+int64(hi.entryCount)*int64(hi.keySize+hi.valueSize) {
+			return nil, errors.Errorf("invalid packs offset")
+		}
+	}
+
+	if int64(formatsOffset) != int64(packsOffset)+int64(packCount)*v2PackInfoSize {
+		return nil, errors.Errorf("invalid formats offset")
+	}
+
+	// make sure the tables are fully present before allocating memory for them.
+	if tablesEnd := int64(formatsOffset) + int64(formatCount)*v2FormatInfoSize; tablesEnd > 0 {
+		var lastByte [1]byte
+
+		if _, err := readerAt.ReadAt(lastByte[:], tablesEnd-1); err != nil {
+			return nil, errors.Wrap(err, "invalid index tables")
+		}
+	}
+
+	packInfoData := make([]byte, int(packCount)*v2PackInfoSize)
+	if _, err := readerAt.ReadAt(packInfoData, int64(packsOffset)); err != nil {
+		return nil, errors.Wrap(err, "unable to read pack information")
+	}
+
+	formatInfoData := make([]byte, int(formatCount)*v2FormatInfoSize)
+	if _, err := readerAt.ReadAt(formatInfoData, int64(formatsOffset)); err != nil {
+		return nil, errors.Wrap(err, "unable to read format information")
+	}
+
+	b := &indexV2{
+		hdr:           hi,
+		baseTimestamp: binary.BigEndian.Uint32(header[24:28]),
+		readerAt:      readerAt,
+	}
+
+	var nameBuf [256]byte
+
+	for i := 0; i < int(packCount); i++ {
+		pi := packInfoData[i*v2PackInfoSize:]
+		nameOffset := binary.BigEndian.Uint32(pi)
+		nameLength := int(pi[v2PackNameLengthOffset])
+
+		if n, err := readerAt.ReadAt(nameBuf[0:nameLength], int64(nameOffset)); err != nil || n != nameLength {
+			return nil, errors.Wrap(err, "unable to read pack blob ID")
+		}
+
+		b.packBlobIDs = append(b.packBlobIDs, blob.ID(nameBuf[0:nameLength]))
+	}
+
+	for i := 0; i < int(formatCount); i++ {
+		fi := formatInfoData[i*v2FormatInfoSize:]
+
+		b.formats = append(b.formats, indexV2FormatInfo{
+			compressionHeaderID: compression.HeaderID(binary.BigEndian.Uint32(fi)),
+			formatVersion:       fi[4],
+			encryptionKeyID:     fi[5],
+		})
+	}
+
+	return b, nil
+}
+
+type indexBuilderV2 struct {
+	packBlobIDs   []blob.ID
+	packIndexes   map[blob.ID]int
+	formats       []indexV2FormatInfo
+	formatIndexes map[indexV2FormatInfo]int
+	baseTimestamp int64
+	keyLength     int
+	entryCount    int
+}
+
+func indexV2FormatInfoFromInfo(it Info) indexV2FormatInfo {
+	return indexV2FormatInfo{
+		compressionHeaderID: it.GetCompressionHeaderID(),
+		formatVersion:       it.GetFormatVersion(),
+		encryptionKeyID:     it.GetEncryptionKeyID(),
+	}
+}
+
+// buildV2 writes the pack index to the provided output.
+func (b packIndexBuilder) buildV2(output io.Writer) error {
+	allContents := b.sortedContents()
+	b2 := &indexBuilderV2{
+		packIndexes:   map[blob.ID]int{},
+		formatIndexes: map[indexV2FormatInfo]int{},
+		keyLength:     -1,
+		entryCount:    len(allContents),
+	}
+
+	if err := b2.prepare(allContents); err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(output)
+
+	entriesSize := b2.entryCount * (b2.keyLength + v2EntryFixedLength)
+	packsOffset := v2IndexHeaderSize + entriesSize
+	formatsOffset := packsOffset + len(b2.packBlobIDs)*v2PackInfoSize
+	extraDataOffset := formatsOffset + len(b2.formats)*v2FormatInfoSize
+
+	// write header
+	header := make([]byte, v2IndexHeaderSize)
+	header[0] = IndexVersion2
+	header[1] = byte(b2.keyLength)
+	binary.BigEndian.PutUint16(header[2:4], uint16(v2EntryFixedLength))
+	binary.BigEndian.PutUint32(header[4:8], uint32(b2.entryCount))
+	binary.BigEndian.PutUint32(header[8:12], uint32(len(b2.packBlobIDs)))
+	binary.BigEndian.PutUint32(header[12:16], uint32(len(b2.formats)))
+	binary.BigEndian.PutUint32(header[16:20], uint32(packsOffset))
+	binary.BigEndian.PutUint32(header[20:24], uint32(formatsOffset))
+	binary.BigEndian.PutUint32(header[24:28], uint32(b2.baseTimestamp))
+
+	if _, err := w.Write(header); err != nil {
+		return errors.Wrap(err, "unable to write header")
+	}
+
+	// write all sorted contents.
+	entry := make([]byte, v2EntryFixedLength)
+
+	for _, it := range allContents {
+		if err := b2.writeEntry(w, it, entry); err != nil {
+			return errors.Wrap(err, "unable to write entry")
+		}
+	}
+
+	// write pack information followed by pack names in extra data.
+	var extraData []byte
+
+	packInfo := make([]byte, v2PackInfoSize)
+
+	for _, packBlobID := range b2.packBlobIDs {
+		binary.BigEndian.PutUint32(packInfo, uint32(extraDataOffset+len(extraData)))
+		packInfo[v2PackNameLengthOffset] = byte(len(packBlobID))
+
+		if _, err := w.Write(packInfo); err != nil {
+			return errors.Wrap(err, "error writing pack information")
+		}
+
+		extraData = append(extraData, packBlobID...)
+	}
+
+	formatInfo := make([]byte, v2FormatInfoSize)
+
+	for _, f := range b2.formats {
+		binary.BigEndian.PutUint32(formatInfo, uint32(f.compressionHeaderID))
+		formatInfo[4] = f.formatVersion
+		formatInfo[5] = f.encryptionKeyID
+
+		if _, err := w.Write(formatInfo); err != nil {
+			return errors.Wrap(err, "error writing format information")
+		}
+	}
+
+	if _, err := w.Write(extraData); err != nil {
+		return errors.Wrap(err, "error writing extra data")
+	}
+
+	randomSuffix := make([]byte, randomSuffixSize)
+	if _, err := rand.Read(randomSuffix); err != nil {
+		return errors.Wrap(err, "error getting random bytes for suffix")
+	}
+
+	if _, err := w.Write(randomSuffix); err != nil {
+		return errors.Wrap(err, "error writing extra random suffix to ensure indexes are always globally unique")
+	}
+
+	return w.Flush()
+}
+
+// prepare computes the key length, base timestamp and the tables of unique packs and formats.
+func (b *indexBuilderV2) prepare(allContents []Info) error {
+	var hashBuf [maxContentIDSize]byte
+
+	for i, it := range allContents {
+		if i == 0 {
+			b.keyLength = len(contentIDToBytes(hashBuf[:0], it.GetContentID()))
+			b.baseTimestamp = it.GetTimestampSeconds()
+		}
+
+		if ts := it.GetTimestampSeconds(); ts < b.baseTimestamp {
+			b.baseTimestamp = ts
+		}
+
+		packBlobID := it.GetPackBlobID()
+		if len(packBlobID) == 0 {
+			return errors.Errorf("empty pack content ID for %v", it.GetContentID())
+		}
+
+		if len(packBlobID) > 255 { // nolint:gomnd
+			return errors.Errorf("pack blob ID too long for %v", it.GetContentID())
+		}
+
+		if _, ok := b.packIndexes[packBlobID]; !ok {
+			b.packIndexes[packBlobID] = len(b.packBlobIDs)
+			b.packBlobIDs = append(b.packBlobIDs, packBlobID)
+		}
+
+		fi := indexV2FormatInfoFromInfo(it)
+		if _, ok := b.formatIndexes[fi]; !ok {
+			if len(b.formats) >= v2MaxFormatCount {
+				return errors.Errorf("too many unique content formats")
+			}
+
+			b.formatIndexes[fi] = len(b.formats)
+			b.formats = append(b.formats, fi)
+		}
+	}
+
+	if b.baseTimestamp < 0 || b.baseTimestamp > v2MaxTimestampDelta {
+		return errors.Errorf("invalid base timestamp: %v", b.baseTimestamp)
+	}
+
+	return nil
+}
+
+func (b *indexBuilderV2) writeEntry(w io.Writer, it Info, entry []byte) error {
+	var hashBuf [maxContentIDSize]byte
+
+	k := contentIDToBytes(hashBuf[:0], it.GetContentID())
+
+	if len(k) != b.keyLength {
+		return errors.Errorf("inconsistent key length: %v vs %v", len(k), b.keyLength)
+	}
+
+	if err := b.formatEntry(entry, it); err != nil {
+		return errors.Wrap(err, "unable to format entry")
+	}
+
+	if _, err := w.Write(k); err != nil {
+		return errors.Wrap(err, "error writing entry key")
+	}
+
+	if _, err := w.Write(entry); err != nil {
+		return errors.Wrap(err, "error writing entry")
+	}
+
+	return nil
+}
+
+func (b *indexBuilderV2) formatEntry(entry []byte, it Info) error {
+	timestampDelta := it.GetTimestampSeconds() - b.baseTimestamp
+	if timestampDelta > v2MaxTimestampDelta {
+		return errors.Errorf("timestamp out of range for %v", it.GetContentID())
+	}
+
+	binary.BigEndian.PutUint32(entry[0:4], uint32(timestampDelta))
+	binary.BigEndian.PutUint32(entry[4:8], uint32(b.packIndexes[it.GetPackBlobID()]))
+
+	if it.GetPackOffset() > v2MaxPackOffset {
+		return errors.Errorf("pack offset %v of %v is out of range", it.GetPackOffset(), it.GetContentID())
+	}
+
+	packOffsetAndFlags := it.GetPackOffset()
+	if it.GetDeleted() {
+		packOffsetAndFlags |= v2DeletedMarker << 40 // nolint:gomnd
+	}
+
+	// 48-bit big-endian value
+	var offsetBuf [8]byte
+
+	binary.BigEndian.PutUint64(offsetBuf[:], packOffsetAndFlags)
+	copy(entry[8:14], offsetBuf[2:])
+
+	binary.BigEndian.PutUint32(entry[14:18], it.GetPackedLength())
+	binary.BigEndian.PutUint32(entry[18:22], it.GetOriginalLength())
+	binary.BigEndian.PutUint16(entry[22:24], uint16(b.formatIndexes[indexV2FormatInfoFromInfo(it)]))
+
+	return nil
+}
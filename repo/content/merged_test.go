@@ -9,7 +9,8 @@
 )
 
 func TestMerged(t *testing.T) {
-	i1, err := indexWithItems(
+	// merged index transparently combines indexes in different formats.
+	i1, err := indexWithItems(IndexVersion1,
 		&InfoStruct{ContentID: "aabbcc", TimestampSeconds: 1, PackBlobID: "xx", PackOffset: 11},
 		&InfoStruct{ContentID: "ddeeff", TimestampSeconds: 1, PackBlobID: "xx", PackOffset: 111},
 		&InfoStruct{ContentID: "z010203", TimestampSeconds: 1, PackBlobID: "xx", PackOffset: 111},
@@ -19,7 +20,7 @@ func TestMerged(t *testing.T) {
 		t.Fatalf("can't create index: %v", err)
 	}
 
-	i2, err := indexWithItems(
+	i2, err := indexWithItems(IndexVersion2,
 		&InfoStruct{ContentID: "aabbcc", TimestampSeconds: 3, PackBlobID: "yy", PackOffset: 33},
 		&InfoStruct{ContentID: "xaabbcc", TimestampSeconds: 1, PackBlobID: "xx", PackOffset: 111},
 		&InfoStruct{ContentID: "de1e1e", TimestampSeconds: 4, PackBlobID: "xx", PackOffset: 222, Deleted: true},
@@ -28,7 +29,7 @@ func TestMerged(t *testing.T) {
 		t.Fatalf("can't create index: %v", err)
 	}
 
-	i3, err := indexWithItems(
+	i3, err := indexWithItems(IndexVersion1,
 		&InfoStruct{ContentID: "aabbcc", TimestampSeconds: 2, PackBlobID: "zz", PackOffset: 22},
 		&InfoStruct{ContentID: "ddeeff", TimestampSeconds: 1, PackBlobID: "zz", PackOffset: 222},
 		&InfoStruct{ContentID: "k010203", TimestampSeconds: 1, PackBlobID: "xx", PackOffset: 111},
@@ -45,7 +46,7 @@ func TestMerged(t *testing.T) {
 		t.Fatalf("unable to get info: %v", err)
 	}
 
-	if got, want := i.GetPackOffset(), uint32(33); got != want {
+	if got, want := i.GetPackOffset(), uint64(33); got != want {
 		t.Errorf("invalid pack offset %v, wanted %v", got, want)
 	}
 
@@ -146,7 +147,7 @@ func iterateIDRange(t *testing.T, m packIndex, r IDRange) []ID {
 	return inOrder
 }
 
-func indexWithItems(items ...Info) (packIndex, error) {
+func indexWithItems(version int, items ...Info) (packIndex, error) {
 	b := make(packIndexBuilder)
 
 	for _, it := range items {
@@ -154,7 +155,7 @@ func indexWithItems(items ...Info) (packIndex, error) {
 	}
 
 	var buf bytes.Buffer
-	if err := b.Build(&buf); err != nil {
+	if err := b.Build(&buf, version); err != nil {
 		return nil, errors.Wrap(err, "build error")
 	}
 
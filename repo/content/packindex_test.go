@@ -10,6 +10,7 @@
 	"testing"
 
 	"github.com/kopia/kopia/repo/blob"
+	"github.com/kopia/kopia/repo/compression"
 )
 
 const fakeEncryptionOverhead = 27
@@ -41,11 +42,11 @@ func deterministicPackBlobID(id int) blob.ID {
 	return blob.ID(fmt.Sprintf("%x", h.Sum(nil)))
 }
 
-func deterministicPackedOffset(id int) uint32 {
+func deterministicPackedOffset(id int) uint64 {
 	s := rand.NewSource(int64(id + 1))
 	rnd := rand.New(s)
 
-	return uint32(rnd.Int31())
+	return uint64(rnd.Int31())
 }
 
 func deterministicPackedLength(id int) uint32 {
@@ -59,36 +60,69 @@ func deterministicFormatVersion(id int) byte {
 	return byte(id % 100)
 }
 
+func deterministicOriginalLength(id int) uint32 {
+	s := rand.NewSource(int64(id + 3))
+	rnd := rand.New(s)
+
+	return uint32(rnd.Int31())
+}
+
+func deterministicCompressionHeaderID(id int) compression.HeaderID {
+	return compression.HeaderID(id % 3)
+}
+
 func randomUnixTime() int64 {
 	return int64(rand.Int31())
 }
 
+func TestPackIndex_V1(t *testing.T) {
+	testPackIndex(t, IndexVersion1)
+}
+
+func TestPackIndex_V2(t *testing.T) {
+	testPackIndex(t, IndexVersion2)
+}
+
+func deterministicInfo(prefix string, i int, deleted bool, version int) *InfoStruct {
+	is := &InfoStruct{
+		TimestampSeconds: randomUnixTime(),
+		Deleted:          deleted,
+		ContentID:        deterministicContentID(prefix, i),
+		PackBlobID:       deterministicPackBlobID(i),
+		PackOffset:       deterministicPackedOffset(i),
+		PackedLength:     deterministicPackedLength(i),
+		FormatVersion:    deterministicFormatVersion(i),
+	}
+
+	// v1 index does not store original length nor compression
+	if version >= IndexVersion2 {
+		is.OriginalLength = deterministicOriginalLength(i)
+		is.CompressionHeaderID = deterministicCompressionHeaderID(i)
+	}
+
+	return is
+}
+
+// expectedInfo returns the information expected to be read back from the index in a given version.
+func expectedInfo(want Info, version int) Info {
+	if version == IndexVersion1 {
+		return withOriginalLength{want, want.GetPackedLength() - fakeEncryptionOverhead}
+	}
+
+	return want
+}
+
 //nolint:gocyclo
-func TestPackIndex(t *testing.T) {
+func testPackIndex(t *testing.T, version int) {
 	var infos []Info
 
 	// deleted contents with all information
 	for i := 0; i < 100; i++ {
-		infos = append(infos, &InfoStruct{
-			TimestampSeconds: randomUnixTime(),
-			Deleted:          true,
-			ContentID:        deterministicContentID("deleted-packed", i),
-			PackBlobID:       deterministicPackBlobID(i),
-			PackOffset:       deterministicPackedOffset(i),
-			PackedLength:     deterministicPackedLength(i),
-			FormatVersion:    deterministicFormatVersion(i),
-		})
+		infos = append(infos, deterministicInfo("deleted-packed", i, true, version))
 	}
 	// non-deleted content
 	for i := 0; i < 100; i++ {
-		infos = append(infos, &InfoStruct{
-			TimestampSeconds: randomUnixTime(),
-			ContentID:        deterministicContentID("packed", i),
-			PackBlobID:       deterministicPackBlobID(i),
-			PackOffset:       deterministicPackedOffset(i),
-			PackedLength:     deterministicPackedLength(i),
-			FormatVersion:    deterministicFormatVersion(i),
-		})
+		infos = append(infos, deterministicInfo("packed", i, false, version))
 	}
 
 	infoMap := map[ID]Info{}
@@ -105,15 +139,15 @@ func TestPackIndex(t *testing.T) {
 
 	var buf1, buf2, buf3 bytes.Buffer
 
-	if err := b1.Build(&buf1); err != nil {
+	if err := b1.Build(&buf1, version); err != nil {
 		t.Errorf("unable to build: %v", err)
 	}
 
-	if err := b1.Build(&buf2); err != nil {
+	if err := b1.Build(&buf2, version); err != nil {
 		t.Errorf("unable to build: %v", err)
 	}
 
-	if err := b1.Build(&buf3); err != nil {
+	if err := b1.Build(&buf3, version); err != nil {
 		t.Errorf("unable to build: %v", err)
 	}
 
@@ -155,7 +189,7 @@ func TestPackIndex(t *testing.T) {
 			continue
 		}
 
-		want = withOriginalLength{want, want.GetPackedLength() - fakeEncryptionOverhead}
+		want = expectedInfo(want, version)
 
 		if diff := infoDiff(want, info2); len(diff) != 0 {
 			t.Errorf("invalid value retrieved: diff: %v", diff)
@@ -166,7 +200,7 @@ func TestPackIndex(t *testing.T) {
 
 	assertNoError(t, ndx.Iterate(AllIDs, func(info2 Info) error {
 		want := infoMap[info2.GetContentID()]
-		want = withOriginalLength{want, want.GetPackedLength() - fakeEncryptionOverhead}
+		want = expectedInfo(want, version)
 
 		if diff := infoDiff(want, info2); len(diff) != 0 {
 			t.Errorf("invalid value retrieved: %v", diff)
@@ -208,6 +242,48 @@ func TestPackIndex(t *testing.T) {
 	}
 }
 
+func TestPackIndexLargeOffset(t *testing.T) {
+	want := &InfoStruct{
+		TimestampSeconds: randomUnixTime(),
+		ContentID:        deterministicContentID("large", 1),
+		PackBlobID:       deterministicPackBlobID(1),
+		PackOffset:       1<<32 + 12345,
+		PackedLength:     100,
+		OriginalLength:   90,
+		FormatVersion:    2,
+	}
+
+	b := packIndexBuilder{}
+	b.Add(want)
+
+	var buf bytes.Buffer
+
+	if err := b.Build(&buf, IndexVersion1); err == nil {
+		t.Fatalf("unexpected success building v1 index with offset that does not fit")
+	}
+
+	buf.Reset()
+
+	if err := b.Build(&buf, IndexVersion2); err != nil {
+		t.Fatalf("unable to build index: %v", err)
+	}
+
+	ndx, err := openPackIndex(bytes.NewReader(buf.Bytes()), fakeEncryptionOverhead)
+	if err != nil {
+		t.Fatalf("can't open index: %v", err)
+	}
+	defer ndx.Close()
+
+	got, err := ndx.GetInfo(want.GetContentID())
+	if err != nil {
+		t.Fatalf("unable to find %v: %v", want.GetContentID(), err)
+	}
+
+	if diff := infoDiff(want, got); len(diff) != 0 {
+		t.Errorf("invalid value retrieved: diff: %v", diff)
+	}
+}
+
 func fuzzTestIndexOpen(originalData []byte) {
 	// use consistent random
 	rnd := rand.New(rand.NewSource(12345))
@@ -319,6 +395,14 @@ func infoDiff(i1, i2 Info, ignore ...string) []string {
 		diffs = append(diffs, fmt.Sprintf("GetTimestampSeconds %v != %v", l, r))
 	}
 
+	if l, r := i1.GetCompressionHeaderID(), i2.GetCompressionHeaderID(); l != r {
+		diffs = append(diffs, fmt.Sprintf("GetCompressionHeaderID %v != %v", l, r))
+	}
+
+	if l, r := i1.GetEncryptionKeyID(), i2.GetEncryptionKeyID(); l != r {
+		diffs = append(diffs, fmt.Sprintf("GetEncryptionKeyID %v != %v", l, r))
+	}
+
 	var result []string
 
 	for _, v := range diffs {
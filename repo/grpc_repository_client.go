@@ -7,6 +7,7 @@
 	"io"
 	"net/url"
 	"runtime"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -22,6 +23,7 @@
 	"github.com/kopia/kopia/internal/retry"
 	"github.com/kopia/kopia/internal/tlsutil"
 	"github.com/kopia/kopia/repo/blob"
+	"github.com/kopia/kopia/repo/compression"
 	"github.com/kopia/kopia/repo/content"
 	"github.com/kopia/kopia/repo/hashing"
 	"github.com/kopia/kopia/repo/manifest"
@@ -42,6 +44,8 @@ func errNoSessionResponse() error {
 // grpcRepositoryClient is an implementation of Repository that connects to an instance of
 // GPRC API server hosted by `kopia server`.
 type grpcRepositoryClient struct {
+	uploadedBytes int64 // must be 64-bit aligned due to atomic access on ARM
+
 	connRefCount *int32
 	conn         *grpc.ClientConn
 
@@ -359,6 +363,10 @@ func (r *grpcRepositoryClient) Refresh(ctx context.Context) error {
 	return nil
 }
 
+func (r *grpcRepositoryClient) UploadedBytes() int64 {
+	return atomic.LoadInt64(&r.uploadedBytes)
+}
+
 func (r *grpcRepositoryClient) Flush(ctx context.Context) error {
 	_, err := r.inSessionWithoutRetry(ctx, func(ctx context.Context, sess *grpcInnerSession) (interface{}, error) {
 		return false, sess.Flush(ctx)
@@ -483,6 +491,8 @@ func errorFromSessionResponse(rr *apipb.ErrorResponse) error {
 		return content.ErrContentNotFound
 	case apipb.ErrorResponse_STREAM_BROKEN:
 		return errors.Wrap(io.EOF, rr.Message)
+	case apipb.ErrorResponse_QUOTA_EXCEEDED:
+		return errors.Wrap(ErrQuotaExceeded, strings.TrimSuffix(rr.Message, ": "+ErrQuotaExceeded.Error()))
 	default:
 		return errors.New(rr.Message)
 	}
@@ -529,11 +539,25 @@ func (r *grpcInnerSession) GetContent(ctx context.Context, contentID content.ID)
 	return nil, errNoSessionResponse()
 }
 
-func (r *grpcRepositoryClient) WriteContent(ctx context.Context, data []byte, prefix content.ID) (content.ID, error) {
+// SupportsContentCompression returns false, because the protocol does not carry content compression.
+func (r *grpcRepositoryClient) SupportsContentCompression() bool {
+	return false
+}
+
+// SupportsSparseObjects returns false, because the repository format of the server is not known.
+func (r *grpcRepositoryClient) SupportsSparseObjects() bool {
+	return false
+}
+
+func (r *grpcRepositoryClient) WriteContent(ctx context.Context, data []byte, prefix content.ID, comp compression.HeaderID) (content.ID, error) {
 	if err := content.ValidatePrefix(prefix); err != nil {
 		return "", errors.Wrap(err, "invalid prefix")
 	}
 
+	if comp != content.NoCompression {
+		return "", errContentCompressionNotSupported
+	}
+
 	var hashOutput [128]byte
 
 	contentID := prefix + content.ID(hex.EncodeToString(r.h(hashOutput[:0], data)))
@@ -544,6 +568,7 @@ func (r *grpcRepositoryClient) WriteContent(ctx context.Context, data []byte, pr
 		return contentID, nil
 	}
 
+	atomic.AddInt64(&r.uploadedBytes, int64(len(data)))
 	r.opt.OnUpload(int64(len(data)))
 
 	v, err := r.inSessionWithoutRetry(ctx, func(ctx context.Context, sess *grpcInnerSession) (interface{}, error) {
@@ -642,14 +667,13 @@ func (c grpcCreds) RequireTransportSecurity() bool {
 // OpenGRPCAPIRepository opens the Repository based on remote GRPC server.
 // The APIServerInfo must have the address of the repository as 'https://host:port'
 func OpenGRPCAPIRepository(ctx context.Context, si *APIServerInfo, cliOpts ClientOptions, contentCache *cache.PersistentCache, password string) (Repository, error) {
-	var transportCreds credentials.TransportCredentials
-
-	if si.TrustedServerCertificateFingerprint != "" {
-		transportCreds = credentials.NewTLS(tlsutil.TLSConfigTrustingSingleCertificate(si.TrustedServerCertificateFingerprint))
-	} else {
-		transportCreds = credentials.NewClientTLSFromCert(nil, "")
+	tlsConfig, err := tlsutil.ClientTLSConfig(si.TrustedServerCertificateFingerprint, si.ClientCertificateFile, si.ClientKeyFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to initialize TLS")
 	}
 
+	transportCreds := credentials.NewTLS(tlsConfig)
+
 	u, err := url.Parse(si.BaseURL)
 	if err != nil {
 		return nil, errors.Wrap(err, "unable to parse server URL")
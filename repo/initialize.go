@@ -8,6 +8,7 @@
 	"github.com/pkg/errors"
 
 	"github.com/kopia/kopia/repo/blob"
+	"github.com/kopia/kopia/repo/blob/ecc"
 	"github.com/kopia/kopia/repo/content"
 	"github.com/kopia/kopia/repo/encryption"
 	"github.com/kopia/kopia/repo/hashing"
@@ -35,6 +36,8 @@ type NewRepositoryOptions struct {
 	BlockFormat  content.FormattingOptions `json:"blockFormat"`
 	DisableHMAC  bool                      `json:"disableHMAC"`
 	ObjectFormat object.Format             `json:"objectFormat"` // object format
+
+	ErrorCorrection *ecc.Options `json:"errorCorrection,omitempty"` // error correction, disabled when nil
 }
 
 // ErrAlreadyInitialized indicates that repository has already been initialized.
@@ -57,6 +60,10 @@ func Initialize(ctx context.Context, st blob.Storage, opt *NewRepositoryOptions,
 		return errors.Wrap(err, "unexpected error when checking for format blob")
 	}
 
+	if err := repositoryObjectFormatFromOptions(opt).Validate(); err != nil {
+		return errors.Wrap(err, "invalid repository format options")
+	}
+
 	format := formatBlobFromOptions(opt)
 
 	masterKey, err := format.deriveMasterKeyFromPassword(password)
@@ -90,12 +97,16 @@ func formatBlobFromOptions(opt *NewRepositoryOptions) *formatBlob {
 func repositoryObjectFormatFromOptions(opt *NewRepositoryOptions) *repositoryObjectFormat {
 	f := &repositoryObjectFormat{
 		FormattingOptions: content.FormattingOptions{
-			Version:     1,
-			Hash:        applyDefaultString(opt.BlockFormat.Hash, hashing.DefaultAlgorithm),
-			Encryption:  applyDefaultString(opt.BlockFormat.Encryption, encryption.DefaultAlgorithm),
-			HMACSecret:  applyDefaultRandomBytes(opt.BlockFormat.HMACSecret, hmacSecretLength),
-			MasterKey:   applyDefaultRandomBytes(opt.BlockFormat.MasterKey, masterKeyLength),
-			MaxPackSize: applyDefaultInt(opt.BlockFormat.MaxPackSize, 20<<20), //nolint:gomnd
+			Version:         applyDefaultInt(opt.BlockFormat.Version, content.FormatVersion1),
+			Hash:            applyDefaultString(opt.BlockFormat.Hash, hashing.DefaultAlgorithm),
+			Encryption:      applyDefaultString(opt.BlockFormat.Encryption, encryption.DefaultAlgorithm),
+			HMACSecret:      applyDefaultRandomBytes(opt.BlockFormat.HMACSecret, hmacSecretLength),
+			MasterKey:       applyDefaultRandomBytes(opt.BlockFormat.MasterKey, masterKeyLength),
+			MaxPackSize:     applyDefaultInt(opt.BlockFormat.MaxPackSize, 20<<20), //nolint:gomnd
+			IndexVersion:    applyDefaultInt(opt.BlockFormat.IndexVersion, content.IndexVersion1),
+			EpochParameters: opt.BlockFormat.EpochParameters,
+			RetentionMode:   opt.BlockFormat.RetentionMode,
+			RetentionPeriod: opt.BlockFormat.RetentionPeriod,
 		},
 		Format: object.Format{
 			Splitter: applyDefaultString(opt.ObjectFormat.Splitter, splitter.DefaultAlgorithm),
@@ -106,6 +117,11 @@ func repositoryObjectFormatFromOptions(opt *NewRepositoryOptions) *repositoryObj
 		f.HMACSecret = nil
 	}
 
+	if opt.ErrorCorrection != nil {
+		ec := opt.ErrorCorrection.ApplyDefaults()
+		f.ErrorCorrection = &ec
+	}
+
 	return f
 }
 
@@ -11,6 +11,8 @@
 
 	"github.com/kopia/kopia/internal/atomicfile"
 	"github.com/kopia/kopia/repo/blob"
+	"github.com/kopia/kopia/repo/blob/ecc"
+	"github.com/kopia/kopia/repo/blob/throttling"
 	"github.com/kopia/kopia/repo/content"
 	"github.com/kopia/kopia/repo/object"
 )
@@ -26,6 +28,9 @@ type ClientOptions struct {
 	Description string `json:"description,omitempty"`
 
 	EnableActions bool `json:"enableActions"`
+
+	// EnableNotificationCommands allows notification profiles stored in the repository to run local commands.
+	EnableNotificationCommands bool `json:"enableNotificationCommands,omitempty"`
 }
 
 // ApplyDefaults returns a copy of ClientOptions with defaults filled out.
@@ -81,6 +86,9 @@ type LocalConfig struct {
 
 	Caching *content.CachingOptions `json:"caching,omitempty"`
 
+	// Throttling specifies limits of storage operations and transferred bytes.
+	Throttling *throttling.Limits `json:"throttling,omitempty"`
+
 	ClientOptions
 }
 
@@ -88,6 +96,24 @@ type LocalConfig struct {
 type repositoryObjectFormat struct {
 	content.FormattingOptions
 	object.Format
+
+	// ErrorCorrection, when set, specifies parameters of error correction applied to all blobs.
+	ErrorCorrection *ecc.Options `json:"errorCorrection,omitempty"`
+}
+
+// Validate checks the validity of repository object format.
+func (f *repositoryObjectFormat) Validate() error {
+	if err := f.FormattingOptions.Validate(); err != nil {
+		return err
+	}
+
+	if f.ErrorCorrection != nil {
+		if err := f.ErrorCorrection.Validate(); err != nil {
+			return errors.Wrap(err, "invalid error correction options")
+		}
+	}
+
+	return nil
 }
 
 // writeToFile writes the config to a given file.
@@ -69,8 +69,10 @@ func DeleteUnreferencedBlobs(ctx context.Context, rep repo.DirectRepositoryWrite
 		return 0, errors.Wrap(err, "unable to load active sessions")
 	}
 
-	// iterate all pack blobs + session blobs and keep ones that are too young or
-	// belong to alive sessions.
+	retentionMode, _ := rep.ContentManager().BlobRetention()
+
+	// iterate all pack blobs + session blobs and keep ones that are too young,
+	// belong to alive sessions or are still locked by retention.
 	if err := rep.ContentManager().IterateUnreferencedBlobs(ctx, prefixes, opt.Parallel, func(bm blob.Metadata) error {
 		if age := rep.Time().Sub(bm.Timestamp); age < safety.BlobDeleteMinAge {
 			log(ctx).Debugf("  preserving %v because it's too new (age: %v<%v)", bm.BlobID, age, safety.BlobDeleteMinAge)
@@ -85,6 +87,18 @@ func DeleteUnreferencedBlobs(ctx context.Context, rep repo.DirectRepositoryWrite
 			}
 		}
 
+		if retentionMode != "" {
+			retainUntil, err := blob.GetBlobRetention(ctx, rep.BlobStorage(), bm.BlobID)
+			if err != nil && !errors.Is(err, blob.ErrRetentionUnsupported) {
+				return errors.Wrapf(err, "unable to get retention of %v", bm.BlobID)
+			}
+
+			if retainUntil.After(rep.Time()) {
+				log(ctx).Debugf("  preserving %v because it's locked until %v", bm.BlobID, retainUntil)
+				return nil
+			}
+		}
+
 		unreferenced.Add(bm.Length)
 
 		if !opt.DryRun {
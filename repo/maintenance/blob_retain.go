@@ -0,0 +1,106 @@
+package maintenance
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/kopia/kopia/internal/stats"
+	"github.com/kopia/kopia/repo"
+	"github.com/kopia/kopia/repo/blob"
+	"github.com/kopia/kopia/repo/content"
+)
+
+// ExtendBlobRetentionTimeOptions provides options for extending retention of pack blobs.
+type ExtendBlobRetentionTimeOptions struct {
+	Parallel int
+	DryRun   bool
+}
+
+// ExtendBlobRetentionTime extends retention of all pack blobs that are still referenced by the index,
+// so that they remain locked for the configured retention period from now. This includes packs with
+// only deleted contents, which can still be undeleted until they are dropped from the index.
+// Pack blobs which are no longer referenced are allowed to expire so that they can be eventually deleted.
+func ExtendBlobRetentionTime(ctx context.Context, rep repo.DirectRepositoryWriter, opt ExtendBlobRetentionTimeOptions) (int, error) {
+	mode, period := rep.ContentManager().BlobRetention()
+	if mode == "" {
+		log(ctx).Debugf("blob retention is not enabled")
+		return 0, nil
+	}
+
+	if opt.Parallel == 0 {
+		opt.Parallel = 16
+	}
+
+	const extendQueueSize = 100
+
+	var extended stats.CountSum
+
+	retainUntil := rep.Time().Add(period)
+	toExtend := make(chan blob.ID, extendQueueSize)
+
+	// workers stop on first error, which cancels the context and stops the producer below.
+	eg, egctx := errgroup.WithContext(ctx)
+
+	if !opt.DryRun {
+		for i := 0; i < opt.Parallel; i++ {
+			eg.Go(func() error {
+				for id := range toExtend {
+					if err := blob.ExtendBlobRetention(egctx, rep.BlobStorage(), id, mode, retainUntil); err != nil {
+						return errors.Wrapf(err, "unable to extend retention of %v", id)
+					}
+
+					if cnt, _ := extended.Add(0); cnt%100 == 0 {
+						log(ctx).Infof("  extended retention of %v blobs", cnt)
+					}
+				}
+
+				return nil
+			})
+		}
+	}
+
+	log(ctx).Infof("Extending retention of pack blobs until %v...", retainUntil.Format("2006-01-02 15:04:05 MST"))
+
+	var found stats.CountSum
+
+	err := rep.ContentManager().IteratePacks(ctx, content.IteratePackOptions{
+		IncludePacksWithOnlyDeletedContent: true,
+	}, func(pi content.PackInfo) error {
+		found.Add(0)
+
+		if opt.DryRun {
+			return nil
+		}
+
+		select {
+		case toExtend <- pi.PackID:
+			return nil
+
+		case <-egctx.Done():
+			return egctx.Err()
+		}
+	})
+
+	close(toExtend)
+
+	if werr := eg.Wait(); werr != nil {
+		return 0, errors.Wrap(werr, "worker error")
+	}
+
+	if err != nil {
+		return 0, errors.Wrap(err, "error iterating packs")
+	}
+
+	if opt.DryRun {
+		cnt, _ := found.Approximate()
+		return int(cnt), nil
+	}
+
+	cnt, _ := extended.Approximate()
+
+	log(ctx).Infof("Extended retention of total %v pack blobs", cnt)
+
+	return int(cnt), nil
+}
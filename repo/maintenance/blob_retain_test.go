@@ -0,0 +1,111 @@
+package maintenance
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kopia/kopia/internal/faketime"
+	"github.com/kopia/kopia/internal/repotesting"
+	"github.com/kopia/kopia/repo"
+	"github.com/kopia/kopia/repo/blob"
+	"github.com/kopia/kopia/repo/blob/filesystem"
+	"github.com/kopia/kopia/repo/content"
+	"github.com/kopia/kopia/repo/object"
+)
+
+func TestExtendBlobRetentionTime(t *testing.T) {
+	const period = time.Hour
+
+	ta := faketime.NewClockTimeWithOffset(0)
+
+	ctx, env := repotesting.NewEnvironment(t, repotesting.Options{
+		OpenOptions: func(o *repo.Options) {
+			o.TimeNowFunc = ta.NowFunc()
+		},
+		NewRepositoryOptions: func(nro *repo.NewRepositoryOptions) {
+			nro.BlockFormat.RetentionMode = blob.RetentionModeGovernance
+			nro.BlockFormat.RetentionPeriod = period
+		},
+	})
+
+	w := env.RepositoryWriter.NewObjectWriter(ctx, object.WriterOptions{})
+	io.WriteString(w, "hello world!")
+	w.Result()
+	w.Close()
+
+	require.NoError(t, env.RepositoryWriter.Flush(ctx))
+
+	st := env.RepositoryWriter.BlobStorage()
+
+	packs, err := blob.ListAllBlobs(ctx, st, content.PackBlobIDPrefixRegular)
+	require.NoError(t, err)
+	require.Len(t, packs, 1)
+
+	packID := packs[0].BlobID
+
+	retainUntil, err := blob.GetBlobRetention(ctx, st, packID)
+	require.NoError(t, err)
+	require.True(t, retainUntil.After(ta.NowFunc()().Add(period/2)), "pack blob not locked")
+
+	// locked blobs can't be deleted.
+	require.ErrorIs(t, st.DeleteBlob(ctx, packID), blob.ErrBlobLocked)
+
+	ta.Advance(period / 2)
+
+	n, err := ExtendBlobRetentionTime(ctx, env.RepositoryWriter, ExtendBlobRetentionTimeOptions{})
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+
+	extendedUntil, err := blob.GetBlobRetention(ctx, st, packID)
+	require.NoError(t, err)
+	require.True(t, extendedUntil.After(retainUntil), "retention not extended: %v, was %v", extendedUntil, retainUntil)
+}
+
+func TestExtendBlobRetentionTime_WorkerError(t *testing.T) {
+	ctx, env := repotesting.NewEnvironment(t, repotesting.Options{
+		NewRepositoryOptions: func(nro *repo.NewRepositoryOptions) {
+			nro.BlockFormat.RetentionMode = blob.RetentionModeGovernance
+			nro.BlockFormat.RetentionPeriod = time.Hour
+		},
+	})
+
+	// more packs than the workers and the queue can hold.
+	for i := 0; i < 150; i++ {
+		_, err := env.RepositoryWriter.ContentManager().WriteContent(ctx, []byte(fmt.Sprintf("content-%v", i)), "", content.NoCompression)
+		require.NoError(t, err)
+		require.NoError(t, env.RepositoryWriter.Flush(ctx))
+	}
+
+	// remove pack blobs behind the back of the storage, so that extending their retention fails.
+	storageDir := env.RepositoryWriter.BlobStorage().ConnectionInfo().Config.(*filesystem.Options).Path
+
+	require.NoError(t, filepath.Walk(storageDir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() && strings.HasPrefix(strings.TrimPrefix(path, storageDir+"/"), string(content.PackBlobIDPrefixRegular)) {
+			return os.Remove(path)
+		}
+
+		return err
+	}))
+
+	errCh := make(chan error, 1)
+
+	go func() {
+		_, err := ExtendBlobRetentionTime(ctx, env.RepositoryWriter, ExtendBlobRetentionTimeOptions{Parallel: 1})
+		errCh <- err
+	}()
+
+	select {
+	case err := <-errCh:
+		require.Error(t, err)
+
+	case <-time.After(30 * time.Second):
+		t.Fatal("extending retention did not finish")
+	}
+}
@@ -0,0 +1,95 @@
+package maintenance
+
+import (
+	"context"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+)
+
+var tagKeyTask = tag.MustNewKey("task")
+
+// maintenance metrics.
+var (
+	metricTaskLastRunTime = stats.Int64(
+		"kopia/maintenance/last_run_time",
+		"Time when the maintenance task last finished, in seconds since the epoch",
+		stats.UnitSeconds,
+	)
+
+	metricTaskLastRunSuccess = stats.Int64(
+		"kopia/maintenance/last_run_success",
+		"Whether the last run of the maintenance task succeeded (1) or failed (0)",
+		stats.UnitDimensionless,
+	)
+
+	metricTaskLastRunDuration = stats.Int64(
+		"kopia/maintenance/last_run_duration",
+		"Duration of the last run of the maintenance task",
+		stats.UnitMilliseconds,
+	)
+
+	metricNextQuickMaintenanceTime = stats.Int64(
+		"kopia/maintenance/next_quick_time",
+		"Time when the next quick maintenance is scheduled, in seconds since the epoch",
+		stats.UnitSeconds,
+	)
+
+	metricNextFullMaintenanceTime = stats.Int64(
+		"kopia/maintenance/next_full_time",
+		"Time when the next full maintenance is scheduled, in seconds since the epoch",
+		stats.UnitSeconds,
+	)
+)
+
+func lastValueAggregation(m stats.Measure, keys ...tag.Key) *view.View {
+	return &view.View{
+		Name:        m.Name(),
+		Aggregation: view.LastValue(),
+		Description: m.Description(),
+		Measure:     m,
+		TagKeys:     keys,
+	}
+}
+
+func init() {
+	if err := view.Register(
+		lastValueAggregation(metricTaskLastRunTime, tagKeyTask),
+		lastValueAggregation(metricTaskLastRunSuccess, tagKeyTask),
+		lastValueAggregation(metricTaskLastRunDuration, tagKeyTask),
+		lastValueAggregation(metricNextQuickMaintenanceTime),
+		lastValueAggregation(metricNextFullMaintenanceTime),
+	); err != nil {
+		panic("unable to register opencensus views: " + err.Error())
+	}
+}
+
+func recordRunMetrics(ctx context.Context, taskType TaskType, ri RunInfo) {
+	var success int64
+	if ri.Success {
+		success = 1
+	}
+
+	// nolint:errcheck
+	stats.RecordWithTags(ctx,
+		[]tag.Mutator{tag.Upsert(tagKeyTask, string(taskType))},
+		metricTaskLastRunTime.M(ri.End.Unix()),
+		metricTaskLastRunSuccess.M(success),
+		metricTaskLastRunDuration.M(ri.End.Sub(ri.Start).Milliseconds()),
+	)
+}
+
+func recordScheduleMetrics(ctx context.Context, s *Schedule) {
+	var ms []stats.Measurement
+
+	if !s.NextQuickMaintenanceTime.IsZero() {
+		ms = append(ms, metricNextQuickMaintenanceTime.M(s.NextQuickMaintenanceTime.Unix()))
+	}
+
+	if !s.NextFullMaintenanceTime.IsZero() {
+		ms = append(ms, metricNextFullMaintenanceTime.M(s.NextFullMaintenanceTime.Unix()))
+	}
+
+	stats.Record(ctx, ms...)
+}
@@ -40,6 +40,8 @@
 	TaskRewriteContentsFull       = "full-rewrite-contents"
 	TaskDropDeletedContentsFull   = "full-drop-deleted-content"
 	TaskIndexCompaction           = "index-compaction"
+	TaskExtendBlobRetentionFull   = "full-extend-blob-retention"
+	TaskRecomputeQuotaUsageFull   = "full-recompute-quota-usage"
 )
 
 // shouldRun returns Mode if repository is due for periodic maintenance.
@@ -320,6 +322,13 @@ func runTaskDeleteOrphanedBlobsQuick(ctx context.Context, runParams RunParameter
 	})
 }
 
+func runTaskExtendBlobRetentionFull(ctx context.Context, runParams RunParameters, s *Schedule) error {
+	return ReportRun(ctx, runParams.rep, TaskExtendBlobRetentionFull, s, func() error {
+		_, err := ExtendBlobRetentionTime(ctx, runParams.rep, ExtendBlobRetentionTimeOptions{})
+		return err
+	})
+}
+
 func runFullMaintenance(ctx context.Context, runParams RunParameters, safety SafetyParameters) error {
 	s, err := GetSchedule(ctx, runParams.rep)
 	if err != nil {
@@ -351,6 +360,13 @@ func runFullMaintenance(ctx context.Context, runParams RunParameters, safety Saf
 		notDeletingOrphanedBlobs(ctx, s, safety)
 	}
 
+	if mode, _ := runParams.rep.ContentManager().BlobRetention(); mode != "" {
+		// extend retention of pack blobs still in use, so that they remain locked.
+		if err := runTaskExtendBlobRetentionFull(ctx, runParams, s); err != nil {
+			return errors.Wrap(err, "error extending blob retention")
+		}
+	}
+
 	return nil
 }
 
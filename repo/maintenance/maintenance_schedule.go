@@ -128,7 +128,47 @@ func SetSchedule(ctx context.Context, rep repo.DirectRepositoryWriter, s *Schedu
 	result := append([]byte(nil), nonce...)
 	ciphertext := c.Seal(result, nonce, v, maintenanceScheduleAEADExtraData)
 
-	return rep.BlobStorage().PutBlob(ctx, maintenanceScheduleBlobID, gather.FromSlice(ciphertext))
+	if err := rep.BlobStorage().PutBlob(ctx, maintenanceScheduleBlobID, gather.FromSlice(ciphertext)); err != nil {
+		return errors.Wrap(err, "unable to write schedule blob")
+	}
+
+	recordScheduleMetrics(ctx, s)
+
+	return nil
+}
+
+// reencryptScheduleOnPasswordChange reads the schedule using the current key, so that it can be written again
+// using the key derived from the new master key once the password has been changed.
+func reencryptScheduleOnPasswordChange(ctx context.Context, rep repo.DirectRepositoryWriter) (func(ctx context.Context) error, error) {
+	if _, err := rep.BlobReader().GetMetadata(ctx, maintenanceScheduleBlobID); errors.Is(err, blob.ErrBlobNotFound) {
+		return func(ctx context.Context) error { return nil }, nil
+	}
+
+	s, err := GetSchedule(ctx, rep)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read maintenance schedule")
+	}
+
+	return func(ctx context.Context) error {
+		return SetSchedule(ctx, rep, s)
+	}, nil
+}
+
+func init() {
+	repo.RegisterPasswordChangeHook(reencryptScheduleOnPasswordChange)
+}
+
+type contextKey string
+
+const taskFailureFuncKey contextKey = "task-failure-func"
+
+// TaskFailureFunc is invoked with information about a failed maintenance task.
+type TaskFailureFunc func(ctx context.Context, taskType TaskType, ri RunInfo)
+
+// WithTaskFailureFunc returns a derived context, which causes ReportRun() to invoke the provided
+// function whenever a maintenance task fails, for example to send notifications.
+func WithTaskFailureFunc(ctx context.Context, f TaskFailureFunc) context.Context {
+	return context.WithValue(ctx, taskFailureFuncKey, f)
 }
 
 // ReportRun reports timing of a maintenance run and persists it in repository.
@@ -147,6 +187,14 @@ func ReportRun(ctx context.Context, rep repo.DirectRepositoryWriter, taskType Ta
 		ri.Success = true
 	}
 
+	recordRunMetrics(ctx, taskType, ri)
+
+	if runErr != nil {
+		if f, ok := ctx.Value(taskFailureFuncKey).(TaskFailureFunc); ok {
+			f(ctx, taskType, ri)
+		}
+	}
+
 	if s == nil {
 		var err error
 
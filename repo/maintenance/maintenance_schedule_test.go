@@ -1,13 +1,16 @@
 package maintenance
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"testing"
 
 	"github.com/kylelemons/godebug/pretty"
 
 	"github.com/kopia/kopia/internal/clock"
 	"github.com/kopia/kopia/internal/repotesting"
+	"github.com/kopia/kopia/repo"
 )
 
 func TestMaintenanceSchedule(t *testing.T) {
@@ -48,6 +51,72 @@ func TestMaintenanceSchedule(t *testing.T) {
 	}
 }
 
+func TestMaintenanceScheduleSurvivesPasswordChange(t *testing.T) {
+	ctx, env := repotesting.NewEnvironment(t)
+
+	s := &Schedule{NextFullMaintenanceTime: clock.Now()}
+
+	if err := SetSchedule(ctx, env.RepositoryWriter, s); err != nil {
+		t.Fatalf("unable to set schedule: %v", err)
+	}
+
+	if err := env.RepositoryWriter.ChangePassword(ctx, "new-password"); err != nil {
+		t.Fatalf("unable to change password: %v", err)
+	}
+
+	s2, err := GetSchedule(ctx, env.RepositoryWriter)
+	if err != nil {
+		t.Fatalf("unable to get schedule: %v", err)
+	}
+
+	if got, want := toJSON(s2), toJSON(s); got != want {
+		t.Errorf("invalid schedule (-want,+got) %v", pretty.Compare(want, got))
+	}
+
+	// the schedule can be read after reopening the repository with the new password.
+	r, err := repo.Open(ctx, env.ConfigFile(), "new-password", &repo.Options{})
+	if err != nil {
+		t.Fatalf("unable to open repository: %v", err)
+	}
+
+	defer r.Close(ctx)
+
+	s3, err := GetSchedule(ctx, r.(repo.DirectRepository))
+	if err != nil {
+		t.Fatalf("unable to get schedule: %v", err)
+	}
+
+	if got, want := toJSON(s3), toJSON(s); got != want {
+		t.Errorf("invalid schedule (-want,+got) %v", pretty.Compare(want, got))
+	}
+}
+
+func TestReportRunTaskFailureFunc(t *testing.T) {
+	ctx, env := repotesting.NewEnvironment(t)
+
+	var failed []TaskType
+
+	ctx = WithTaskFailureFunc(ctx, func(ctx context.Context, taskType TaskType, ri RunInfo) {
+		if ri.Error != "some error" {
+			t.Errorf("unexpected error: %v", ri.Error)
+		}
+
+		failed = append(failed, taskType)
+	})
+
+	if err := ReportRun(ctx, env.RepositoryWriter, "ok", nil, func() error { return nil }); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := ReportRun(ctx, env.RepositoryWriter, "failing", nil, func() error { return errors.New("some error") }); err == nil {
+		t.Fatalf("expected error")
+	}
+
+	if len(failed) != 1 || failed[0] != "failing" {
+		t.Errorf("unexpected failed tasks: %v", failed)
+	}
+}
+
 func toJSON(v interface{}) string {
 	b, _ := json.MarshalIndent(v, "", "  ")
 	return string(b)
@@ -5,10 +5,12 @@
 	"compress/gzip"
 	"context"
 	"encoding/json"
+	"io"
 	"sync"
 
 	"github.com/pkg/errors"
 
+	"github.com/kopia/kopia/repo/compression"
 	"github.com/kopia/kopia/repo/content"
 )
 
@@ -71,12 +73,21 @@ func (m *committedManifestManager) writeEntriesLocked(ctx context.Context, entri
 	}
 
 	var buf bytes.Buffer
-	gz := gzip.NewWriter(&buf)
-	mustSucceed(json.NewEncoder(gz).Encode(man))
-	mustSucceed(gz.Flush())
-	mustSucceed(gz.Close())
 
-	contentID, err := m.b.WriteContent(ctx, buf.Bytes(), ContentPrefix)
+	comp := content.NoCompression
+
+	if m.b.SupportsContentCompression() {
+		// let the content manager compress plain JSON.
+		comp = compression.ByName[manifestCompressor].HeaderID()
+		mustSucceed(json.NewEncoder(&buf).Encode(man))
+	} else {
+		gz := gzip.NewWriter(&buf)
+		mustSucceed(json.NewEncoder(gz).Encode(man))
+		mustSucceed(gz.Flush())
+		mustSucceed(gz.Close())
+	}
+
+	contentID, err := m.b.WriteContent(ctx, buf.Bytes(), ContentPrefix, comp)
 	if err != nil {
 		return nil, errors.Wrap(err, "unable to write content")
 	}
@@ -288,12 +299,19 @@ func loadManifestContent(ctx context.Context, b contentManager, contentID conten
 		return man, errors.Wrap(err, "error loading manifest content")
 	}
 
-	gz, err := gzip.NewReader(bytes.NewReader(blk))
-	if err != nil {
-		return man, errors.Wrapf(err, "unable to unpack manifest data %q", contentID)
+	var r io.Reader = bytes.NewReader(blk)
+
+	// manifests compressed at the content level contain plain JSON, others are gzipped.
+	if !bytes.HasPrefix(blk, []byte("{")) {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return man, errors.Wrapf(err, "unable to unpack manifest data %q", contentID)
+		}
+
+		r = gz
 	}
 
-	if err := json.NewDecoder(gz).Decode(&man); err != nil {
+	if err := json.NewDecoder(r).Decode(&man); err != nil {
 		return man, errors.Wrapf(err, "unable to parse manifest %q", contentID)
 	}
 
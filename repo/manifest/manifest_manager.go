@@ -13,6 +13,7 @@
 	"github.com/pkg/errors"
 
 	"github.com/kopia/kopia/internal/clock"
+	"github.com/kopia/kopia/repo/compression"
 	"github.com/kopia/kopia/repo/content"
 	"github.com/kopia/kopia/repo/logging"
 )
@@ -28,6 +29,9 @@
 const (
 	ContentPrefix              = "m"
 	autoCompactionContentCount = 16
+
+	// compressor used for manifest contents when the content manager supports content-level compression.
+	manifestCompressor compression.Name = "zstd-fastest"
 )
 
 // TypeLabelKey is the label key for manifest type.
@@ -36,7 +40,8 @@
 type contentManager interface {
 	Revision() int64
 	GetContent(ctx context.Context, contentID content.ID) ([]byte, error)
-	WriteContent(ctx context.Context, data []byte, prefix content.ID) (content.ID, error)
+	WriteContent(ctx context.Context, data []byte, prefix content.ID, comp compression.HeaderID) (content.ID, error)
+	SupportsContentCompression() bool
 	DeleteContent(ctx context.Context, contentID content.ID) error
 	IterateContents(ctx context.Context, options content.IterateOptions, callback content.IterateCallback) error
 	DisableIndexFlush(ctx context.Context)
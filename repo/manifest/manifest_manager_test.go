@@ -3,6 +3,7 @@
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"reflect"
 	"sort"
 	"strings"
@@ -322,6 +323,61 @@ func newManagerForTesting(ctx context.Context, t *testing.T, data blobtesting.Da
 	return mm
 }
 
+func TestManifestContentCompression(t *testing.T) {
+	ctx := testlogging.Context(t)
+	data := blobtesting.DataMap{}
+	st := blobtesting.NewMapStorage(data, nil, nil)
+	f := &content.FormattingOptions{
+		Hash:         hashing.DefaultAlgorithm,
+		Encryption:   encryption.DefaultAlgorithm,
+		MaxPackSize:  100000,
+		Version:      content.FormatVersion2,
+		IndexVersion: content.IndexVersion2,
+	}
+
+	bm, err := content.NewManager(ctx, st, f, nil, nil)
+	require.NoError(t, err)
+
+	defer bm.Close(ctx)
+
+	mgr, err := NewManager(ctx, bm, ManagerOptions{})
+	require.NoError(t, err)
+
+	// large enough to be compressible.
+	item1 := map[string]int{}
+	for i := 0; i < 1000; i++ {
+		item1[fmt.Sprintf("key%v", i)] = i
+	}
+
+	labels1 := map[string]string{"type": "item", "color": "red"}
+	id1 := addAndVerify(ctx, t, mgr, labels1, item1)
+
+	require.NoError(t, mgr.Flush(ctx))
+	require.NoError(t, bm.Flush(ctx))
+
+	var compressed int
+
+	require.NoError(t, bm.IterateContents(ctx, content.IterateOptions{Range: content.PrefixRange(ContentPrefix)}, func(ci content.Info) error {
+		if ci.GetCompressionHeaderID() != content.NoCompression {
+			compressed++
+		}
+
+		return nil
+	}))
+
+	require.Equal(t, 1, compressed)
+
+	bm2, err := content.NewManager(ctx, st, f, nil, nil)
+	require.NoError(t, err)
+
+	defer bm2.Close(ctx)
+
+	mgr2, err := NewManager(ctx, bm2, ManagerOptions{})
+	require.NoError(t, err)
+
+	verifyItem(ctx, t, mgr2, id1, labels1, item1)
+}
+
 func TestManifestInvalidPut(t *testing.T) {
 	ctx := testlogging.Context(t)
 	data := blobtesting.DataMap{}
@@ -1,10 +1,12 @@
 package object
 
 // indirectObjectEntry represents an entry in indirect object stream.
+// Entries with Hole set have no backing object and represent Length bytes of zeros.
 type indirectObjectEntry struct {
 	Start  int64 `json:"s,omitempty"`
 	Length int64 `json:"l,omitempty"`
 	Object ID    `json:"o,omitempty"`
+	Hole   bool  `json:"h,omitempty"`
 }
 
 func (i *indirectObjectEntry) endOffset() int64 {
@@ -19,6 +21,7 @@ func (i *indirectObjectEntry) endOffset() int64 {
 {"s":3000180,"l":4352499,"o":"D6b6eb48ca5361d06d72fe193813e42e1"},
 {"s":7352679,"l":1170821,"o":"Dd14653f76b63802ed48be64a0e67fea9"},
 
-{"s":91094118,"l":1645153,"o":"Daa55df764d881a1daadb5ea9de17abbb"}
+{"s":91094118,"l":1645153,"o":"Daa55df764d881a1daadb5ea9de17abbb"},
+{"s":92739271,"l":1073741824,"h":true}
 ]}
 */
@@ -34,7 +34,9 @@ type contentReader interface {
 
 type contentManager interface {
 	contentReader
-	WriteContent(ctx context.Context, data []byte, prefix content.ID) (content.ID, error)
+	WriteContent(ctx context.Context, data []byte, prefix content.ID, comp compression.HeaderID) (content.ID, error)
+	SupportsContentCompression() bool
+	SupportsSparseObjects() bool
 }
 
 // Format describes the format of objects in a repository.
@@ -165,6 +167,7 @@ func appendIndexEntries(indexEntries []indirectObjectEntry, startingLength int64
 			Start:  inc.Start + startingLength,
 			Length: inc.Length,
 			Object: inc.Object,
+			Hole:   inc.Hole,
 		})
 
 		totalLength += inc.Length
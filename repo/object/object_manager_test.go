@@ -31,6 +31,10 @@
 type fakeContentManager struct {
 	mu   sync.Mutex
 	data map[content.ID][]byte
+
+	supportsContentCompression bool
+	supportsSparseObjects      bool
+	compression                map[content.ID]compression.HeaderID
 }
 
 func (f *fakeContentManager) GetContent(ctx context.Context, contentID content.ID) ([]byte, error) {
@@ -44,7 +48,7 @@ func (f *fakeContentManager) GetContent(ctx context.Context, contentID content.I
 	return nil, content.ErrContentNotFound
 }
 
-func (f *fakeContentManager) WriteContent(ctx context.Context, data []byte, prefix content.ID) (content.ID, error) {
+func (f *fakeContentManager) WriteContent(ctx context.Context, data []byte, prefix content.ID, comp compression.HeaderID) (content.ID, error) {
 	h := sha256.New()
 	h.Write(data)
 	contentID := prefix + content.ID(hex.EncodeToString(h.Sum(nil)))
@@ -54,9 +58,25 @@ func (f *fakeContentManager) WriteContent(ctx context.Context, data []byte, pref
 
 	f.data[contentID] = append([]byte(nil), data...)
 
+	if comp != content.NoCompression {
+		if f.compression == nil {
+			f.compression = map[content.ID]compression.HeaderID{}
+		}
+
+		f.compression[contentID] = comp
+	}
+
 	return contentID, nil
 }
 
+func (f *fakeContentManager) SupportsContentCompression() bool {
+	return f.supportsContentCompression
+}
+
+func (f *fakeContentManager) SupportsSparseObjects() bool {
+	return f.supportsSparseObjects
+}
+
 func (f *fakeContentManager) ContentInfo(ctx context.Context, contentID content.ID) (content.Info, error) {
 	f.mu.Lock()
 	defer f.mu.Unlock()
@@ -710,6 +730,44 @@ func TestEndToEndReadAndSeekWithCompression(t *testing.T) {
 	}
 }
 
+func TestContentLevelCompression(t *testing.T) {
+	ctx := testlogging.Context(t)
+	cm := &fakeContentManager{data: map[content.ID][]byte{}, supportsContentCompression: true}
+
+	om, err := NewObjectManager(ctx, cm, Format{Splitter: "FIXED-1M"})
+	if err != nil {
+		t.Fatalf("can't create object manager: %v", err)
+	}
+
+	defer om.Close()
+
+	inputData := makeMaybeCompressibleData(10000, true)
+
+	w := om.NewWriter(ctx, WriterOptions{Compressor: "zstd-fastest"})
+	defer w.Close()
+
+	if _, err = w.Write(inputData); err != nil {
+		t.Fatalf("write error: %v", err)
+	}
+
+	oid, err := w.Result()
+	if err != nil {
+		t.Fatalf("result error: %v", err)
+	}
+
+	// the content is compressed by the content manager, not at the object level.
+	cid, isCompressed, ok := oid.ContentID()
+	if !ok || isCompressed {
+		t.Fatalf("unexpected object ID: %v", oid)
+	}
+
+	if got, want := cm.compression[cid], compression.ByName["zstd-fastest"].HeaderID(); got != want {
+		t.Errorf("unexpected content compression %x, want %x", got, want)
+	}
+
+	verify(ctx, t, cm, oid, inputData, "content-level compression")
+}
+
 func makeMaybeCompressibleData(size int, compressible bool) []byte {
 	if compressible {
 		phrase := []byte("quick brown fox")
@@ -809,3 +867,130 @@ func TestSeek(t *testing.T) {
 		}
 	}
 }
+
+func TestWriteHole(t *testing.T) {
+	ctx := testlogging.Context(t)
+	data := map[content.ID][]byte{}
+
+	om, err := NewObjectManager(ctx, &fakeContentManager{data: data, supportsSparseObjects: true}, Format{
+		Splitter: "FIXED-1M",
+	})
+	if err != nil {
+		t.Fatalf("can't create object manager: %v", err)
+	}
+
+	defer om.Close()
+
+	const holeSize = 100 << 20
+
+	cases := []struct {
+		desc   string
+		layout []int // positive values are data lengths, negative values are hole lengths
+	}{
+		{"hole only", []int{-holeSize}},
+		{"data and hole", []int{1000, -holeSize}},
+		{"hole and data", []int{-holeSize, 1000}},
+		{"data, hole and data", []int{1000, -holeSize, 3000000, -5, 7}},
+	}
+
+	for _, tc := range cases {
+		contentCountBefore := len(data)
+
+		var want []byte
+
+		w := om.NewWriter(ctx, WriterOptions{})
+
+		for _, l := range tc.layout {
+			if l < 0 {
+				verifyNoError(t, w.(HoleWriter).WriteHole(int64(-l)))
+
+				want = append(want, make([]byte, -l)...)
+
+				continue
+			}
+
+			b := make([]byte, l)
+			cryptorand.Read(b)
+
+			if _, err := w.Write(b); err != nil {
+				t.Fatalf("write error: %v", err)
+			}
+
+			want = append(want, b...)
+		}
+
+		oid, err := w.Result()
+		if err != nil {
+			t.Fatalf("unable to write %v: %v", tc.desc, err)
+		}
+
+		if _, ok := oid.IndexObjectID(); !ok {
+			t.Fatalf("expected index object for %v, got %v", tc.desc, oid)
+		}
+
+		if got, limit := len(data)-contentCountBefore, len(tc.layout)+10; got > limit {
+			t.Errorf("too many contents written for %v: %v, expected at most %v", tc.desc, got, limit)
+		}
+
+		verifyFull(ctx, t, om, oid, want)
+
+		r, err := Open(ctx, om.contentMgr, oid)
+		if err != nil {
+			t.Fatalf("open error: %v", err)
+		}
+
+		for _, off := range []int64{0, 500, holeSize / 2, int64(len(want)) - 1} {
+			if _, err := r.Seek(off, io.SeekStart); err != nil {
+				t.Fatalf("unable to seek to %v of %v: %v", off, tc.desc, err)
+			}
+
+			b := make([]byte, 1)
+			if _, err := io.ReadFull(r, b); err != nil {
+				t.Fatalf("unable to read at %v of %v: %v", off, tc.desc, err)
+			}
+
+			if b[0] != want[off] {
+				t.Errorf("invalid data at %v of %v: %v, want %v", off, tc.desc, b[0], want[off])
+			}
+		}
+
+		r.Close()
+
+		if _, err := VerifyObject(ctx, om.contentMgr, oid); err != nil {
+			t.Errorf("unable to verify %v: %v", tc.desc, err)
+		}
+
+		concatenated, err := om.Concatenate(ctx, []ID{oid, oid})
+		if err != nil {
+			t.Fatalf("unable to concatenate %v: %v", tc.desc, err)
+		}
+
+		verifyFull(ctx, t, om, concatenated, append(append([]byte(nil), want...), want...))
+	}
+}
+
+func TestWriteHoleWithoutSparseObjects(t *testing.T) {
+	ctx := testlogging.Context(t)
+	_, om := setupTest(t)
+
+	w := om.NewWriter(ctx, WriterOptions{})
+	defer w.Close()
+
+	if _, err := w.Write([]byte{1, 2, 3}); err != nil {
+		t.Fatalf("write error: %v", err)
+	}
+
+	verifyNoError(t, w.(HoleWriter).WriteHole(5000))
+
+	oid, err := w.Result()
+	if err != nil {
+		t.Fatalf("result error: %v", err)
+	}
+
+	// zeros are written as regular data, so the object fits in a single content.
+	if _, _, ok := oid.ContentID(); !ok {
+		t.Fatalf("expected direct object, got %v", oid)
+	}
+
+	verifyFull(ctx, t, om, oid, append([]byte{1, 2, 3}, make([]byte, 5000)...))
+}
@@ -41,6 +41,16 @@ type objectReader struct {
 	currentChunkIndex    int    // Index of current chunk in the seek table
 	currentChunkData     []byte // Current chunk data
 	currentChunkPosition int    // Read position in the current chunk
+	currentChunkHole     bool   // Current chunk is a hole, currentChunkData is not allocated
+}
+
+// currentChunkLength returns the length of the currently open chunk.
+func (r *objectReader) currentChunkLength() int {
+	if r.currentChunkHole {
+		return int(r.seekTable[r.currentChunkIndex].Length)
+	}
+
+	return len(r.currentChunkData)
 }
 
 func (r *objectReader) Read(buffer []byte) (int, error) {
@@ -52,8 +62,8 @@ func (r *objectReader) Read(buffer []byte) (int, error) {
 	}
 
 	for remaining > 0 {
-		if r.currentChunkData != nil {
-			toCopy := len(r.currentChunkData) - r.currentChunkPosition
+		if r.currentChunkData != nil || r.currentChunkHole {
+			toCopy := r.currentChunkLength() - r.currentChunkPosition
 			if toCopy == 0 {
 				// EOF on current chunk
 				r.closeCurrentChunk()
@@ -66,8 +76,12 @@ func (r *objectReader) Read(buffer []byte) (int, error) {
 				toCopy = remaining
 			}
 
-			copy(buffer[readBytes:],
-				r.currentChunkData[r.currentChunkPosition:r.currentChunkPosition+toCopy])
+			if r.currentChunkHole {
+				zero(buffer[readBytes : readBytes+toCopy])
+			} else {
+				copy(buffer[readBytes:],
+					r.currentChunkData[r.currentChunkPosition:r.currentChunkPosition+toCopy])
+			}
 
 			r.currentChunkPosition += toCopy
 			r.currentPosition += int64(toCopy)
@@ -97,6 +111,13 @@ func (r *objectReader) Read(buffer []byte) (int, error) {
 func (r *objectReader) openCurrentChunk() error {
 	st := r.seekTable[r.currentChunkIndex]
 
+	if st.Hole {
+		r.currentChunkHole = true
+		r.currentChunkPosition = 0
+
+		return nil
+	}
+
 	rd, err := openAndAssertLength(r.ctx, r.cr, st.Object, st.Length)
 	if err != nil {
 		return err
@@ -117,6 +138,13 @@ func (r *objectReader) openCurrentChunk() error {
 
 func (r *objectReader) closeCurrentChunk() {
 	r.currentChunkData = nil
+	r.currentChunkHole = false
+}
+
+func zero(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
 }
 
 func (r *objectReader) findChunkIndexForOffset(offset int64) (int, error) {
@@ -153,7 +181,7 @@ func (r *objectReader) Seek(offset int64, whence int) (int64, error) {
 
 	if offset >= r.totalLength {
 		r.currentChunkIndex = len(r.seekTable)
-		r.currentChunkData = nil
+		r.closeCurrentChunk()
 		r.currentPosition = offset
 
 		return offset, nil
@@ -171,7 +199,7 @@ func (r *objectReader) Seek(offset int64, whence int) (int64, error) {
 		r.currentChunkIndex = index
 	}
 
-	if r.currentChunkData == nil {
+	if r.currentChunkData == nil && !r.currentChunkHole {
 		if err := r.openCurrentChunk(); err != nil {
 			return 0, err
 		}
@@ -223,6 +251,10 @@ func verifyIndirectObjectInternal(ctx context.Context, cr contentReader, indexOb
 	}
 
 	for _, m := range seekTable {
+		if m.Hole {
+			continue
+		}
+
 		err := verifyObjectInternal(ctx, cr, m.Object, tracker)
 		if err != nil {
 			return err
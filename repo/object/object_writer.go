@@ -34,6 +34,13 @@ type Writer interface {
 	Result() (ID, error)
 }
 
+// HoleWriter is implemented by writers that can efficiently append zero bytes to the object.
+type HoleWriter interface {
+	// WriteHole appends the provided number of zero bytes to the object. The zeros are not stored
+	// if the repository format supports sparse objects, otherwise they are written as regular data.
+	WriteHole(length int64) error
+}
+
 type contentIDTracker struct {
 	mu       sync.Mutex
 	contents map[content.ID]bool
@@ -139,6 +146,65 @@ func (w *objectWriter) Write(data []byte) (n int, err error) {
 	return dataLen, nil
 }
 
+func (w *objectWriter) WriteHole(length int64) error {
+	if length <= 0 {
+		return nil
+	}
+
+	if !w.om.contentMgr.SupportsSparseObjects() {
+		return w.writeZeros(length)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	// holes always start at a chunk boundary.
+	if w.buffer.Len() > 0 {
+		if err := w.flushBuffer(); err != nil {
+			return err
+		}
+	}
+
+	w.indirectIndexGrowMutex.Lock()
+	w.indirectIndex = append(w.indirectIndex, indirectObjectEntry{
+		Start:  w.currentPosition,
+		Length: length,
+		Hole:   true,
+	})
+	w.currentPosition += length
+	w.indirectIndexGrowMutex.Unlock()
+
+	w.totalLength += length
+
+	return nil
+}
+
+func (w *objectWriter) writeZeros(length int64) error {
+	const maxZeroBufferSize = 1 << 20
+
+	bufSize := length
+	if bufSize > maxZeroBufferSize {
+		bufSize = maxZeroBufferSize
+	}
+
+	zeros := make([]byte, bufSize)
+
+	for length > 0 {
+		n := bufSize
+		if n > length {
+			n = length
+		}
+
+		if _, err := w.Write(zeros[:n]); err != nil {
+			return err
+		}
+
+		length -= n
+	}
+
+	return nil
+}
+
 func (w *objectWriter) flushBuffer() error {
 	length := w.buffer.Len()
 
@@ -187,13 +253,22 @@ func (w *objectWriter) prepareAndWriteContentChunk(chunkID int, data []byte) err
 	b := w.om.bufferPool.Allocate(len(data) + maxCompressionOverheadPerSegment)
 	defer b.Release()
 
+	comp := content.NoCompression
+	objectComp := w.compressor
+
+	// when supported, let the content manager compress the content instead of compressing the object.
+	if w.compressor != nil && w.om.contentMgr.SupportsContentCompression() {
+		comp = w.compressor.HeaderID()
+		objectComp = nil
+	}
+
 	// contentBytes is what we're going to write to the content manager, it potentially uses bytes from b
-	contentBytes, isCompressed, err := maybeCompressedContentBytes(w.compressor, bytes.NewBuffer(b.Data[:0]), data)
+	contentBytes, isCompressed, err := maybeCompressedContentBytes(objectComp, bytes.NewBuffer(b.Data[:0]), data)
 	if err != nil {
 		return errors.Wrap(err, "unable to prepare content bytes")
 	}
 
-	contentID, err := w.om.contentMgr.WriteContent(w.ctx, contentBytes, w.prefix)
+	contentID, err := w.om.contentMgr.WriteContent(w.ctx, contentBytes, w.prefix, comp)
 	if err != nil {
 		return errors.Wrapf(err, "unable to write content chunk %v of %v: %v", chunkID, w.description, err)
 	}
@@ -277,7 +352,7 @@ func (w *objectWriter) checkpointLocked() (ID, error) {
 		return "", nil
 	}
 
-	if len(w.indirectIndex) == 1 {
+	if len(w.indirectIndex) == 1 && !w.indirectIndex[0].Hole {
 		return w.indirectIndex[0].Object, nil
 	}
 
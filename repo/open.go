@@ -13,9 +13,13 @@
 
 	"github.com/kopia/kopia/internal/atomicfile"
 	"github.com/kopia/kopia/internal/cache"
+	"github.com/kopia/kopia/internal/clock"
 	"github.com/kopia/kopia/repo/blob"
+	"github.com/kopia/kopia/repo/blob/ecc"
 	loggingwrapper "github.com/kopia/kopia/repo/blob/logging"
 	"github.com/kopia/kopia/repo/blob/readonly"
+	"github.com/kopia/kopia/repo/blob/storagemetrics"
+	"github.com/kopia/kopia/repo/blob/throttling"
 	"github.com/kopia/kopia/repo/content"
 	"github.com/kopia/kopia/repo/logging"
 	"github.com/kopia/kopia/repo/manifest"
@@ -32,6 +36,9 @@
 // refresh indexes every 15 minutes while the repository remains open.
 const backgroundRefreshInterval = 15 * time.Minute
 
+// formatBlobCacheDuration is the maximum amount of time the locally cached format blob is used.
+const formatBlobCacheDuration = 15 * time.Minute
+
 const cacheDirMarkerContents = CacheDirMarkerHeader + `
 #
 # This file is a cache directory tag created by Kopia - Fast And Secure Open-Source Backup.
@@ -154,6 +161,18 @@ func openDirect(ctx context.Context, configFile string, lc *LocalConfig, passwor
 func openWithConfig(ctx context.Context, st blob.Storage, lc *LocalConfig, password string, options *Options, caching *content.CachingOptions, configFile string) (DirectRepository, error) {
 	caching = caching.CloneOrDefault()
 
+	var limits throttling.Limits
+	if lc.Throttling != nil {
+		limits = *lc.Throttling
+	}
+
+	throttler, err := throttling.NewThrottler(limits)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to create throttler")
+	}
+
+	st = throttling.NewWrapper(storagemetrics.NewWrapper(st), throttler)
+
 	// Read format blob, potentially from cache.
 	fb, err := readAndCacheFormatBlobBytes(ctx, st, caching.CacheDirectory)
 	if err != nil {
@@ -194,6 +213,14 @@ func openWithConfig(ctx context.Context, st blob.Storage, lc *LocalConfig, passw
 		fo.MaxPackSize = 20 << 20 // nolint:gomnd
 	}
 
+	if ec := repoConfig.ErrorCorrection; ec != nil {
+		// the format blob must remain readable before the repository format is known.
+		st, err = ecc.NewWrapper(st, *ec, FormatBlobID, FormatBlobBackupID)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to initialize error correction")
+		}
+	}
+
 	cmOpts := &content.ManagerOptions{
 		RepositoryFormatBytes: fb,
 		TimeNow:               defaultTime(options.TimeNowFunc),
@@ -233,6 +260,7 @@ func openWithConfig(ctx context.Context, st blob.Storage, lc *LocalConfig, passw
 			timeNow:        cmOpts.TimeNow,
 			cliOpts:        lc.ClientOptions.ApplyDefaults(ctx, "Repository in "+st.DisplayName()),
 			configFile:     configFile,
+			throttler:      throttler,
 		},
 		closed: make(chan struct{}),
 	}
@@ -279,10 +307,14 @@ func readAndCacheFormatBlobBytes(ctx context.Context, st blob.Storage, cacheDire
 			log(ctx).Errorf("unable to create cache directory: %v", err)
 		}
 
-		b, err := ioutil.ReadFile(cachedFile) //nolint:gosec
-		if err == nil {
-			// read from cache.
-			return b, nil
+		// the cached copy is only used for a limited time, so that changes to the format blob
+		// (such as password changes) made by other clients are eventually detected.
+		if fi, err := os.Stat(cachedFile); err == nil && clock.Since(fi.ModTime()) < formatBlobCacheDuration {
+			b, err := ioutil.ReadFile(cachedFile) //nolint:gosec
+			if err == nil {
+				// read from cache.
+				return b, nil
+			}
 		}
 	}
 
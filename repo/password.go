@@ -44,8 +44,8 @@ func GetPersistedPassword(ctx context.Context, configFile string) (string, bool)
 	return "", false
 }
 
-// persistPassword stores password for a given repository config.
-func persistPassword(ctx context.Context, configFile, password string) error {
+// SetPersistedPassword stores password for a given repository config.
+func SetPersistedPassword(ctx context.Context, configFile, password string) error {
 	if KeyRingEnabled {
 		log(ctx).Debugf("saving password to OS keyring...")
 
@@ -8,6 +8,7 @@
 
 	"github.com/kopia/kopia/internal/clock"
 	"github.com/kopia/kopia/repo/blob"
+	"github.com/kopia/kopia/repo/blob/throttling"
 	"github.com/kopia/kopia/repo/content"
 	"github.com/kopia/kopia/repo/manifest"
 	"github.com/kopia/kopia/repo/object"
@@ -40,6 +41,9 @@ type RepositoryWriter interface {
 	PutManifest(ctx context.Context, labels map[string]string, payload interface{}) (manifest.ID, error)
 	DeleteManifest(ctx context.Context, id manifest.ID) error
 
+	// UploadedBytes returns the number of bytes uploaded to the storage by the writer so far.
+	UploadedBytes() int64
+
 	Flush(ctx context.Context) error
 }
 
@@ -59,6 +63,7 @@ type DirectRepository interface {
 	ConfigFilename() string
 	DeriveKey(purpose []byte, keyLength int) []byte
 	Token(password string) (string, error)
+	Throttler() throttling.SettableThrottler
 }
 
 // DirectRepositoryWriter provides low-level write access to the repository.
@@ -69,6 +74,7 @@ type DirectRepositoryWriter interface {
 	BlobStorage() blob.Storage
 	ContentManager() *content.WriteManager
 	Upgrade(ctx context.Context) error
+	ChangePassword(ctx context.Context, newPassword string) error
 }
 
 type directRepositoryParameters struct {
@@ -79,6 +85,7 @@ type directRepositoryParameters struct {
 	timeNow        func() time.Time
 	formatBlob     *formatBlob
 	masterKey      []byte
+	throttler      throttling.SettableThrottler
 }
 
 // directRepository is an implementation of repository that directly manipulates underlying storage.
@@ -104,6 +111,11 @@ func (r *directRepository) ClientOptions() ClientOptions {
 	return r.cliOpts
 }
 
+// Throttler returns the throttler of blob storage operations, which allows limits to be changed at runtime.
+func (r *directRepository) Throttler() throttling.SettableThrottler {
+	return r.throttler
+}
+
 // BlobStorage returns the blob storage.
 func (r *directRepository) BlobStorage() blob.Storage {
 	return r.blobs
@@ -226,6 +238,11 @@ func (r *directRepository) Close(ctx context.Context) error {
 	return nil
 }
 
+// UploadedBytes returns the number of bytes uploaded to the storage by the writer so far.
+func (r *directRepository) UploadedBytes() int64 {
+	return r.cmgr.UploadedBytes()
+}
+
 // Flush waits for all in-flight writes to complete.
 func (r *directRepository) Flush(ctx context.Context) error {
 	if err := r.mmgr.Flush(ctx); err != nil {
@@ -14,6 +14,9 @@
 	"github.com/kopia/kopia/internal/repotesting"
 	"github.com/kopia/kopia/internal/testutil"
 	"github.com/kopia/kopia/repo"
+	"github.com/kopia/kopia/repo/blob"
+	"github.com/kopia/kopia/repo/blob/ecc"
+	"github.com/kopia/kopia/repo/blob/throttling"
 	"github.com/kopia/kopia/repo/content"
 	"github.com/kopia/kopia/repo/object"
 )
@@ -159,6 +162,9 @@ func TestHMAC(t *testing.T) {
 func TestUpgrade(t *testing.T) {
 	ctx, env := repotesting.NewEnvironment(t)
 
+	oid1 := writeObject(ctx, t, env.RepositoryWriter, []byte{1, 2, 3}, "before-upgrade")
+	require.NoError(t, env.RepositoryWriter.Flush(ctx))
+
 	if err := env.RepositoryWriter.Upgrade(ctx); err != nil {
 		t.Errorf("upgrade error: %v", err)
 	}
@@ -166,6 +172,97 @@ func TestUpgrade(t *testing.T) {
 	if err := env.RepositoryWriter.Upgrade(ctx); err != nil {
 		t.Errorf("2nd upgrade error: %v", err)
 	}
+
+	env.MustReopen(t)
+
+	// contents written before the upgrade must be visible after migration.
+	verify(ctx, t, env.RepositoryWriter, oid1, []byte{1, 2, 3}, "before-upgrade")
+
+	oid2 := writeObject(ctx, t, env.RepositoryWriter, []byte{4, 5, 6}, "after-upgrade")
+	require.NoError(t, env.RepositoryWriter.Flush(ctx))
+
+	env.MustReopen(t)
+
+	verify(ctx, t, env.RepositoryWriter, oid1, []byte{1, 2, 3}, "before-upgrade")
+	verify(ctx, t, env.RepositoryWriter, oid2, []byte{4, 5, 6}, "after-upgrade")
+}
+
+func TestWriteAfterUpgrade(t *testing.T) {
+	ctx, env := repotesting.NewEnvironment(t)
+
+	oid1 := writeObject(ctx, t, env.RepositoryWriter, []byte{1, 2, 3}, "before-upgrade")
+
+	require.NoError(t, env.RepositoryWriter.Upgrade(ctx))
+
+	// write using the session that performed the upgrade, without reopening.
+	oid2 := writeObject(ctx, t, env.RepositoryWriter, []byte{4, 5, 6}, "after-upgrade")
+	require.NoError(t, env.RepositoryWriter.Flush(ctx))
+
+	r, err := repo.Open(ctx, env.ConfigFile(), "foobarbazfoobarbaz", &repo.Options{})
+	require.NoError(t, err)
+
+	defer r.Close(ctx)
+
+	verify(ctx, t, r, oid1, []byte{1, 2, 3}, "before-upgrade")
+	verify(ctx, t, r, oid2, []byte{4, 5, 6}, "after-upgrade")
+}
+
+func TestChangePassword(t *testing.T) {
+	ctx, env := repotesting.NewEnvironment(t)
+
+	oid := writeObject(ctx, t, env.RepositoryWriter, []byte{1, 2, 3}, "before-change")
+	require.NoError(t, env.RepositoryWriter.Flush(ctx))
+
+	require.NoError(t, env.RepositoryWriter.ChangePassword(ctx, "new-password"))
+
+	_, err := repo.Open(ctx, env.ConfigFile(), "foobarbazfoobarbaz", &repo.Options{})
+	require.ErrorIs(t, err, repo.ErrInvalidPassword)
+
+	r, err := repo.Open(ctx, env.ConfigFile(), "new-password", &repo.Options{})
+	require.NoError(t, err)
+
+	defer r.Close(ctx)
+
+	verify(ctx, t, r, oid, []byte{1, 2, 3}, "before-change")
+
+	// backup of the format blob is encrypted using the old password, so it must not be kept.
+	_, err = env.RepositoryWriter.BlobStorage().GetMetadata(ctx, repo.FormatBlobBackupID)
+	require.ErrorIs(t, err, blob.ErrBlobNotFound)
+}
+
+func TestErrorCorrection(t *testing.T) {
+	ctx, env := repotesting.NewEnvironment(t, repotesting.Options{
+		NewRepositoryOptions: func(nro *repo.NewRepositoryOptions) {
+			nro.ErrorCorrection = &ecc.Options{}
+		},
+	})
+
+	oid := writeObject(ctx, t, env.RepositoryWriter, []byte{1, 2, 3}, "ecc")
+	require.NoError(t, env.RepositoryWriter.Flush(ctx))
+
+	env.MustReopen(t)
+
+	verify(ctx, t, env.RepositoryWriter, oid, []byte{1, 2, 3}, "ecc")
+
+	// pack blobs must be stored with error correction data.
+	st := env.RepositoryWriter.BlobStorage()
+	packs := 0
+
+	require.NoError(t, st.ListBlobs(ctx, content.PackBlobIDPrefixRegular, func(bm blob.Metadata) error {
+		packs++
+
+		data, err := st.GetBlob(ctx, bm.BlobID, 0, -1)
+		require.NoError(t, err)
+		require.Less(t, int64(len(data)), bm.Length)
+
+		repaired, err := ecc.Repair(ctx, st, bm.BlobID)
+		require.NoError(t, err)
+		require.False(t, repaired)
+
+		return nil
+	}))
+
+	require.NotZero(t, packs)
 }
 
 func TestReaderStoredBlockNotFound(t *testing.T) {
@@ -458,3 +555,25 @@ func verifyNotFound(ctx context.Context, t *testing.T, rep repo.Repository, obje
 		return
 	}
 }
+
+func TestThrottlingLimits(t *testing.T) {
+	ctx, env := repotesting.NewEnvironment(t)
+
+	require.Equal(t, throttling.Limits{}, env.RepositoryWriter.Throttler().Limits())
+
+	limits := throttling.Limits{
+		ListsPerSecond:         5,
+		DownloadBytesPerSecond: 1e9,
+	}
+
+	require.NoError(t, repo.SetThrottlingLimits(ctx, env.ConfigFile(), limits))
+	require.Error(t, repo.SetThrottlingLimits(ctx, env.ConfigFile(), throttling.Limits{ReadsPerSecond: -1}))
+
+	got, err := repo.GetThrottlingLimits(ctx, env.ConfigFile())
+	require.NoError(t, err)
+	require.Equal(t, limits, got)
+
+	env.MustReopen(t)
+
+	require.Equal(t, limits, env.RepositoryWriter.Throttler().Limits())
+}
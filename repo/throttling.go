@@ -0,0 +1,45 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/kopia/kopia/repo/blob/throttling"
+)
+
+// GetThrottlingLimits reads throttling limits stored in the provided configuration file.
+func GetThrottlingLimits(ctx context.Context, configFile string) (throttling.Limits, error) {
+	lc, err := LoadConfigFromFile(configFile)
+	if err != nil {
+		return throttling.Limits{}, err
+	}
+
+	if lc.Throttling == nil {
+		return throttling.Limits{}, nil
+	}
+
+	return *lc.Throttling, nil
+}
+
+// SetThrottlingLimits persists throttling limits in the provided configuration file.
+// The limits will be applied next time the repository is opened, to change the limits
+// of an open repository use DirectRepository.Throttler().
+func SetThrottlingLimits(ctx context.Context, configFile string, limits throttling.Limits) error {
+	if err := limits.Validate(); err != nil {
+		return errors.Wrap(err, "invalid throttling limits")
+	}
+
+	lc, err := LoadConfigFromFile(configFile)
+	if err != nil {
+		return err
+	}
+
+	if lc.Storage == nil {
+		return errors.Errorf("throttling is only supported for direct repository connections")
+	}
+
+	lc.Throttling = &limits
+
+	return lc.writeToFile(configFile)
+}
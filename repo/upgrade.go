@@ -4,6 +4,11 @@
 	"context"
 
 	"github.com/pkg/errors"
+
+	"github.com/kopia/kopia/internal/epoch"
+	"github.com/kopia/kopia/internal/gather"
+	"github.com/kopia/kopia/repo/blob"
+	"github.com/kopia/kopia/repo/content"
 )
 
 // Upgrade upgrades repository data structures to the latest version.
@@ -15,9 +20,35 @@ func (r *directRepository) Upgrade(ctx context.Context) error {
 		return errors.Wrap(err, "unable to decrypt repository config")
 	}
 
+	// make sure contents written by this session so far are in index blobs that will be migrated.
+	if err := r.Flush(ctx); err != nil {
+		return errors.Wrap(err, "error flushing pending writes")
+	}
+
 	var migrated bool
 
-	// add migration code here
+	if !repoConfig.EpochParameters.Enabled {
+		log(ctx).Infof("migrating index blobs to epoch-based index management...")
+
+		// copy existing index blobs before and after updating the format blob to also pick up
+		// index blobs written by other clients while the migration was in progress.
+		if err := r.copyIndexBlobsToEpochZero(ctx); err != nil {
+			return errors.Wrap(err, "error copying index blobs")
+		}
+
+		repoConfig.Version = content.FormatVersion2
+		repoConfig.EpochParameters = epoch.DefaultParameters
+		migrated = true
+	}
+
+	if repoConfig.IndexVersion < content.IndexVersion2 {
+		log(ctx).Infof("upgrading index format to v%v...", content.IndexVersion2)
+
+		repoConfig.Version = content.FormatVersion2
+		repoConfig.IndexVersion = content.IndexVersion2
+		migrated = true
+	}
+
 	if !migrated {
 		log(ctx).Infof("nothing to do")
 		return nil
@@ -29,5 +60,65 @@ func (r *directRepository) Upgrade(ctx context.Context) error {
 
 	log(ctx).Infof("writing updated format content...")
 
-	return writeFormatBlob(ctx, r.blobs, f)
+	if err := writeFormatBlob(ctx, r.blobs, f); err != nil {
+		return err
+	}
+
+	if err := r.copyIndexBlobsToEpochZero(ctx); err != nil {
+		return errors.Wrap(err, "error copying index blobs")
+	}
+
+	// switch this session to the new format, otherwise its subsequent writes would go to legacy index blobs
+	// which are ignored by clients using the new format.
+	if err := r.cmgr.UpgradeFormat(ctx, &repoConfig.FormattingOptions); err != nil {
+		return errors.Wrap(err, "error switching to upgraded format")
+	}
+
+	if _, err := r.cmgr.Refresh(ctx); err != nil {
+		return errors.Wrap(err, "error refreshing indexes")
+	}
+
+	log(ctx).Infof("Repository upgraded. All other clients must be upgraded and reconnected before writing to the repository.")
+
+	return nil
+}
+
+// copyIndexBlobsToEpochZero copies all active legacy index blobs into the first epoch.
+// Index blob encryption only depends on the suffix of the blob ID, so copies can be read without re-encrypting.
+func (r *directRepository) copyIndexBlobsToEpochZero(ctx context.Context) error {
+	if _, err := r.cmgr.Refresh(ctx); err != nil {
+		return errors.Wrap(err, "error refreshing indexes")
+	}
+
+	indexBlobs, err := r.cmgr.IndexBlobs(ctx, false)
+	if err != nil {
+		return errors.Wrap(err, "error listing index blobs")
+	}
+
+	for _, ib := range indexBlobs {
+		targetBlobID := epoch.UncompactedEpochBlobPrefix(0) + ib.BlobID
+
+		_, err := r.blobs.GetMetadata(ctx, targetBlobID)
+		if err == nil {
+			// already copied
+			continue
+		}
+
+		if !errors.Is(err, blob.ErrBlobNotFound) {
+			return errors.Wrapf(err, "error checking for index blob %v", targetBlobID)
+		}
+
+		data, err := r.blobs.GetBlob(ctx, ib.BlobID, 0, -1)
+		if err != nil {
+			return errors.Wrapf(err, "error reading index blob %v", ib.BlobID)
+		}
+
+		log(ctx).Debugf("copying index blob %v to %v", ib.BlobID, targetBlobID)
+
+		if err := r.blobs.PutBlob(ctx, targetBlobID, gather.FromSlice(data)); err != nil {
+			return errors.Wrapf(err, "error writing index blob %v", targetBlobID)
+		}
+	}
+
+	return nil
 }
@@ -123,11 +123,15 @@ func SaveSnapshot(ctx context.Context, rep repo.RepositoryWriter, man *Manifest)
 		return "", errors.New("missing path")
 	}
 
+	if err := ValidateTags(man.Tags); err != nil {
+		return "", err
+	}
+
 	// clear manifest ID in case it was set, since we'll be generating a new one and we don't want
 	// to write previous ID in JSON.
 	man.ID = ""
 
-	id, err := rep.PutManifest(ctx, sourceInfoToLabels(man.Source), man)
+	id, err := rep.PutManifest(ctx, mergeLabels(sourceInfoToLabels(man.Source), tagsToLabels(man.Tags)), man)
 	if err != nil {
 		return "", errors.Wrap(err, "error putting manifest")
 	}
@@ -137,6 +141,24 @@ func SaveSnapshot(ctx context.Context, rep repo.RepositoryWriter, man *Manifest)
 	return id, nil
 }
 
+// UpdateSnapshot replaces the provided snapshot manifest with its updated version and returns the new manifest ID.
+func UpdateSnapshot(ctx context.Context, rep repo.RepositoryWriter, man *Manifest) (manifest.ID, error) {
+	oldID := man.ID
+
+	id, err := SaveSnapshot(ctx, rep, man)
+	if err != nil {
+		return "", err
+	}
+
+	if oldID != "" && oldID != id {
+		if err := rep.DeleteManifest(ctx, oldID); err != nil {
+			return "", errors.Wrap(err, "unable to delete previous manifest")
+		}
+	}
+
+	return id, nil
+}
+
 // LoadSnapshots efficiently loads and parses a given list of snapshot IDs.
 func LoadSnapshots(ctx context.Context, rep repo.Repository, manifestIDs []manifest.ID) ([]*Manifest, error) {
 	result := make([]*Manifest, len(manifestIDs))
@@ -176,6 +198,12 @@ func LoadSnapshots(ctx context.Context, rep repo.Repository, manifestIDs []manif
 
 // ListSnapshotManifests returns the list of snapshot manifests for a given source or all sources if nil.
 func ListSnapshotManifests(ctx context.Context, rep repo.Repository, src *SourceInfo) ([]manifest.ID, error) {
+	return ListSnapshotManifestsWithTags(ctx, rep, src, nil)
+}
+
+// ListSnapshotManifestsWithTags returns the list of snapshot manifests for a given source or all sources if nil,
+// which have all of the provided tags.
+func ListSnapshotManifestsWithTags(ctx context.Context, rep repo.Repository, src *SourceInfo, tags map[string]string) ([]manifest.ID, error) {
 	labels := map[string]string{
 		typeKey: ManifestType,
 	}
@@ -184,6 +212,8 @@ func ListSnapshotManifests(ctx context.Context, rep repo.Repository, src *Source
 		labels = sourceInfoToLabels(*src)
 	}
 
+	labels = mergeLabels(labels, tagsToLabels(tags))
+
 	entries, err := rep.FindManifests(ctx, labels)
 	if err != nil {
 		return nil, errors.Wrap(err, "unable to find manifest entries")
@@ -18,9 +18,11 @@ type Manifest struct {
 	ID     manifest.ID `json:"id"`
 	Source SourceInfo  `json:"source"`
 
-	Description string    `json:"description"`
-	StartTime   time.Time `json:"startTime"`
-	EndTime     time.Time `json:"endTime"`
+	Description string            `json:"description"`
+	Tags        map[string]string `json:"tags,omitempty"`
+	Pins        []string          `json:"pins,omitempty"`
+	StartTime   time.Time         `json:"startTime"`
+	EndTime     time.Time         `json:"endTime"`
 
 	Stats            Stats  `json:"stats,omitempty"`
 	IncompleteReason string `json:"incomplete,omitempty"`
@@ -84,6 +86,18 @@ type DirEntry struct {
 	GroupID     uint32               `json:"gid,omitempty"`
 	ObjectID    object.ID            `json:"obj,omitempty"`
 	DirSummary  *fs.DirectorySummary `json:"summ,omitempty"`
+
+	ExtendedAttributes *ExtendedAttributes `json:"xattrs,omitempty"`
+
+	// HardLinkGroup identifies files in the snapshot that are hard links to the same inode.
+	HardLinkGroup string `json:"hlink,omitempty"`
+}
+
+// ExtendedAttributes references extended attributes of a directory entry, which are stored
+// inline when small or as a separate object otherwise.
+type ExtendedAttributes struct {
+	Inline   fs.ExtendedAttributes `json:"inline,omitempty"`
+	ObjectID object.ID             `json:"obj,omitempty"`
 }
 
 // HasDirEntry is implemented by objects that have a DirEntry associated with them.
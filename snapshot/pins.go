@@ -0,0 +1,41 @@
+package snapshot
+
+import (
+	"sort"
+)
+
+// UpdatePins adds and removes the provided named pins, which prevent the snapshot from being expired
+// by the retention policy, and returns true if the pins have changed.
+func (m *Manifest) UpdatePins(add, remove []string) bool {
+	pins := map[string]bool{}
+
+	for _, p := range m.Pins {
+		pins[p] = true
+	}
+
+	for _, p := range add {
+		pins[p] = true
+	}
+
+	for _, p := range remove {
+		delete(pins, p)
+	}
+
+	var result []string
+
+	for p := range pins {
+		result = append(result, p)
+	}
+
+	sort.Strings(result)
+
+	changed := len(result) != len(m.Pins)
+
+	for i := 0; !changed && i < len(result); i++ {
+		changed = result[i] != m.Pins[i]
+	}
+
+	m.Pins = result
+
+	return changed
+}
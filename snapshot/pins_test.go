@@ -0,0 +1,26 @@
+package snapshot
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdatePins(t *testing.T) {
+	var m Manifest
+
+	require.False(t, m.UpdatePins(nil, nil))
+	require.Empty(t, m.Pins)
+
+	require.True(t, m.UpdatePins([]string{"b", "a", "b"}, nil))
+	require.Equal(t, []string{"a", "b"}, m.Pins)
+
+	require.False(t, m.UpdatePins([]string{"a"}, []string{"c"}))
+	require.Equal(t, []string{"a", "b"}, m.Pins)
+
+	require.True(t, m.UpdatePins([]string{"c"}, []string{"a"}))
+	require.Equal(t, []string{"b", "c"}, m.Pins)
+
+	require.True(t, m.UpdatePins(nil, []string{"b", "c"}))
+	require.Empty(t, m.Pins)
+}
@@ -0,0 +1,234 @@
+package policy
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	daysPerWeek = 7
+
+	unlimitedBandwidthString = "unlimited"
+)
+
+// BandwidthWindow limits the upload rate during a range of time on selected days of the week.
+// A window whose end time is not after its start time extends past midnight into the following day.
+type BandwidthWindow struct {
+	// DaysOfWeek on which the window starts, empty means every day.
+	DaysOfWeek []time.Weekday `json:"days,omitempty"`
+
+	Start TimeOfDay `json:"start"`
+	End   TimeOfDay `json:"end"`
+
+	// UploadBytesPerSecond is the maximum upload rate during the window, zero means unlimited.
+	UploadBytesPerSecond float64 `json:"maxUploadSpeedBytesPerSecond"`
+}
+
+func (t TimeOfDay) minutes() int {
+	return t.Hour*60 + t.Minute //nolint:gomnd
+}
+
+func (w BandwidthWindow) startsOn(d time.Weekday) bool {
+	if len(w.DaysOfWeek) == 0 {
+		return true
+	}
+
+	for _, wd := range w.DaysOfWeek {
+		if wd == d {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsActive returns true if the window is in effect at the provided time.
+func (w BandwidthWindow) IsActive(t time.Time) bool {
+	m := t.Hour()*60 + t.Minute() //nolint:gomnd
+	start, end := w.Start.minutes(), w.End.minutes()
+
+	if start < end {
+		return w.startsOn(t.Weekday()) && m >= start && m < end
+	}
+
+	// window that started on the previous day and extends past midnight.
+	if m < end && w.startsOn((t.Weekday()+daysPerWeek-1)%daysPerWeek) {
+		return true
+	}
+
+	return m >= start && w.startsOn(t.Weekday())
+}
+
+// Validate returns an error if the window is invalid.
+func (w BandwidthWindow) Validate() error {
+	for _, tod := range []TimeOfDay{w.Start, w.End} {
+		if tod.Hour < 0 || tod.Hour > 23 || tod.Minute < 0 || tod.Minute > 59 {
+			return errors.Errorf("invalid time of day: %v", tod)
+		}
+	}
+
+	for _, d := range w.DaysOfWeek {
+		if d < time.Sunday || d > time.Saturday {
+			return errors.Errorf("invalid day of week: %v", int(d))
+		}
+	}
+
+	if w.UploadBytesPerSecond < 0 {
+		return errors.Errorf("invalid upload rate: %v", w.UploadBytesPerSecond)
+	}
+
+	return nil
+}
+
+// String returns string representation of the bandwidth window, which can be parsed using ParseBandwidthWindow().
+func (w BandwidthWindow) String() string {
+	var parts []string
+
+	if len(w.DaysOfWeek) > 0 {
+		var days []string
+
+		for _, d := range w.DaysOfWeek {
+			days = append(days, strings.ToLower(d.String()[0:3]))
+		}
+
+		parts = append(parts, strings.Join(days, ","))
+	}
+
+	parts = append(parts, w.Start.String()+"-"+w.End.String())
+
+	if w.UploadBytesPerSecond == 0 {
+		parts = append(parts, unlimitedBandwidthString)
+	} else {
+		parts = append(parts, strconv.FormatFloat(w.UploadBytesPerSecond, 'f', -1, 64))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+func parseWeekday(s string) (time.Weekday, error) {
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if strings.EqualFold(s, d.String()[0:3]) || strings.EqualFold(s, d.String()) {
+			return d, nil
+		}
+	}
+
+	return 0, errors.Errorf("invalid day of week: %q", s)
+}
+
+// parseDaysOfWeek parses comma-separated list of days of week or ranges of days, such as "mon-fri,sun".
+func parseDaysOfWeek(s string) ([]time.Weekday, error) {
+	var result []time.Weekday
+
+	for _, item := range strings.Split(s, ",") {
+		first, last := item, item
+		if p := strings.Index(item, "-"); p >= 0 {
+			first, last = item[0:p], item[p+1:]
+		}
+
+		firstDay, err := parseWeekday(first)
+		if err != nil {
+			return nil, err
+		}
+
+		lastDay, err := parseWeekday(last)
+		if err != nil {
+			return nil, err
+		}
+
+		for d := firstDay; ; d = (d + 1) % daysPerWeek {
+			result = append(result, d)
+
+			if d == lastDay {
+				break
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// ParseBandwidthWindow parses the bandwidth window in the format "[DAYS] HH:MM-HH:MM RATE",
+// where DAYS is a comma-separated list of days of week or ranges of days (such as "mon-fri,sun")
+// and RATE is the maximum number of bytes per second or "unlimited".
+func ParseBandwidthWindow(s string) (BandwidthWindow, error) {
+	var w BandwidthWindow
+
+	fields := strings.Fields(s)
+
+	switch len(fields) {
+	case 2: //nolint:gomnd
+	case 3: //nolint:gomnd
+		days, err := parseDaysOfWeek(fields[0])
+		if err != nil {
+			return w, err
+		}
+
+		w.DaysOfWeek = days
+		fields = fields[1:]
+
+	default:
+		return w, errors.Errorf("invalid bandwidth window %q, must be [DAYS] HH:MM-HH:MM RATE", s)
+	}
+
+	p := strings.Index(fields[0], "-")
+	if p < 0 {
+		return w, errors.Errorf("invalid time range %q, must be HH:MM-HH:MM", fields[0])
+	}
+
+	if err := w.Start.Parse(fields[0][0:p]); err != nil {
+		return w, errors.Wrap(err, "invalid start time")
+	}
+
+	if err := w.End.Parse(fields[0][p+1:]); err != nil {
+		return w, errors.Wrap(err, "invalid end time")
+	}
+
+	if fields[1] != unlimitedBandwidthString {
+		rate, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil || rate <= 0 {
+			return w, errors.Errorf("invalid rate %q, must be a positive number of bytes per second or %q", fields[1], unlimitedBandwidthString)
+		}
+
+		w.UploadBytesPerSecond = rate
+	}
+
+	return w, nil
+}
+
+// UploadBandwidthAt returns the maximum upload rate in bytes per second (zero means unlimited) in effect
+// at the provided time and the time when it may change next (zero if it will never change).
+// When multiple bandwidth windows are in effect, the first one wins.
+func (p *SchedulingPolicy) UploadBandwidthAt(t time.Time) (bytesPerSecond float64, nextChange time.Time) {
+	if len(p.BandwidthWindows) == 0 {
+		return 0, time.Time{}
+	}
+
+	for _, w := range p.BandwidthWindows {
+		if w.IsActive(t) {
+			bytesPerSecond = w.UploadBytesPerSecond
+			break
+		}
+	}
+
+	// find the nearest window boundary after the provided time, ignoring days of week, which may
+	// report boundaries where the rate does not actually change, but never misses one.
+	for dayOffset := 0; dayOffset <= 1; dayOffset++ {
+		for _, w := range p.BandwidthWindows {
+			for _, tod := range []TimeOfDay{w.Start, w.End} {
+				b := time.Date(t.Year(), t.Month(), t.Day()+dayOffset, tod.Hour, tod.Minute, 0, 0, t.Location())
+				if b.After(t) && (nextChange.IsZero() || b.Before(nextChange)) {
+					nextChange = b
+				}
+			}
+		}
+
+		if !nextChange.IsZero() {
+			break
+		}
+	}
+
+	return bytesPerSecond, nextChange
+}
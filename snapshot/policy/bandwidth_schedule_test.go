@@ -0,0 +1,119 @@
+package policy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBandwidthWindow(t *testing.T) {
+	cases := []struct {
+		input   string
+		want    BandwidthWindow
+		wantErr bool
+	}{
+		{
+			input: "9:00-17:00 1000000",
+			want:  BandwidthWindow{Start: TimeOfDay{9, 0}, End: TimeOfDay{17, 0}, UploadBytesPerSecond: 1000000},
+		},
+		{
+			input: "mon-fri 9:00-17:30 1048576",
+			want: BandwidthWindow{
+				DaysOfWeek:           []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+				Start:                TimeOfDay{9, 0},
+				End:                  TimeOfDay{17, 30},
+				UploadBytesPerSecond: 1048576,
+			},
+		},
+		{
+			input: "fri-sun,wed 22:00-6:00 unlimited",
+			want: BandwidthWindow{
+				DaysOfWeek: []time.Weekday{time.Friday, time.Saturday, time.Sunday, time.Wednesday},
+				Start:      TimeOfDay{22, 0},
+				End:        TimeOfDay{6, 0},
+			},
+		},
+		{input: "9:00-17:00", wantErr: true},
+		{input: "9:00 1000", wantErr: true},
+		{input: "9:00-25:00 1000", wantErr: true},
+		{input: "9:00-17:00 -5", wantErr: true},
+		{input: "9:00-17:00 fast", wantErr: true},
+		{input: "mon-xyz 9:00-17:00 1000", wantErr: true},
+	}
+
+	for _, tc := range cases {
+		got, err := ParseBandwidthWindow(tc.input)
+		if tc.wantErr {
+			require.Error(t, err, tc.input)
+			continue
+		}
+
+		require.NoError(t, err, tc.input)
+		require.Equal(t, tc.want, got, tc.input)
+
+		// round-trip through String()
+		got2, err := ParseBandwidthWindow(got.String())
+		require.NoError(t, err, got.String())
+		require.Equal(t, got, got2)
+	}
+}
+
+func TestUploadBandwidthAt(t *testing.T) {
+	sp := &SchedulingPolicy{
+		BandwidthWindows: []BandwidthWindow{
+			{
+				DaysOfWeek:           []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+				Start:                TimeOfDay{9, 0},
+				End:                  TimeOfDay{17, 0},
+				UploadBytesPerSecond: 1000000,
+			},
+			{
+				DaysOfWeek:           []time.Weekday{time.Friday},
+				Start:                TimeOfDay{22, 0},
+				End:                  TimeOfDay{2, 0},
+				UploadBytesPerSecond: 5000,
+			},
+		},
+	}
+
+	// 2021-06-07 is a Monday.
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2021, 6, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	cases := []struct {
+		t          time.Time
+		wantRate   float64
+		wantChange time.Time
+	}{
+		{at(7, 8, 59), 0, at(7, 9, 0)},
+		{at(7, 9, 0), 1000000, at(7, 17, 0)},
+		{at(7, 16, 59), 1000000, at(7, 17, 0)},
+		{at(7, 17, 0), 0, at(7, 22, 0)},
+		{at(7, 23, 0), 0, at(8, 2, 0)},
+		{at(11, 23, 0), 5000, at(12, 2, 0)},
+		{at(12, 1, 59), 5000, at(12, 2, 0)},
+		{at(12, 2, 0), 0, at(12, 9, 0)},
+		{at(12, 10, 0), 0, at(12, 17, 0)},
+	}
+
+	for _, tc := range cases {
+		rate, nextChange := sp.UploadBandwidthAt(tc.t)
+		require.Equal(t, tc.wantRate, rate, tc.t)
+		require.Equal(t, tc.wantChange, nextChange, tc.t)
+	}
+
+	rate, nextChange := (&SchedulingPolicy{}).UploadBandwidthAt(at(7, 10, 0))
+	require.Zero(t, rate)
+	require.True(t, nextChange.IsZero())
+}
+
+func TestValidateSchedulingPolicy_BandwidthWindows(t *testing.T) {
+	w := BandwidthWindow{Start: TimeOfDay{9, 0}, End: TimeOfDay{17, 0}, UploadBytesPerSecond: 1000}
+
+	require.NoError(t, ValidateSchedulingPolicy(SchedulingPolicy{Manual: true, BandwidthWindows: []BandwidthWindow{w}}))
+
+	w.UploadBytesPerSecond = -1
+	require.Error(t, ValidateSchedulingPolicy(SchedulingPolicy{BandwidthWindows: []BandwidthWindow{w}}))
+}
@@ -0,0 +1,244 @@
+package policy
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	maxWeeksPerMonth = 5
+
+	// cron expressions that never match (such as "0 0 30 2 *") give up after this many years.
+	maxCronSearchYears = 5
+)
+
+var (
+	cronMonthNames = []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}
+	cronDayNames   = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+	cronMacros = map[string]string{
+		"@yearly":   "0 0 1 1 *",
+		"@annually": "0 0 1 1 *",
+		"@monthly":  "0 0 1 * *",
+		"@weekly":   "0 0 * * 0",
+		"@daily":    "0 0 * * *",
+		"@midnight": "0 0 * * *",
+		"@hourly":   "0 * * * *",
+	}
+)
+
+// cronSchedule is a parsed cron expression.
+type cronSchedule struct {
+	minutes     [60]bool
+	hours       [24]bool
+	daysOfMonth [32]bool
+	months      [13]bool
+	daysOfWeek  [7]bool
+
+	// nthDaysOfWeek[d][n] is true when the expression includes the n-th (1-based) weekday d of the month.
+	nthDaysOfWeek [7][maxWeeksPerMonth + 1]bool
+
+	domRestricted bool
+	dowRestricted bool
+}
+
+// parseCronField parses a single comma-separated cron field, calling set() for each value that matches.
+// Values may be numbers or names (when provided), ranges ("a-b"), wildcards and steps ("*/n", "a-b/n").
+func parseCronField(s string, min, max int, names []string, set func(v int)) error {
+	for _, item := range strings.Split(s, ",") {
+		step := 1
+
+		if p := strings.Index(item, "/"); p >= 0 {
+			n, err := strconv.Atoi(item[p+1:])
+			if err != nil || n <= 0 {
+				return errors.Errorf("invalid step in %q", item)
+			}
+
+			step = n
+			item = item[0:p]
+		}
+
+		first, last := min, max
+
+		if item != "*" {
+			r := strings.SplitN(item, "-", 2) //nolint:gomnd
+
+			v, err := parseCronValue(r[0], min, max, names)
+			if err != nil {
+				return err
+			}
+
+			first, last = v, v
+
+			if len(r) == 2 { //nolint:gomnd
+				if last, err = parseCronValue(r[1], min, max, names); err != nil {
+					return err
+				}
+			} else if step != 1 {
+				// "a/n" means starting at a until the maximum value.
+				last = max
+			}
+
+			if last < first {
+				return errors.Errorf("invalid range %q", item)
+			}
+		}
+
+		for v := first; v <= last; v += step {
+			set(v)
+		}
+	}
+
+	return nil
+}
+
+func parseCronValue(s string, min, max int, names []string) (int, error) {
+	for i, n := range names {
+		if strings.EqualFold(s, n) {
+			return min + i, nil
+		}
+	}
+
+	v, err := strconv.Atoi(s)
+	if err != nil || v < min || v > max {
+		return 0, errors.Errorf("invalid value %q, must be between %v and %v", s, min, max)
+	}
+
+	return v, nil
+}
+
+// parseCronDaysOfWeek parses the day-of-week field, which in addition to regular syntax supports "d#n"
+// meaning n-th weekday d of the month (e.g. "sun#1" is the first Sunday).
+func (c *cronSchedule) parseCronDaysOfWeek(s string) error {
+	var regular []string
+
+	for _, item := range strings.Split(s, ",") {
+		p := strings.Index(item, "#")
+		if p < 0 {
+			regular = append(regular, item)
+			continue
+		}
+
+		d, err := parseCronValue(item[0:p], 0, 7, cronDayNames) //nolint:gomnd
+		if err != nil {
+			return err
+		}
+
+		n, err := strconv.Atoi(item[p+1:])
+		if err != nil || n < 1 || n > maxWeeksPerMonth {
+			return errors.Errorf("invalid week number in %q", item)
+		}
+
+		c.nthDaysOfWeek[d%7][n] = true
+	}
+
+	if len(regular) == 0 {
+		return nil
+	}
+
+	return parseCronField(strings.Join(regular, ","), 0, 7, cronDayNames, func(v int) { //nolint:gomnd
+		// both 0 and 7 mean Sunday.
+		c.daysOfWeek[v%7] = true
+	})
+}
+
+// parseCronExpression parses standard 5-field cron expression "minute hour day-of-month month day-of-week"
+// or one of the predefined macros, such as "@daily".
+func parseCronExpression(expr string) (*cronSchedule, error) {
+	if m, ok := cronMacros[strings.ToLower(strings.TrimSpace(expr))]; ok {
+		expr = m
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 { //nolint:gomnd
+		return nil, errors.Errorf("invalid cron expression %q, must have 5 fields: minute hour day-of-month month day-of-week", expr)
+	}
+
+	c := &cronSchedule{
+		domRestricted: fields[2] != "*",
+		dowRestricted: fields[4] != "*",
+	}
+
+	if err := parseCronField(fields[0], 0, 59, nil, func(v int) { c.minutes[v] = true }); err != nil { //nolint:gomnd
+		return nil, errors.Wrap(err, "minute")
+	}
+
+	if err := parseCronField(fields[1], 0, 23, nil, func(v int) { c.hours[v] = true }); err != nil { //nolint:gomnd
+		return nil, errors.Wrap(err, "hour")
+	}
+
+	if err := parseCronField(fields[2], 1, 31, nil, func(v int) { c.daysOfMonth[v] = true }); err != nil { //nolint:gomnd
+		return nil, errors.Wrap(err, "day of month")
+	}
+
+	if err := parseCronField(fields[3], 1, 12, cronMonthNames, func(v int) { c.months[v] = true }); err != nil { //nolint:gomnd
+		return nil, errors.Wrap(err, "month")
+	}
+
+	if err := c.parseCronDaysOfWeek(fields[4]); err != nil {
+		return nil, errors.Wrap(err, "day of week")
+	}
+
+	return c, nil
+}
+
+func (c *cronSchedule) dayMatches(t time.Time) bool {
+	wd := t.Weekday()
+	dowMatches := c.daysOfWeek[wd] || c.nthDaysOfWeek[wd][(t.Day()-1)/7+1]
+	domMatches := c.daysOfMonth[t.Day()]
+
+	switch {
+	case c.domRestricted && c.dowRestricted:
+		// like in traditional cron, when both fields are restricted the day matches either of them.
+		return domMatches || dowMatches
+	case c.dowRestricted:
+		return dowMatches
+	default:
+		return domMatches
+	}
+}
+
+// next returns the earliest time strictly after the provided time that matches the schedule
+// or zero time if there is none.
+func (c *cronSchedule) next(after time.Time) time.Time {
+	limit := after.AddDate(maxCronSearchYears, 0, 0)
+	loc := after.Location()
+
+	t := time.Date(after.Year(), after.Month(), after.Day(), after.Hour(), after.Minute(), 0, 0, loc).Add(time.Minute)
+
+	for t.Before(limit) {
+		var nt time.Time
+
+		switch {
+		case !c.months[t.Month()]:
+			nt = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+		case !c.dayMatches(t):
+			nt = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+		case !c.hours[t.Hour()]:
+			nt = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+		case !c.minutes[t.Minute()] || !t.After(after):
+			nt = t.Add(time.Minute)
+		default:
+			return t
+		}
+
+		// around daylight saving time changes the wall clock may repeat, always make progress.
+		if !nt.After(t) {
+			nt = t.Add(time.Minute)
+		}
+
+		t = nt
+	}
+
+	return time.Time{}
+}
+
+// ValidateCronExpression returns an error if the provided cron expression is invalid.
+func ValidateCronExpression(expr string) error {
+	_, err := parseCronExpression(expr)
+
+	return err
+}
@@ -0,0 +1,143 @@
+package policy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCronNext(t *testing.T) {
+	// 2021-06-07 is a Monday.
+	at := func(month time.Month, day, hour, minute int) time.Time {
+		return time.Date(2021, month, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	cases := []struct {
+		expr  string
+		after time.Time
+		want  []time.Time
+	}{
+		{
+			expr:  "*/15 * * * *",
+			after: at(6, 7, 10, 7),
+			want:  []time.Time{at(6, 7, 10, 15), at(6, 7, 10, 30), at(6, 7, 10, 45), at(6, 7, 11, 0)},
+		},
+		{
+			expr:  "@daily",
+			after: at(6, 7, 0, 0),
+			want:  []time.Time{at(6, 8, 0, 0), at(6, 9, 0, 0)},
+		},
+		{
+			// weekdays at 02:00
+			expr:  "0 2 * * mon-fri",
+			after: at(6, 10, 12, 0),
+			want:  []time.Time{at(6, 11, 2, 0), at(6, 14, 2, 0), at(6, 15, 2, 0)},
+		},
+		{
+			// first Sunday of the month
+			expr:  "30 3 * * sun#1",
+			after: at(6, 1, 0, 0),
+			want:  []time.Time{at(6, 6, 3, 30), at(7, 4, 3, 30), at(8, 1, 3, 30)},
+		},
+		{
+			// day of month or day of week, like in traditional cron
+			expr:  "0 0 15 * sat",
+			after: at(6, 10, 0, 0),
+			want:  []time.Time{at(6, 12, 0, 0), at(6, 15, 0, 0), at(6, 19, 0, 0)},
+		},
+		{
+			expr:  "0 12 29 feb *",
+			after: at(6, 7, 0, 0),
+			want:  []time.Time{time.Date(2024, 2, 29, 12, 0, 0, 0, time.UTC)},
+		},
+		{
+			expr:  "0 0 30 2 *",
+			after: at(6, 7, 0, 0),
+			want:  []time.Time{{}},
+		},
+	}
+
+	for _, tc := range cases {
+		c, err := parseCronExpression(tc.expr)
+		require.NoError(t, err, tc.expr)
+
+		t0 := tc.after
+		for _, want := range tc.want {
+			t0 = c.next(t0)
+			require.Equal(t, want, t0, tc.expr)
+		}
+	}
+}
+
+func TestCronInvalid(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"* * * * mon#6",
+		"*/0 * * * *",
+		"10-5 * * * *",
+		"@sometimes",
+	} {
+		require.Error(t, ValidateCronExpression(expr), expr)
+	}
+}
+
+func TestNextCronSnapshotTime(t *testing.T) {
+	now := time.Date(2021, 6, 7, 10, 0, 0, 0, time.UTC)
+
+	sp := &SchedulingPolicy{
+		// weekdays at 02:00 and Saturday at 12:00
+		Cron: []string{"0 2 * * 1-5", "0 12 * * 6"},
+	}
+
+	next, ok := sp.NextCronSnapshotTime("src", now)
+	require.True(t, ok)
+	require.Equal(t, time.Date(2021, 6, 8, 2, 0, 0, 0, time.UTC), next)
+
+	next, ok = sp.NextCronSnapshotTime("src", time.Date(2021, 6, 11, 3, 0, 0, 0, time.UTC))
+	require.True(t, ok)
+	require.Equal(t, time.Date(2021, 6, 12, 12, 0, 0, 0, time.UTC), next)
+
+	// with jitter the time is delayed, but stable.
+	sp.JitterSeconds = 3600
+	next, ok = sp.NextCronSnapshotTime("src", now)
+	require.True(t, ok)
+	require.False(t, next.Before(time.Date(2021, 6, 8, 2, 0, 0, 0, time.UTC)))
+	require.True(t, next.Before(time.Date(2021, 6, 8, 3, 0, 0, 0, time.UTC)))
+
+	// recalculating the time while the snapshot is waiting for its jitter does not skip it.
+	if delayed := next.Add(-time.Second); delayed.After(time.Date(2021, 6, 8, 2, 0, 0, 0, time.UTC)) {
+		next2, ok2 := sp.NextCronSnapshotTime("src", delayed)
+		require.True(t, ok2)
+		require.Equal(t, next, next2)
+	}
+
+	// once the snapshot time has passed, the next one is returned.
+	next2, ok := sp.NextCronSnapshotTime("src", next)
+	require.True(t, ok)
+	require.True(t, next2.After(time.Date(2021, 6, 9, 2, 0, 0, 0, time.UTC)))
+
+	_, ok = (&SchedulingPolicy{}).NextCronSnapshotTime("src", now)
+	require.False(t, ok)
+}
+
+func TestSchedulingPolicyMerge_Cron(t *testing.T) {
+	var p SchedulingPolicy
+
+	p.Merge(SchedulingPolicy{Cron: []string{"@daily"}, JitterSeconds: 60})
+	require.Equal(t, []string{"@daily"}, p.Cron)
+	require.Equal(t, int64(60), p.JitterSeconds)
+
+	p2 := SchedulingPolicy{Cron: []string{"0 2 * * *"}}
+	p2.Merge(SchedulingPolicy{Cron: []string{"@daily"}})
+	require.Equal(t, []string{"0 2 * * *"}, p2.Cron)
+
+	require.Error(t, ValidateSchedulingPolicy(SchedulingPolicy{Manual: true, Cron: []string{"@daily"}}))
+	require.Error(t, ValidateSchedulingPolicy(SchedulingPolicy{Cron: []string{"bad"}}))
+}
@@ -2,24 +2,40 @@
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"strings"
 
 	"github.com/pkg/errors"
 
+	"github.com/kopia/kopia/internal/units"
 	"github.com/kopia/kopia/repo"
+	"github.com/kopia/kopia/repo/object"
 	"github.com/kopia/kopia/snapshot"
 )
 
+const noRetentionRuleMatched = "no retention rule matched"
+
+// RetentionDecision describes whether a snapshot is retained or expired by the retention policy and why.
+type RetentionDecision struct {
+	Manifest *snapshot.Manifest
+	Expire   bool
+	Reasons  []string
+}
+
 // ApplyRetentionPolicy applies retention policy to a given source by deleting expired snapshots.
 func ApplyRetentionPolicy(ctx context.Context, rep repo.RepositoryWriter, sourceInfo snapshot.SourceInfo, reallyDelete bool) ([]*snapshot.Manifest, error) {
-	snapshots, err := snapshot.ListSnapshots(ctx, rep, sourceInfo)
+	decisions, err := ComputeRetentionDecisions(ctx, rep, sourceInfo)
 	if err != nil {
-		return nil, errors.Wrap(err, "error listing snapshots")
+		return nil, err
 	}
 
-	toDelete, err := getExpiredSnapshots(ctx, rep, snapshots)
-	if err != nil {
-		return nil, errors.Wrap(err, "unable to compute snapshots to delete")
+	var toDelete []*snapshot.Manifest
+
+	for _, d := range decisions {
+		if d.Expire {
+			toDelete = append(toDelete, d.Manifest)
+		}
 	}
 
 	if reallyDelete {
@@ -33,22 +49,38 @@ func ApplyRetentionPolicy(ctx context.Context, rep repo.RepositoryWriter, source
 	return toDelete, nil
 }
 
-func getExpiredSnapshots(ctx context.Context, rep repo.Repository, snapshots []*snapshot.Manifest) ([]*snapshot.Manifest, error) {
-	var toDelete []*snapshot.Manifest
+// ComputeRetentionDecisions evaluates retention policy for all snapshots of a given source
+// without deleting anything and returns the decision made for each snapshot.
+func ComputeRetentionDecisions(ctx context.Context, rep repo.Repository, sourceInfo snapshot.SourceInfo) ([]*RetentionDecision, error) {
+	snapshots, err := snapshot.ListSnapshots(ctx, rep, sourceInfo)
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing snapshots")
+	}
+
+	decisions, err := getRetentionDecisions(ctx, rep, snapshots)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to compute snapshots to delete")
+	}
+
+	return decisions, nil
+}
+
+func getRetentionDecisions(ctx context.Context, rep repo.Repository, snapshots []*snapshot.Manifest) ([]*RetentionDecision, error) {
+	var result []*RetentionDecision
 
 	for _, snapshotGroup := range snapshot.GroupBySource(snapshots) {
-		td, err := getExpiredSnapshotsForSource(ctx, rep, snapshotGroup)
+		td, err := getRetentionDecisionsForSource(ctx, rep, snapshotGroup)
 		if err != nil {
 			return nil, err
 		}
 
-		toDelete = append(toDelete, td...)
+		result = append(result, td...)
 	}
 
-	return toDelete, nil
+	return result, nil
 }
 
-func getExpiredSnapshotsForSource(ctx context.Context, rep repo.Repository, snapshots []*snapshot.Manifest) ([]*snapshot.Manifest, error) {
+func getRetentionDecisionsForSource(ctx context.Context, rep repo.Repository, snapshots []*snapshot.Manifest) ([]*RetentionDecision, error) {
 	src := snapshots[0].Source
 
 	pol, _, err := GetEffectivePolicy(ctx, rep, src)
@@ -58,16 +90,150 @@ func getExpiredSnapshotsForSource(ctx context.Context, rep repo.Repository, snap
 
 	pol.RetentionPolicy.ComputeRetentionReasons(snapshots)
 
-	var toDelete []*snapshot.Manifest
+	var droppedBySize map[*snapshot.Manifest]bool
+
+	if limit := pol.RetentionPolicy.MaxRetainedUniqueBytes; limit != nil && *limit > 0 {
+		droppedBySize, err = applyMaxRetainedUniqueBytes(ctx, rep, snapshots, *limit)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var result []*RetentionDecision
 
 	for _, s := range snapshots {
-		if len(s.RetentionReasons) == 0 {
-			log(ctx).Debugf("  deleting %v", s.StartTime)
-			toDelete = append(toDelete, s)
-		} else {
+		d := &RetentionDecision{Manifest: s, Reasons: s.RetentionReasons}
+
+		switch {
+		case len(s.RetentionReasons) > 0 || len(s.Pins) > 0:
 			log(ctx).Debugf("  keeping %v reasons: [%v]", s.StartTime, strings.Join(s.RetentionReasons, ","))
+
+		case droppedBySize[s]:
+			d.Expire = true
+			d.Reasons = []string{fmt.Sprintf("exceeds max retained unique bytes (%v)", units.BytesStringBase10(*pol.RetentionPolicy.MaxRetainedUniqueBytes))}
+
+			log(ctx).Debugf("  deleting %v: %v", s.StartTime, d.Reasons[0])
+
+		default:
+			d.Expire = true
+			d.Reasons = []string{noRetentionRuleMatched}
+
+			log(ctx).Debugf("  deleting %v", s.StartTime)
 		}
+
+		result = append(result, d)
 	}
 
-	return toDelete, nil
+	return result, nil
+}
+
+// applyMaxRetainedUniqueBytes walks retained complete snapshots from newest to oldest, adding up sizes of files
+// not present in newer snapshots. Once the limit is exceeded, retention reasons of the remaining snapshots
+// are cleared, except for the most recent snapshot and snapshots that are pinned or retained by keep-within rule.
+// Returns the set of snapshots that were dropped.
+func applyMaxRetainedUniqueBytes(ctx context.Context, rep repo.Repository, snapshots []*snapshot.Manifest, limit int64) (map[*snapshot.Manifest]bool, error) {
+	var (
+		dropped  = map[*snapshot.Manifest]bool{}
+		counted  = map[object.ID]bool{}
+		total    int64
+		exceeded bool
+		first    = true
+	)
+
+	for _, s := range snapshot.SortByTime(snapshots, true) {
+		if s.IncompleteReason != "" || len(s.RetentionReasons) == 0 {
+			continue
+		}
+
+		protected := first || containsString(s.RetentionReasons, retentionReasonPinned) || containsString(s.RetentionReasons, retentionReasonKeepWithin)
+		first = false
+
+		if exceeded && !protected {
+			s.RetentionReasons = nil
+			dropped[s] = true
+
+			continue
+		}
+
+		added := map[object.ID]bool{}
+
+		n, err := uniqueBytes(ctx, rep, s.RootEntry, counted, added)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to compute size of snapshot %v", s.ID)
+		}
+
+		if total+n > limit && !protected {
+			exceeded = true
+			s.RetentionReasons = nil
+			dropped[s] = true
+
+			continue
+		}
+
+		total += n
+
+		for oid := range added {
+			counted[oid] = true
+		}
+	}
+
+	return dropped, nil
+}
+
+// uniqueBytes returns the total size of files under the provided entry whose objects are not
+// in 'counted' or 'added' and adds their object IDs to 'added'.
+func uniqueBytes(ctx context.Context, rep repo.Repository, e *snapshot.DirEntry, counted, added map[object.ID]bool) (int64, error) {
+	if e == nil || e.ObjectID == "" || counted[e.ObjectID] || added[e.ObjectID] {
+		return 0, nil
+	}
+
+	added[e.ObjectID] = true
+
+	if e.Type != snapshot.EntryTypeDirectory {
+		return e.FileSize, nil
+	}
+
+	entries, err := readDirEntries(ctx, rep, e.ObjectID)
+	if err != nil {
+		return 0, err
+	}
+
+	var total int64
+
+	for _, child := range entries {
+		n, err := uniqueBytes(ctx, rep, child, counted, added)
+		if err != nil {
+			return 0, err
+		}
+
+		total += n
+	}
+
+	return total, nil
+}
+
+func readDirEntries(ctx context.Context, rep repo.Repository, oid object.ID) ([]*snapshot.DirEntry, error) {
+	r, err := rep.OpenObject(ctx, oid)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to open directory %v", oid)
+	}
+	defer r.Close() //nolint:errcheck
+
+	var dir snapshot.DirManifest
+
+	if err := json.NewDecoder(r).Decode(&dir); err != nil {
+		return nil, errors.Wrapf(err, "unable to parse directory %v", oid)
+	}
+
+	return dir.Entries, nil
+}
+
+func containsString(s []string, v string) bool {
+	for _, it := range s {
+		if it == v {
+			return true
+		}
+	}
+
+	return false
 }
@@ -0,0 +1,113 @@
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kopia/kopia/internal/repotesting"
+	"github.com/kopia/kopia/repo"
+	"github.com/kopia/kopia/repo/object"
+	"github.com/kopia/kopia/snapshot"
+)
+
+func TestApplyRetentionPolicy_MaxRetainedUniqueBytes(t *testing.T) {
+	ctx, env := repotesting.NewEnvironment(t)
+
+	src := snapshot.SourceInfo{Host: "host", UserName: "user", Path: "/path"}
+
+	require.NoError(t, SetPolicy(ctx, env.RepositoryWriter, src, &Policy{
+		RetentionPolicy: RetentionPolicy{
+			KeepLatest:             intPtr(10),
+			MaxRetainedUniqueBytes: int64Ptr(250),
+		},
+	}))
+
+	common := writeTestObject(ctx, t, env.RepositoryWriter, make([]byte, 100))
+	base := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	var manifests []*snapshot.Manifest
+
+	// each snapshot has a shared file of 100 bytes and unique file of 60 bytes.
+	for i := 0; i < 5; i++ {
+		unique := writeTestObject(ctx, t, env.RepositoryWriter, []byte(fmt.Sprintf("%060d", i)))
+
+		dir, err := json.Marshal(&snapshot.DirManifest{
+			StreamType: "kopia:directory",
+			Entries: []*snapshot.DirEntry{
+				{Name: "common", Type: snapshot.EntryTypeFile, FileSize: 100, ObjectID: common},
+				{Name: "unique", Type: snapshot.EntryTypeFile, FileSize: 60, ObjectID: unique},
+			},
+		})
+		require.NoError(t, err)
+
+		m := &snapshot.Manifest{
+			Source:    src,
+			StartTime: base.Add(time.Duration(i) * time.Hour),
+			RootEntry: &snapshot.DirEntry{
+				Type:     snapshot.EntryTypeDirectory,
+				ObjectID: writeTestObject(ctx, t, env.RepositoryWriter, dir),
+			},
+		}
+
+		if i == 0 {
+			m.Pins = []string{"audit"}
+		}
+
+		_, err = snapshot.SaveSnapshot(ctx, env.RepositoryWriter, m)
+		require.NoError(t, err)
+
+		manifests = append(manifests, m)
+	}
+
+	decisions, err := ComputeRetentionDecisions(ctx, env.RepositoryWriter, src)
+	require.NoError(t, err)
+
+	got := map[time.Time]*RetentionDecision{}
+	for _, d := range decisions {
+		got[d.Manifest.StartTime] = d
+	}
+
+	require.Len(t, got, 5)
+
+	// the two newest snapshots fit in 250 bytes, the third one would exceed the limit
+	// and all older ones except the pinned one are dropped.
+	require.False(t, got[manifests[4].StartTime].Expire)
+	require.False(t, got[manifests[3].StartTime].Expire)
+	require.True(t, got[manifests[2].StartTime].Expire)
+	require.Equal(t, []string{"exceeds max retained unique bytes (250 B)"}, got[manifests[2].StartTime].Reasons)
+	require.True(t, got[manifests[1].StartTime].Expire)
+	require.False(t, got[manifests[0].StartTime].Expire)
+	require.Contains(t, got[manifests[0].StartTime].Reasons, "pinned")
+
+	deleted, err := ApplyRetentionPolicy(ctx, env.RepositoryWriter, src, true)
+	require.NoError(t, err)
+	require.Len(t, deleted, 2)
+
+	remaining, err := snapshot.ListSnapshots(ctx, env.RepositoryWriter, src)
+	require.NoError(t, err)
+	require.Len(t, remaining, 3)
+}
+
+func writeTestObject(ctx context.Context, t *testing.T, w repo.RepositoryWriter, data []byte) object.ID {
+	t.Helper()
+
+	ow := w.NewObjectWriter(ctx, object.WriterOptions{})
+	defer ow.Close()
+
+	_, err := ow.Write(data)
+	require.NoError(t, err)
+
+	oid, err := ow.Result()
+	require.NoError(t, err)
+
+	return oid
+}
+
+func int64Ptr(n int64) *int64 {
+	return &n
+}
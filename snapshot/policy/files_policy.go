@@ -1,5 +1,10 @@
 package policy
 
+import "strings"
+
+// AllExtendedAttributeNamespaces matches extended attributes in all namespaces.
+const AllExtendedAttributeNamespaces = "*"
+
 // FilesPolicy describes files to be ignored when taking snapshots.
 type FilesPolicy struct {
 	IgnoreRules         []string `json:"ignore,omitempty"`
@@ -13,6 +18,10 @@ type FilesPolicy struct {
 	MaxFileSize int64 `json:"maxFileSize,omitempty"`
 
 	OneFileSystem *bool `json:"oneFileSystem,omitempty"`
+
+	// namespaces of extended attributes (such as 'user', 'security' or 'system') to capture, all if empty.
+	ExtendedAttributeNamespaces         []string `json:"xattrNamespaces,omitempty"`
+	ExcludedExtendedAttributeNamespaces []string `json:"excludeXattrNamespaces,omitempty"`
 }
 
 // Merge applies default values from the provided policy.
@@ -36,6 +45,38 @@ func (p *FilesPolicy) Merge(src FilesPolicy) {
 	if p.OneFileSystem == nil {
 		p.OneFileSystem = src.OneFileSystem
 	}
+
+	if len(p.ExtendedAttributeNamespaces) == 0 {
+		p.ExtendedAttributeNamespaces = src.ExtendedAttributeNamespaces
+	}
+
+	if len(p.ExcludedExtendedAttributeNamespaces) == 0 {
+		p.ExcludedExtendedAttributeNamespaces = src.ExcludedExtendedAttributeNamespaces
+	}
+}
+
+// ShouldCaptureExtendedAttribute returns true if the extended attribute with a given name should be captured.
+func (p *FilesPolicy) ShouldCaptureExtendedAttribute(name string) bool {
+	ns := name
+	if i := strings.Index(name, "."); i >= 0 {
+		ns = name[0:i]
+	}
+
+	if matchesNamespace(p.ExcludedExtendedAttributeNamespaces, ns) {
+		return false
+	}
+
+	return len(p.ExtendedAttributeNamespaces) == 0 || matchesNamespace(p.ExtendedAttributeNamespaces, ns)
+}
+
+func matchesNamespace(namespaces []string, ns string) bool {
+	for _, n := range namespaces {
+		if n == ns || n == AllExtendedAttributeNamespaces {
+			return true
+		}
+	}
+
+	return false
 }
 
 // IgnoreCacheDirectoriesOrDefault gets the value of IgnoreCacheDirs or the provided default if not set.
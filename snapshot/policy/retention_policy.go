@@ -4,6 +4,8 @@
 	"fmt"
 	"time"
 
+	"github.com/pkg/errors"
+
 	"github.com/kopia/kopia/snapshot"
 )
 
@@ -13,6 +15,9 @@
 
 	// minimal number of incomplete snapshots to keep.
 	retainIncompleteSnapshotMinimumCount = 3
+
+	retentionReasonKeepWithin = "keep-within"
+	retentionReasonPinned     = "pinned"
 )
 
 // RetentionPolicy describes snapshot retention policy.
@@ -23,6 +28,24 @@ type RetentionPolicy struct {
 	KeepWeekly  *int `json:"keepWeekly,omitempty"`
 	KeepMonthly *int `json:"keepMonthly,omitempty"`
 	KeepAnnual  *int `json:"keepAnnual,omitempty"`
+
+	// KeepWithinSeconds retains all complete snapshots younger than the provided duration,
+	// measured from the most recent complete snapshot.
+	KeepWithinSeconds *int64 `json:"keepWithinSeconds,omitempty"`
+
+	// MaxRetainedUniqueBytes limits the total size of unique files retained in snapshots of a source.
+	// It is evaluated when expiring snapshots, newest first, and once exceeded all older snapshots
+	// are expired unless they are pinned or retained by KeepWithinSeconds.
+	MaxRetainedUniqueBytes *int64 `json:"maxRetainedUniqueBytes,omitempty"`
+}
+
+// KeepWithin returns the duration for which all snapshots are retained or zero if not set.
+func (r *RetentionPolicy) KeepWithin() time.Duration {
+	if r.KeepWithinSeconds == nil {
+		return 0
+	}
+
+	return time.Duration(*r.KeepWithinSeconds) * time.Second
 }
 
 // ComputeRetentionReasons computes the reasons why each snapshot is retained, based on
@@ -64,6 +87,7 @@ func (r *RetentionPolicy) ComputeRetentionReasons(manifests []*snapshot.Manifest
 		daily:   cutoffTime(r.KeepDaily, daysAgo),
 		hourly:  cutoffTime(r.KeepHourly, hoursAgo),
 		weekly:  cutoffTime(r.KeepHourly, weeksAgo),
+		within:  maxCompleteStartTime.Add(-r.KeepWithin()),
 	}
 
 	ids := make(map[string]bool)
@@ -96,6 +120,13 @@ func (r *RetentionPolicy) ComputeRetentionReasons(manifests []*snapshot.Manifest
 			break
 		}
 	}
+
+	// pinned snapshots are always retained.
+	for _, s := range sorted {
+		if len(s.Pins) > 0 {
+			s.RetentionReasons = append(s.RetentionReasons, retentionReasonPinned)
+		}
+	}
 }
 
 func (r *RetentionPolicy) getRetentionReasons(i int, s *snapshot.Manifest, cutoff *cutoffTimes, ids map[string]bool, idCounters map[string]int) []string {
@@ -143,6 +174,10 @@ func (r *RetentionPolicy) getRetentionReasons(i int, s *snapshot.Manifest, cutof
 		}
 	}
 
+	if r.KeepWithin() > 0 && !s.StartTime.Before(cutoff.within) {
+		keepReasons = append(keepReasons, retentionReasonKeepWithin)
+	}
+
 	return keepReasons
 }
 
@@ -152,6 +187,7 @@ type cutoffTimes struct {
 	daily   time.Time
 	hourly  time.Time
 	weekly  time.Time
+	within  time.Time
 }
 
 func yearsAgo(base time.Time, n int) time.Time {
@@ -217,4 +253,25 @@ func (r *RetentionPolicy) Merge(src RetentionPolicy) {
 	if r.KeepAnnual == nil {
 		r.KeepAnnual = src.KeepAnnual
 	}
+
+	if r.KeepWithinSeconds == nil {
+		r.KeepWithinSeconds = src.KeepWithinSeconds
+	}
+
+	if r.MaxRetainedUniqueBytes == nil {
+		r.MaxRetainedUniqueBytes = src.MaxRetainedUniqueBytes
+	}
+}
+
+// ValidateRetentionPolicy returns an error if the retention policy has invalid values.
+func ValidateRetentionPolicy(r RetentionPolicy) error {
+	if r.KeepWithinSeconds != nil && *r.KeepWithinSeconds < 0 {
+		return errors.New("keep-within duration cannot be negative")
+	}
+
+	if r.MaxRetainedUniqueBytes != nil && *r.MaxRetainedUniqueBytes < 0 {
+		return errors.New("max retained unique bytes cannot be negative")
+	}
+
+	return nil
 }
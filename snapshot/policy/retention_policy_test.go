@@ -194,3 +194,65 @@ func TestRetentionPolicyTest(t *testing.T) {
 		})
 	}
 }
+
+func TestRetentionPolicy_Pinned(t *testing.T) {
+	base := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	var manifests []*snapshot.Manifest
+
+	for i := 0; i < 5; i++ {
+		manifests = append(manifests, &snapshot.Manifest{
+			StartTime: base.Add(time.Duration(i) * time.Hour),
+		})
+	}
+
+	manifests[0].Pins = []string{"forever"}
+	manifests[4].Pins = []string{"release", "audit"}
+
+	(&RetentionPolicy{KeepLatest: intPtr(2)}).ComputeRetentionReasons(manifests)
+
+	want := [][]string{
+		{"pinned"},
+		{},
+		{},
+		{"latest-2"},
+		{"latest-1", "pinned"},
+	}
+
+	for i, m := range manifests {
+		if diff := cmp.Diff(m.RetentionReasons, want[i]); diff != "" {
+			t.Errorf("unexpected retention reasons for snapshot %v diff: %v", i, diff)
+		}
+	}
+}
+
+func TestRetentionPolicy_KeepWithin(t *testing.T) {
+	base := time.Date(2020, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	var manifests []*snapshot.Manifest
+
+	for i := 0; i < 5; i++ {
+		manifests = append(manifests, &snapshot.Manifest{
+			StartTime: base.AddDate(0, 0, -i),
+		})
+	}
+
+	manifests[1].IncompleteReason = "canceled"
+
+	// snapshots younger than 2 days, relative to the most recent complete one are retained.
+	(&RetentionPolicy{KeepLatest: intPtr(1), KeepWithinSeconds: int64Ptr(2 * 86400)}).ComputeRetentionReasons(manifests)
+
+	want := [][]string{
+		{"latest-1", "keep-within"},
+		{},
+		{"keep-within"},
+		{},
+		{},
+	}
+
+	for i, m := range manifests {
+		if diff := cmp.Diff(m.RetentionReasons, want[i]); diff != "" {
+			t.Errorf("unexpected retention reasons for snapshot %v diff: %v", i, diff)
+		}
+	}
+}
@@ -3,6 +3,7 @@
 import (
 	"context"
 	"fmt"
+	"hash/fnv"
 	"reflect"
 	"sort"
 	"time"
@@ -58,6 +59,17 @@ type SchedulingPolicy struct {
 	IntervalSeconds int64       `json:"intervalSeconds,omitempty"`
 	TimesOfDay      []TimeOfDay `json:"timeOfDay,omitempty"`
 	Manual          bool        `json:"manual,omitempty"`
+
+	// Cron expressions when to take snapshots, with snapshot times delayed by up to JitterSeconds.
+	Cron          []string `json:"cron,omitempty"`
+	JitterSeconds int64    `json:"jitterSeconds,omitempty"`
+
+	// RunMissed causes snapshots missed while the machine was asleep or not running to be taken as soon as possible,
+	// unless they were missed more than MaxStalenessSeconds ago.
+	RunMissed           *bool `json:"runMissed,omitempty"`
+	MaxStalenessSeconds int64 `json:"maxStalenessSeconds,omitempty"`
+
+	BandwidthWindows []BandwidthWindow `json:"bandwidth,omitempty"`
 }
 
 // Interval returns the snapshot interval or zero if not specified.
@@ -70,6 +82,123 @@ func (p *SchedulingPolicy) SetInterval(d time.Duration) {
 	p.IntervalSeconds = int64(d.Seconds())
 }
 
+// Jitter returns the maximum delay of snapshots scheduled using cron expressions.
+func (p *SchedulingPolicy) Jitter() time.Duration {
+	return time.Duration(p.JitterSeconds) * time.Second
+}
+
+// cronJitter returns the delay of a snapshot scheduled at the provided time, which is pseudo-random
+// but stable for the given key, so that repeated calculations of the next snapshot time agree.
+func (p *SchedulingPolicy) cronJitter(key string, scheduled time.Time) time.Duration {
+	if p.JitterSeconds <= 0 {
+		return 0
+	}
+
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%v/%v", key, scheduled.Unix())
+
+	return time.Duration(h.Sum64()%uint64(p.JitterSeconds)) * time.Second
+}
+
+// NextCronSnapshotTime returns the earliest time after now when a snapshot should be taken according
+// to the cron expressions of the policy, including jitter. The key (such as the snapshot source) is used
+// to spread snapshots of different sources scheduled at the same time.
+func (p *SchedulingPolicy) NextCronSnapshotTime(key string, now time.Time) (time.Time, bool) {
+	var schedules []*cronSchedule
+
+	for _, expr := range p.Cron {
+		if c, err := parseCronExpression(expr); err == nil {
+			schedules = append(schedules, c)
+		}
+	}
+
+	if len(schedules) == 0 {
+		return time.Time{}, false
+	}
+
+	// start looking before now, since a snapshot scheduled shortly before may still be waiting for its jitter.
+	t := now.Add(-p.Jitter())
+
+	for {
+		var scheduled time.Time
+
+		for _, c := range schedules {
+			if n := c.next(t); !n.IsZero() && (scheduled.IsZero() || n.Before(scheduled)) {
+				scheduled = n
+			}
+		}
+
+		if scheduled.IsZero() {
+			return time.Time{}, false
+		}
+
+		if result := scheduled.Add(p.cronJitter(key, scheduled)); result.After(now) {
+			return result, true
+		}
+
+		t = scheduled
+	}
+}
+
+// RunMissedOrDefault returns the RunMissed setting if it is set, and returns the passed default if not.
+func (p *SchedulingPolicy) RunMissedOrDefault(def bool) bool {
+	if p.RunMissed == nil {
+		return def
+	}
+
+	return *p.RunMissed
+}
+
+// MaxStaleness returns the maximum age of missed snapshots that will be caught up or zero if not limited.
+func (p *SchedulingPolicy) MaxStaleness() time.Duration {
+	return time.Duration(p.MaxStalenessSeconds) * time.Second
+}
+
+// NextScheduledTime returns the earliest time after the provided time when a snapshot is scheduled
+// using times of day or cron expressions, not including jitter.
+func (p *SchedulingPolicy) NextScheduledTime(after time.Time) (time.Time, bool) {
+	var result time.Time
+
+	for _, tod := range p.TimesOfDay {
+		t := time.Date(after.Year(), after.Month(), after.Day(), tod.Hour, tod.Minute, 0, 0, after.Location())
+		if !t.After(after) {
+			t = time.Date(after.Year(), after.Month(), after.Day()+1, tod.Hour, tod.Minute, 0, 0, after.Location())
+		}
+
+		if result.IsZero() || t.Before(result) {
+			result = t
+		}
+	}
+
+	for _, expr := range p.Cron {
+		c, err := parseCronExpression(expr)
+		if err != nil {
+			continue
+		}
+
+		if t := c.next(after); !t.IsZero() && (result.IsZero() || t.Before(result)) {
+			result = t
+		}
+	}
+
+	return result, !result.IsZero()
+}
+
+// MissedScheduledTime returns the earliest time after the last run and no later than now (less jitter) when
+// a snapshot was scheduled using times of day or cron expressions. Times older than max staleness are ignored.
+func (p *SchedulingPolicy) MissedScheduledTime(lastRun, now time.Time) (time.Time, bool) {
+	if ms := p.MaxStaleness(); ms > 0 && lastRun.Before(now.Add(-ms)) {
+		lastRun = now.Add(-ms)
+	}
+
+	t, ok := p.NextScheduledTime(lastRun)
+	if !ok || t.After(now.Add(-p.Jitter())) {
+		return time.Time{}, false
+	}
+
+	return t, true
+}
+
 // Merge applies default values from the provided policy.
 func (p *SchedulingPolicy) Merge(src SchedulingPolicy) {
 	if p.IntervalSeconds == 0 {
@@ -82,6 +211,26 @@ func (p *SchedulingPolicy) Merge(src SchedulingPolicy) {
 	if !p.Manual {
 		p.Manual = src.Manual
 	}
+
+	if len(p.Cron) == 0 {
+		p.Cron = src.Cron
+	}
+
+	if p.JitterSeconds == 0 {
+		p.JitterSeconds = src.JitterSeconds
+	}
+
+	if p.RunMissed == nil {
+		p.RunMissed = src.RunMissed
+	}
+
+	if p.MaxStalenessSeconds == 0 {
+		p.MaxStalenessSeconds = src.MaxStalenessSeconds
+	}
+
+	if len(p.BandwidthWindows) == 0 {
+		p.BandwidthWindows = src.BandwidthWindows
+	}
 }
 
 // IsManualSnapshot returns the SchedulingPolicy manual value from the given policy tree.
@@ -110,12 +259,33 @@ func SetManual(ctx context.Context, rep repo.RepositoryWriter, sourceInfo snapsh
 	return nil
 }
 
-// ValidateSchedulingPolicy returns an error if manual field is set along with scheduling fields.
+// ValidateSchedulingPolicy returns an error if manual field is set along with scheduling fields
+// or cron expressions or bandwidth windows are invalid.
 func ValidateSchedulingPolicy(p SchedulingPolicy) error {
-	if p.Manual && !reflect.DeepEqual(p, SchedulingPolicy{Manual: true}) {
+	if p.Manual && !reflect.DeepEqual(p, SchedulingPolicy{Manual: true, BandwidthWindows: p.BandwidthWindows}) {
 		return errors.New("invalid scheduling policy: manual cannot be combined with other scheduling policies")
 	}
 
+	for _, expr := range p.Cron {
+		if err := ValidateCronExpression(expr); err != nil {
+			return errors.Wrapf(err, "invalid scheduling policy: invalid cron expression %q", expr)
+		}
+	}
+
+	if p.JitterSeconds < 0 {
+		return errors.New("invalid scheduling policy: jitter must not be negative")
+	}
+
+	if p.MaxStalenessSeconds < 0 {
+		return errors.New("invalid scheduling policy: max staleness must not be negative")
+	}
+
+	for _, w := range p.BandwidthWindows {
+		if err := w.Validate(); err != nil {
+			return errors.Wrapf(err, "invalid bandwidth window %v", w)
+		}
+	}
+
 	return nil
 }
 
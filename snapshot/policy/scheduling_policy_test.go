@@ -0,0 +1,72 @@
+package policy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMissedScheduledTime(t *testing.T) {
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2021, 6, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	sp := &SchedulingPolicy{
+		TimesOfDay: []TimeOfDay{{Hour: 9, Minute: 0}, {Hour: 18, Minute: 0}},
+		Cron:       []string{"0 12 * * sat"},
+	}
+
+	next, ok := sp.NextScheduledTime(at(7, 9, 0))
+	require.True(t, ok)
+	require.Equal(t, at(7, 18, 0), next)
+
+	next, ok = sp.NextScheduledTime(at(11, 19, 0))
+	require.True(t, ok)
+	require.Equal(t, at(12, 9, 0), next)
+
+	// nothing was missed.
+	_, ok = sp.MissedScheduledTime(at(7, 9, 1), at(7, 17, 59))
+	require.False(t, ok)
+
+	// slept through 18:00 and 9:00 slots, the earliest one is reported.
+	missed, ok := sp.MissedScheduledTime(at(7, 9, 1), at(8, 10, 0))
+	require.True(t, ok)
+	require.Equal(t, at(7, 18, 0), missed)
+
+	// with max staleness, older slots are ignored.
+	sp.MaxStalenessSeconds = 3 * 3600
+	missed, ok = sp.MissedScheduledTime(at(7, 9, 1), at(8, 10, 0))
+	require.True(t, ok)
+	require.Equal(t, at(8, 9, 0), missed)
+
+	_, ok = sp.MissedScheduledTime(at(7, 9, 1), at(8, 13, 0))
+	require.False(t, ok)
+
+	// slots waiting for their jitter are not missed yet.
+	sp.MaxStalenessSeconds = 0
+	sp.JitterSeconds = 600
+	_, ok = sp.MissedScheduledTime(at(7, 9, 1), at(7, 18, 5))
+	require.False(t, ok)
+
+	_, ok = sp.MissedScheduledTime(at(7, 9, 1), at(7, 18, 11))
+	require.True(t, ok)
+
+	_, ok = (&SchedulingPolicy{IntervalSeconds: 3600}).MissedScheduledTime(at(7, 9, 1), at(9, 0, 0))
+	require.False(t, ok)
+}
+
+func TestSchedulingPolicyMerge_RunMissed(t *testing.T) {
+	yes, no := true, false
+
+	p := SchedulingPolicy{RunMissed: &no}
+	p.Merge(SchedulingPolicy{RunMissed: &yes, MaxStalenessSeconds: 60})
+	require.False(t, p.RunMissedOrDefault(true))
+	require.Equal(t, time.Minute, p.MaxStaleness())
+
+	var p2 SchedulingPolicy
+
+	require.False(t, p2.RunMissedOrDefault(false))
+	p2.Merge(SchedulingPolicy{RunMissed: &yes})
+	require.True(t, p2.RunMissedOrDefault(false))
+}
@@ -7,6 +7,7 @@
 	"os"
 	"path/filepath"
 	"runtime"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
@@ -14,6 +15,7 @@
 	"github.com/kopia/kopia/fs"
 	"github.com/kopia/kopia/fs/localfs"
 	"github.com/kopia/kopia/internal/atomicfile"
+	"github.com/kopia/kopia/snapshot"
 )
 
 const modBits = os.ModePerm | os.ModeSetgid | os.ModeSetuid | os.ModeSticky
@@ -49,6 +51,39 @@ type FilesystemOutput struct {
 
 	// SkipTimes when set to true causes restore to skip restoring modification times.
 	SkipTimes bool `json:"skipTimes"`
+
+	// SkipExtendedAttributes when set to true causes restore to skip restoring extended attributes and ACLs.
+	SkipExtendedAttributes bool `json:"skipExtendedAttributes"`
+
+	// WriteSparseFiles when set to true causes restore to skip over blocks consisting entirely of zeros
+	// instead of writing them, so that the resulting files are sparse.
+	WriteSparseFiles bool `json:"writeSparseFiles"`
+}
+
+// hardLinkTracker keeps track of hard link groups restored so far during a single restore.
+type hardLinkTracker struct {
+	mu     sync.Mutex
+	groups map[string]*restoredHardLink
+}
+
+type hardLinkTrackerContextKey struct{}
+
+// withHardLinkTracker returns the context used to restore a single snapshot, which allows files
+// of the same hard link group to be restored as hard links.
+func withHardLinkTracker(ctx context.Context) context.Context {
+	return context.WithValue(ctx, hardLinkTrackerContextKey{}, &hardLinkTracker{groups: map[string]*restoredHardLink{}})
+}
+
+func hardLinkTrackerFromContext(ctx context.Context) *hardLinkTracker {
+	t, _ := ctx.Value(hardLinkTrackerContextKey{}).(*hardLinkTracker)
+
+	return t
+}
+
+// restoredHardLink tracks the first restored file of a hard link group.
+type restoredHardLink struct {
+	done chan struct{}
+	path string // set when the file has been successfully restored
 }
 
 // Parallelizable implements restore.Output interface.
@@ -70,7 +105,7 @@ func (o *FilesystemOutput) BeginDirectory(ctx context.Context, relativePath stri
 // FinishDirectory implements restore.Output interface.
 func (o *FilesystemOutput) FinishDirectory(ctx context.Context, relativePath string, e fs.Directory) error {
 	path := filepath.Join(o.TargetPath, filepath.FromSlash(relativePath))
-	if err := o.setAttributes(path, e); err != nil {
+	if err := o.setAttributes(ctx, path, e); err != nil {
 		return errors.Wrap(err, "error setting attributes")
 	}
 
@@ -87,17 +122,93 @@ func (o *FilesystemOutput) WriteFile(ctx context.Context, relativePath string, f
 	log(ctx).Debugf("WriteFile %v (%v bytes) %v", filepath.Join(o.TargetPath, relativePath), f.Size(), f.Mode())
 	path := filepath.Join(o.TargetPath, filepath.FromSlash(relativePath))
 
+	if linked, err := o.linkToRestoredHardLink(ctx, path, f); err != nil || linked {
+		return err
+	}
+
 	if err := o.copyFileContent(ctx, path, f); err != nil {
 		return errors.Wrap(err, "error creating directory")
 	}
 
-	if err := o.setAttributes(path, f); err != nil {
+	if err := o.setAttributes(ctx, path, f); err != nil {
 		return errors.Wrap(err, "error setting attributes")
 	}
 
 	return nil
 }
 
+// linkToRestoredHardLink creates a hard link to an already restored file of the same hard link group.
+// When f is the first file of its group to be restored, it is restored normally (with any subsequent
+// files waiting for its completion), in which case false is returned.
+func (o *FilesystemOutput) linkToRestoredHardLink(ctx context.Context, path string, f fs.File) (bool, error) {
+	h, ok := f.(snapshot.HasDirEntry)
+	if !ok || h.DirEntry().HardLinkGroup == "" {
+		return false, nil
+	}
+
+	t := hardLinkTrackerFromContext(ctx)
+	if t == nil {
+		// not restoring through Entry(), files are restored independently.
+		return false, nil
+	}
+
+	group := h.DirEntry().HardLinkGroup
+
+	t.mu.Lock()
+
+	g := t.groups[group]
+	if g == nil {
+		g = &restoredHardLink{done: make(chan struct{})}
+		t.groups[group] = g
+		t.mu.Unlock()
+
+		defer close(g.done)
+
+		if err := o.copyFileContent(ctx, path, f); err != nil {
+			return true, errors.Wrap(err, "error creating directory")
+		}
+
+		if err := o.setAttributes(ctx, path, f); err != nil {
+			return true, errors.Wrap(err, "error setting attributes")
+		}
+
+		g.path = path
+
+		return true, nil
+	}
+
+	t.mu.Unlock()
+
+	<-g.done
+
+	if g.path == "" {
+		// first file of the group has failed to restore, restore the contents independently.
+		return false, nil
+	}
+
+	switch _, err := os.Lstat(path); {
+	case os.IsNotExist(err): // create link below
+	case err == nil:
+		if !o.OverwriteFiles {
+			return true, errors.Errorf("unable to create %q, it already exists", path)
+		}
+
+		if err := os.Remove(path); err != nil {
+			return true, errors.Wrap(err, "unable to remove existing file")
+		}
+	default:
+		return true, errors.Wrap(err, "failed to stat "+path)
+	}
+
+	log(ctx).Debugf("linking %v to %v", path, g.path)
+
+	if err := os.Link(g.path, path); err != nil {
+		return true, errors.Wrap(err, "error creating hard link")
+	}
+
+	return true, nil
+}
+
 // FileExists implements restore.Output interface.
 func (o *FilesystemOutput) FileExists(ctx context.Context, relativePath string, e fs.File) bool {
 	st, err := os.Lstat(filepath.Join(o.TargetPath, relativePath))
@@ -156,7 +267,7 @@ func (o *FilesystemOutput) CreateSymlink(ctx context.Context, relativePath strin
 		return errors.Wrap(err, "error creating symlink")
 	}
 
-	if err := o.setAttributes(path, e); err != nil {
+	if err := o.setAttributes(ctx, path, e); err != nil {
 		return errors.Wrap(err, "error setting attributes")
 	}
 
@@ -178,7 +289,7 @@ func (o *FilesystemOutput) SymlinkExists(ctx context.Context, relativePath strin
 }
 
 // set permission, modification time and user/group ids on targetPath.
-func (o *FilesystemOutput) setAttributes(targetPath string, e fs.Entry) error {
+func (o *FilesystemOutput) setAttributes(ctx context.Context, targetPath string, e fs.Entry) error {
 	le, err := localfs.NewEntry(targetPath)
 	if err != nil {
 		return errors.Wrap(err, "could not create local FS entry for "+targetPath)
@@ -205,6 +316,18 @@ func (o *FilesystemOutput) setAttributes(targetPath string, e fs.Entry) error {
 		}
 	}
 
+	// Extended attributes are applied before permissions, since setting POSIX ACLs also updates the permission bits.
+	if !o.SkipExtendedAttributes {
+		attrs, err := fs.GetExtendedAttributes(ctx, e)
+		if err != nil {
+			return errors.Wrap(err, "could not read extended attributes for "+targetPath)
+		}
+
+		if err = o.maybeIgnorePermissionError(setExtendedAttributes(targetPath, attrs)); err != nil {
+			return errors.Wrap(err, "could not set extended attributes on "+targetPath)
+		}
+	}
+
 	// Set file permissions from e
 	if o.shouldUpdatePermissions(le, e) {
 		if err = o.maybeIgnorePermissionError(osChmod(targetPath, e.Mode()&modBits)); err != nil {
@@ -308,6 +431,10 @@ func (o *FilesystemOutput) copyFileContent(ctx context.Context, targetPath strin
 
 	log(ctx).Debugf("copying file contents to: %v", targetPath)
 
+	if o.WriteSparseFiles {
+		return atomicfile.Write(targetPath, sparseReader{r})
+	}
+
 	return atomicfile.Write(targetPath, r)
 }
 
@@ -0,0 +1,34 @@
+package restore
+
+import (
+	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+
+	"github.com/kopia/kopia/fs"
+)
+
+// setExtendedAttributes sets the provided extended attributes on the file, without following symlinks.
+func setExtendedAttributes(path string, attrs fs.ExtendedAttributes) error {
+	for name, value := range attrs {
+		err := unix.Lsetxattr(path, name, value, 0)
+
+		switch {
+		case err == nil:
+			continue
+
+		case errors.Is(err, unix.ENOTSUP) || errors.Is(err, unix.EOPNOTSUPP):
+			// target filesystem does not support this attribute, skip it.
+			continue
+
+		case errors.Is(err, unix.EPERM) || errors.Is(err, unix.EACCES):
+			// return unwrapped error, so that it can be recognized as permission error.
+			// nolint:wrapcheck
+			return err
+
+		default:
+			return errors.Wrapf(err, "unable to set %v", name)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,12 @@
+// +build !linux
+
+package restore
+
+import (
+	"github.com/kopia/kopia/fs"
+)
+
+// setExtendedAttributes is a no-op on platforms where extended attributes are not captured.
+func setExtendedAttributes(path string, attrs fs.ExtendedAttributes) error {
+	return nil
+}
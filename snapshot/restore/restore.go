@@ -73,6 +73,8 @@ type Options struct {
 
 // Entry walks a snapshot root with given root entry and restores it to the provided output.
 func Entry(ctx context.Context, rep repo.Repository, output Output, rootEntry fs.Entry, options Options) (Stats, error) {
+	ctx = withHardLinkTracker(ctx)
+
 	c := copier{
 		output:       output,
 		q:            parallelwork.NewQueue(),
@@ -0,0 +1,115 @@
+package restore
+
+import (
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// sparseBlockSize is the granularity at which zero blocks are detected, matching typical file system block size.
+	sparseBlockSize = 4096
+
+	sparseBufferSize = 256 * sparseBlockSize
+)
+
+// sparseFile is implemented by output files that can be written sparsely, such as *os.File.
+type sparseFile interface {
+	io.WriteSeeker
+	Truncate(size int64) error
+}
+
+// sparseReader wraps a reader so that io.Copy() into a sparseFile seeks over blocks consisting
+// entirely of zeros instead of writing them, which leaves holes in the file.
+type sparseReader struct {
+	io.Reader
+}
+
+// WriteTo implements io.WriterTo.
+func (r sparseReader) WriteTo(w io.Writer) (int64, error) {
+	f, ok := w.(sparseFile)
+	if !ok {
+		// hide WriteTo() to avoid infinite recursion.
+		return io.Copy(w, struct{ io.Reader }{r.Reader})
+	}
+
+	buf := make([]byte, sparseBufferSize)
+
+	var (
+		total   int64
+		pending int64 // number of zero bytes to seek over before the next write
+	)
+
+	for {
+		n, readErr := io.ReadFull(r.Reader, buf)
+
+		for b := buf[:n]; len(b) > 0; {
+			// find the run of blocks that are either all zero or all non-zero.
+			zeroRun := isZeroBlock(b[:minInt(len(b), sparseBlockSize)])
+			runLength := 0
+
+			for runLength < len(b) {
+				blk := b[runLength:minInt(len(b), runLength+sparseBlockSize)]
+				if isZeroBlock(blk) != zeroRun {
+					break
+				}
+
+				runLength += len(blk)
+			}
+
+			if zeroRun {
+				pending += int64(runLength)
+			} else {
+				if pending > 0 {
+					if _, err := f.Seek(pending, io.SeekCurrent); err != nil {
+						return total, errors.Wrap(err, "seek error")
+					}
+
+					pending = 0
+				}
+
+				if _, err := f.Write(b[:runLength]); err != nil {
+					return total, errors.Wrap(err, "write error")
+				}
+			}
+
+			total += int64(runLength)
+			b = b[runLength:]
+		}
+
+		if errors.Is(readErr, io.EOF) || errors.Is(readErr, io.ErrUnexpectedEOF) {
+			break
+		}
+
+		if readErr != nil {
+			return total, errors.Wrap(readErr, "read error")
+		}
+	}
+
+	if pending > 0 {
+		// extend the file to its full size, trailing zeros become a hole.
+		if err := f.Truncate(total); err != nil {
+			return total, errors.Wrap(err, "unable to truncate")
+		}
+	}
+
+	return total, nil
+}
+
+func isZeroBlock(b []byte) bool {
+	for _, v := range b {
+		if v != 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
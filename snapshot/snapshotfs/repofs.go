@@ -17,7 +17,8 @@
 
 // Well-known object ID prefixes.
 const (
-	objectIDPrefixDirectory = "k"
+	objectIDPrefixDirectory          = "k"
+	objectIDPrefixExtendedAttributes = "y"
 )
 
 type repositoryEntry struct {
@@ -83,6 +84,10 @@ func (e *repositoryEntry) LocalFilesystemPath() string {
 	return ""
 }
 
+func (e *repositoryEntry) ExtendedAttributes(ctx context.Context) (fs.ExtendedAttributes, error) {
+	return readExtendedAttributes(ctx, e.repo, e.metadata)
+}
+
 type repositoryDirectory struct {
 	repositoryEntry
 	summary *fs.DirectorySummary
@@ -22,6 +22,7 @@
 	"github.com/kopia/kopia/fs/ignorefs"
 	"github.com/kopia/kopia/internal/clock"
 	"github.com/kopia/kopia/repo"
+	"github.com/kopia/kopia/repo/compression"
 	"github.com/kopia/kopia/repo/logging"
 	"github.com/kopia/kopia/repo/object"
 	"github.com/kopia/kopia/snapshot"
@@ -33,6 +34,9 @@
 
 const copyBufferSize = 128 * 1024
 
+// defaultDirectoryCompressor is used to compress directory objects in repositories that support content-level compression.
+const defaultDirectoryCompressor compression.Name = "zstd-fastest"
+
 var log = logging.GetContextLoggerFunc("snapshotfs")
 
 var errCanceled = errors.New("canceled")
@@ -71,14 +75,24 @@ type Uploader struct {
 	// How frequently to create checkpoint snapshot entries.
 	CheckpointInterval time.Duration
 
+	// BandwidthSchedule returns the maximum upload rate at a given time along with the time when it may
+	// change next, defaults to bandwidth windows of the scheduling policy of the uploaded source.
+	BandwidthSchedule BandwidthScheduleFunc
+
 	repo repo.RepositoryWriter
 
 	// stats must be allocated on heap to enforce 64-bit alignment due to atomic access on ARM.
 	stats    *snapshot.Stats
 	canceled int32
 
+	// first error returned by the repository server when a write was rejected because the storage quota was exceeded.
+	quotaErrMutex sync.Mutex
+	quotaErr      error
+
 	uploadBufPool sync.Pool
 
+	hardLinks hardLinkTracker
+
 	getTicker func(time.Duration) <-chan time.Time
 
 	// for testing only, when set will write to a given channel whenever checkpoint completes
@@ -140,7 +154,7 @@ func (u *Uploader) uploadFileInternal(ctx context.Context, parentCheckpointRegis
 
 	defer parentCheckpointRegistry.removeCheckpointCallback(f)
 
-	written, err := u.copyWithProgress(writer, file, 0, f.Size())
+	written, err := u.copyFileWithProgress(writer, file, f.Size())
 	if err != nil {
 		return nil, err
 	}
@@ -718,7 +732,7 @@ func (u *Uploader) processSubdirectories(
 				return errors.Wrapf(err, "unable to process directory %q", entry.Name())
 			}
 		} else {
-			parentDirBuilder.addEntry(de)
+			u.addEntryWithExtendedAttributes(ctx, parentDirBuilder, entry, de, policyTree, entryRelativePath)
 		}
 
 		return nil
@@ -818,7 +832,11 @@ func (u *Uploader) processNonDirectories(ctx context.Context, parentCheckpointRe
 				return errors.Wrap(err, "unable to create dir entry")
 			}
 
-			parentDirBuilder.addEntry(cachedDirEntry)
+			cachedDirEntry.HardLinkGroup = hardLinkGroupOf(entry)
+			u.hardLinks.setObjectID(cachedDirEntry.HardLinkGroup, cachedDirEntry.ObjectID)
+
+			u.addEntryWithExtendedAttributes(ctx, parentDirBuilder, entry, cachedDirEntry, policyTree, entryRelativePath)
+
 			return nil
 		}
 
@@ -830,12 +848,30 @@ func (u *Uploader) processNonDirectories(ctx context.Context, parentCheckpointRe
 
 				u.reportErrorAndMaybeCancel(err, isIgnoredError, parentDirBuilder, entryRelativePath)
 			} else {
-				parentDirBuilder.addEntry(de)
+				u.addEntryWithExtendedAttributes(ctx, parentDirBuilder, entry, de, policyTree, entryRelativePath)
 			}
 
 			return nil
 
 		case fs.File:
+			hardLinkGroup := hardLinkGroupOf(entry)
+
+			if oid := u.hardLinks.objectID(hardLinkGroup); oid != "" {
+				// contents of the same inode were already uploaded through another hard link.
+				atomic.AddInt32(&u.stats.TotalFileCount, 1)
+				atomic.AddInt64(&u.stats.TotalFileSize, entry.Size())
+
+				de, err := newDirEntry(entry, oid)
+				if err != nil {
+					return errors.Wrap(err, "unable to create dir entry")
+				}
+
+				de.HardLinkGroup = hardLinkGroup
+				u.addEntryWithExtendedAttributes(ctx, parentDirBuilder, entry, de, policyTree, entryRelativePath)
+
+				return nil
+			}
+
 			atomic.AddInt32(&u.stats.NonCachedFiles, 1)
 
 			de, err := u.uploadFileInternal(ctx, parentCheckpointRegistry, entryRelativePath, entry, policyTree.Child(entry.Name()).EffectivePolicy(), asyncWritesPerFile)
@@ -844,7 +880,9 @@ func (u *Uploader) processNonDirectories(ctx context.Context, parentCheckpointRe
 
 				u.reportErrorAndMaybeCancel(err, isIgnoredError, parentDirBuilder, entryRelativePath)
 			} else {
-				parentDirBuilder.addEntry(de)
+				de.HardLinkGroup = hardLinkGroup
+				u.hardLinks.setObjectID(hardLinkGroup, de.ObjectID)
+				u.addEntryWithExtendedAttributes(ctx, parentDirBuilder, entry, de, policyTree, entryRelativePath)
 			}
 
 			return nil
@@ -870,7 +908,7 @@ func (u *Uploader) processNonDirectories(ctx context.Context, parentCheckpointRe
 
 				u.reportErrorAndMaybeCancel(err, isIgnoredError, parentDirBuilder, entryRelativePath)
 			} else {
-				parentDirBuilder.addEntry(de)
+				u.addEntryWithExtendedAttributes(ctx, parentDirBuilder, entry, de, policyTree, entryRelativePath)
 			}
 
 			return nil
@@ -1013,6 +1051,7 @@ func (u *Uploader) writeDirManifest(ctx context.Context, dirRelativePath string,
 	writer := u.repo.NewObjectWriter(ctx, object.WriterOptions{
 		Description: "DIR:" + dirRelativePath,
 		Prefix:      objectIDPrefixDirectory,
+		Compressor:  u.directoryCompressor(),
 	})
 
 	defer writer.Close() //nolint:errcheck
@@ -1029,6 +1068,16 @@ func (u *Uploader) writeDirManifest(ctx context.Context, dirRelativePath string,
 	return oid, nil
 }
 
+// directoryCompressor returns the compressor for directory objects. Directories are only compressed at the
+// content level, because compressing them at the object level would change their object IDs.
+func (u *Uploader) directoryCompressor() compression.Name {
+	if dr, ok := u.repo.(repo.DirectRepositoryWriter); ok && dr.ContentManager().SupportsContentCompression() {
+		return defaultDirectoryCompressor
+	}
+
+	return ""
+}
+
 func (u *Uploader) reportErrorAndMaybeCancel(err error, isIgnored bool, dmb *dirManifestBuilder, entryRelativePath string) {
 	if isIgnored {
 		atomic.AddInt32(&u.stats.IgnoredErrorCount, 1)
@@ -1040,6 +1089,19 @@ func (u *Uploader) reportErrorAndMaybeCancel(err error, isIgnored bool, dmb *dir
 	u.Progress.Error(entryRelativePath, rc, isIgnored)
 	dmb.addFailedEntry(entryRelativePath, isIgnored, rc)
 
+	if errors.Is(err, repo.ErrQuotaExceeded) {
+		// all subsequent writes would fail as well, there's no point in continuing.
+		u.quotaErrMutex.Lock()
+		if u.quotaErr == nil {
+			u.quotaErr = err
+		}
+		u.quotaErrMutex.Unlock()
+
+		u.Cancel()
+
+		return
+	}
+
 	if u.FailFast && !isIgnored {
 		u.Cancel()
 	}
@@ -1087,12 +1149,30 @@ func (u *Uploader) maybeOpenDirectoryFromManifest(ctx context.Context, man *snap
 
 // Upload uploads contents of the specified filesystem entry (file or directory) to the repository and returns snapshot.Manifest with statistics.
 // Old snapshot manifest, when provided can be used to speed up uploads by utilizing hash cache.
+// The outcome of the upload is recorded in snapshot metrics and reported to notification profiles configured in the repository.
 func (u *Uploader) Upload(
 	ctx context.Context,
 	source fs.Entry,
 	policyTree *policy.Tree,
 	sourceInfo snapshot.SourceInfo,
 	previousManifests ...*snapshot.Manifest,
+) (*snapshot.Manifest, error) {
+	uploadedBytesBefore := u.repo.UploadedBytes()
+
+	s, err := u.upload(ctx, source, policyTree, sourceInfo, previousManifests...)
+
+	recordSnapshotMetrics(ctx, sourceInfo, s, err, u.stats, atomic.LoadInt64(&u.totalWrittenBytes), u.repo.UploadedBytes()-uploadedBytesBefore)
+	u.notifyUploadResult(ctx, sourceInfo, s, err)
+
+	return s, err
+}
+
+func (u *Uploader) upload(
+	ctx context.Context,
+	source fs.Entry,
+	policyTree *policy.Tree,
+	sourceInfo snapshot.SourceInfo,
+	previousManifests ...*snapshot.Manifest,
 ) (*snapshot.Manifest, error) {
 	log(ctx).Debugf("Uploading %v", sourceInfo)
 
@@ -1106,6 +1186,11 @@ func (u *Uploader) Upload(
 
 	u.stats = &snapshot.Stats{}
 	u.totalWrittenBytes = 0
+	u.quotaErr = nil
+	u.hardLinks.reset()
+
+	cancelBandwidthSchedule := u.periodicallyApplyBandwidthSchedule(ctx, u.bandwidthSchedule(policyTree))
+	defer cancelBandwidthSchedule()
 
 	var err error
 
@@ -1161,9 +1246,22 @@ func (u *Uploader) Upload(
 		return nil, err
 	}
 
+	if err := u.attachExtendedAttributes(ctx, source, s.RootEntry, &policyTree.EffectivePolicy().FilesPolicy); err != nil {
+		return nil, errors.Wrap(err, "unable to capture extended attributes of the snapshot root")
+	}
+
 	cancelScan()
 	scanWG.Wait()
 
+	if u.quotaErr != nil {
+		return nil, errors.Wrapf(u.quotaErr, "unable to complete snapshot of %v", sourceInfo)
+	}
+
+	// flush pending contents, so that they are included in uploaded bytes of this snapshot.
+	if err := u.repo.Flush(ctx); err != nil {
+		return nil, errors.Wrap(err, "error flushing uploaded contents")
+	}
+
 	s.IncompleteReason = u.incompleteReason()
 	s.EndTime = u.repo.Time()
 	s.Stats = *u.stats
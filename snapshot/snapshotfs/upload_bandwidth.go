@@ -0,0 +1,105 @@
+package snapshotfs
+
+import (
+	"context"
+	"time"
+
+	"github.com/kopia/kopia/internal/clock"
+	"github.com/kopia/kopia/repo"
+	"github.com/kopia/kopia/repo/blob/throttling"
+	"github.com/kopia/kopia/snapshot/policy"
+)
+
+// maxBandwidthScheduleRecheckInterval is the maximum time between evaluations of the bandwidth schedule,
+// which allows changes to the schedule itself to take effect during the upload.
+const maxBandwidthScheduleRecheckInterval = time.Minute
+
+// BandwidthScheduleFunc returns the maximum upload rate in bytes per second (zero means unlimited)
+// in effect at the provided time and the time when it may change next (zero if unknown).
+type BandwidthScheduleFunc func(now time.Time) (bytesPerSecond float64, nextChange time.Time)
+
+func (u *Uploader) bandwidthSchedule(policyTree *policy.Tree) BandwidthScheduleFunc {
+	if u.BandwidthSchedule != nil {
+		return u.BandwidthSchedule
+	}
+
+	sp := policyTree.EffectivePolicy().SchedulingPolicy
+
+	return sp.UploadBandwidthAt
+}
+
+// setUploadCap changes the cap imposed on the upload rate by the schedule.
+func setUploadCap(ctx context.Context, t throttling.SettableThrottler, bytesPerSecond float64) {
+	if t.UploadCap() == bytesPerSecond {
+		return
+	}
+
+	if bytesPerSecond == 0 {
+		log(ctx).Debugf("removing scheduled upload bandwidth limit")
+	} else {
+		log(ctx).Debugf("limiting upload bandwidth to %v bytes per second", bytesPerSecond)
+	}
+
+	if err := t.SetUploadCap(bytesPerSecond); err != nil {
+		log(ctx).Errorf("unable to set upload bandwidth limit: %v", err)
+	}
+}
+
+// applyBandwidthSchedule sets the upload cap in effect now and returns the time until it needs to be re-evaluated.
+func applyBandwidthSchedule(ctx context.Context, t throttling.SettableThrottler, sched BandwidthScheduleFunc) time.Duration {
+	now := clock.Now()
+	rate, nextChange := sched(now)
+
+	setUploadCap(ctx, t, rate)
+
+	wait := maxBandwidthScheduleRecheckInterval
+	if nextChange.After(now) && nextChange.Sub(now) < wait {
+		wait = nextChange.Sub(now)
+	}
+
+	return wait
+}
+
+// periodicallyApplyBandwidthSchedule limits uploads to the repository storage according to the provided schedule
+// until the returned cancelation function has been called, after which the previous upload cap is restored.
+// The schedule is applied as a cap on top of the limits configured for the repository, which remain unchanged
+// and can still be modified during the upload. Blobs are uploaded through the throttler of the storage, so the
+// schedule limits bytes actually sent to the storage and not the rate at which files are read and hashed.
+func (u *Uploader) periodicallyApplyBandwidthSchedule(ctx context.Context, sched BandwidthScheduleFunc) (cancelFunc func()) {
+	dr, ok := u.repo.(repo.DirectRepository)
+	if !ok {
+		// remote repositories upload through the server, which applies its own limits.
+		return func() {}
+	}
+
+	t := dr.Throttler()
+	previousCap := t.UploadCap()
+
+	shutdown := make(chan struct{})
+	stopped := make(chan struct{})
+	wait := applyBandwidthSchedule(ctx, t, sched)
+
+	go func() {
+		defer close(stopped)
+
+		for {
+			timer := time.NewTimer(wait)
+
+			select {
+			case <-shutdown:
+				timer.Stop()
+				return
+
+			case <-timer.C:
+				wait = applyBandwidthSchedule(ctx, t, sched)
+			}
+		}
+	}()
+
+	return func() {
+		close(shutdown)
+		<-stopped
+
+		setUploadCap(ctx, t, previousCap)
+	}
+}
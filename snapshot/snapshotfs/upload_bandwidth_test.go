@@ -0,0 +1,174 @@
+package snapshotfs
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kopia/kopia/internal/testlogging"
+	"github.com/kopia/kopia/repo"
+	"github.com/kopia/kopia/repo/blob/throttling"
+	"github.com/kopia/kopia/snapshot"
+	"github.com/kopia/kopia/snapshot/policy"
+)
+
+// uploadLimit returns the effective upload rate of the repository throttler, where zero means unlimited.
+func uploadLimit(t *testing.T, r repo.RepositoryWriter) float64 {
+	t.Helper()
+
+	th := r.(repo.DirectRepository).Throttler()
+	configured, capped := th.Limits().UploadBytesPerSecond, th.UploadCap()
+
+	if configured == 0 || (capped != 0 && capped < configured) {
+		return capped
+	}
+
+	return configured
+}
+
+func TestUpload_BandwidthScheduleChangesMidUpload(t *testing.T) {
+	ctx := testlogging.Context(t)
+	th := newUploadTestHarness(ctx, t)
+
+	defer th.cleanup()
+
+	var currentRate int64 = 1e9
+
+	u := NewUploader(th.repo)
+	u.BandwidthSchedule = func(now time.Time) (float64, time.Time) {
+		return float64(atomic.LoadInt64(&currentRate)), now.Add(10 * time.Millisecond)
+	}
+
+	var rateBefore, rateAfter float64
+
+	th.sourceDir.Subdir("d1").OnReaddir(func() {
+		rateBefore = uploadLimit(t, th.repo)
+
+		// window boundary passes, the new rate must be picked up while the upload is in progress.
+		atomic.StoreInt64(&currentRate, 5e9)
+
+		deadline := time.Now().Add(5 * time.Second)
+		for uploadLimit(t, th.repo) != 5e9 && time.Now().Before(deadline) {
+			time.Sleep(5 * time.Millisecond)
+		}
+
+		rateAfter = uploadLimit(t, th.repo)
+	})
+
+	policyTree := policy.BuildTree(nil, policy.DefaultPolicy)
+
+	_, err := u.Upload(ctx, th.sourceDir, policyTree, snapshot.SourceInfo{})
+	require.NoError(t, err)
+	require.Equal(t, 1e9, rateBefore)
+	require.Equal(t, 5e9, rateAfter)
+
+	// limit is removed after the upload.
+	require.Zero(t, uploadLimit(t, th.repo))
+}
+
+func TestUpload_BandwidthScheduleRespectsRepositoryLimit(t *testing.T) {
+	ctx := testlogging.Context(t)
+	th := newUploadTestHarness(ctx, t)
+
+	defer th.cleanup()
+
+	throttler := th.repo.(repo.DirectRepository).Throttler()
+	require.NoError(t, throttler.SetLimits(throttling.Limits{UploadBytesPerSecond: 2e9, WritesPerSecond: 100}))
+
+	var scheduledRate float64 = 1e9
+
+	u := NewUploader(th.repo)
+	u.BandwidthSchedule = func(now time.Time) (float64, time.Time) {
+		return scheduledRate, time.Time{}
+	}
+
+	var rateDuringUpload float64
+
+	th.sourceDir.Subdir("d1").OnReaddir(func() {
+		rateDuringUpload = uploadLimit(t, th.repo)
+	})
+
+	policyTree := policy.BuildTree(nil, policy.DefaultPolicy)
+
+	// scheduled rate lower than the repository limit is applied.
+	_, err := u.Upload(ctx, th.sourceDir, policyTree, snapshot.SourceInfo{})
+	require.NoError(t, err)
+	require.Equal(t, 1e9, rateDuringUpload)
+
+	// repository limits are unchanged and the cap is removed after the upload.
+	require.Equal(t, throttling.Limits{UploadBytesPerSecond: 2e9, WritesPerSecond: 100}, throttler.Limits())
+	require.Zero(t, throttler.UploadCap())
+
+	// scheduled rate higher than the repository limit or unlimited does not raise the limit.
+	for _, scheduledRate = range []float64{5e9, 0} {
+		_, err = u.Upload(ctx, th.sourceDir, policyTree, snapshot.SourceInfo{})
+		require.NoError(t, err)
+		require.Equal(t, 2e9, rateDuringUpload)
+	}
+}
+
+func TestUpload_BandwidthScheduleFromPolicy(t *testing.T) {
+	ctx := testlogging.Context(t)
+	th := newUploadTestHarness(ctx, t)
+
+	defer th.cleanup()
+
+	u := NewUploader(th.repo)
+
+	// window that is always in effect.
+	pol := *policy.DefaultPolicy
+	pol.SchedulingPolicy.BandwidthWindows = []policy.BandwidthWindow{
+		{Start: policy.TimeOfDay{Hour: 0, Minute: 0}, End: policy.TimeOfDay{Hour: 0, Minute: 0}, UploadBytesPerSecond: 1e9},
+	}
+
+	var rateDuringUpload float64
+
+	th.sourceDir.Subdir("d1").OnReaddir(func() {
+		rateDuringUpload = uploadLimit(t, th.repo)
+	})
+
+	_, err := u.Upload(ctx, th.sourceDir, policy.BuildTree(nil, &pol), snapshot.SourceInfo{})
+	require.NoError(t, err)
+	require.Equal(t, 1e9, rateDuringUpload)
+}
+
+func TestUpload_BandwidthScheduleKeepsLimitsChangedDuringUpload(t *testing.T) {
+	ctx := testlogging.Context(t)
+	th := newUploadTestHarness(ctx, t)
+
+	defer th.cleanup()
+
+	throttler := th.repo.(repo.DirectRepository).Throttler()
+
+	u := NewUploader(th.repo)
+	u.BandwidthSchedule = func(now time.Time) (float64, time.Time) {
+		return 1e9, now.Add(10 * time.Millisecond)
+	}
+
+	var (
+		limitsDuringUpload throttling.Limits
+		once               sync.Once
+	)
+
+	th.sourceDir.Subdir("d1").OnReaddir(func() {
+		// limits reported to the user do not include the schedule.
+		once.Do(func() { limitsDuringUpload = throttler.Limits() })
+
+		// limits changed while the upload is in progress, such as through the server API.
+		require.NoError(t, throttler.SetLimits(throttling.Limits{UploadBytesPerSecond: 5e8}))
+
+		// wait for the schedule to be re-applied at least once.
+		time.Sleep(50 * time.Millisecond)
+	})
+
+	_, err := u.Upload(ctx, th.sourceDir, policy.BuildTree(nil, policy.DefaultPolicy), snapshot.SourceInfo{})
+	require.NoError(t, err)
+	require.Equal(t, throttling.Limits{}, limitsDuringUpload)
+
+	// limits set during the upload survive the schedule.
+	require.Equal(t, throttling.Limits{UploadBytesPerSecond: 5e8}, throttler.Limits())
+	require.Zero(t, throttler.UploadCap())
+}
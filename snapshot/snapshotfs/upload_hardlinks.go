@@ -0,0 +1,67 @@
+package snapshotfs
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/kopia/kopia/fs"
+	"github.com/kopia/kopia/repo/object"
+)
+
+// hardLinkTracker keeps track of contents of files with multiple hard links seen during upload,
+// so that each inode is only hashed once.
+type hardLinkTracker struct {
+	mu      sync.Mutex
+	objects map[string]object.ID // hard link group => object ID of contents
+}
+
+func (t *hardLinkTracker) reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.objects = nil
+}
+
+// objectID returns the object ID of contents of the provided hard link group or empty ID if not uploaded yet.
+func (t *hardLinkTracker) objectID(group string) object.ID {
+	if group == "" {
+		return ""
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.objects[group]
+}
+
+func (t *hardLinkTracker) setObjectID(group string, oid object.ID) {
+	if group == "" {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.objects == nil {
+		t.objects = map[string]object.ID{}
+	}
+
+	t.objects[group] = oid
+}
+
+// hardLinkGroupOf returns the identifier of the group of hard links the entry belongs to
+// or empty string if the entry only has a single link.
+// The identifier is derived from the device and inode, so that it remains stable across snapshots.
+func hardLinkGroupOf(e fs.Entry) string {
+	ie, ok := e.(fs.EntryWithInodeInfo)
+	if !ok {
+		return ""
+	}
+
+	ii := ie.InodeInfo()
+	if ii.LinkCount < 2 { // nolint:gomnd
+		return ""
+	}
+
+	return fmt.Sprintf("%x:%x", e.Device().Dev, ii.Inode)
+}
@@ -0,0 +1,100 @@
+// +build !windows
+
+package snapshotfs
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kopia/kopia/fs"
+	"github.com/kopia/kopia/fs/localfs"
+	"github.com/kopia/kopia/internal/testlogging"
+	"github.com/kopia/kopia/internal/testutil"
+	"github.com/kopia/kopia/snapshot"
+	"github.com/kopia/kopia/snapshot/policy"
+	"github.com/kopia/kopia/snapshot/restore"
+)
+
+func TestUpload_HardLinks(t *testing.T) {
+	ctx := testlogging.Context(t)
+	th := newUploadTestHarness(ctx, t)
+
+	defer th.cleanup()
+
+	src := testutil.TempDirectory(t)
+
+	require.NoError(t, os.Mkdir(filepath.Join(src, "subdir"), 0o700))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(src, "file1"), []byte{1, 2, 3}, 0o600))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(src, "file2"), []byte{1, 2, 3}, 0o600))
+	require.NoError(t, os.Link(filepath.Join(src, "file1"), filepath.Join(src, "subdir", "link1")))
+
+	dir, err := localfs.Directory(src)
+	require.NoError(t, err)
+
+	man, err := NewUploader(th.repo).Upload(ctx, dir, policy.BuildTree(nil, policy.DefaultPolicy), snapshot.SourceInfo{})
+	require.NoError(t, err)
+
+	root, err := SnapshotRoot(th.repo, man)
+	require.NoError(t, err)
+
+	file1 := dirEntryAt(t, root, "file1")
+	file2 := dirEntryAt(t, root, "file2")
+	link1 := dirEntryAt(t, root, "subdir", "link1")
+
+	require.NotEmpty(t, file1.HardLinkGroup)
+	require.Equal(t, file1.HardLinkGroup, link1.HardLinkGroup)
+	require.Equal(t, file1.ObjectID, link1.ObjectID)
+	require.Empty(t, file2.HardLinkGroup)
+
+	target := testutil.TempDirectory(t)
+
+	output := &restore.FilesystemOutput{TargetPath: target}
+
+	_, err = restore.Entry(ctx, th.repo, output, root, restore.Options{})
+	require.NoError(t, err)
+
+	requireSameFile(t, true, filepath.Join(target, "file1"), filepath.Join(target, "subdir", "link1"))
+	requireSameFile(t, false, filepath.Join(target, "file1"), filepath.Join(target, "file2"))
+
+	// hard links are tracked separately by each restore, even when the output is reused.
+	target2 := testutil.TempDirectory(t)
+	output.TargetPath = target2
+
+	_, err = restore.Entry(ctx, th.repo, output, root, restore.Options{})
+	require.NoError(t, err)
+
+	requireSameFile(t, true, filepath.Join(target2, "file1"), filepath.Join(target2, "subdir", "link1"))
+	requireSameFile(t, false, filepath.Join(target, "file1"), filepath.Join(target2, "file1"))
+}
+
+func dirEntryAt(t *testing.T, root fs.Entry, names ...string) *snapshot.DirEntry {
+	t.Helper()
+
+	ctx := testlogging.Context(t)
+	e := root
+
+	for _, n := range names {
+		var err error
+
+		e, err = e.(fs.Directory).Child(ctx, n)
+		require.NoError(t, err)
+	}
+
+	return e.(snapshot.HasDirEntry).DirEntry()
+}
+
+func requireSameFile(t *testing.T, want bool, path1, path2 string) {
+	t.Helper()
+
+	fi1, err := os.Stat(path1)
+	require.NoError(t, err)
+
+	fi2, err := os.Stat(path2)
+	require.NoError(t, err)
+
+	require.Equal(t, want, os.SameFile(fi1, fi2))
+}
@@ -0,0 +1,136 @@
+package snapshotfs
+
+import (
+	"context"
+	"sync/atomic"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+
+	"github.com/kopia/kopia/snapshot"
+)
+
+// snapshot metrics tags.
+var (
+	tagKeySource = tag.MustNewKey("source")
+	tagKeyStatus = tag.MustNewKey("status")
+)
+
+const (
+	snapshotStatusComplete   = "complete"
+	snapshotStatusIncomplete = "incomplete"
+)
+
+// snapshot metrics, all tagged with the source being snapshotted.
+var (
+	metricSnapshotCount = stats.Int64(
+		"kopia/snapshot/count",
+		"Number of snapshots taken",
+		stats.UnitDimensionless,
+	)
+
+	metricSnapshotFailureCount = stats.Int64(
+		"kopia/snapshot/failure_count",
+		"Number of snapshots that failed",
+		stats.UnitDimensionless,
+	)
+
+	metricSnapshotLastTime = stats.Int64(
+		"kopia/snapshot/last_time",
+		"Time when the last snapshot of a source has finished, in seconds since the epoch",
+		stats.UnitSeconds,
+	)
+
+	metricSnapshotDuration = stats.Int64(
+		"kopia/snapshot/duration",
+		"Duration of the last snapshot of a source",
+		stats.UnitMilliseconds,
+	)
+
+	metricSnapshotHashedBytes = stats.Int64(
+		"kopia/snapshot/hashed_bytes",
+		"Number of bytes hashed while taking snapshots",
+		stats.UnitBytes,
+	)
+
+	metricSnapshotUploadedBytes = stats.Int64(
+		"kopia/snapshot/uploaded_bytes",
+		"Number of bytes uploaded to the repository while taking snapshots",
+		stats.UnitBytes,
+	)
+
+	metricSnapshotErrorCount = stats.Int64(
+		"kopia/snapshot/error_count",
+		"Number of errors encountered while taking snapshots",
+		stats.UnitDimensionless,
+	)
+
+	metricSnapshotIgnoredErrorCount = stats.Int64(
+		"kopia/snapshot/ignored_error_count",
+		"Number of ignored errors encountered while taking snapshots",
+		stats.UnitDimensionless,
+	)
+)
+
+func taggedAggregation(m stats.Measure, agg *view.Aggregation, keys ...tag.Key) *view.View {
+	return &view.View{
+		Name:        m.Name(),
+		Aggregation: agg,
+		Description: m.Description(),
+		Measure:     m,
+		TagKeys:     keys,
+	}
+}
+
+func init() {
+	if err := view.Register(
+		taggedAggregation(metricSnapshotCount, view.Count(), tagKeySource, tagKeyStatus),
+		taggedAggregation(metricSnapshotFailureCount, view.Count(), tagKeySource),
+		taggedAggregation(metricSnapshotLastTime, view.LastValue(), tagKeySource),
+		taggedAggregation(metricSnapshotDuration, view.LastValue(), tagKeySource),
+		taggedAggregation(metricSnapshotHashedBytes, view.Sum(), tagKeySource),
+		taggedAggregation(metricSnapshotUploadedBytes, view.Sum(), tagKeySource),
+		taggedAggregation(metricSnapshotErrorCount, view.Sum(), tagKeySource),
+		taggedAggregation(metricSnapshotIgnoredErrorCount, view.Sum(), tagKeySource),
+	); err != nil {
+		panic("unable to register opencensus views: " + err.Error())
+	}
+}
+
+// recordSnapshotMetrics records metrics of a snapshot of the provided source, which failed if err is not nil,
+// in which case the manifest is nil and only statistics gathered so far are recorded.
+func recordSnapshotMetrics(ctx context.Context, src snapshot.SourceInfo, man *snapshot.Manifest, err error, st *snapshot.Stats, hashedBytes, uploadedBytes int64) {
+	sourceTag := tag.Upsert(tagKeySource, src.String())
+
+	if err != nil {
+		// nolint:errcheck
+		stats.RecordWithTags(ctx, []tag.Mutator{sourceTag}, metricSnapshotFailureCount.M(1))
+	} else {
+		status := snapshotStatusComplete
+		if man.IncompleteReason != "" {
+			status = snapshotStatusIncomplete
+		}
+
+		// nolint:errcheck
+		stats.RecordWithTags(ctx,
+			[]tag.Mutator{sourceTag, tag.Upsert(tagKeyStatus, status)},
+			metricSnapshotCount.M(1))
+
+		// nolint:errcheck
+		stats.RecordWithTags(ctx,
+			[]tag.Mutator{sourceTag},
+			metricSnapshotLastTime.M(man.EndTime.Unix()),
+			metricSnapshotDuration.M(man.EndTime.Sub(man.StartTime).Milliseconds()),
+		)
+	}
+
+	// nolint:errcheck
+	stats.RecordWithTags(ctx,
+		[]tag.Mutator{sourceTag},
+		metricSnapshotHashedBytes.M(hashedBytes),
+		metricSnapshotUploadedBytes.M(uploadedBytes),
+		metricSnapshotErrorCount.M(int64(atomic.LoadInt32(&st.ErrorCount))),
+		metricSnapshotIgnoredErrorCount.M(int64(atomic.LoadInt32(&st.IgnoredErrorCount))),
+	)
+}
@@ -0,0 +1,72 @@
+package snapshotfs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opencensus.io/stats/view"
+
+	"github.com/kopia/kopia/internal/testlogging"
+	"github.com/kopia/kopia/snapshot"
+	"github.com/kopia/kopia/snapshot/policy"
+)
+
+func TestUpload_Metrics(t *testing.T) {
+	ctx := testlogging.Context(t)
+	th := newUploadTestHarness(ctx, t)
+
+	defer th.cleanup()
+
+	src := snapshot.SourceInfo{Host: "host", UserName: "user", Path: "/metrics"}
+	policyTree := policy.BuildTree(nil, policy.DefaultPolicy)
+
+	uploadedBefore := th.repo.UploadedBytes()
+
+	_, err := NewUploader(th.repo).Upload(ctx, th.sourceDir, policyTree, src)
+	require.NoError(t, err)
+
+	// all contents are uploaded by the time the upload finishes and counted towards uploaded bytes.
+	uploaded := th.repo.UploadedBytes() - uploadedBefore
+	require.NoError(t, th.repo.Flush(ctx))
+	require.Equal(t, uploaded, th.repo.UploadedBytes()-uploadedBefore)
+
+	require.Equal(t, int64(1), sumForSource(t, metricSnapshotCount.Name(), src))
+	require.Greater(t, sumForSource(t, metricSnapshotHashedBytes.Name(), src), int64(0))
+	require.Equal(t, uploaded, sumForSource(t, metricSnapshotUploadedBytes.Name(), src))
+	require.Zero(t, sumForSource(t, metricSnapshotFailureCount.Name(), src))
+
+	// failed snapshots are recorded too.
+	th.sourceDir.FailReaddir(errTest)
+
+	_, err = NewUploader(th.repo).Upload(ctx, th.sourceDir, policyTree, src)
+	require.Error(t, err)
+
+	require.Equal(t, int64(1), sumForSource(t, metricSnapshotCount.Name(), src))
+	require.Equal(t, int64(1), sumForSource(t, metricSnapshotFailureCount.Name(), src))
+}
+
+func sumForSource(t *testing.T, viewName string, src snapshot.SourceInfo) int64 {
+	t.Helper()
+
+	rows, err := view.RetrieveData(viewName)
+	require.NoError(t, err)
+
+	var result int64
+
+	for _, r := range rows {
+		for _, tg := range r.Tags {
+			if tg.Key != tagKeySource || tg.Value != src.String() {
+				continue
+			}
+
+			switch d := r.Data.(type) {
+			case *view.CountData:
+				result += d.Value
+			case *view.SumData:
+				result += int64(d.Value)
+			}
+		}
+	}
+
+	return result
+}
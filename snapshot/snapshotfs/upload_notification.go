@@ -0,0 +1,53 @@
+package snapshotfs
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/kopia/kopia/internal/units"
+	"github.com/kopia/kopia/notification"
+	"github.com/kopia/kopia/notification/sender"
+	"github.com/kopia/kopia/snapshot"
+)
+
+// notifyUploadResult reports the outcome of the upload to notification profiles configured in the repository.
+func (u *Uploader) notifyUploadResult(ctx context.Context, src snapshot.SourceInfo, man *snapshot.Manifest, err error) {
+	var (
+		severity sender.Severity
+		subject  string
+		body     strings.Builder
+	)
+
+	switch {
+	case err != nil:
+		severity = sender.SeverityError
+		subject = fmt.Sprintf("Snapshot of %v failed", src)
+
+		fmt.Fprintf(&body, "Error: %v\n", err)
+
+	case man.IncompleteReason != "":
+		severity = sender.SeverityWarning
+		subject = fmt.Sprintf("Snapshot of %v is incomplete", src)
+
+		fmt.Fprintf(&body, "Incomplete reason: %v\n", man.IncompleteReason)
+
+	case man.Stats.ErrorCount > 0:
+		severity = sender.SeverityWarning
+		subject = fmt.Sprintf("Snapshot of %v completed with errors", src)
+
+	default:
+		severity = sender.SeveritySuccess
+		subject = fmt.Sprintf("Snapshot of %v succeeded", src)
+	}
+
+	if man != nil {
+		fmt.Fprintf(&body, "Start time: %v\n", man.StartTime)
+		fmt.Fprintf(&body, "End time: %v\n", man.EndTime)
+		fmt.Fprintf(&body, "Files: %v (%v)\n", man.Stats.TotalFileCount, units.BytesStringBase10(man.Stats.TotalFileSize))
+		fmt.Fprintf(&body, "Directories: %v\n", man.Stats.TotalDirectoryCount)
+		fmt.Fprintf(&body, "Errors: %v (ignored: %v)\n", man.Stats.ErrorCount, man.Stats.IgnoredErrorCount)
+	}
+
+	notification.Send(ctx, u.repo, notification.NewMessage(u.repo, notification.EventSnapshot, severity, subject, body.String()))
+}
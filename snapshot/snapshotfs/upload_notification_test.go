@@ -0,0 +1,72 @@
+package snapshotfs
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kopia/kopia/internal/testlogging"
+	"github.com/kopia/kopia/notification/notifyprofile"
+	"github.com/kopia/kopia/notification/sender"
+	"github.com/kopia/kopia/notification/sender/webhook"
+	"github.com/kopia/kopia/snapshot"
+	"github.com/kopia/kopia/snapshot/policy"
+)
+
+func TestUpload_Notifications(t *testing.T) {
+	ctx := testlogging.Context(t)
+	th := newUploadTestHarness(ctx, t)
+
+	defer th.cleanup()
+
+	received := make(chan *sender.Message, 10)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg struct {
+			Subject  string `json:"subject"`
+			Severity string `json:"severity"`
+		}
+
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&msg))
+
+		sev, err := sender.ParseSeverity(msg.Severity)
+		require.NoError(t, err)
+
+		received <- &sender.Message{Subject: msg.Subject, Severity: sev}
+	}))
+	defer srv.Close()
+
+	require.NoError(t, notifyprofile.SaveProfile(ctx, th.repo, &notifyprofile.Config{
+		ProfileName: "test",
+		MethodConfig: sender.MethodConfig{
+			Type:   webhook.MethodWebhook,
+			Config: &webhook.Options{Endpoint: srv.URL},
+		},
+		MinSeverity: sender.SeveritySuccess,
+	}))
+
+	src := snapshot.SourceInfo{Host: "host", UserName: "user", Path: "/path"}
+	policyTree := policy.BuildTree(nil, policy.DefaultPolicy)
+
+	_, err := NewUploader(th.repo).Upload(ctx, th.sourceDir, policyTree, src)
+	require.NoError(t, err)
+
+	msg := <-received
+	require.Equal(t, sender.SeveritySuccess, msg.Severity)
+	require.Equal(t, "Snapshot of user@host:/path succeeded", msg.Subject)
+
+	th.sourceDir.FailReaddir(errTest)
+
+	u := NewUploader(th.repo)
+	u.FailFast = true
+
+	_, err = u.Upload(ctx, th.sourceDir, policyTree, src)
+	require.Error(t, err)
+
+	msg = <-received
+	require.Equal(t, sender.SeverityError, msg.Severity)
+	require.Equal(t, "Snapshot of user@host:/path failed", msg.Subject)
+}
@@ -0,0 +1,81 @@
+package snapshotfs
+
+import (
+	"io"
+
+	"github.com/pkg/errors"
+
+	"github.com/kopia/kopia/fs"
+	"github.com/kopia/kopia/repo/object"
+)
+
+// copyFileWithProgress copies the contents of the file to the object writer.
+// Holes in sparse files are skipped without reading them and written using object.HoleWriter.
+func (u *Uploader) copyFileWithProgress(dst object.Writer, file fs.Reader, length int64) (int64, error) {
+	sr, ok := file.(fs.SparseReader)
+	if !ok {
+		return u.copyWithProgress(dst, file, 0, length)
+	}
+
+	hw, ok := dst.(object.HoleWriter)
+	if !ok {
+		return u.copyWithProgress(dst, file, 0, length)
+	}
+
+	var pos int64
+
+	for pos < length {
+		start, end, err := sr.NextDataRegion(pos)
+		if errors.Is(err, io.EOF) {
+			start, end = length, length
+		} else if err != nil {
+			return pos, errors.Wrap(err, "unable to find data")
+		}
+
+		if start > length {
+			start = length
+		}
+
+		if end > length {
+			end = length
+		}
+
+		if start > pos {
+			if err := hw.WriteHole(start - pos); err != nil {
+				return pos, errors.Wrap(err, "unable to write hole")
+			}
+
+			u.Progress.HashedBytes(start - pos)
+			pos = start
+		}
+
+		if end <= start {
+			continue
+		}
+
+		if _, err := file.Seek(start, io.SeekStart); err != nil {
+			return pos, errors.Wrap(err, "seek error")
+		}
+
+		n, err := u.copyWithProgress(dst, io.LimitReader(file, end-start), pos, length)
+		pos += n
+
+		if err != nil {
+			return pos, err
+		}
+
+		if n < end-start {
+			// file was truncated while reading it.
+			return pos, nil
+		}
+	}
+
+	// copy any data appended after the size was determined.
+	if _, err := file.Seek(pos, io.SeekStart); err != nil {
+		return pos, errors.Wrap(err, "seek error")
+	}
+
+	n, err := u.copyWithProgress(dst, file, pos, length)
+
+	return pos + n, err
+}
@@ -0,0 +1,91 @@
+package snapshotfs
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kopia/kopia/fs/localfs"
+	"github.com/kopia/kopia/internal/testlogging"
+	"github.com/kopia/kopia/internal/testutil"
+	"github.com/kopia/kopia/snapshot"
+	"github.com/kopia/kopia/snapshot/policy"
+	"github.com/kopia/kopia/snapshot/restore"
+)
+
+func TestUpload_SparseFile(t *testing.T) {
+	ctx := testlogging.Context(t)
+	th := newUploadTestHarness(ctx, t)
+
+	defer th.cleanup()
+
+	const fileSize = 64 << 20
+
+	src := testutil.TempDirectory(t)
+	sparseFile := filepath.Join(src, "sparse")
+
+	data := bytes.Repeat([]byte{1, 2, 3, 4}, 100000)
+
+	f, err := os.Create(sparseFile)
+	require.NoError(t, err)
+
+	_, err = f.WriteAt(data, 8<<20)
+	require.NoError(t, err)
+
+	_, err = f.WriteAt(data, 40<<20)
+	require.NoError(t, err)
+
+	require.NoError(t, f.Truncate(fileSize))
+	require.NoError(t, f.Close())
+
+	if allocatedSize(t, sparseFile) >= fileSize/2 {
+		t.Skip("file system does not support sparse files")
+	}
+
+	want, err := ioutil.ReadFile(sparseFile)
+	require.NoError(t, err)
+
+	dir, err := localfs.Directory(src)
+	require.NoError(t, err)
+
+	u := NewUploader(th.repo)
+
+	man, err := u.Upload(ctx, dir, policy.BuildTree(nil, policy.DefaultPolicy), snapshot.SourceInfo{})
+	require.NoError(t, err)
+
+	// only the data regions should have been hashed and written.
+	require.Less(t, u.totalWrittenBytes, int64(fileSize/2))
+
+	root, err := SnapshotRoot(th.repo, man)
+	require.NoError(t, err)
+
+	target := testutil.TempDirectory(t)
+
+	_, err = restore.Entry(ctx, th.repo, &restore.FilesystemOutput{
+		TargetPath:       target,
+		WriteSparseFiles: true,
+	}, root, restore.Options{})
+	require.NoError(t, err)
+
+	restoredFile := filepath.Join(target, "sparse")
+
+	got, err := ioutil.ReadFile(restoredFile)
+	require.NoError(t, err)
+	require.Equal(t, want, got)
+	require.Less(t, allocatedSize(t, restoredFile), int64(fileSize/2))
+}
+
+func allocatedSize(t *testing.T, fname string) int64 {
+	t.Helper()
+
+	fi, err := os.Stat(fname)
+	require.NoError(t, err)
+
+	// st_blocks is always in 512-byte units.
+	return fi.Sys().(*syscall.Stat_t).Blocks * 512
+}
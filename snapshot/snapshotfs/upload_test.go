@@ -2,6 +2,7 @@
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"path/filepath"
 	"reflect"
@@ -17,10 +18,12 @@
 	"github.com/kopia/kopia/internal/clock"
 	"github.com/kopia/kopia/internal/faketime"
 	"github.com/kopia/kopia/internal/mockfs"
+	"github.com/kopia/kopia/internal/repotesting"
 	"github.com/kopia/kopia/internal/testlogging"
 	"github.com/kopia/kopia/internal/testutil"
 	"github.com/kopia/kopia/repo"
 	"github.com/kopia/kopia/repo/blob/filesystem"
+	"github.com/kopia/kopia/repo/content"
 	"github.com/kopia/kopia/repo/object"
 	"github.com/kopia/kopia/snapshot"
 	"github.com/kopia/kopia/snapshot/policy"
@@ -204,6 +207,31 @@ func TestUpload(t *testing.T) {
 	}
 }
 
+func TestUpload_DirectoryContentCompression(t *testing.T) {
+	ctx, env := repotesting.NewEnvironment(t, repotesting.Options{
+		NewRepositoryOptions: func(nro *repo.NewRepositoryOptions) {
+			nro.BlockFormat.Version = content.FormatVersion2
+			nro.BlockFormat.IndexVersion = content.IndexVersion2
+		},
+	})
+
+	sourceDir := mockfs.NewDirectory()
+	for i := 0; i < 100; i++ {
+		sourceDir.AddFile(fmt.Sprintf("file%v", i), []byte{1, 2, 3}, defaultPermissions)
+	}
+
+	man, err := NewUploader(env.RepositoryWriter).Upload(ctx, sourceDir, nil, snapshot.SourceInfo{})
+	require.NoError(t, err)
+
+	cid, isCompressed, ok := man.RootObjectID().ContentID()
+	require.True(t, ok)
+	require.False(t, isCompressed)
+
+	ci, err := env.RepositoryWriter.ContentReader().ContentInfo(ctx, cid)
+	require.NoError(t, err)
+	require.NotEqual(t, content.NoCompression, ci.GetCompressionHeaderID())
+}
+
 func TestUpload_TopLevelDirectoryReadFailure(t *testing.T) {
 	ctx := testlogging.Context(t)
 	th := newUploadTestHarness(ctx, t)
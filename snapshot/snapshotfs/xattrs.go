@@ -0,0 +1,112 @@
+package snapshotfs
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+
+	"github.com/kopia/kopia/fs"
+	"github.com/kopia/kopia/repo"
+	"github.com/kopia/kopia/repo/object"
+	"github.com/kopia/kopia/snapshot"
+	"github.com/kopia/kopia/snapshot/policy"
+)
+
+// maxInlineExtendedAttributesSize is the maximum total size of names and values of extended attributes
+// stored directly in the directory entry, larger ones are stored as a separate object.
+const maxInlineExtendedAttributesSize = 4096
+
+// attachExtendedAttributes stores extended attributes of the entry permitted by the policy in the directory entry.
+func (u *Uploader) attachExtendedAttributes(ctx context.Context, e fs.Entry, de *snapshot.DirEntry, fp *policy.FilesPolicy) error {
+	attrs, err := fs.GetExtendedAttributes(ctx, e)
+	if err != nil {
+		return errors.Wrap(err, "unable to get extended attributes")
+	}
+
+	var (
+		captured  fs.ExtendedAttributes
+		totalSize int
+	)
+
+	for name, value := range attrs {
+		if !fp.ShouldCaptureExtendedAttribute(name) {
+			continue
+		}
+
+		if captured == nil {
+			captured = fs.ExtendedAttributes{}
+		}
+
+		captured[name] = value
+		totalSize += len(name) + len(value)
+	}
+
+	if len(captured) == 0 {
+		return nil
+	}
+
+	if totalSize <= maxInlineExtendedAttributesSize {
+		de.ExtendedAttributes = &snapshot.ExtendedAttributes{Inline: captured}
+		return nil
+	}
+
+	w := u.repo.NewObjectWriter(ctx, object.WriterOptions{
+		Description: "XATTR:" + e.Name(),
+		Prefix:      objectIDPrefixExtendedAttributes,
+	})
+	defer w.Close() //nolint:errcheck
+
+	if err := json.NewEncoder(w).Encode(captured); err != nil {
+		return errors.Wrap(err, "unable to encode extended attributes")
+	}
+
+	oid, err := w.Result()
+	if err != nil {
+		return errors.Wrap(err, "unable to write extended attributes")
+	}
+
+	de.ExtendedAttributes = &snapshot.ExtendedAttributes{ObjectID: oid}
+
+	return nil
+}
+
+// addEntryWithExtendedAttributes attaches extended attributes to the directory entry and adds it to the builder.
+// Failure to capture extended attributes is reported, but the entry is still added without them.
+func (u *Uploader) addEntryWithExtendedAttributes(ctx context.Context, b *dirManifestBuilder, e fs.Entry, de *snapshot.DirEntry, policyTree *policy.Tree, entryRelativePath string) {
+	if err := u.attachExtendedAttributes(ctx, e, de, &policyTree.Child(e.Name()).EffectivePolicy().FilesPolicy); err != nil {
+		isIgnoredError := policyTree.EffectivePolicy().ErrorHandlingPolicy.IgnoreFileErrorsOrDefault(false)
+
+		u.reportErrorAndMaybeCancel(err, isIgnoredError, b, entryRelativePath)
+
+		de.ExtendedAttributes = nil
+	}
+
+	b.addEntry(de)
+}
+
+// readExtendedAttributes reads extended attributes referenced by the provided directory entry.
+func readExtendedAttributes(ctx context.Context, rep repo.Repository, de *snapshot.DirEntry) (fs.ExtendedAttributes, error) {
+	ea := de.ExtendedAttributes
+	if ea == nil {
+		return nil, nil
+	}
+
+	if ea.ObjectID == "" {
+		return ea.Inline, nil
+	}
+
+	r, err := rep.OpenObject(ctx, ea.ObjectID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to open extended attributes object: %v", ea.ObjectID)
+	}
+	defer r.Close() //nolint:errcheck
+
+	var attrs fs.ExtendedAttributes
+
+	if err := json.NewDecoder(r).Decode(&attrs); err != nil {
+		return nil, errors.Wrap(err, "unable to decode extended attributes")
+	}
+
+	return attrs, nil
+}
@@ -0,0 +1,140 @@
+package snapshotfs
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+
+	"github.com/kopia/kopia/fs"
+	"github.com/kopia/kopia/fs/localfs"
+	"github.com/kopia/kopia/internal/testlogging"
+	"github.com/kopia/kopia/internal/testutil"
+	"github.com/kopia/kopia/snapshot"
+	"github.com/kopia/kopia/snapshot/policy"
+)
+
+func TestUpload_ExtendedAttributes(t *testing.T) {
+	ctx := testlogging.Context(t)
+	th := newUploadTestHarness(ctx, t)
+
+	defer th.cleanup()
+
+	src := testutil.TempDirectory(t)
+	small := filepath.Join(src, "small")
+	excluded := filepath.Join(src, "excluded")
+
+	require.NoError(t, ioutil.WriteFile(small, []byte{1, 2, 3}, 0o600))
+	require.NoError(t, ioutil.WriteFile(excluded, []byte{4, 5, 6}, 0o600))
+
+	if err := unix.Setxattr(small, "user.test", []byte("small-value"), 0); err != nil {
+		t.Skipf("extended attributes not supported: %v", err)
+	}
+
+	require.NoError(t, unix.Setxattr(excluded, "user.excluded", []byte("x"), 0))
+
+	dir, err := localfs.Directory(src)
+	require.NoError(t, err)
+
+	policyTree := policy.BuildTree(map[string]*policy.Policy{
+		".": {
+			FilesPolicy: policy.FilesPolicy{
+				ExtendedAttributeNamespaces: []string{"user"},
+			},
+		},
+		"./excluded": {
+			FilesPolicy: policy.FilesPolicy{
+				ExcludedExtendedAttributeNamespaces: []string{"*"},
+			},
+		},
+	}, policy.DefaultPolicy)
+
+	man, err := NewUploader(th.repo).Upload(ctx, dir, policyTree, snapshot.SourceInfo{})
+	require.NoError(t, err)
+
+	root, err := SnapshotRoot(th.repo, man)
+	require.NoError(t, err)
+
+	smallEntry, err := root.(fs.Directory).Child(ctx, "small")
+	require.NoError(t, err)
+
+	attrs, err := fs.GetExtendedAttributes(ctx, smallEntry)
+	require.NoError(t, err)
+	require.Equal(t, fs.ExtendedAttributes{"user.test": []byte("small-value")}, attrs)
+	require.NotNil(t, smallEntry.(snapshot.HasDirEntry).DirEntry().ExtendedAttributes.Inline)
+
+	excludedEntry, err := root.(fs.Directory).Child(ctx, "excluded")
+	require.NoError(t, err)
+
+	attrs, err = fs.GetExtendedAttributes(ctx, excludedEntry)
+	require.NoError(t, err)
+	require.Empty(t, attrs)
+}
+
+type entryWithExtendedAttributes struct {
+	fs.Entry
+	attrs fs.ExtendedAttributes
+	err   error
+}
+
+func (e entryWithExtendedAttributes) ExtendedAttributes(ctx context.Context) (fs.ExtendedAttributes, error) {
+	return e.attrs, e.err
+}
+
+func TestUpload_LargeExtendedAttributes(t *testing.T) {
+	ctx := testlogging.Context(t)
+	th := newUploadTestHarness(ctx, t)
+
+	defer th.cleanup()
+
+	want := fs.ExtendedAttributes{
+		"user.large":                bytes.Repeat([]byte{1}, 2*maxInlineExtendedAttributesSize),
+		"system.posix_acl_access":   {2, 0, 0, 0},
+		"trusted.not-captured-here": {3},
+	}
+
+	e := entryWithExtendedAttributes{th.sourceDir.AddFile("xattr", []byte{1}, defaultPermissions), want, nil}
+	de := &snapshot.DirEntry{}
+
+	u := NewUploader(th.repo)
+	require.NoError(t, u.attachExtendedAttributes(ctx, e, de, &policy.FilesPolicy{
+		ExcludedExtendedAttributeNamespaces: []string{"trusted"},
+	}))
+
+	require.Nil(t, de.ExtendedAttributes.Inline)
+	require.NotEmpty(t, de.ExtendedAttributes.ObjectID)
+	require.False(t, IsDirectoryID(de.ExtendedAttributes.ObjectID))
+
+	delete(want, "trusted.not-captured-here")
+
+	got, err := readExtendedAttributes(ctx, th.repo, de)
+	require.NoError(t, err)
+	require.Equal(t, want, got)
+}
+
+func TestUpload_ExtendedAttributesErrorKeepsEntry(t *testing.T) {
+	ctx := testlogging.Context(t)
+	th := newUploadTestHarness(ctx, t)
+
+	defer th.cleanup()
+
+	e := entryWithExtendedAttributes{th.sourceDir.AddFile("xattr", []byte{1}, defaultPermissions), nil, errTest}
+	de := &snapshot.DirEntry{Name: "xattr"}
+	b := &dirManifestBuilder{}
+
+	u := NewUploader(th.repo)
+	u.stats = &snapshot.Stats{}
+	u.addEntryWithExtendedAttributes(ctx, b, e, de, policy.BuildTree(nil, policy.DefaultPolicy), "xattr")
+
+	dm := b.Build(time.Time{}, "")
+	require.Len(t, dm.Entries, 1)
+	require.Equal(t, "xattr", dm.Entries[0].Name)
+	require.Nil(t, dm.Entries[0].ExtendedAttributes)
+	require.Equal(t, 1, dm.Summary.FatalErrorCount)
+	require.Equal(t, int32(1), u.stats.ErrorCount)
+}
@@ -61,6 +61,20 @@ func findInUseContentIDs(ctx context.Context, rep repo.Repository, used *sync.Ma
 			used.Store(cid, nil)
 		}
 
+		// extended attributes that don't fit in the directory entry are stored as separate objects.
+		if h, ok := entry.(snapshot.HasDirEntry); ok {
+			if ea := h.DirEntry().ExtendedAttributes; ea != nil && ea.ObjectID != "" {
+				contentIDs, err := rep.VerifyObject(ctx, ea.ObjectID)
+				if err != nil {
+					return errors.Wrapf(err, "error verifying extended attributes %v", ea.ObjectID)
+				}
+
+				for _, cid := range contentIDs {
+					used.Store(cid, nil)
+				}
+			}
+		}
+
 		return nil
 	}
 
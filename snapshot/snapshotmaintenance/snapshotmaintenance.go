@@ -3,16 +3,30 @@
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/pkg/errors"
 
+	"github.com/kopia/kopia/internal/quota"
+	"github.com/kopia/kopia/notification"
+	"github.com/kopia/kopia/notification/sender"
 	"github.com/kopia/kopia/repo"
+	"github.com/kopia/kopia/repo/logging"
 	"github.com/kopia/kopia/repo/maintenance"
 	"github.com/kopia/kopia/snapshot/snapshotgc"
 )
 
+var log = logging.GetContextLoggerFunc("snapshotmaintenance")
+
 // Run runs the complete snapshot and repository maintenance.
+// Failures of maintenance tasks are reported to notification profiles configured in the repository.
 func Run(ctx context.Context, dr repo.DirectRepositoryWriter, mode maintenance.Mode, force bool, safety maintenance.SafetyParameters) error {
+	ctx = maintenance.WithTaskFailureFunc(ctx, func(ctx context.Context, taskType maintenance.TaskType, ri maintenance.RunInfo) {
+		notification.Send(ctx, dr, notification.NewMessage(dr, notification.EventMaintenance, sender.SeverityError,
+			fmt.Sprintf("Maintenance task %v failed", taskType),
+			fmt.Sprintf("Task: %v\nStart time: %v\nEnd time: %v\nError: %v\n", taskType, ri.Start, ri.End, ri.Error)))
+	})
+
 	return maintenance.RunExclusive(ctx, dr, mode, force,
 		func(runParams maintenance.RunParameters) error {
 			// run snapshot GC before full maintenance
@@ -20,8 +34,35 @@ func(runParams maintenance.RunParameters) error {
 				if _, err := snapshotgc.Run(ctx, dr, true, safety); err != nil {
 					return errors.Wrap(err, "snapshot GC failure")
 				}
+
+				// failures are reported to notification profiles by ReportRun(), but stale usage only affects
+				// enforcement of quotas, so it must not prevent the remaining maintenance from running.
+				if err := recomputeQuotaUsage(ctx, dr); err != nil {
+					log(ctx).Errorf("quota usage recomputation failure: %v", err)
+				}
 			}
 
 			return maintenance.Run(ctx, runParams, safety)
 		})
 }
+
+// recomputeQuotaUsage recomputes storage usage of users from their snapshots, which is only done
+// when storage quotas are in use, since that requires walking all snapshots.
+func recomputeQuotaUsage(ctx context.Context, dr repo.DirectRepositoryWriter) error {
+	limits, err := quota.ListLimits(ctx, dr)
+	if err != nil {
+		return errors.Wrap(err, "unable to list quotas")
+	}
+
+	if len(limits) == 0 {
+		return nil
+	}
+
+	err = maintenance.ReportRun(ctx, dr, maintenance.TaskRecomputeQuotaUsageFull, nil, func() error {
+		_, rerr := quota.Recompute(ctx, dr)
+
+		return rerr
+	})
+
+	return errors.Wrap(err, "error recomputing quota usage")
+}
@@ -12,6 +12,7 @@
 	"github.com/kopia/kopia/fs"
 	"github.com/kopia/kopia/internal/faketime"
 	"github.com/kopia/kopia/internal/mockfs"
+	"github.com/kopia/kopia/internal/quota"
 	"github.com/kopia/kopia/internal/repotesting"
 	"github.com/kopia/kopia/internal/testlogging"
 	"github.com/kopia/kopia/repo"
@@ -77,6 +78,41 @@ func TestSnapshotGCSimple(t *testing.T) {
 	t.Log("root info:", pretty.Sprint(info))
 }
 
+func TestMaintenanceRecomputesQuotaUsage(t *testing.T) {
+	ctx := testlogging.Context(t)
+	th := newTestHarness(t)
+
+	th.sourceDir.AddFile("f1", []byte{1, 2, 3, 4}, defaultPermissions)
+
+	si := snapshot.SourceInfo{
+		Host:     "host",
+		UserName: "user",
+		Path:     "/foo",
+	}
+
+	mustSnapshot(t, th.RepositoryWriter, th.sourceDir, si)
+
+	// usage is not recomputed unless quotas are in use.
+	require.NoError(t, snapshotmaintenance.Run(ctx, th.RepositoryWriter, maintenance.ModeFull, true, maintenance.SafetyFull))
+
+	usage, err := quota.ListUsage(ctx, th.RepositoryWriter)
+	require.NoError(t, err)
+	require.Empty(t, usage)
+
+	require.NoError(t, quota.SetLimit(ctx, th.RepositoryWriter, &quota.Limit{Subject: "*@host", MaxBytes: 1e9}))
+	mustFlush(t, th.RepositoryWriter)
+
+	require.NoError(t, snapshotmaintenance.Run(ctx, th.RepositoryWriter, maintenance.ModeFull, true, maintenance.SafetyFull))
+
+	u, err := quota.GetUsage(ctx, th.RepositoryWriter, "user@host")
+	require.NoError(t, err)
+	require.NotZero(t, u.Bytes)
+
+	sched, err := maintenance.GetSchedule(ctx, th.RepositoryWriter)
+	require.NoError(t, err)
+	require.NotEmpty(t, sched.Runs[maintenance.TaskRecomputeQuotaUsageFull])
+}
+
 // Test maintenance when a directory is deleted and then reused.
 // Scenario / events:
 // - create snapshot s1 on a directory d is created
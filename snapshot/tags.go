@@ -0,0 +1,65 @@
+package snapshot
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// TagLabelPrefix is the prefix of manifest labels that store snapshot tags, which allows
+// snapshots to be efficiently found by their tags.
+const TagLabelPrefix = "tag:"
+
+// ValidateTags ensures that the provided snapshot tags are valid.
+func ValidateTags(tags map[string]string) error {
+	for k, v := range tags {
+		if k == "" {
+			return errors.Errorf("tag key must not be empty")
+		}
+
+		if v == "" {
+			return errors.Errorf("value of tag %q must not be empty", k)
+		}
+
+		if strings.Contains(k, ":") {
+			return errors.Errorf("tag key %q must not contain ':'", k)
+		}
+	}
+
+	return nil
+}
+
+// ParseTags parses the provided list of tags in "key:value" format.
+func ParseTags(kvs []string) (map[string]string, error) {
+	tags := map[string]string{}
+
+	for _, kv := range kvs {
+		p := strings.Index(kv, ":")
+		if p <= 0 {
+			return nil, errors.Errorf("invalid tag %q, expected key:value", kv)
+		}
+
+		tags[kv[0:p]] = kv[p+1:]
+	}
+
+	return tags, ValidateTags(tags)
+}
+
+// tagsToLabels returns manifest labels representing the provided tags.
+func tagsToLabels(tags map[string]string) map[string]string {
+	m := map[string]string{}
+
+	for k, v := range tags {
+		m[TagLabelPrefix+k] = v
+	}
+
+	return m
+}
+
+func mergeLabels(m1, m2 map[string]string) map[string]string {
+	for k, v := range m2 {
+		m1[k] = v
+	}
+
+	return m1
+}
@@ -0,0 +1,87 @@
+package snapshot_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kopia/kopia/internal/repotesting"
+	"github.com/kopia/kopia/internal/testlogging"
+	"github.com/kopia/kopia/repo/manifest"
+	"github.com/kopia/kopia/snapshot"
+)
+
+func TestSnapshotTags(t *testing.T) {
+	ctx, env := repotesting.NewEnvironment(t)
+
+	src := snapshot.SourceInfo{
+		Host:     "host-1",
+		UserName: "user-1",
+		Path:     "/some/path",
+	}
+
+	id1 := mustSaveSnapshot(t, env.RepositoryWriter, &snapshot.Manifest{
+		Source: src,
+		Tags:   map[string]string{"purpose": "pre-upgrade", "ticket": "1234"},
+	})
+
+	man2 := &snapshot.Manifest{
+		Source: src,
+		Tags:   map[string]string{"purpose": "release-1.4"},
+	}
+
+	id2 := mustSaveSnapshot(t, env.RepositoryWriter, man2)
+
+	verifySnapshotManifestIDsWithTags(t, env, &src, nil, []manifest.ID{id1, id2})
+	verifySnapshotManifestIDsWithTags(t, env, nil, map[string]string{"purpose": "pre-upgrade"}, []manifest.ID{id1})
+	verifySnapshotManifestIDsWithTags(t, env, &src, map[string]string{"purpose": "release-1.4"}, []manifest.ID{id2})
+	verifySnapshotManifestIDsWithTags(t, env, nil, map[string]string{"purpose": "pre-upgrade", "ticket": "1234"}, []manifest.ID{id1})
+	verifySnapshotManifestIDsWithTags(t, env, nil, map[string]string{"purpose": "release-1.4", "ticket": "1234"}, nil)
+
+	// update tags of the second snapshot.
+	man2.Tags["ticket"] = "1234"
+	delete(man2.Tags, "purpose")
+
+	id2b, err := snapshot.UpdateSnapshot(ctx, env.RepositoryWriter, man2)
+	require.NoError(t, err)
+	require.NotEqual(t, id2, id2b)
+
+	_, err = snapshot.LoadSnapshot(ctx, env.RepositoryWriter, id2)
+	require.ErrorIs(t, err, snapshot.ErrSnapshotNotFound)
+
+	loaded, err := snapshot.LoadSnapshot(ctx, env.RepositoryWriter, id2b)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"ticket": "1234"}, loaded.Tags)
+
+	verifySnapshotManifestIDsWithTags(t, env, nil, map[string]string{"ticket": "1234"}, []manifest.ID{id1, id2b})
+	verifySnapshotManifestIDsWithTags(t, env, nil, map[string]string{"purpose": "release-1.4"}, nil)
+
+	_, err = snapshot.SaveSnapshot(ctx, env.RepositoryWriter, &snapshot.Manifest{
+		Source: src,
+		Tags:   map[string]string{"": "x"},
+	})
+	require.Error(t, err)
+}
+
+func TestParseTags(t *testing.T) {
+	tags, err := snapshot.ParseTags([]string{"purpose:pre-upgrade", "url:http://example.com"})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"purpose": "pre-upgrade", "url": "http://example.com"}, tags)
+
+	for _, invalid := range []string{"no-colon", ":value", "key:"} {
+		_, err := snapshot.ParseTags([]string{invalid})
+		require.Error(t, err, invalid)
+	}
+}
+
+func verifySnapshotManifestIDsWithTags(t *testing.T, env *repotesting.Environment, src *snapshot.SourceInfo, tags map[string]string, expected []manifest.ID) {
+	t.Helper()
+
+	res, err := snapshot.ListSnapshotManifestsWithTags(testlogging.Context(t), env.RepositoryWriter, src, tags)
+	require.NoError(t, err)
+
+	sortManifestIDs(res)
+	sortManifestIDs(expected)
+
+	require.Equal(t, expected, res)
+}
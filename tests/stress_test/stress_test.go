@@ -95,7 +95,7 @@ type writtenBlock struct {
 
 		dataCopy := append([]byte{}, data...)
 
-		contentID, err := bm.WriteContent(ctx, data, "")
+		contentID, err := bm.WriteContent(ctx, data, "", content.NoCompression)
 		if err != nil {
 			t.Errorf("err: %v", err)
 			return